@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"vitess.io/vitess-operator/pkg/crd"
+)
+
+var dir = flag.String("dir", "deploy/crds", "The directory the CRD files are written to.")
+
+// crd-gen writes the CRDs of the Vitess custom resources, with validation schemas generated from their Go types
+func main() {
+	flag.Parse()
+
+	files, err := crd.GenerateFiles()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(*dir, name), content, 0644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+}
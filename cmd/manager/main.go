@@ -13,6 +13,7 @@ import (
 	sdkVersion "github.com/operator-framework/operator-sdk/version"
 	"vitess.io/vitess-operator/pkg/apis"
 	"vitess.io/vitess-operator/pkg/controller"
+	"vitess.io/vitess-operator/pkg/webhook"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -22,6 +23,11 @@ import (
 
 var log = logf.Log.WithName("cmd")
 
+// The deployment exposes the metrics port as containerPort 60000
+var metricsAddr = flag.String("metrics-addr", ":60000", "The address the Prometheus metrics endpoint binds to.")
+
+var enableWebhooks = flag.Bool("enable-webhooks", true, "Serve the admission webhooks that validate and default Vitess objects, and the conversion webhook of the Vitess CRDs. Requires the operator to run in the cluster.")
+
 func printVersion() {
 	log.Info(fmt.Sprintf("Go Version: %s", runtime.Version()))
 	log.Info(fmt.Sprintf("Go OS/Arch: %s/%s", runtime.GOOS, runtime.GOARCH))
@@ -64,7 +70,7 @@ func main() {
 	defer r.Unset()
 
 	// Create a new Cmd to provide shared dependencies and start components
-	mgr, err := manager.New(cfg, manager.Options{Namespace: namespace})
+	mgr, err := manager.New(cfg, manager.Options{Namespace: namespace, MetricsBindAddress: *metricsAddr})
 	if err != nil {
 		log.Error(err, "")
 		os.Exit(1)
@@ -84,6 +90,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Setup the admission webhooks
+	if *enableWebhooks {
+		if err := webhook.AddToManager(mgr, namespace); err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
+	}
+
 	log.Info("Starting the Cmd.")
 
 	// Start the Cmd
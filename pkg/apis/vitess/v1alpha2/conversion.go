@@ -0,0 +1,13 @@
+package v1alpha2
+
+// v1alpha2 is the Hub that the other versions convert to and from. It is the stored version of the CRDs
+// and the version the controllers work with.
+
+func (*VitessCluster) Hub()        {}
+func (*VitessCell) Hub()           {}
+func (*VitessKeyspace) Hub()       {}
+func (*VitessShard) Hub()          {}
+func (*VitessTablet) Hub()         {}
+func (*VitessLockserver) Hub()     {}
+func (*VitessBackupSchedule) Hub() {}
+func (*VitessRestore) Hub()        {}
@@ -2,4 +2,7 @@ package v1alpha2
 
 type ConfigProvider interface {
 	GetTabletContainers() *TabletContainers
+	GetTabletVolumeClaim() *TabletVolumeClaim
+	GetTabletBackupStorage() *VitessBackupStorage
+	GetTabletRestorePolicy() *TabletRestorePolicy
 }
@@ -1,5 +1,13 @@
 package v1alpha2
 
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
 func (kr *KeyRange) String() string {
 	if kr.From != "" || kr.To != "" {
 		return kr.From + "-" + kr.To
@@ -8,3 +16,185 @@ func (kr *KeyRange) String() string {
 	// If no From or To is set, then default to the Vitess convention of 0 as they Keyrange string
 	return "0"
 }
+
+// IsFull returns true if the keyrange covers the whole keyspace ID range, like the shard of an unsharded keyspace
+func (kr *KeyRange) IsFull() bool {
+	return kr.From == "" && kr.To == ""
+}
+
+// Parse returns the keyspace IDs the keyrange starts and ends at. The end is excluded from the range, and an
+// empty end is the end of the keyspace ID range. Trailing zero bytes are trimmed, since 80 and 8000 are the
+// same bound. It returns an error if a bound isn't hex or if the keyrange is empty.
+func (kr *KeyRange) Parse() (start []byte, end []byte, err error) {
+	if start, err = hex.DecodeString(kr.From); err != nil {
+		return nil, nil, fmt.Errorf("invalid keyrange start %q: %s", kr.From, err)
+	}
+	if end, err = hex.DecodeString(kr.To); err != nil {
+		return nil, nil, fmt.Errorf("invalid keyrange end %q: %s", kr.To, err)
+	}
+
+	start = bytes.TrimRight(start, "\x00")
+	end = bytes.TrimRight(end, "\x00")
+
+	// An end of all zeros trims down to the empty end of the keyspace ID range, but ends before anything
+	if kr.To != "" && (len(end) == 0 || bytes.Compare(start, end) >= 0) {
+		return nil, nil, fmt.Errorf("keyrange %s is empty", kr.String())
+	}
+
+	return start, end, nil
+}
+
+// Overlaps returns true if the two keyranges have keyspace IDs in common
+func (kr *KeyRange) Overlaps(other *KeyRange) (bool, error) {
+	start, end, err := kr.Parse()
+	if err != nil {
+		return false, err
+	}
+
+	otherStart, otherEnd, err := other.Parse()
+	if err != nil {
+		return false, err
+	}
+
+	return (len(otherEnd) == 0 || bytes.Compare(start, otherEnd) < 0) &&
+		(len(end) == 0 || bytes.Compare(otherStart, end) < 0), nil
+}
+
+// GetDBFlavor returns the given container DB flavor, falling back to the flavor of the containers and then to the default
+func (containers *TabletContainers) GetDBFlavor(flavor string) string {
+	if flavor != "" {
+		return flavor
+	}
+	if containers.DBFlavor != "" {
+		return containers.DBFlavor
+	}
+	return DBFlavorDefault
+}
+
+// GetType returns the backup storage implementation, or an empty string if none is configured
+func (bs *VitessBackupStorage) GetType() BackupStorageType {
+	switch {
+	case bs.File != nil:
+		return BackupStorageTypeFile
+	case bs.S3 != nil:
+		return BackupStorageTypeS3
+	case bs.GCS != nil:
+		return BackupStorageTypeGCS
+	}
+	return ""
+}
+
+// GetFileBackupStorageRoot returns the value for the Vitess -file_backup_storage_root flag,
+// or an empty string if the storage isn't a file storage
+func (bs *VitessBackupStorage) GetFileBackupStorageRoot() string {
+	if bs.GetType() == BackupStorageTypeFile {
+		return BackupMountPath
+	}
+	return ""
+}
+
+// GetGCSCredentialsFile returns the path of the mounted GCS service account key, or an empty string if there is none
+func (bs *VitessBackupStorage) GetGCSCredentialsFile() string {
+	if bs.GetType() == BackupStorageTypeGCS && bs.GCS.CredentialsSecretRef != nil {
+		return BackupCredentialsMountPath + "/gcs.json"
+	}
+	return ""
+}
+
+// GetBackupEnv returns the environment variables that containers reading or writing backups need.
+// It is safe to call on a nil storage.
+func (bs *VitessBackupStorage) GetBackupEnv() []corev1.EnvVar {
+	if bs == nil {
+		return nil
+	}
+
+	env := []corev1.EnvVar{}
+	switch bs.GetType() {
+	case BackupStorageTypeS3:
+		// The AWS SDK used by the Vitess S3 backup storage reads these standard variables
+		if ref := bs.S3.AccessKeyIDSecretRef; ref != nil {
+			env = append(env, corev1.EnvVar{
+				Name:      "AWS_ACCESS_KEY_ID",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: ref},
+			})
+		}
+		if ref := bs.S3.SecretAccessKeySecretRef; ref != nil {
+			env = append(env, corev1.EnvVar{
+				Name:      "AWS_SECRET_ACCESS_KEY",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: ref},
+			})
+		}
+	case BackupStorageTypeGCS:
+		if file := bs.GetGCSCredentialsFile(); file != "" {
+			env = append(env, corev1.EnvVar{
+				Name:  "GOOGLE_APPLICATION_CREDENTIALS",
+				Value: file,
+			})
+		}
+	}
+
+	return env
+}
+
+// GetBackupVolumes returns the volumes that pods reading or writing backups need. It is safe to call on a nil storage.
+func (bs *VitessBackupStorage) GetBackupVolumes() []corev1.Volume {
+	if bs == nil {
+		return nil
+	}
+
+	volumes := []corev1.Volume{}
+	switch bs.GetType() {
+	case BackupStorageTypeFile:
+		volumes = append(volumes, corev1.Volume{
+			Name: "backup",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: bs.File.ClaimName,
+				},
+			},
+		})
+	case BackupStorageTypeGCS:
+		if ref := bs.GCS.CredentialsSecretRef; ref != nil {
+			volumes = append(volumes, corev1.Volume{
+				Name: "backup-credentials",
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: ref.Name,
+						Items: []corev1.KeyToPath{
+							{
+								Key:  ref.Key,
+								Path: "gcs.json",
+							},
+						},
+					},
+				},
+			})
+		}
+	}
+
+	return volumes
+}
+
+// GetBackupVolumeMounts returns the volume mounts for the volumes from GetBackupVolumes
+func (bs *VitessBackupStorage) GetBackupVolumeMounts() []corev1.VolumeMount {
+	mounts := []corev1.VolumeMount{}
+	for _, volume := range bs.GetBackupVolumes() {
+		mount := corev1.VolumeMount{
+			Name:      volume.Name,
+			MountPath: BackupMountPath,
+		}
+
+		if volume.Secret != nil {
+			mount.MountPath = BackupCredentialsMountPath
+			mount.ReadOnly = true
+		}
+
+		mounts = append(mounts, mount)
+	}
+	return mounts
+}
+
+// IsReady returns true if all of the pods of the component are ready
+func (cs *ComponentStatus) IsReady() bool {
+	return cs.ReadyReplicas >= cs.Replicas
+}
@@ -29,12 +29,15 @@ const (
 	ResourceSelectorOpDoesNotExist ResourceSelectorOperator = "DoesNotExist"
 )
 
+// DBFlavorDefault is the DB flavor of the tablet containers when neither the container nor its TabletContainers set one
+const DBFlavorDefault = "mysql56"
+
 type TabletContainers struct {
 	DBFlavor string `json:"dbFlavor,omitempty"`
 
-	MySQL *MySQLContainer `json:"mysql"`
+	MySQL *MySQLContainer `json:"mysql,omitempty"`
 
-	VTTablet *VTTabletContainer `json:"vttablet"`
+	VTTablet *VTTabletContainer `json:"vttablet,omitempty"`
 }
 
 type MySQLContainer struct {
@@ -53,8 +56,85 @@ type VTTabletContainer struct {
 	DBFlavor string `json:"dbFlavor,omitempty"`
 }
 
+// ComponentStatus is the observed state of the pods of a Deployment
+type ComponentStatus struct {
+	// Replicas is the number of pods the component should have
+	Replicas int32 `json:"replicas"`
+
+	// ReadyReplicas is the number of pods of the component that are ready
+	ReadyReplicas int32 `json:"readyReplicas"`
+
+	// UpdatedReplicas is the number of pods of the component that run the current pod template
+	UpdatedReplicas int32 `json:"updatedReplicas"`
+}
+
 type KeyRange struct {
 	From string `json:"from,omitempty"`
 
 	To string `json:"to,omitempty"`
 }
+
+// VitessBackupStorage configures where vttablet stores its backups and where vtctld finds them.
+// Only one storage implementation can be set.
+type VitessBackupStorage struct {
+	File *FileBackupStorage `json:"file,omitempty"`
+
+	S3 *S3BackupStorage `json:"s3,omitempty"`
+
+	GCS *GCSBackupStorage `json:"gcs,omitempty"`
+}
+
+type BackupStorageType string
+
+const (
+	BackupStorageTypeFile BackupStorageType = "file"
+	BackupStorageTypeS3   BackupStorageType = "s3"
+	BackupStorageTypeGCS  BackupStorageType = "gcs"
+)
+
+// FileBackupStorage stores backups in a PersistentVolumeClaim that is mounted into every tablet and vtctld pod.
+// The claim must be ReadWriteMany unless all of them run on a single node, so this is mostly useful for testing.
+type FileBackupStorage struct {
+	ClaimName string `json:"claimName"`
+}
+
+// S3BackupStorage stores backups in an S3 bucket, or in a bucket of any S3-compatible object store
+// when Endpoint is set
+type S3BackupStorage struct {
+	Bucket string `json:"bucket"`
+	Region string `json:"region,omitempty"`
+
+	// Endpoint is the URL of an S3-compatible object store, e.g. Minio or Ceph
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Root is the prefix of the backup objects within the bucket
+	Root string `json:"root,omitempty"`
+
+	// ForcePathStyle puts the bucket name in the path instead of the host name, which most S3-compatible stores need
+	ForcePathStyle bool `json:"forcePathStyle,omitempty"`
+
+	// AccessKeyIDSecretRef and SecretAccessKeySecretRef select the Secret keys holding the credentials.
+	// Without them the AWS SDK falls back to its default credential chain, e.g. the instance profile of the node.
+	AccessKeyIDSecretRef     *corev1.SecretKeySelector `json:"accessKeyIDSecretRef,omitempty"`
+	SecretAccessKeySecretRef *corev1.SecretKeySelector `json:"secretAccessKeySecretRef,omitempty"`
+}
+
+// GCSBackupStorage stores backups in a Google Cloud Storage bucket
+type GCSBackupStorage struct {
+	Bucket string `json:"bucket"`
+
+	// Root is the prefix of the backup objects within the bucket
+	Root string `json:"root,omitempty"`
+
+	// CredentialsSecretRef selects the Secret key holding a service account JSON key.
+	// Without it the default credentials of the node are used.
+	CredentialsSecretRef *corev1.SecretKeySelector `json:"credentialsSecretRef,omitempty"`
+}
+
+const (
+	// BackupMountPath is where file backup storage is mounted in generated pods
+	BackupMountPath = "/vt/backups"
+
+	// BackupCredentialsMountPath is where backup storage credential files are mounted in generated pods
+	BackupCredentialsMountPath = "/vt/backup-credentials"
+)
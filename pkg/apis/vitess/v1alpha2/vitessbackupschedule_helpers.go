@@ -0,0 +1,38 @@
+package v1alpha2
+
+// GetKeyspaceShard returns the keyspace/shard name that Vitess uses for the shard being backed up
+func (bs *VitessBackupSchedule) GetKeyspaceShard() string {
+	return bs.Spec.Keyspace + "/" + bs.Spec.Shard
+}
+
+// GetRetention returns the retention policy, falling back to the defaults if none is set
+func (bs *VitessBackupSchedule) GetRetention() *BackupRetentionPolicy {
+	if bs.Spec.Retention != nil {
+		return bs.Spec.Retention
+	}
+	return &BackupRetentionPolicy{}
+}
+
+// GetSuccessfulJobsHistoryLimit returns how many finished backup Jobs that succeeded are kept
+func (bs *VitessBackupSchedule) GetSuccessfulJobsHistoryLimit() int32 {
+	if bs.Spec.SuccessfulJobsHistoryLimit != nil {
+		return *bs.Spec.SuccessfulJobsHistoryLimit
+	}
+	return SuccessfulJobsHistoryLimitDefault
+}
+
+// GetFailedJobsHistoryLimit returns how many finished backup Jobs that failed are kept
+func (bs *VitessBackupSchedule) GetFailedJobsHistoryLimit() int32 {
+	if bs.Spec.FailedJobsHistoryLimit != nil {
+		return *bs.Spec.FailedJobsHistoryLimit
+	}
+	return FailedJobsHistoryLimitDefault
+}
+
+// GetKeepLast returns how many of the newest backups are always kept
+func (policy *BackupRetentionPolicy) GetKeepLast() int32 {
+	if policy.KeepLast != nil {
+		return *policy.KeepLast
+	}
+	return BackupKeepLastDefault
+}
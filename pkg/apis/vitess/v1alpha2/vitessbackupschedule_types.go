@@ -0,0 +1,100 @@
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
+
+// VitessBackupScheduleSpec defines the desired state of VitessBackupSchedule
+type VitessBackupScheduleSpec struct {
+	// Cluster is the name of the VitessCluster in the same namespace. It must have backup storage configured.
+	Cluster string `json:"cluster"`
+
+	Keyspace string `json:"keyspace"`
+
+	// Shard is the Vitess name of the shard, e.g. "0" or "-80"
+	Shard string `json:"shard"`
+
+	// Schedule is a standard 5 field cron schedule in UTC, e.g. "0 3 * * *"
+	Schedule string `json:"schedule"`
+
+	Retention *BackupRetentionPolicy `json:"retention,omitempty"`
+
+	// Suspend stops new backups from being scheduled. A backup that is already running is not affected.
+	Suspend bool `json:"suspend,omitempty"`
+
+	// SuccessfulJobsHistoryLimit is how many finished backup Jobs that succeeded are kept, along with their pods.
+	// Like CronJob, it defaults to 3.
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
+
+	// FailedJobsHistoryLimit is how many finished backup Jobs that failed are kept, along with their pods.
+	// Like CronJob, it defaults to 1.
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
+}
+
+// BackupRetentionPolicy decides which backups of the shard are removed after each successful backup.
+// A backup is removed if it is not one of the KeepLast newest backups, or if it is older than MaxAge.
+// The newest backup is never removed.
+type BackupRetentionPolicy struct {
+	KeepLast *int32 `json:"keepLast,omitempty"`
+
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+}
+
+const BackupKeepLastDefault int32 = 7
+
+const (
+	SuccessfulJobsHistoryLimitDefault int32 = 3
+	FailedJobsHistoryLimitDefault     int32 = 1
+)
+
+// VitessBackupScheduleStatus defines the observed state of VitessBackupSchedule
+type VitessBackupScheduleStatus struct {
+	// LastScheduleTime is the scheduled time of the last backup that was started
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// LastSuccessfulTime is when the last successful backup finished
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+
+	// ActiveJob is the name of the Job running the current backup
+	ActiveJob string `json:"activeJob,omitempty"`
+
+	// LastBackupTablet is the alias of the tablet the last backup was taken from
+	LastBackupTablet string `json:"lastBackupTablet,omitempty"`
+
+	// LastBackup is the name of the last successful backup
+	LastBackup string `json:"lastBackup,omitempty"`
+
+	// Backups lists the backups of the shard that were kept after the last prune, oldest first
+	Backups []string `json:"backups,omitempty"`
+
+	// Error is set when the last backup or prune failed, or when the schedule can't run at all
+	Error string `json:"error,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VitessBackupSchedule is the Schema for the vitessbackupschedules API
+// +k8s:openapi-gen=true
+type VitessBackupSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VitessBackupScheduleSpec   `json:"spec,omitempty"`
+	Status VitessBackupScheduleStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VitessBackupScheduleList contains a list of VitessBackupSchedule
+type VitessBackupScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VitessBackupSchedule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VitessBackupSchedule{}, &VitessBackupScheduleList{})
+}
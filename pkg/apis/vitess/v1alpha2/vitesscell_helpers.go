@@ -16,6 +16,12 @@ func (cell *VitessCell) Lockserver() *VitessLockserver {
 	return cell.Spec.Lockserver
 }
 
+// TopoLockserver returns the global lockserver with the credentials of the cell lockserver merged in. The
+// components of the cell talk to both lockservers with its topo flags, env and volumes.
+func (cell *VitessCell) TopoLockserver() *VitessLockserver {
+	return cell.Cluster().Lockserver().MergeTopoCredentials(cell.Lockserver())
+}
+
 func (cell *VitessCell) GetScopedName(extra ...string) string {
 	return strings.Join(append(
 		[]string{
@@ -10,21 +10,21 @@ import (
 
 // VitessCellSpec defines the desired state of VitessCell
 type VitessCellSpec struct {
-	Lockserver *VitessLockserver `json:"lockserver"`
+	Lockserver *VitessLockserver `json:"lockserver,omitempty"`
 
 	LockserverRef *corev1.LocalObjectReference `json:"lockserverRef,omitempty"`
 
-	Defaults *VitessCellDefaults `json:"defaults"`
+	Defaults *VitessCellDefaults `json:"defaults,omitempty"`
 
-	MySQLProtocol *VitessCellMySQLProtocol `json:"mysqlProtocol"`
+	MySQLProtocol *VitessCellMySQLProtocol `json:"mysqlProtocol,omitempty"`
 
-	VTGate []VTComponent `json:"vtgate"`
+	VTGate []VTComponent `json:"vtgate,omitempty"`
 
-	VTWorker []VTComponent `json:"vtworker"`
+	VTWorker []VTComponent `json:"vtworker,omitempty"`
 
-	VTCtld []VTComponent `json:"vtctld"`
+	VTCtld []VTComponent `json:"vtctld,omitempty"`
 
-	Orchestrator []VTComponent `json:"orchestrator"`
+	Orchestrator []VTComponent `json:"orchestrator,omitempty"`
 
 	// parent is unexported on purpose.
 	// It should only be used during processing and never stored
@@ -35,8 +35,16 @@ type VitessCellParents struct {
 	Cluster *VitessCluster
 }
 
+// VitessCellStatus is the observed state of the components of a cell.
+// It is recorded in the VitessCluster status.
+type VitessCellStatus struct {
+	VTCtld ComponentStatus `json:"vtctld,omitempty"`
+
+	VTGate ComponentStatus `json:"vtgate,omitempty"`
+}
+
 type VitessCellDefaults struct {
-	Replicas *int32 `json:"replicas"`
+	Replicas *int32 `json:"replicas,omitempty"`
 
 	Image string `json:"image"`
 }
@@ -44,6 +52,7 @@ type VitessCellDefaults struct {
 type VitessCellMySQLProtocol struct {
 	AuthType VitessMySQLAuthType `json:"authType,omitempty"`
 
+	// Username is encoded as image, which the stored v1alpha2 objects already use. v1beta1 encodes it as username.
 	Username string `json:"image,omitempty"`
 
 	// Password string `json:"password"`
@@ -63,7 +72,7 @@ type VTGate struct {
 
 	Credentials VTGateCredentials `json:"credentials,omitempty"`
 
-	Cells []string `json:"cells:`
+	Cells []string `json:"cells,omitempty"`
 
 	CellSelector *CellSelector `json:"cellSelector,omitempty"`
 }
@@ -1,7 +1,12 @@
 package v1alpha2
 
 import (
+	"fmt"
+	"sort"
 	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
 )
 
 func (cluster *VitessCluster) Cells() []*VitessCell {
@@ -16,8 +21,11 @@ func (cluster *VitessCluster) Keyspaces() []*VitessKeyspace {
 	return cluster.Spec.Keyspaces
 }
 
+// EmbedKeyspaceCopy embeds a copy of a standalone VitessKeyspace in the cluster
 func (cluster *VitessCluster) EmbedKeyspaceCopy(keyspace *VitessKeyspace) {
-	cluster.Spec.Keyspaces = append(cluster.Spec.Keyspaces, keyspace.DeepCopy())
+	embedded := keyspace.DeepCopy()
+	embedded.Spec.standalone = true
+	cluster.Spec.Keyspaces = append(cluster.Spec.Keyspaces, embedded)
 }
 
 func (cluster *VitessCluster) Shards() []*VitessShard {
@@ -40,6 +48,26 @@ func (cluster *VitessCluster) Lockserver() *VitessLockserver {
 	return cluster.Spec.Lockserver
 }
 
+func (cluster *VitessCluster) BackupStorage() *VitessBackupStorage {
+	return cluster.Spec.BackupStorage
+}
+
+// MySQLExporter returns the configuration of the mysqld_exporter sidecar, or nil if it is disabled
+func (cluster *VitessCluster) MySQLExporter() *MySQLExporter {
+	if cluster.Spec.Monitoring == nil {
+		return nil
+	}
+	return cluster.Spec.Monitoring.MySQLExporter
+}
+
+// ServiceMonitor returns the configuration of the generated ServiceMonitor, or nil if none should be generated
+func (cluster *VitessCluster) ServiceMonitor() *VitessServiceMonitor {
+	if cluster.Spec.Monitoring == nil {
+		return nil
+	}
+	return cluster.Spec.Monitoring.ServiceMonitor
+}
+
 func (cluster *VitessCluster) GetCellByID(cellID string) *VitessCell {
 	for _, cell := range cluster.Cells() {
 		if cell.GetName() == cellID {
@@ -62,6 +90,21 @@ func (cluster *VitessCluster) GetTabletServiceName() string {
 	return cluster.GetScopedName("tab")
 }
 
+// GetVtctldAddress returns the address of the vtctld web port in the first cell of the cluster
+func (cluster *VitessCluster) GetVtctldAddress() string {
+	return cluster.getVtctldHost() + ":15000"
+}
+
+// GetVtctldGRPCAddress returns the address of the vtctld gRPC port in the first cell of the cluster,
+// which is what vtctlclient connects to
+func (cluster *VitessCluster) GetVtctldGRPCAddress() string {
+	return cluster.getVtctldHost() + ":15999"
+}
+
+func (cluster *VitessCluster) getVtctldHost() string {
+	return fmt.Sprintf("%s.%s", cluster.Cells()[0].GetScopedName("vtctld"), cluster.GetNamespace())
+}
+
 func (cluster *VitessCluster) Phase() ClusterPhase {
 	return cluster.Status.Phase
 }
@@ -82,3 +125,108 @@ func (cluster *VitessCluster) AllTabletsReady() bool {
 	}
 	return true
 }
+
+func (cluster *VitessCluster) GetCondition(t ClusterConditionType) *VitessClusterCondition {
+	for i := range cluster.Status.Conditions {
+		if cluster.Status.Conditions[i].Type == t {
+			return &cluster.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetCondition sets the given condition, only moving the transition time if the status changed
+// and the update time if anything changed
+func (cluster *VitessCluster) SetCondition(t ClusterConditionType, status corev1.ConditionStatus, reason, message string) {
+	cond := cluster.GetCondition(t)
+	if cond == nil {
+		cluster.Status.Conditions = append(cluster.Status.Conditions, VitessClusterCondition{Type: t})
+		cond = &cluster.Status.Conditions[len(cluster.Status.Conditions)-1]
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	if cond.Status != status {
+		cond.Status = status
+		cond.LastTransitionTime = now
+		cond.LastUpdateTime = now
+	}
+	if cond.Reason != reason || cond.Message != message {
+		cond.Reason = reason
+		cond.Message = message
+		cond.LastUpdateTime = now
+	}
+}
+
+func (cluster *VitessCluster) IsConditionTrue(t ClusterConditionType) bool {
+	cond := cluster.GetCondition(t)
+	return cond != nil && cond.Status == corev1.ConditionTrue
+}
+
+func (cluster *VitessCluster) IsCellRegistered(name string) bool {
+	for _, registered := range cluster.Status.RegisteredCells {
+		if registered == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetCellRegistered adds or removes the cell from the registered cells, keeping them sorted
+func (cluster *VitessCluster) SetCellRegistered(name string, registered bool) {
+	cells := []string{}
+	for _, cell := range cluster.Status.RegisteredCells {
+		if cell != name {
+			cells = append(cells, cell)
+		}
+	}
+
+	if registered {
+		cells = append(cells, name)
+		sort.Strings(cells)
+	}
+
+	if len(cells) == 0 {
+		cells = nil
+	}
+
+	cluster.Status.RegisteredCells = cells
+}
+
+// GetShardStatus returns the status recorded for the shard, or an empty status if there is none
+func (cluster *VitessCluster) GetShardStatus(shard *VitessShard) *VitessShardStatus {
+	if status, ok := cluster.Status.Shards[shard.GetKeyspaceShard()]; ok && status != nil {
+		return status
+	}
+	return &VitessShardStatus{}
+}
+
+func (cluster *VitessCluster) SetShardStatus(shard *VitessShard, status *VitessShardStatus) {
+	if cluster.Status.Shards == nil {
+		cluster.Status.Shards = map[string]*VitessShardStatus{}
+	}
+	cluster.Status.Shards[shard.GetKeyspaceShard()] = status
+}
+
+// GetTabletStatus returns the status recorded for the tablet, or an empty status if there is none
+func (cluster *VitessCluster) GetTabletStatus(tablet *VitessTablet) *VitessTabletStatus {
+	if status, ok := cluster.Status.Tablets[tablet.GetStatefulSetName()]; ok && status != nil {
+		return status
+	}
+	return &VitessTabletStatus{}
+}
+
+func (cluster *VitessCluster) SetTabletStatus(tablet *VitessTablet, status *VitessTabletStatus) {
+	if cluster.Status.Tablets == nil {
+		cluster.Status.Tablets = map[string]*VitessTabletStatus{}
+	}
+	cluster.Status.Tablets[tablet.GetStatefulSetName()] = status
+}
+
+// GetImage returns the mysqld_exporter image, or the default one if none is set
+func (exporter *MySQLExporter) GetImage() string {
+	if exporter.Image == "" {
+		return MySQLExporterImageDefault
+	}
+	return exporter.Image
+}
@@ -21,6 +21,43 @@ type VitessClusterSpec struct {
 	Keyspaces []*VitessKeyspace `json:"keyspaces,omitempty"`
 
 	KeyspaceSelector []ResourceSelector `json:"keyspaceSelector,omitempty"`
+
+	// BackupStorage is where the tablets of the cluster store backups. Backups can't be taken without it.
+	BackupStorage *VitessBackupStorage `json:"backupStorage,omitempty"`
+
+	// Monitoring configures the metrics exporters and Prometheus Operator objects of the cluster
+	Monitoring *VitessMonitoring `json:"monitoring,omitempty"`
+}
+
+// VitessMonitoring configures how the cluster is monitored. The vttablet, vtgate and vtctld pods always
+// carry Prometheus scrape annotations for their /metrics endpoint.
+type VitessMonitoring struct {
+	// MySQLExporter adds a mysqld_exporter sidecar to every tablet pod, serving the MySQL metrics on the
+	// mysql-metrics port of the tablet Service
+	MySQLExporter *MySQLExporter `json:"mysqlExporter,omitempty"`
+
+	// ServiceMonitor creates a Prometheus Operator ServiceMonitor for the vttablet, vtgate and vtctld Services.
+	// The ServiceMonitor CRD must be installed.
+	ServiceMonitor *VitessServiceMonitor `json:"serviceMonitor,omitempty"`
+}
+
+// MySQLExporter configures the mysqld_exporter sidecar of the tablet pods
+type MySQLExporter struct {
+	// Image defaults to prom/mysqld-exporter:v0.11.0
+	Image string `json:"image,omitempty"`
+
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+const MySQLExporterImageDefault = "prom/mysqld-exporter:v0.11.0"
+
+// VitessServiceMonitor configures the generated ServiceMonitor
+type VitessServiceMonitor struct {
+	// Labels are added to the ServiceMonitor, so that the serviceMonitorSelector of a Prometheus can select it
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Interval is how often the endpoints are scraped, e.g. 30s. Defaults to the interval of the Prometheus.
+	Interval string `json:"interval,omitempty"`
 }
 
 // VitessClusterStatus defines the observed state of VitessCluster
@@ -29,11 +66,30 @@ type VitessClusterStatus struct {
 
 	Reason string `json:"reason,omitempty"`
 
-	Message string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
 
+	// Conditions summarize whether the cluster is available and whether it is scaling, upgrading or recovering
 	Conditions []VitessClusterCondition `json:"conditions,omitempty"`
 
 	Lockserver *VitessLockserverStatus `json:"lockserver,omitempty"`
+
+	// RegisteredCells lists the cells that the operator has registered in the global topology
+	RegisteredCells []string `json:"registeredCells,omitempty"`
+
+	// Cells holds the status of the vtctld and vtgate of every cell in the cluster, keyed by cell name
+	Cells map[string]*VitessCellStatus `json:"cells,omitempty"`
+
+	// Keyspaces holds a summary of every keyspace in the cluster, keyed by keyspace name
+	Keyspaces map[string]*VitessKeyspaceStatus `json:"keyspaces,omitempty"`
+
+	// Shards holds the status of every shard in the cluster, keyed by keyspace/shard
+	Shards map[string]*VitessShardStatus `json:"shards,omitempty"`
+
+	// Tablets holds the status of every tablet in the cluster, keyed by StatefulSet name
+	Tablets map[string]*VitessTabletStatus `json:"tablets,omitempty"`
+
+	// ReadyTablets is the number of tablets of the cluster with all their pods ready
+	ReadyTablets int32 `json:"readyTablets"`
 }
 
 type ClusterPhase string
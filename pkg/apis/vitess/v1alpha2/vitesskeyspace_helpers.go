@@ -20,12 +20,44 @@ func (keyspace *VitessKeyspace) GetTabletContainers() *TabletContainers {
 	return nil
 }
 
+// GetTabletVolumeClaim satisfies ConfigProvider
+func (keyspace *VitessKeyspace) GetTabletVolumeClaim() *TabletVolumeClaim {
+	if keyspace.Spec.Defaults != nil {
+		return keyspace.Spec.Defaults.VolumeClaim
+	}
+	return nil
+}
+
+// GetTabletBackupStorage satisfies ConfigProvider
+func (keyspace *VitessKeyspace) GetTabletBackupStorage() *VitessBackupStorage {
+	if keyspace.Spec.Defaults != nil {
+		return keyspace.Spec.Defaults.BackupStorage
+	}
+	return nil
+}
+
+// GetTabletRestorePolicy satisfies ConfigProvider
+func (keyspace *VitessKeyspace) GetTabletRestorePolicy() *TabletRestorePolicy {
+	if keyspace.Spec.Defaults != nil {
+		return keyspace.Spec.Defaults.Restore
+	}
+	return nil
+}
+
 func (keyspace *VitessKeyspace) Shards() []*VitessShard {
 	return keyspace.Spec.Shards
 }
 
+// EmbedShardCopy embeds a copy of a standalone VitessShard in the keyspace
 func (keyspace *VitessKeyspace) EmbedShardCopy(shard *VitessShard) {
-	keyspace.Spec.Shards = append(keyspace.Spec.Shards, shard.DeepCopy())
+	embedded := shard.DeepCopy()
+	embedded.Spec.standalone = true
+	keyspace.Spec.Shards = append(keyspace.Spec.Shards, embedded)
+}
+
+// IsStandalone returns true if the keyspace is a copy of a VitessKeyspace object matched by a keyspaceSelector
+func (keyspace *VitessKeyspace) IsStandalone() bool {
+	return keyspace.Spec.standalone
 }
 
 func (keyspace *VitessKeyspace) GetScopedName(extra ...string) string {
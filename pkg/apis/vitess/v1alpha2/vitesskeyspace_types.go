@@ -9,21 +9,35 @@ import (
 
 // VitessKeyspaceSpec defines the desired state of VitessKeyspace
 type VitessKeyspaceSpec struct {
-	Defaults *VitessShardOptions `json:"defaults"`
+	Defaults *VitessShardOptions `json:"defaults,omitempty"`
 
-	Shards []*VitessShard `json:"shards"`
+	Shards []*VitessShard `json:"shards,omitempty"`
 
 	ShardSelector []ResourceSelector `json:"shardSelector,omitempty"`
 
 	// parent is unexported on purpose.
 	// It should only be used during processing and never stored
 	parent VitessKeyspaceParents
+
+	// standalone is set on copies of VitessKeyspace objects matched by a selector, whose status is written back to them.
+	// Like parent, it is only set during processing and never stored
+	standalone bool
 }
 
 type VitessKeyspaceParents struct {
 	Cluster *VitessCluster
 }
 
+// VitessKeyspaceStatus defines the observed state of VitessKeyspace. It is recorded in the VitessCluster status
+// and in the VitessKeyspace object of keyspaces matched by a keyspaceSelector.
+type VitessKeyspaceStatus struct {
+	// Shards is the number of shards in the keyspace
+	Shards int32 `json:"shards,omitempty"`
+
+	// ReadyShards is the number of shards with a master and all of their tablets ready
+	ReadyShards int32 `json:"readyShards"`
+}
+
 type VitessBatchOptions struct {
 	Count int64 `json:"count"`
 }
@@ -36,7 +50,8 @@ type VitessKeyspace struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec VitessKeyspaceSpec `json:"spec,omitempty"`
+	Spec   VitessKeyspaceSpec   `json:"spec,omitempty"`
+	Status VitessKeyspaceStatus `json:"status,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -0,0 +1,265 @@
+package v1alpha2
+
+import (
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// GetType returns the lockserver type, falling back to the default type if none is set
+func (ls *VitessLockserver) GetType() LockserverType {
+	if ls.Spec.Type == "" {
+		return LockserverTypeDefault
+	}
+	return ls.Spec.Type
+}
+
+// GetTopoServerAddress returns the value for the Vitess -topo_global_server_address
+// and related server address flags
+func (ls *VitessLockserver) GetTopoServerAddress() string {
+	switch ls.GetType() {
+	case LockserverTypeEtcd2:
+		if ls.Spec.Etcd2 != nil {
+			return ls.Spec.Etcd2.Address
+		}
+	case LockserverTypeZk2:
+		if ls.Spec.Zk2 != nil {
+			return strings.Join(ls.Spec.Zk2.Servers, ",")
+		}
+	case LockserverTypeConsul:
+		if ls.Spec.Consul != nil {
+			return ls.Spec.Consul.Address
+		}
+	}
+	return ""
+}
+
+// GetTopoRoot returns the value for the Vitess -topo_global_root and related root flags
+func (ls *VitessLockserver) GetTopoRoot() string {
+	switch ls.GetType() {
+	case LockserverTypeEtcd2:
+		if ls.Spec.Etcd2 != nil {
+			return ls.Spec.Etcd2.Path
+		}
+	case LockserverTypeZk2:
+		if ls.Spec.Zk2 != nil {
+			return ls.Spec.Zk2.Path
+		}
+	case LockserverTypeConsul:
+		if ls.Spec.Consul != nil {
+			return ls.Spec.Consul.Path
+		}
+	}
+	return ""
+}
+
+// GetTopoEnv returns the environment variables that containers talking to this lockserver need
+func (ls *VitessLockserver) GetTopoEnv() []corev1.EnvVar {
+	env := []corev1.EnvVar{}
+
+	// The Vitess consul topo client reads the ACL token from the standard Consul environment variable
+	if ls.GetType() == LockserverTypeConsul && ls.Spec.Consul != nil && ls.Spec.Consul.ACLTokenSecretRef != nil {
+		env = append(env, corev1.EnvVar{
+			Name: "CONSUL_HTTP_TOKEN",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: ls.Spec.Consul.ACLTokenSecretRef,
+			},
+		})
+	}
+
+	return env
+}
+
+// GetEtcdTLS returns the TLS configuration of an etcd2 lockserver, or nil if it doesn't use TLS
+func (ls *VitessLockserver) GetEtcdTLS() *LockserverTLS {
+	if ls.GetType() == LockserverTypeEtcd2 && ls.Spec.Etcd2 != nil {
+		return ls.Spec.Etcd2.TLS
+	}
+	return nil
+}
+
+// GetEtcdTLSCAFile returns the path of the mounted etcd CA certificate, or an empty string if there is none
+func (ls *VitessLockserver) GetEtcdTLSCAFile() string {
+	if tls := ls.GetEtcdTLS(); tls != nil && tls.CASecretRef != nil {
+		return topoTLSFile("ca")
+	}
+	return ""
+}
+
+// GetEtcdTLSCertFile returns the path of the mounted etcd client certificate, or an empty string if there is none
+func (ls *VitessLockserver) GetEtcdTLSCertFile() string {
+	if tls := ls.GetEtcdTLS(); tls != nil && tls.CertSecretRef != nil {
+		return topoTLSFile("cert")
+	}
+	return ""
+}
+
+// GetEtcdTLSKeyFile returns the path of the mounted etcd client key, or an empty string if there is none
+func (ls *VitessLockserver) GetEtcdTLSKeyFile() string {
+	if tls := ls.GetEtcdTLS(); tls != nil && tls.KeySecretRef != nil {
+		return topoTLSFile("key")
+	}
+	return ""
+}
+
+// GetTopoVolumes returns the volumes that pods talking to this lockserver need
+func (ls *VitessLockserver) GetTopoVolumes() []corev1.Volume {
+	volumes := []corev1.Volume{}
+	for _, r := range ls.getTopoTLSSecretRefs() {
+		volumes = append(volumes, corev1.Volume{
+			Name: "topo-tls-" + r.name,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: r.ref.Name,
+					Items: []corev1.KeyToPath{
+						{
+							Key:  r.ref.Key,
+							Path: r.name + ".pem",
+						},
+					},
+				},
+			},
+		})
+	}
+	return volumes
+}
+
+// GetTopoVolumeMounts returns the volume mounts for the volumes from GetTopoVolumes
+func (ls *VitessLockserver) GetTopoVolumeMounts() []corev1.VolumeMount {
+	mounts := []corev1.VolumeMount{}
+	for _, r := range ls.getTopoTLSSecretRefs() {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      "topo-tls-" + r.name,
+			MountPath: TopoTLSMountPath + "/" + r.name,
+			ReadOnly:  true,
+		})
+	}
+	return mounts
+}
+
+type topoTLSSecretRef struct {
+	name string
+	ref  *corev1.SecretKeySelector
+}
+
+// getTopoTLSSecretRefs returns the TLS Secret refs that are set in a stable order
+func (ls *VitessLockserver) getTopoTLSSecretRefs() []topoTLSSecretRef {
+	refs := []topoTLSSecretRef{}
+	if tls := ls.GetEtcdTLS(); tls != nil {
+		for _, r := range []topoTLSSecretRef{
+			{"ca", tls.CASecretRef},
+			{"cert", tls.CertSecretRef},
+			{"key", tls.KeySecretRef},
+		} {
+			if r.ref != nil {
+				refs = append(refs, r)
+			}
+		}
+	}
+	return refs
+}
+
+// MergeTopoCredentials returns a copy of the lockserver with the etcd TLS Secret refs and the Consul ACL token
+// that only other sets added to it. Vitess components talk to the global and the cell topology with a single set
+// of topo credentials, so they get the credentials of both lockservers. Credentials set by both lockservers
+// must be the same, see TopoCredentialsConflict.
+func (ls *VitessLockserver) MergeTopoCredentials(other *VitessLockserver) *VitessLockserver {
+	merged := ls.DeepCopy()
+	if other == nil || other == ls || other.GetType() != ls.GetType() {
+		return merged
+	}
+
+	if otherTLS := other.GetEtcdTLS(); otherTLS != nil && merged.Spec.Etcd2 != nil {
+		if merged.Spec.Etcd2.TLS == nil {
+			merged.Spec.Etcd2.TLS = &LockserverTLS{}
+		}
+		tls := merged.Spec.Etcd2.TLS
+		if tls.CASecretRef == nil {
+			tls.CASecretRef = otherTLS.CASecretRef.DeepCopy()
+		}
+		if tls.CertSecretRef == nil {
+			tls.CertSecretRef = otherTLS.CertSecretRef.DeepCopy()
+		}
+		if tls.KeySecretRef == nil {
+			tls.KeySecretRef = otherTLS.KeySecretRef.DeepCopy()
+		}
+	}
+
+	if other.GetType() == LockserverTypeConsul && other.Spec.Consul != nil && merged.Spec.Consul != nil && merged.Spec.Consul.ACLTokenSecretRef == nil {
+		merged.Spec.Consul.ACLTokenSecretRef = other.Spec.Consul.ACLTokenSecretRef.DeepCopy()
+	}
+
+	return merged
+}
+
+// TopoCredentialsConflict returns true if both lockservers set the same etcd TLS Secret ref or Consul ACL token
+// to different Secret keys, which a single set of topo credentials can't hold
+func (ls *VitessLockserver) TopoCredentialsConflict(other *VitessLockserver) bool {
+	conflict := func(a, b *corev1.SecretKeySelector) bool {
+		return a != nil && b != nil && (a.Name != b.Name || a.Key != b.Key)
+	}
+
+	if tls, otherTLS := ls.GetEtcdTLS(), other.GetEtcdTLS(); tls != nil && otherTLS != nil {
+		if conflict(tls.CASecretRef, otherTLS.CASecretRef) || conflict(tls.CertSecretRef, otherTLS.CertSecretRef) || conflict(tls.KeySecretRef, otherTLS.KeySecretRef) {
+			return true
+		}
+	}
+
+	if ls.GetType() == LockserverTypeConsul && other.GetType() == LockserverTypeConsul && ls.Spec.Consul != nil && other.Spec.Consul != nil {
+		return conflict(ls.Spec.Consul.ACLTokenSecretRef, other.Spec.Consul.ACLTokenSecretRef)
+	}
+
+	return false
+}
+
+func topoTLSFile(name string) string {
+	return TopoTLSMountPath + "/" + name + "/" + name + ".pem"
+}
+
+func (ls *VitessLockserver) GetEtcdReplicas() *int32 {
+	if ls.Spec.Etcd2 != nil && ls.Spec.Etcd2.Replicas != nil {
+		return ls.Spec.Etcd2.Replicas
+	}
+
+	def := EtcdReplicasDefault
+	return &def
+}
+
+func (ls *VitessLockserver) GetEtcdImage() string {
+	if ls.Spec.Etcd2 != nil && ls.Spec.Etcd2.Image != "" {
+		return ls.Spec.Etcd2.Image
+	}
+
+	return EtcdImageDefault
+}
+
+func (ls *VitessLockserver) GetCondition(t LockserverConditionType) *VitessLockserverCondition {
+	for i := range ls.Status.Conditions {
+		if ls.Status.Conditions[i].Type == t {
+			return &ls.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetCondition sets the given condition, only moving the transition time if the status changed
+func (ls *VitessLockserver) SetCondition(t LockserverConditionType, status corev1.ConditionStatus, reason, message string) {
+	cond := ls.GetCondition(t)
+	if cond == nil {
+		ls.Status.Conditions = append(ls.Status.Conditions, VitessLockserverCondition{Type: t})
+		cond = &ls.Status.Conditions[len(ls.Status.Conditions)-1]
+	}
+
+	if cond.Status != status {
+		cond.Status = status
+		cond.LastTransitionTime = time.Now().UTC().Format(time.RFC3339)
+	}
+	cond.Reason = reason
+	cond.Message = message
+}
+
+func (ls *VitessLockserver) IsAvailable() bool {
+	cond := ls.GetCondition(LockserverConditionAvailable)
+	return cond != nil && cond.Status == corev1.ConditionTrue
+}
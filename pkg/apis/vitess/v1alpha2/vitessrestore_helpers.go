@@ -0,0 +1,26 @@
+package v1alpha2
+
+import (
+	"strings"
+)
+
+// IsActive returns true while the restore is neither complete nor failed
+func (restore *VitessRestore) IsActive() bool {
+	return restore.Status.Phase != RestorePhaseComplete && restore.Status.Phase != RestorePhaseFailed
+}
+
+// AppliesTo returns true if the restore targets the given keyspace
+func (restore *VitessRestore) AppliesTo(keyspace *VitessKeyspace) bool {
+	return restore.Spec.Cluster == keyspace.Cluster().GetName() &&
+		(restore.Spec.Keyspace == "" || restore.Spec.Keyspace == keyspace.GetName())
+}
+
+// IsPointInTime returns true if the restore replays binlogs to a point in time after restoring the latest backup
+func (restore *VitessRestore) IsPointInTime() bool {
+	return restore.Spec.RestoreToTime != nil || restore.Spec.RestoreToPosition != ""
+}
+
+// GetRestoreToGTIDSet returns the GTID set of RestoreToPosition without its flavor, as MySQL takes it
+func (restore *VitessRestore) GetRestoreToGTIDSet() string {
+	return strings.TrimPrefix(restore.Spec.RestoreToPosition, RestorePositionFlavor+"/")
+}
@@ -24,8 +24,16 @@ func (shard *VitessShard) Tablets() []*VitessTablet {
 	return shard.Spec.Tablets
 }
 
+// EmbedTabletCopy embeds a copy of a standalone VitessTablet in the shard
 func (shard *VitessShard) EmbedTabletCopy(tablet *VitessTablet) {
-	shard.Spec.Tablets = append(shard.Spec.Tablets, tablet.DeepCopy())
+	embedded := tablet.DeepCopy()
+	embedded.Spec.standalone = true
+	shard.Spec.Tablets = append(shard.Spec.Tablets, embedded)
+}
+
+// IsStandalone returns true if the shard is a copy of a VitessShard object matched by a shardSelector
+func (shard *VitessShard) IsStandalone() bool {
+	return shard.Spec.standalone
 }
 
 // GetTabletContainers satisfies ConfigProvider
@@ -36,6 +44,30 @@ func (shard *VitessShard) GetTabletContainers() *TabletContainers {
 	return nil
 }
 
+// GetTabletVolumeClaim satisfies ConfigProvider
+func (shard *VitessShard) GetTabletVolumeClaim() *TabletVolumeClaim {
+	if shard.Spec.Defaults != nil {
+		return shard.Spec.Defaults.VolumeClaim
+	}
+	return nil
+}
+
+// GetTabletBackupStorage satisfies ConfigProvider
+func (shard *VitessShard) GetTabletBackupStorage() *VitessBackupStorage {
+	if shard.Spec.Defaults != nil {
+		return shard.Spec.Defaults.BackupStorage
+	}
+	return nil
+}
+
+// GetTabletRestorePolicy satisfies ConfigProvider
+func (shard *VitessShard) GetTabletRestorePolicy() *TabletRestorePolicy {
+	if shard.Spec.Defaults != nil {
+		return shard.Spec.Defaults.Restore
+	}
+	return nil
+}
+
 func (shard *VitessShard) GetScopedName(extra ...string) string {
 	return strings.Join(append(
 		[]string{
@@ -43,3 +75,53 @@ func (shard *VitessShard) GetScopedName(extra ...string) string {
 		},
 		extra...), "-")
 }
+
+// GetBackupStorage returns the backup storage of the shard, inherited from the keyspace and then the cluster
+func (shard *VitessShard) GetBackupStorage() *VitessBackupStorage {
+	// Inheritance order, with most specific first
+	providers := []ConfigProvider{
+		shard,
+		shard.Keyspace(),
+	}
+
+	for _, p := range providers {
+		if bs := p.GetTabletBackupStorage(); bs != nil {
+			return bs
+		}
+	}
+	return shard.Cluster().BackupStorage()
+}
+
+// GetKeyspaceShard returns the keyspace/shard name that Vitess uses for the shard
+func (shard *VitessShard) GetKeyspaceShard() string {
+	return shard.Keyspace().GetName() + "/" + shard.Spec.KeyRange.String()
+}
+
+// GetExpectedTabletCount returns the number of tablets that should register themselves in the shard
+func (shard *VitessShard) GetExpectedTabletCount() int {
+	count := 0
+	for _, tablet := range shard.Tablets() {
+		count += int(*tablet.GetReplicas())
+	}
+	return count
+}
+
+func (shard *VitessShard) GetMasterElectionPolicy() *MasterElectionPolicy {
+	if shard.Spec.Defaults != nil && shard.Spec.Defaults.MasterElection != nil {
+		return shard.Spec.Defaults.MasterElection
+	}
+	return &MasterElectionPolicy{}
+}
+
+// GetMaxReplicationLagSeconds returns the replication lag above which a replica can't take over as master
+func (policy *MasterElectionPolicy) GetMaxReplicationLagSeconds() int32 {
+	if policy.MaxReplicationLagSeconds != nil {
+		return *policy.MaxReplicationLagSeconds
+	}
+	return MaxReplicationLagSecondsDefault
+}
+
+// IsReady returns true if the shard has a master and all of its tablet pods are ready
+func (status *VitessShardStatus) IsReady() bool {
+	return status.MasterAlias != "" && status.ReadyReplicas >= status.Replicas
+}
@@ -9,17 +9,21 @@ import (
 
 // VitessShardSpec defines the desired state of VitessShard
 type VitessShardSpec struct {
-	Defaults *VitessShardOptions `json:"defaults"`
+	Defaults *VitessShardOptions `json:"defaults,omitempty"`
 
 	KeyRange KeyRange `json:"keyRange,omitempty"`
 
-	Tablets []*VitessTablet `json:"tablets"`
+	Tablets []*VitessTablet `json:"tablets,omitempty"`
 
 	TabletSelector []ResourceSelector `json:"tabletSelector,omitempty"`
 
 	// parent is unexported on purpose.
 	// It should only be used during processing and never stored
 	parent VitessShardParents
+
+	// standalone is set on copies of VitessShard objects matched by a selector, whose status is written back to them.
+	// Like parent, it is only set during processing and never stored
+	standalone bool
 }
 
 type VitessShardParents struct {
@@ -28,15 +32,54 @@ type VitessShardParents struct {
 }
 
 type VitessShardOptions struct {
-	Replicas *int32 `json:"replicas"`
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	Batch VitessBatchOptions `json:"batch"`
+
+	Containers *TabletContainers `json:"containers,omitempty"`
+
+	VolumeClaim *TabletVolumeClaim `json:"volumeClaim,omitempty"`
 
-	Batch VitessBatchOptions `json:""batch`
+	// BackupStorage replaces the backup storage of the cluster for these tablets
+	BackupStorage *VitessBackupStorage `json:"backupStorage,omitempty"`
 
-	Containers *TabletContainers `json:"containers"`
+	Restore *TabletRestorePolicy `json:"restore,omitempty"`
 
-	Cells []string `json:"cells"`
+	Cells []string `json:"cells,omitempty"`
 
 	CellSelector []ResourceSelector `json:"cellSelector,omitempty"`
+
+	MasterElection *MasterElectionPolicy `json:"masterElection,omitempty"`
+}
+
+// MasterElectionPolicy controls how the operator picks the master of a shard, both when the shard is
+// first set up and when the master pod is about to be removed
+type MasterElectionPolicy struct {
+	// PreferredCells are tried in order when picking a master candidate.
+	// Candidates in other cells are only used when none of these cells has one.
+	PreferredCells []string `json:"preferredCells,omitempty"`
+
+	// MaxReplicationLagSeconds is how far behind the master a replica may be and still
+	// take over in a planned reparent. Defaults to 10.
+	MaxReplicationLagSeconds *int32 `json:"maxReplicationLagSeconds,omitempty"`
+}
+
+const MaxReplicationLagSecondsDefault int32 = 10
+
+// VitessShardStatus defines the observed state of VitessShard. Like the tablet status, it is recorded in
+// the VitessCluster status and in the VitessShard object of shards matched by a shardSelector.
+type VitessShardStatus struct {
+	// MasterAlias is the alias of the shard master tablet
+	MasterAlias string `json:"masterAlias,omitempty"`
+
+	// MasterPodName is the name of the pod the shard master tablet runs in
+	MasterPodName string `json:"masterPodName,omitempty"`
+
+	// Replicas is the number of tablet pods the shard should have
+	Replicas int32 `json:"replicas"`
+
+	// ReadyReplicas is the number of tablet pods of the shard that are ready
+	ReadyReplicas int32 `json:"readyReplicas"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -47,7 +90,8 @@ type VitessShard struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec VitessShardSpec `json:"spec,omitempty"`
+	Spec   VitessShardSpec   `json:"spec,omitempty"`
+	Status VitessShardStatus `json:"status,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
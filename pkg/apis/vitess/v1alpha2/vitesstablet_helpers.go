@@ -2,8 +2,13 @@ package v1alpha2
 
 import (
 	// "fmt"
+	"crypto/md5"
+	"encoding/binary"
 	"strconv"
 	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 // GetTabletContainers satisfies ConfigProvider
@@ -11,6 +16,74 @@ func (tablet *VitessTablet) GetTabletContainers() *TabletContainers {
 	return tablet.Spec.Containers
 }
 
+// GetTabletVolumeClaim satisfies ConfigProvider
+func (tablet *VitessTablet) GetTabletVolumeClaim() *TabletVolumeClaim {
+	return tablet.Spec.VolumeClaim
+}
+
+// GetTabletBackupStorage satisfies ConfigProvider. Backups belong to the shard, so every tablet of a shard
+// uses the same backup storage and it can't be set on the tablet itself.
+func (tablet *VitessTablet) GetTabletBackupStorage() *VitessBackupStorage {
+	return nil
+}
+
+// GetBackupStorage returns the backup storage of the shard of the tablet, or nil if there is none.
+// The source of the restore of the tablet replaces it.
+func (tablet *VitessTablet) GetBackupStorage() *VitessBackupStorage {
+	if restore := tablet.Restore(); restore != nil {
+		return &restore.Spec.Source
+	}
+	return tablet.Shard().GetBackupStorage()
+}
+
+// SetRestore makes the tablet restore from the source of the given VitessRestore
+func (tablet *VitessTablet) SetRestore(restore *VitessRestore) {
+	tablet.Spec.restore = restore
+}
+
+// Restore returns the VitessRestore the tablet restores from, or nil if there is none
+func (tablet *VitessTablet) Restore() *VitessRestore {
+	return tablet.Spec.restore
+}
+
+// GetTabletRestorePolicy satisfies ConfigProvider
+func (tablet *VitessTablet) GetTabletRestorePolicy() *TabletRestorePolicy {
+	return tablet.Spec.Restore
+}
+
+// GetRestorePolicy returns the restore policy of the tablet, inherited from the shard and then the keyspace
+func (tablet *VitessTablet) GetRestorePolicy() *TabletRestorePolicy {
+	// Inheritance order, with most specific first
+	providers := []ConfigProvider{
+		tablet,
+		tablet.Shard(),
+		tablet.Keyspace(),
+	}
+
+	for _, p := range providers {
+		if policy := p.GetTabletRestorePolicy(); policy != nil {
+			return policy
+		}
+	}
+	return &TabletRestorePolicy{}
+}
+
+// RestoresFromBackup returns true if new pods of the tablet are bootstrapped from the latest backup of the shard
+func (tablet *VitessTablet) RestoresFromBackup() bool {
+	if tablet.Restore() != nil {
+		return true
+	}
+	return tablet.GetRestorePolicy().FromBackup && tablet.GetBackupStorage() != nil
+}
+
+// GetMaxReplicationLagSeconds returns the replication lag above which a restored tablet isn't ready
+func (policy *TabletRestorePolicy) GetMaxReplicationLagSeconds() int32 {
+	if policy.MaxReplicationLagSeconds != nil {
+		return *policy.MaxReplicationLagSeconds
+	}
+	return RestoreMaxReplicationLagSecondsDefault
+}
+
 func (tablet *VitessTablet) SetParentCluster(cluster *VitessCluster) {
 	tablet.Spec.parent.Cluster = cluster
 }
@@ -48,7 +121,15 @@ func (tablet *VitessTablet) Shard() *VitessShard {
 }
 
 func (tablet *VitessTablet) GetStatefulSetName() string {
-	return tablet.GetScopedName(string(tablet.Spec.Type))
+	return tablet.GetScopedName(string(tablet.GetType()))
+}
+
+// GetType returns the initial type of the tablet pods, falling back to the default type if none is set
+func (tablet *VitessTablet) GetType() TabletType {
+	if tablet.Spec.Type == "" {
+		return TabletTypeDefault
+	}
+	return tablet.Spec.Type
 }
 
 func (tablet *VitessTablet) GetScopedName(extra ...string) string {
@@ -75,6 +156,8 @@ func (tablet *VitessTablet) GetReplicas() *int32 {
 	return &def
 }
 
+// GetMySQLContainer returns the mysql container configuration of the tablet, inherited from the shard and then
+// the keyspace, with its DB flavor filled in
 func (tablet *VitessTablet) GetMySQLContainer() *MySQLContainer {
 	// Inheritance order, with most specific first
 	providers := []ConfigProvider{
@@ -86,18 +169,17 @@ func (tablet *VitessTablet) GetMySQLContainer() *MySQLContainer {
 	for _, p := range providers {
 		if containers := p.GetTabletContainers(); containers != nil && containers.MySQL != nil {
 			// TODO get defaults from full range of providers
-			if containers.MySQL.DBFlavor == "" && containers.DBFlavor != "" {
-				containers.MySQL.DBFlavor = containers.DBFlavor
-			}
-			if containers.MySQL.DBFlavor == "" {
-				containers.MySQL.DBFlavor = "mysql56"
-			}
-			return containers.MySQL
+			// The container is copied since it is shared with the objects the tablet inherits from
+			mysql := *containers.MySQL
+			mysql.DBFlavor = containers.GetDBFlavor(mysql.DBFlavor)
+			return &mysql
 		}
 	}
 	return nil
 }
 
+// GetVTTabletContainer returns the vttablet container configuration of the tablet, inherited from the shard and
+// then the keyspace, with its DB flavor filled in
 func (tablet *VitessTablet) GetVTTabletContainer() *VTTabletContainer {
 	// Inheritance order, with most specific first
 	providers := []ConfigProvider{
@@ -109,30 +191,110 @@ func (tablet *VitessTablet) GetVTTabletContainer() *VTTabletContainer {
 	for _, p := range providers {
 		if containers := p.GetTabletContainers(); containers != nil && containers.VTTablet != nil {
 			// TODO get defaults from full range of providers
-			if containers.VTTablet.DBFlavor == "" && containers.DBFlavor != "" {
-				containers.VTTablet.DBFlavor = containers.DBFlavor
-			}
-			if containers.VTTablet.DBFlavor == "" {
-				containers.VTTablet.DBFlavor = "mysql56"
-			}
-			return containers.VTTablet
+			vttablet := *containers.VTTablet
+			vttablet.DBFlavor = containers.GetDBFlavor(vttablet.DBFlavor)
+			return &vttablet
 		}
 	}
 	return nil
 }
 
+// GetVolumeClaim returns the vtdataroot claim configuration of the tablet with the defaults filled in
+func (tablet *VitessTablet) GetVolumeClaim() *TabletVolumeClaim {
+	claim := &TabletVolumeClaim{}
+
+	// Inheritance order, with most specific first
+	providers := []ConfigProvider{
+		tablet,
+		tablet.Shard(),
+		tablet.Keyspace(),
+	}
+
+	for _, p := range providers {
+		if c := p.GetTabletVolumeClaim(); c != nil {
+			claim = c.DeepCopy()
+			break
+		}
+	}
+
+	if len(claim.Spec.AccessModes) == 0 {
+		claim.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	}
+
+	if _, ok := claim.Spec.Resources.Requests[corev1.ResourceStorage]; !ok {
+		if claim.Spec.Resources.Requests == nil {
+			claim.Spec.Resources.Requests = corev1.ResourceList{}
+		}
+		claim.Spec.Resources.Requests[corev1.ResourceStorage] = resource.MustParse(TabletVolumeSizeDefault)
+	}
+
+	return claim
+}
+
 func (tablet *VitessTablet) GetTabletID() string {
 	return strconv.FormatInt(tablet.Spec.TabletID, 10)
 }
 
+// GetTabletUIDBase returns the UID of the first pod of the tablet. The pod with ordinal N gets the UID base + N.
+// Tablets that already have a StatefulSet keep the UID base it was created with.
+func (tablet *VitessTablet) GetTabletUIDBase() uint32 {
+	if tablet.Spec.uidBase != nil {
+		return *tablet.Spec.uidBase
+	}
+
+	if tablet.Spec.TabletID != 0 {
+		return uint32(tablet.Spec.TabletID) * TabletUIDOrdinalRange
+	}
+
+	return hashTabletUIDBase(tablet.Cell().GetName() + "-" + tablet.GetStatefulSetName())
+}
+
+// GetLegacyTabletUIDBase returns the UID base of tablets whose StatefulSet was created before the operator
+// computed the UIDs. Their init script hashed the StatefulSet name with a hard-coded zone1 cell.
+func (tablet *VitessTablet) GetLegacyTabletUIDBase() uint32 {
+	return hashTabletUIDBase("zone1-" + tablet.GetStatefulSetName())
+}
+
+// SetTabletUIDBase pins the UID base of the tablet to the one its StatefulSet was created with
+func (tablet *VitessTablet) SetTabletUIDBase(base uint32) {
+	tablet.Spec.uidBase = &base
+}
+
+// hashTabletUIDBase takes the first 24 bits of the MD5 hash of the name, so that the base fits in 32 bits
+// with room for the ordinals
+func hashTabletUIDBase(name string) uint32 {
+	sum := md5.Sum([]byte(name))
+	return (binary.BigEndian.Uint32(sum[:4]) >> 8) * TabletUIDOrdinalRange
+}
+
+// GetTabletUID returns the UID of the tablet pod with the given ordinal
+func (tablet *VitessTablet) GetTabletUID(ordinal int32) uint32 {
+	return tablet.GetTabletUIDBase() + uint32(ordinal)
+}
+
+// IsStandalone returns true if the tablet is a copy of a VitessTablet object matched by a tabletSelector
+func (tablet *VitessTablet) IsStandalone() bool {
+	return tablet.Spec.standalone
+}
+
 func (tablet *VitessTablet) Phase() TabletPhase {
-	return tablet.status.Phase
+	return tablet.Status.Phase
 }
 
 func (tablet *VitessTablet) SetPhase(p TabletPhase) {
-	tablet.status.Phase = p
+	tablet.Status.Phase = p
 }
 
 func (tablet *VitessTablet) InPhase(p TabletPhase) bool {
-	return tablet.status.Phase == p
+	return tablet.Status.Phase == p
+}
+
+// IsRestoring returns true while a restored pod of the tablet hasn't finished restoring and caught up
+func (status *VitessTabletStatus) IsRestoring() bool {
+	for _, restore := range status.Restores {
+		if !restore.Complete {
+			return true
+		}
+	}
+	return false
 }
@@ -1,6 +1,8 @@
 package v1alpha2
 
 import (
+	"math"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -10,25 +12,42 @@ import (
 
 // VitessTabletSpec defines the desired state of VitessTablet
 type VitessTabletSpec struct {
+	// TabletID sets the UIDs of the tablet pods to TabletID*100 plus the pod ordinal.
+	// When it is not set the UIDs are derived from a hash of the cell and StatefulSet names.
+	// Tablets that already exist keep the UIDs they were created with.
 	TabletID int64 `json:"tabletID"`
 
-	Replicas *int32 `json:"replicas"`
+	Replicas *int32 `json:"replicas,omitempty"`
 
 	CellID string `json:"cellID"`
 
-	Type TabletType `json:"type"`
+	Type TabletType `json:"type,omitempty"`
 
 	Datastore TabletDatastore `json:"datastore"`
 
-	Containers *TabletContainers `json:"containers"`
+	Containers *TabletContainers `json:"containers,omitempty"`
 
-	VolumeClaim *corev1.PersistentVolumeClaimVolumeSource `json:"volumeclaim, omitempty"`
+	VolumeClaim *TabletVolumeClaim `json:"volumeClaim,omitempty"`
 
 	Credentials *TabletCredentials `json:"credentials,omitempty"`
 
+	Restore *TabletRestorePolicy `json:"restore,omitempty"`
+
 	// parent is unexported on purpose.
 	// It should only be used during processing and never stored
 	parent VitessTabletParents
+
+	// restore is the VitessRestore the tablet restores from, if any.
+	// Like parent, it is only set during processing and never stored
+	restore *VitessRestore
+
+	// uidBase is the UID base recorded on the StatefulSet of the tablet, if it has one.
+	// Like parent, it is only set during processing and never stored
+	uidBase *uint32
+
+	// standalone is set on copies of VitessTablet objects matched by a selector, whose status is written back to them.
+	// Like parent, it is only set during processing and never stored
+	standalone bool
 }
 
 type VitessTabletParents struct {
@@ -51,8 +70,21 @@ const (
 
 const TabletTypeDefault TabletType = TabletTypeReplica
 
+const (
+	// TabletUIDOrdinalRange is the number of UIDs reserved for the pods of each tablet
+	TabletUIDOrdinalRange = 100
+
+	// MaxTabletID keeps the UIDs of every pod of the tablet within the 32 bits Vitess allows
+	MaxTabletID = (math.MaxUint32 - TabletUIDOrdinalRange + 1) / TabletUIDOrdinalRange
+)
+
+// TabletUIDBaseAnnotation is set on the StatefulSets of tablets to the UID base their pods were created with.
+// The pods keep it for as long as the StatefulSet exists, since their data directory and alias are named
+// after their UID.
+const TabletUIDBaseAnnotation = "vitess.io/tablet-uid-base"
+
 type TabletDatastore struct {
-	Type TabletDatastoreType `json:"type"`
+	Type TabletDatastoreType `json:"type,omitempty"`
 }
 
 type TabletDatastoreType string
@@ -63,17 +95,115 @@ const (
 
 const TabletDatastoreTypeDefault TabletDatastoreType = TabletDatastoreTypeLocal
 
+// TabletVolumeClaim configures the vtdataroot PersistentVolumeClaim of every tablet pod
+type TabletVolumeClaim struct {
+	// Labels are added to the claim
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are added to the claim
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Spec is the claim spec. The access modes default to ReadWriteOnce and the storage request to 10Gi.
+	Spec corev1.PersistentVolumeClaimSpec `json:"spec,omitempty"`
+}
+
+const TabletVolumeSizeDefault = "10Gi"
+
+// TabletRestorePolicy controls how new tablet pods are bootstrapped
+type TabletRestorePolicy struct {
+	// FromBackup starts new tablet pods from the latest backup of their shard instead of empty.
+	// Pods of a shard that has no backup yet still start empty.
+	FromBackup bool `json:"fromBackup,omitempty"`
+
+	// MaxReplicationLagSeconds is how far behind the master a restored tablet may be for its restore to be complete.
+	// Defaults to 30.
+	MaxReplicationLagSeconds *int32 `json:"maxReplicationLagSeconds,omitempty"`
+}
+
+const RestoreMaxReplicationLagSecondsDefault int32 = 30
+
 type TabletCredentials struct {
 	// SecretRef points a Secret resource which contains the credentials
 	// +optional
 	SecretRef *corev1.SecretReference `json:"secretRef,omitempty" protobuf:"bytes,4,opt,name=secretRef"`
 }
 
-// status is for internal use only. If it was exported then it would dirty-up the
-// tablet objects embedded in other resources and would result in mixed status and spec data
-// it is here for use by the VitessCluster object and its controller
+// VitessTabletStatus defines the observed state of VitessTablet. The status of every tablet is recorded
+// in the VitessCluster status, and also in the VitessTablet object of tablets matched by a tabletSelector.
+// The status of tablets embedded in their parent is ignored.
 type VitessTabletStatus struct {
-	Phase TabletPhase `json:"-"`
+	// Phase is Ready once all of the tablet pods are ready
+	Phase TabletPhase `json:"phase,omitempty"`
+
+	// Replicas is the number of pods the tablet should have
+	Replicas int32 `json:"replicas"`
+
+	// ReadyReplicas is the number of pods of the tablet that are ready
+	ReadyReplicas int32 `json:"readyReplicas"`
+
+	// UpdatedReplicas is the number of pods of the tablet that run the current StatefulSet revision
+	UpdatedReplicas int32 `json:"updatedReplicas"`
+
+	// Volume tracks the resizing of the tablet vtdataroot claims
+	Volume *TabletVolumeStatus `json:"volume,omitempty"`
+
+	// Restores lists the tablet pods that were bootstrapped from a backup
+	Restores []TabletRestoreStatus `json:"restores,omitempty"`
+
+	// Pods lists the tablet pods registered in the topology
+	Pods []TabletPodStatus `json:"pods,omitempty"`
+}
+
+// TabletPodStatus is the state of a tablet pod as reported by vtctld
+type TabletPodStatus struct {
+	// PodName is the name of the pod
+	PodName string `json:"podName"`
+
+	// Alias is the alias of the tablet in the topology
+	Alias string `json:"alias"`
+
+	// Type is the tablet type recorded in the topology, e.g. master, replica or restore
+	Type string `json:"type"`
+
+	// Serving is set when the tablet serves queries
+	Serving bool `json:"serving"`
+
+	// ReplicationLagSeconds is how far behind the master the tablet is. It is unset when the health is unknown.
+	ReplicationLagSeconds *int32 `json:"replicationLagSeconds,omitempty"`
+
+	// HealthError is why the tablet is unhealthy, or why its health couldn't be checked
+	HealthError string `json:"healthError,omitempty"`
+}
+
+// TabletVolumeStatus is the observed state of the vtdataroot claims of a tablet
+type TabletVolumeStatus struct {
+	// RequestedSize is the storage the claims are being resized to
+	RequestedSize string `json:"requestedSize,omitempty"`
+
+	// Resizing lists the claims whose capacity hasn't reached the requested size yet
+	Resizing []string `json:"resizing,omitempty"`
+
+	// Error is set when the claims can't be expanded, e.g. because their storage class doesn't allow it
+	Error string `json:"error,omitempty"`
+}
+
+// TabletRestoreStatus is the observed state of a tablet pod that restored from a backup when it was created
+type TabletRestoreStatus struct {
+	// PodName is the name of the restored pod
+	PodName string `json:"podName"`
+
+	// Backup is the name of the backup the pod restored from. It is empty if the shard had no backup
+	// and the pod started empty.
+	Backup string `json:"backup,omitempty"`
+
+	// BinlogsReplayed is set once the pod replayed the binlogs up to the point in time of its VitessRestore
+	BinlogsReplayed bool `json:"binlogsReplayed,omitempty"`
+
+	// Complete is set once the pod finished restoring and caught up on replication
+	Complete bool `json:"complete,omitempty"`
+
+	// Error explains why the restore of the pod can't complete
+	Error string `json:"error,omitempty"`
 }
 
 type TabletPhase string
@@ -91,10 +221,8 @@ type VitessTablet struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec VitessTabletSpec `json:"spec,omitempty"`
-
-	// internal use only. See struct def for details
-	status VitessTabletStatus `json:"-"`
+	Spec   VitessTabletSpec   `json:"spec,omitempty"`
+	Status VitessTabletStatus `json:"status,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
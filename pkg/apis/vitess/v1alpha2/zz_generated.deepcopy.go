@@ -21,10 +21,37 @@ limitations under the License.
 package v1alpha2
 
 import (
-	v1 "k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupRetentionPolicy) DeepCopyInto(out *BackupRetentionPolicy) {
+	*out = *in
+	if in.KeepLast != nil {
+		in, out := &in.KeepLast, &out.KeepLast
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupRetentionPolicy.
+func (in *BackupRetentionPolicy) DeepCopy() *BackupRetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupRetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CellSelector) DeepCopyInto(out *CellSelector) {
 	*out = *in
@@ -55,9 +82,56 @@ func (in *CellSelector) DeepCopy() *CellSelector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentStatus) DeepCopyInto(out *ComponentStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentStatus.
+func (in *ComponentStatus) DeepCopy() *ComponentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsulLockserver) DeepCopyInto(out *ConsulLockserver) {
+	*out = *in
+	if in.ACLTokenSecretRef != nil {
+		in, out := &in.ACLTokenSecretRef, &out.ACLTokenSecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsulLockserver.
+func (in *ConsulLockserver) DeepCopy() *ConsulLockserver {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsulLockserver)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Etcd2Lockserver) DeepCopyInto(out *Etcd2Lockserver) {
 	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(LockserverTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
@@ -71,6 +145,43 @@ func (in *Etcd2Lockserver) DeepCopy() *Etcd2Lockserver {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileBackupStorage) DeepCopyInto(out *FileBackupStorage) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileBackupStorage.
+func (in *FileBackupStorage) DeepCopy() *FileBackupStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(FileBackupStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GCSBackupStorage) DeepCopyInto(out *GCSBackupStorage) {
+	*out = *in
+	if in.CredentialsSecretRef != nil {
+		in, out := &in.CredentialsSecretRef, &out.CredentialsSecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GCSBackupStorage.
+func (in *GCSBackupStorage) DeepCopy() *GCSBackupStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(GCSBackupStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KeyRange) DeepCopyInto(out *KeyRange) {
 	*out = *in
@@ -87,6 +198,89 @@ func (in *KeyRange) DeepCopy() *KeyRange {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LockserverMember) DeepCopyInto(out *LockserverMember) {
+	*out = *in
+	if in.ClientURLs != nil {
+		in, out := &in.ClientURLs, &out.ClientURLs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PeerURLs != nil {
+		in, out := &in.PeerURLs, &out.PeerURLs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LockserverMember.
+func (in *LockserverMember) DeepCopy() *LockserverMember {
+	if in == nil {
+		return nil
+	}
+	out := new(LockserverMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LockserverTLS) DeepCopyInto(out *LockserverTLS) {
+	*out = *in
+	if in.CASecretRef != nil {
+		in, out := &in.CASecretRef, &out.CASecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CertSecretRef != nil {
+		in, out := &in.CertSecretRef, &out.CertSecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KeySecretRef != nil {
+		in, out := &in.KeySecretRef, &out.KeySecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LockserverTLS.
+func (in *LockserverTLS) DeepCopy() *LockserverTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(LockserverTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MasterElectionPolicy) DeepCopyInto(out *MasterElectionPolicy) {
+	*out = *in
+	if in.PreferredCells != nil {
+		in, out := &in.PreferredCells, &out.PreferredCells
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxReplicationLagSeconds != nil {
+		in, out := &in.MaxReplicationLagSeconds, &out.MaxReplicationLagSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MasterElectionPolicy.
+func (in *MasterElectionPolicy) DeepCopy() *MasterElectionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(MasterElectionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MySQLContainer) DeepCopyInto(out *MySQLContainer) {
 	*out = *in
@@ -104,6 +298,23 @@ func (in *MySQLContainer) DeepCopy() *MySQLContainer {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MySQLExporter) DeepCopyInto(out *MySQLExporter) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MySQLExporter.
+func (in *MySQLExporter) DeepCopy() *MySQLExporter {
+	if in == nil {
+		return nil
+	}
+	out := new(MySQLExporter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceSelector) DeepCopyInto(out *ResourceSelector) {
 	*out = *in
@@ -125,6 +336,32 @@ func (in *ResourceSelector) DeepCopy() *ResourceSelector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3BackupStorage) DeepCopyInto(out *S3BackupStorage) {
+	*out = *in
+	if in.AccessKeyIDSecretRef != nil {
+		in, out := &in.AccessKeyIDSecretRef, &out.AccessKeyIDSecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretAccessKeySecretRef != nil {
+		in, out := &in.SecretAccessKeySecretRef, &out.SecretAccessKeySecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3BackupStorage.
+func (in *S3BackupStorage) DeepCopy() *S3BackupStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(S3BackupStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TabletContainers) DeepCopyInto(out *TabletContainers) {
 	*out = *in
@@ -156,7 +393,7 @@ func (in *TabletCredentials) DeepCopyInto(out *TabletCredentials) {
 	*out = *in
 	if in.SecretRef != nil {
 		in, out := &in.SecretRef, &out.SecretRef
-		*out = new(v1.SecretReference)
+		*out = new(corev1.SecretReference)
 		**out = **in
 	}
 	return
@@ -188,16 +425,126 @@ func (in *TabletDatastore) DeepCopy() *TabletDatastore {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TabletPodStatus) DeepCopyInto(out *TabletPodStatus) {
+	*out = *in
+	if in.ReplicationLagSeconds != nil {
+		in, out := &in.ReplicationLagSeconds, &out.ReplicationLagSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TabletPodStatus.
+func (in *TabletPodStatus) DeepCopy() *TabletPodStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TabletPodStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TabletRestorePolicy) DeepCopyInto(out *TabletRestorePolicy) {
+	*out = *in
+	if in.MaxReplicationLagSeconds != nil {
+		in, out := &in.MaxReplicationLagSeconds, &out.MaxReplicationLagSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TabletRestorePolicy.
+func (in *TabletRestorePolicy) DeepCopy() *TabletRestorePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(TabletRestorePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TabletRestoreStatus) DeepCopyInto(out *TabletRestoreStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TabletRestoreStatus.
+func (in *TabletRestoreStatus) DeepCopy() *TabletRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TabletRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TabletVolumeClaim) DeepCopyInto(out *TabletVolumeClaim) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TabletVolumeClaim.
+func (in *TabletVolumeClaim) DeepCopy() *TabletVolumeClaim {
+	if in == nil {
+		return nil
+	}
+	out := new(TabletVolumeClaim)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TabletVolumeStatus) DeepCopyInto(out *TabletVolumeStatus) {
+	*out = *in
+	if in.Resizing != nil {
+		in, out := &in.Resizing, &out.Resizing
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TabletVolumeStatus.
+func (in *TabletVolumeStatus) DeepCopy() *TabletVolumeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TabletVolumeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VTComponent) DeepCopyInto(out *VTComponent) {
 	*out = *in
 	if in.ContainerSpec != nil {
 		in, out := &in.ContainerSpec, &out.ContainerSpec
-		*out = make([]*v1.Container, len(*in))
+		*out = make([]*corev1.Container, len(*in))
 		for i := range *in {
 			if (*in)[i] != nil {
 				in, out := &(*in)[i], &(*out)[i]
-				*out = new(v1.Container)
+				*out = new(corev1.Container)
 				(*in).DeepCopyInto(*out)
 			}
 		}
@@ -248,7 +595,7 @@ func (in *VTGateCredentials) DeepCopyInto(out *VTGateCredentials) {
 	*out = *in
 	if in.SecretRef != nil {
 		in, out := &in.SecretRef, &out.SecretRef
-		*out = new(v1.SecretReference)
+		*out = new(corev1.SecretReference)
 		**out = **in
 	}
 	return
@@ -281,6 +628,158 @@ func (in *VTTabletContainer) DeepCopy() *VTTabletContainer {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessBackupSchedule) DeepCopyInto(out *VitessBackupSchedule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessBackupSchedule.
+func (in *VitessBackupSchedule) DeepCopy() *VitessBackupSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessBackupSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VitessBackupSchedule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessBackupScheduleList) DeepCopyInto(out *VitessBackupScheduleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VitessBackupSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessBackupScheduleList.
+func (in *VitessBackupScheduleList) DeepCopy() *VitessBackupScheduleList {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessBackupScheduleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VitessBackupScheduleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessBackupScheduleSpec) DeepCopyInto(out *VitessBackupScheduleSpec) {
+	*out = *in
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(BackupRetentionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SuccessfulJobsHistoryLimit != nil {
+		in, out := &in.SuccessfulJobsHistoryLimit, &out.SuccessfulJobsHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailedJobsHistoryLimit != nil {
+		in, out := &in.FailedJobsHistoryLimit, &out.FailedJobsHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessBackupScheduleSpec.
+func (in *VitessBackupScheduleSpec) DeepCopy() *VitessBackupScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessBackupScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessBackupScheduleStatus) DeepCopyInto(out *VitessBackupScheduleStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulTime != nil {
+		in, out := &in.LastSuccessfulTime, &out.LastSuccessfulTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Backups != nil {
+		in, out := &in.Backups, &out.Backups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessBackupScheduleStatus.
+func (in *VitessBackupScheduleStatus) DeepCopy() *VitessBackupScheduleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessBackupScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessBackupStorage) DeepCopyInto(out *VitessBackupStorage) {
+	*out = *in
+	if in.File != nil {
+		in, out := &in.File, &out.File
+		*out = new(FileBackupStorage)
+		**out = **in
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3BackupStorage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GCS != nil {
+		in, out := &in.GCS, &out.GCS
+		*out = new(GCSBackupStorage)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessBackupStorage.
+func (in *VitessBackupStorage) DeepCopy() *VitessBackupStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessBackupStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VitessBatchOptions) DeepCopyInto(out *VitessBatchOptions) {
 	*out = *in
@@ -383,7 +882,7 @@ func (in *VitessCellMySQLProtocol) DeepCopyInto(out *VitessCellMySQLProtocol) {
 	*out = *in
 	if in.PasswordSecretRef != nil {
 		in, out := &in.PasswordSecretRef, &out.PasswordSecretRef
-		*out = new(v1.SecretKeySelector)
+		*out = new(corev1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
 	return
@@ -430,7 +929,7 @@ func (in *VitessCellSpec) DeepCopyInto(out *VitessCellSpec) {
 	}
 	if in.LockserverRef != nil {
 		in, out := &in.LockserverRef, &out.LockserverRef
-		*out = new(v1.LocalObjectReference)
+		*out = new(corev1.LocalObjectReference)
 		**out = **in
 	}
 	if in.Defaults != nil {
@@ -485,6 +984,24 @@ func (in *VitessCellSpec) DeepCopy() *VitessCellSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessCellStatus) DeepCopyInto(out *VitessCellStatus) {
+	*out = *in
+	out.VTCtld = in.VTCtld
+	out.VTGate = in.VTGate
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessCellStatus.
+func (in *VitessCellStatus) DeepCopy() *VitessCellStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessCellStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VitessCluster) DeepCopyInto(out *VitessCluster) {
 	*out = *in
@@ -572,7 +1089,7 @@ func (in *VitessClusterSpec) DeepCopyInto(out *VitessClusterSpec) {
 	}
 	if in.LockserverRef != nil {
 		in, out := &in.LockserverRef, &out.LockserverRef
-		*out = new(v1.LocalObjectReference)
+		*out = new(corev1.LocalObjectReference)
 		**out = **in
 	}
 	if in.Cells != nil {
@@ -611,6 +1128,16 @@ func (in *VitessClusterSpec) DeepCopyInto(out *VitessClusterSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.BackupStorage != nil {
+		in, out := &in.BackupStorage, &out.BackupStorage
+		*out = new(VitessBackupStorage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(VitessMonitoring)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -632,10 +1159,75 @@ func (in *VitessClusterStatus) DeepCopyInto(out *VitessClusterStatus) {
 		*out = make([]VitessClusterCondition, len(*in))
 		copy(*out, *in)
 	}
-	if in.Lockserver != nil {
-		in, out := &in.Lockserver, &out.Lockserver
-		*out = new(VitessLockserverStatus)
-		**out = **in
+	if in.Lockserver != nil {
+		in, out := &in.Lockserver, &out.Lockserver
+		*out = new(VitessLockserverStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RegisteredCells != nil {
+		in, out := &in.RegisteredCells, &out.RegisteredCells
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Cells != nil {
+		in, out := &in.Cells, &out.Cells
+		*out = make(map[string]*VitessCellStatus, len(*in))
+		for key, val := range *in {
+			var outVal *VitessCellStatus
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(VitessCellStatus)
+				**out = **in
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Keyspaces != nil {
+		in, out := &in.Keyspaces, &out.Keyspaces
+		*out = make(map[string]*VitessKeyspaceStatus, len(*in))
+		for key, val := range *in {
+			var outVal *VitessKeyspaceStatus
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(VitessKeyspaceStatus)
+				**out = **in
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Shards != nil {
+		in, out := &in.Shards, &out.Shards
+		*out = make(map[string]*VitessShardStatus, len(*in))
+		for key, val := range *in {
+			var outVal *VitessShardStatus
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(VitessShardStatus)
+				**out = **in
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Tablets != nil {
+		in, out := &in.Tablets, &out.Tablets
+		*out = make(map[string]*VitessTabletStatus, len(*in))
+		for key, val := range *in {
+			var outVal *VitessTabletStatus
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(VitessTabletStatus)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
 	}
 	return
 }
@@ -656,6 +1248,7 @@ func (in *VitessKeyspace) DeepCopyInto(out *VitessKeyspace) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
 	return
 }
 
@@ -771,13 +1364,29 @@ func (in *VitessKeyspaceSpec) DeepCopy() *VitessKeyspaceSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessKeyspaceStatus) DeepCopyInto(out *VitessKeyspaceStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessKeyspaceStatus.
+func (in *VitessKeyspaceStatus) DeepCopy() *VitessKeyspaceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessKeyspaceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VitessLockserver) DeepCopyInto(out *VitessLockserver) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -799,6 +1408,22 @@ func (in *VitessLockserver) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessLockserverCondition) DeepCopyInto(out *VitessLockserverCondition) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessLockserverCondition.
+func (in *VitessLockserverCondition) DeepCopy() *VitessLockserverCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessLockserverCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VitessLockserverList) DeepCopyInto(out *VitessLockserverList) {
 	*out = *in
@@ -838,7 +1463,17 @@ func (in *VitessLockserverSpec) DeepCopyInto(out *VitessLockserverSpec) {
 	if in.Etcd2 != nil {
 		in, out := &in.Etcd2, &out.Etcd2
 		*out = new(Etcd2Lockserver)
-		**out = **in
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Zk2 != nil {
+		in, out := &in.Zk2, &out.Zk2
+		*out = new(Zk2Lockserver)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Consul != nil {
+		in, out := &in.Consul, &out.Consul
+		*out = new(ConsulLockserver)
+		(*in).DeepCopyInto(*out)
 	}
 	return
 }
@@ -856,6 +1491,18 @@ func (in *VitessLockserverSpec) DeepCopy() *VitessLockserverSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VitessLockserverStatus) DeepCopyInto(out *VitessLockserverStatus) {
 	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]LockserverMember, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]VitessLockserverCondition, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -869,12 +1516,195 @@ func (in *VitessLockserverStatus) DeepCopy() *VitessLockserverStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessMonitoring) DeepCopyInto(out *VitessMonitoring) {
+	*out = *in
+	if in.MySQLExporter != nil {
+		in, out := &in.MySQLExporter, &out.MySQLExporter
+		*out = new(MySQLExporter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceMonitor != nil {
+		in, out := &in.ServiceMonitor, &out.ServiceMonitor
+		*out = new(VitessServiceMonitor)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessMonitoring.
+func (in *VitessMonitoring) DeepCopy() *VitessMonitoring {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessMonitoring)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessRestore) DeepCopyInto(out *VitessRestore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessRestore.
+func (in *VitessRestore) DeepCopy() *VitessRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VitessRestore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessRestoreList) DeepCopyInto(out *VitessRestoreList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VitessRestore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessRestoreList.
+func (in *VitessRestoreList) DeepCopy() *VitessRestoreList {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessRestoreList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VitessRestoreList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessRestoreShardStatus) DeepCopyInto(out *VitessRestoreShardStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessRestoreShardStatus.
+func (in *VitessRestoreShardStatus) DeepCopy() *VitessRestoreShardStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessRestoreShardStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessRestoreSpec) DeepCopyInto(out *VitessRestoreSpec) {
+	*out = *in
+	in.Source.DeepCopyInto(&out.Source)
+	if in.RestoreToTime != nil {
+		in, out := &in.RestoreToTime, &out.RestoreToTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessRestoreSpec.
+func (in *VitessRestoreSpec) DeepCopy() *VitessRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessRestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessRestoreStatus) DeepCopyInto(out *VitessRestoreStatus) {
+	*out = *in
+	if in.Shards != nil {
+		in, out := &in.Shards, &out.Shards
+		*out = make(map[string]*VitessRestoreShardStatus, len(*in))
+		for key, val := range *in {
+			var outVal *VitessRestoreShardStatus
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(VitessRestoreShardStatus)
+				**out = **in
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessRestoreStatus.
+func (in *VitessRestoreStatus) DeepCopy() *VitessRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessServiceMonitor) DeepCopyInto(out *VitessServiceMonitor) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessServiceMonitor.
+func (in *VitessServiceMonitor) DeepCopy() *VitessServiceMonitor {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessServiceMonitor)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VitessShard) DeepCopyInto(out *VitessShard) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
 	return
 }
 
@@ -943,6 +1773,21 @@ func (in *VitessShardOptions) DeepCopyInto(out *VitessShardOptions) {
 		*out = new(TabletContainers)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.VolumeClaim != nil {
+		in, out := &in.VolumeClaim, &out.VolumeClaim
+		*out = new(TabletVolumeClaim)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BackupStorage != nil {
+		in, out := &in.BackupStorage, &out.BackupStorage
+		*out = new(VitessBackupStorage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Restore != nil {
+		in, out := &in.Restore, &out.Restore
+		*out = new(TabletRestorePolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Cells != nil {
 		in, out := &in.Cells, &out.Cells
 		*out = make([]string, len(*in))
@@ -955,6 +1800,11 @@ func (in *VitessShardOptions) DeepCopyInto(out *VitessShardOptions) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.MasterElection != nil {
+		in, out := &in.MasterElection, &out.MasterElection
+		*out = new(MasterElectionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1035,12 +1885,29 @@ func (in *VitessShardSpec) DeepCopy() *VitessShardSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessShardStatus) DeepCopyInto(out *VitessShardStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessShardStatus.
+func (in *VitessShardStatus) DeepCopy() *VitessShardStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessShardStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VitessTablet) DeepCopyInto(out *VitessTablet) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -1147,15 +2014,30 @@ func (in *VitessTabletSpec) DeepCopyInto(out *VitessTabletSpec) {
 	}
 	if in.VolumeClaim != nil {
 		in, out := &in.VolumeClaim, &out.VolumeClaim
-		*out = new(v1.PersistentVolumeClaimVolumeSource)
-		**out = **in
+		*out = new(TabletVolumeClaim)
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Credentials != nil {
 		in, out := &in.Credentials, &out.Credentials
 		*out = new(TabletCredentials)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Restore != nil {
+		in, out := &in.Restore, &out.Restore
+		*out = new(TabletRestorePolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	in.parent.DeepCopyInto(&out.parent)
+	if in.restore != nil {
+		in, out := &in.restore, &out.restore
+		*out = new(VitessRestore)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.uidBase != nil {
+		in, out := &in.uidBase, &out.uidBase
+		*out = new(uint32)
+		**out = **in
+	}
 	return
 }
 
@@ -1168,3 +2050,57 @@ func (in *VitessTabletSpec) DeepCopy() *VitessTabletSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessTabletStatus) DeepCopyInto(out *VitessTabletStatus) {
+	*out = *in
+	if in.Volume != nil {
+		in, out := &in.Volume, &out.Volume
+		*out = new(TabletVolumeStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Restores != nil {
+		in, out := &in.Restores, &out.Restores
+		*out = make([]TabletRestoreStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]TabletPodStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessTabletStatus.
+func (in *VitessTabletStatus) DeepCopy() *VitessTabletStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessTabletStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Zk2Lockserver) DeepCopyInto(out *Zk2Lockserver) {
+	*out = *in
+	if in.Servers != nil {
+		in, out := &in.Servers, &out.Servers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Zk2Lockserver.
+func (in *Zk2Lockserver) DeepCopy() *Zk2Lockserver {
+	if in == nil {
+		return nil
+	}
+	out := new(Zk2Lockserver)
+	in.DeepCopyInto(out)
+	return out
+}
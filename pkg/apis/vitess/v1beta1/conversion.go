@@ -0,0 +1,999 @@
+package v1beta1
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	"vitess.io/vitess-operator/pkg/util/conversion"
+)
+
+// v1beta1 objects convert to and from the v1alpha2 Hub. Types whose fields are the same in both versions
+// are converted in place, so the converted objects share memory with the objects they were converted from.
+
+var (
+	_ conversion.Convertible = &VitessCluster{}
+	_ conversion.Convertible = &VitessCell{}
+	_ conversion.Convertible = &VitessKeyspace{}
+	_ conversion.Convertible = &VitessShard{}
+	_ conversion.Convertible = &VitessTablet{}
+	_ conversion.Convertible = &VitessLockserver{}
+	_ conversion.Convertible = &VitessBackupSchedule{}
+	_ conversion.Convertible = &VitessRestore{}
+)
+
+func hubTypeError(kind string, hub conversion.Hub) error {
+	return fmt.Errorf("Cannot convert %s to or from %T", kind, hub)
+}
+
+// ConvertTo converts the cluster and the objects embedded in it to v1alpha2
+func (cluster *VitessCluster) ConvertTo(hub conversion.Hub) error {
+	dst, ok := hub.(*v1alpha2.VitessCluster)
+	if !ok {
+		return hubTypeError("VitessCluster", hub)
+	}
+	*dst = *clusterToV1alpha2(cluster)
+	return nil
+}
+
+// ConvertFrom converts the cluster and the objects embedded in it from v1alpha2
+func (cluster *VitessCluster) ConvertFrom(hub conversion.Hub) error {
+	src, ok := hub.(*v1alpha2.VitessCluster)
+	if !ok {
+		return hubTypeError("VitessCluster", hub)
+	}
+	*cluster = *clusterFromV1alpha2(src)
+	return nil
+}
+
+// ConvertTo converts the cell to v1alpha2
+func (cell *VitessCell) ConvertTo(hub conversion.Hub) error {
+	dst, ok := hub.(*v1alpha2.VitessCell)
+	if !ok {
+		return hubTypeError("VitessCell", hub)
+	}
+	*dst = *cellToV1alpha2(cell)
+	return nil
+}
+
+// ConvertFrom converts the cell from v1alpha2
+func (cell *VitessCell) ConvertFrom(hub conversion.Hub) error {
+	src, ok := hub.(*v1alpha2.VitessCell)
+	if !ok {
+		return hubTypeError("VitessCell", hub)
+	}
+	*cell = *cellFromV1alpha2(src)
+	return nil
+}
+
+// ConvertTo converts the keyspace and the objects embedded in it to v1alpha2
+func (keyspace *VitessKeyspace) ConvertTo(hub conversion.Hub) error {
+	dst, ok := hub.(*v1alpha2.VitessKeyspace)
+	if !ok {
+		return hubTypeError("VitessKeyspace", hub)
+	}
+	*dst = *keyspaceToV1alpha2(keyspace)
+	return nil
+}
+
+// ConvertFrom converts the keyspace and the objects embedded in it from v1alpha2
+func (keyspace *VitessKeyspace) ConvertFrom(hub conversion.Hub) error {
+	src, ok := hub.(*v1alpha2.VitessKeyspace)
+	if !ok {
+		return hubTypeError("VitessKeyspace", hub)
+	}
+	*keyspace = *keyspaceFromV1alpha2(src)
+	return nil
+}
+
+// ConvertTo converts the shard and the tablets embedded in it to v1alpha2
+func (shard *VitessShard) ConvertTo(hub conversion.Hub) error {
+	dst, ok := hub.(*v1alpha2.VitessShard)
+	if !ok {
+		return hubTypeError("VitessShard", hub)
+	}
+	*dst = *shardToV1alpha2(shard)
+	return nil
+}
+
+// ConvertFrom converts the shard and the tablets embedded in it from v1alpha2
+func (shard *VitessShard) ConvertFrom(hub conversion.Hub) error {
+	src, ok := hub.(*v1alpha2.VitessShard)
+	if !ok {
+		return hubTypeError("VitessShard", hub)
+	}
+	*shard = *shardFromV1alpha2(src)
+	return nil
+}
+
+// ConvertTo converts the tablet to v1alpha2
+func (tablet *VitessTablet) ConvertTo(hub conversion.Hub) error {
+	dst, ok := hub.(*v1alpha2.VitessTablet)
+	if !ok {
+		return hubTypeError("VitessTablet", hub)
+	}
+	*dst = *tabletToV1alpha2(tablet)
+	return nil
+}
+
+// ConvertFrom converts the tablet from v1alpha2
+func (tablet *VitessTablet) ConvertFrom(hub conversion.Hub) error {
+	src, ok := hub.(*v1alpha2.VitessTablet)
+	if !ok {
+		return hubTypeError("VitessTablet", hub)
+	}
+	*tablet = *tabletFromV1alpha2(src)
+	return nil
+}
+
+// ConvertTo converts the lockserver to v1alpha2
+func (lockserver *VitessLockserver) ConvertTo(hub conversion.Hub) error {
+	dst, ok := hub.(*v1alpha2.VitessLockserver)
+	if !ok {
+		return hubTypeError("VitessLockserver", hub)
+	}
+	*dst = *lockserverToV1alpha2(lockserver)
+	return nil
+}
+
+// ConvertFrom converts the lockserver from v1alpha2
+func (lockserver *VitessLockserver) ConvertFrom(hub conversion.Hub) error {
+	src, ok := hub.(*v1alpha2.VitessLockserver)
+	if !ok {
+		return hubTypeError("VitessLockserver", hub)
+	}
+	*lockserver = *lockserverFromV1alpha2(src)
+	return nil
+}
+
+// ConvertTo converts the backup schedule to v1alpha2
+func (schedule *VitessBackupSchedule) ConvertTo(hub conversion.Hub) error {
+	dst, ok := hub.(*v1alpha2.VitessBackupSchedule)
+	if !ok {
+		return hubTypeError("VitessBackupSchedule", hub)
+	}
+	*dst = v1alpha2.VitessBackupSchedule{
+		TypeMeta:   typeMetaToV1alpha2(schedule.TypeMeta),
+		ObjectMeta: schedule.ObjectMeta,
+		Spec: v1alpha2.VitessBackupScheduleSpec{
+			Cluster:   schedule.Spec.Cluster,
+			Keyspace:  schedule.Spec.Keyspace,
+			Shard:     schedule.Spec.Shard,
+			Schedule:  schedule.Spec.Schedule,
+			Retention: (*v1alpha2.BackupRetentionPolicy)(schedule.Spec.Retention),
+			Suspend:   schedule.Spec.Suspend,
+
+			SuccessfulJobsHistoryLimit: schedule.Spec.SuccessfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     schedule.Spec.FailedJobsHistoryLimit,
+		},
+		Status: v1alpha2.VitessBackupScheduleStatus(schedule.Status),
+	}
+	return nil
+}
+
+// ConvertFrom converts the backup schedule from v1alpha2
+func (schedule *VitessBackupSchedule) ConvertFrom(hub conversion.Hub) error {
+	src, ok := hub.(*v1alpha2.VitessBackupSchedule)
+	if !ok {
+		return hubTypeError("VitessBackupSchedule", hub)
+	}
+	*schedule = VitessBackupSchedule{
+		TypeMeta:   typeMetaFromV1alpha2(src.TypeMeta),
+		ObjectMeta: src.ObjectMeta,
+		Spec: VitessBackupScheduleSpec{
+			Cluster:   src.Spec.Cluster,
+			Keyspace:  src.Spec.Keyspace,
+			Shard:     src.Spec.Shard,
+			Schedule:  src.Spec.Schedule,
+			Retention: (*BackupRetentionPolicy)(src.Spec.Retention),
+			Suspend:   src.Spec.Suspend,
+
+			SuccessfulJobsHistoryLimit: src.Spec.SuccessfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     src.Spec.FailedJobsHistoryLimit,
+		},
+		Status: VitessBackupScheduleStatus(src.Status),
+	}
+	return nil
+}
+
+// ConvertTo converts the restore to v1alpha2
+func (restore *VitessRestore) ConvertTo(hub conversion.Hub) error {
+	dst, ok := hub.(*v1alpha2.VitessRestore)
+	if !ok {
+		return hubTypeError("VitessRestore", hub)
+	}
+	*dst = v1alpha2.VitessRestore{
+		TypeMeta:   typeMetaToV1alpha2(restore.TypeMeta),
+		ObjectMeta: restore.ObjectMeta,
+		Spec: v1alpha2.VitessRestoreSpec{
+			Cluster:             restore.Spec.Cluster,
+			Keyspace:            restore.Spec.Keyspace,
+			Source:              *backupStorageToV1alpha2(&restore.Spec.Source),
+			RestoreToTime:       restore.Spec.RestoreToTime,
+			RestoreToPosition:   restore.Spec.RestoreToPosition,
+			BinlogSourceCluster: restore.Spec.BinlogSourceCluster,
+		},
+		Status: v1alpha2.VitessRestoreStatus{
+			Phase:          v1alpha2.RestorePhase(restore.Status.Phase),
+			Message:        restore.Status.Message,
+			CompletionTime: restore.Status.CompletionTime,
+		},
+	}
+	if restore.Status.Shards != nil {
+		dst.Status.Shards = map[string]*v1alpha2.VitessRestoreShardStatus{}
+		for key, shard := range restore.Status.Shards {
+			dst.Status.Shards[key] = nil
+			if shard != nil {
+				dst.Status.Shards[key] = &v1alpha2.VitessRestoreShardStatus{
+					Phase:           v1alpha2.RestorePhase(shard.Phase),
+					Backup:          shard.Backup,
+					RestoredTablets: shard.RestoredTablets,
+					ReplayedTablets: shard.ReplayedTablets,
+					Tablets:         shard.Tablets,
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts the restore from v1alpha2
+func (restore *VitessRestore) ConvertFrom(hub conversion.Hub) error {
+	src, ok := hub.(*v1alpha2.VitessRestore)
+	if !ok {
+		return hubTypeError("VitessRestore", hub)
+	}
+	*restore = VitessRestore{
+		TypeMeta:   typeMetaFromV1alpha2(src.TypeMeta),
+		ObjectMeta: src.ObjectMeta,
+		Spec: VitessRestoreSpec{
+			Cluster:             src.Spec.Cluster,
+			Keyspace:            src.Spec.Keyspace,
+			Source:              *backupStorageFromV1alpha2(&src.Spec.Source),
+			RestoreToTime:       src.Spec.RestoreToTime,
+			RestoreToPosition:   src.Spec.RestoreToPosition,
+			BinlogSourceCluster: src.Spec.BinlogSourceCluster,
+		},
+		Status: VitessRestoreStatus{
+			Phase:          RestorePhase(src.Status.Phase),
+			Message:        src.Status.Message,
+			CompletionTime: src.Status.CompletionTime,
+		},
+	}
+	if src.Status.Shards != nil {
+		restore.Status.Shards = map[string]*VitessRestoreShardStatus{}
+		for key, shard := range src.Status.Shards {
+			restore.Status.Shards[key] = nil
+			if shard != nil {
+				restore.Status.Shards[key] = &VitessRestoreShardStatus{
+					Phase:           RestorePhase(shard.Phase),
+					Backup:          shard.Backup,
+					RestoredTablets: shard.RestoredTablets,
+					ReplayedTablets: shard.ReplayedTablets,
+					Tablets:         shard.Tablets,
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// typeMetaToV1alpha2 keeps the kind and the version of embedded objects that set them
+func typeMetaToV1alpha2(in metav1.TypeMeta) metav1.TypeMeta {
+	if in.APIVersion == SchemeGroupVersion.String() {
+		in.APIVersion = v1alpha2.SchemeGroupVersion.String()
+	}
+	return in
+}
+
+func typeMetaFromV1alpha2(in metav1.TypeMeta) metav1.TypeMeta {
+	if in.APIVersion == v1alpha2.SchemeGroupVersion.String() {
+		in.APIVersion = SchemeGroupVersion.String()
+	}
+	return in
+}
+
+func clusterToV1alpha2(in *VitessCluster) *v1alpha2.VitessCluster {
+	out := &v1alpha2.VitessCluster{
+		TypeMeta:   typeMetaToV1alpha2(in.TypeMeta),
+		ObjectMeta: in.ObjectMeta,
+		Spec: v1alpha2.VitessClusterSpec{
+			LockserverRef:    in.Spec.LockserverRef,
+			CellSelector:     selectorsToV1alpha2(in.Spec.CellSelector),
+			KeyspaceSelector: selectorsToV1alpha2(in.Spec.KeyspaceSelector),
+			BackupStorage:    backupStorageToV1alpha2(in.Spec.BackupStorage),
+		},
+		Status: v1alpha2.VitessClusterStatus{
+			Phase:           v1alpha2.ClusterPhase(in.Status.Phase),
+			Reason:          in.Status.Reason,
+			Message:         in.Status.Message,
+			Lockserver:      lockserverStatusToV1alpha2(in.Status.Lockserver),
+			RegisteredCells: in.Status.RegisteredCells,
+			ReadyTablets:    in.Status.ReadyTablets,
+		},
+	}
+
+	if in.Spec.Lockserver != nil {
+		out.Spec.Lockserver = lockserverToV1alpha2(in.Spec.Lockserver)
+	}
+	if in.Spec.Cells != nil {
+		out.Spec.Cells = []*v1alpha2.VitessCell{}
+		for _, cell := range in.Spec.Cells {
+			out.Spec.Cells = append(out.Spec.Cells, cellToV1alpha2(cell))
+		}
+	}
+	if in.Spec.Keyspaces != nil {
+		out.Spec.Keyspaces = []*v1alpha2.VitessKeyspace{}
+		for _, keyspace := range in.Spec.Keyspaces {
+			out.Spec.Keyspaces = append(out.Spec.Keyspaces, keyspaceToV1alpha2(keyspace))
+		}
+	}
+	if in.Spec.Monitoring != nil {
+		out.Spec.Monitoring = &v1alpha2.VitessMonitoring{
+			MySQLExporter:  (*v1alpha2.MySQLExporter)(in.Spec.Monitoring.MySQLExporter),
+			ServiceMonitor: (*v1alpha2.VitessServiceMonitor)(in.Spec.Monitoring.ServiceMonitor),
+		}
+	}
+
+	if in.Status.Conditions != nil {
+		out.Status.Conditions = []v1alpha2.VitessClusterCondition{}
+		for _, condition := range in.Status.Conditions {
+			out.Status.Conditions = append(out.Status.Conditions, v1alpha2.VitessClusterCondition{
+				Type:               v1alpha2.ClusterConditionType(condition.Type),
+				Status:             condition.Status,
+				LastUpdateTime:     condition.LastUpdateTime,
+				LastTransitionTime: condition.LastTransitionTime,
+				Reason:             condition.Reason,
+				Message:            condition.Message,
+			})
+		}
+	}
+	if in.Status.Cells != nil {
+		out.Status.Cells = map[string]*v1alpha2.VitessCellStatus{}
+		for name, status := range in.Status.Cells {
+			out.Status.Cells[name] = nil
+			if status != nil {
+				out.Status.Cells[name] = &v1alpha2.VitessCellStatus{
+					VTCtld: v1alpha2.ComponentStatus(status.VTCtld),
+					VTGate: v1alpha2.ComponentStatus(status.VTGate),
+				}
+			}
+		}
+	}
+	if in.Status.Keyspaces != nil {
+		out.Status.Keyspaces = map[string]*v1alpha2.VitessKeyspaceStatus{}
+		for name, status := range in.Status.Keyspaces {
+			out.Status.Keyspaces[name] = (*v1alpha2.VitessKeyspaceStatus)(status)
+		}
+	}
+	if in.Status.Shards != nil {
+		out.Status.Shards = map[string]*v1alpha2.VitessShardStatus{}
+		for name, status := range in.Status.Shards {
+			out.Status.Shards[name] = (*v1alpha2.VitessShardStatus)(status)
+		}
+	}
+	if in.Status.Tablets != nil {
+		out.Status.Tablets = map[string]*v1alpha2.VitessTabletStatus{}
+		for name, status := range in.Status.Tablets {
+			out.Status.Tablets[name] = nil
+			if status != nil {
+				out.Status.Tablets[name] = tabletStatusToV1alpha2(status)
+			}
+		}
+	}
+
+	return out
+}
+
+func clusterFromV1alpha2(in *v1alpha2.VitessCluster) *VitessCluster {
+	out := &VitessCluster{
+		TypeMeta:   typeMetaFromV1alpha2(in.TypeMeta),
+		ObjectMeta: in.ObjectMeta,
+		Spec: VitessClusterSpec{
+			LockserverRef:    in.Spec.LockserverRef,
+			CellSelector:     selectorsFromV1alpha2(in.Spec.CellSelector),
+			KeyspaceSelector: selectorsFromV1alpha2(in.Spec.KeyspaceSelector),
+			BackupStorage:    backupStorageFromV1alpha2(in.Spec.BackupStorage),
+		},
+		Status: VitessClusterStatus{
+			Phase:           ClusterPhase(in.Status.Phase),
+			Reason:          in.Status.Reason,
+			Message:         in.Status.Message,
+			Lockserver:      lockserverStatusFromV1alpha2(in.Status.Lockserver),
+			RegisteredCells: in.Status.RegisteredCells,
+			ReadyTablets:    in.Status.ReadyTablets,
+		},
+	}
+
+	if in.Spec.Lockserver != nil {
+		out.Spec.Lockserver = lockserverFromV1alpha2(in.Spec.Lockserver)
+	}
+	if in.Spec.Cells != nil {
+		out.Spec.Cells = []*VitessCell{}
+		for _, cell := range in.Spec.Cells {
+			out.Spec.Cells = append(out.Spec.Cells, cellFromV1alpha2(cell))
+		}
+	}
+	if in.Spec.Keyspaces != nil {
+		out.Spec.Keyspaces = []*VitessKeyspace{}
+		for _, keyspace := range in.Spec.Keyspaces {
+			out.Spec.Keyspaces = append(out.Spec.Keyspaces, keyspaceFromV1alpha2(keyspace))
+		}
+	}
+	if in.Spec.Monitoring != nil {
+		out.Spec.Monitoring = &VitessMonitoring{
+			MySQLExporter:  (*MySQLExporter)(in.Spec.Monitoring.MySQLExporter),
+			ServiceMonitor: (*VitessServiceMonitor)(in.Spec.Monitoring.ServiceMonitor),
+		}
+	}
+
+	if in.Status.Conditions != nil {
+		out.Status.Conditions = []VitessClusterCondition{}
+		for _, condition := range in.Status.Conditions {
+			out.Status.Conditions = append(out.Status.Conditions, VitessClusterCondition{
+				Type:               ClusterConditionType(condition.Type),
+				Status:             condition.Status,
+				LastUpdateTime:     condition.LastUpdateTime,
+				LastTransitionTime: condition.LastTransitionTime,
+				Reason:             condition.Reason,
+				Message:            condition.Message,
+			})
+		}
+	}
+	if in.Status.Cells != nil {
+		out.Status.Cells = map[string]*VitessCellStatus{}
+		for name, status := range in.Status.Cells {
+			out.Status.Cells[name] = nil
+			if status != nil {
+				out.Status.Cells[name] = &VitessCellStatus{
+					VTCtld: ComponentStatus(status.VTCtld),
+					VTGate: ComponentStatus(status.VTGate),
+				}
+			}
+		}
+	}
+	if in.Status.Keyspaces != nil {
+		out.Status.Keyspaces = map[string]*VitessKeyspaceStatus{}
+		for name, status := range in.Status.Keyspaces {
+			out.Status.Keyspaces[name] = (*VitessKeyspaceStatus)(status)
+		}
+	}
+	if in.Status.Shards != nil {
+		out.Status.Shards = map[string]*VitessShardStatus{}
+		for name, status := range in.Status.Shards {
+			out.Status.Shards[name] = (*VitessShardStatus)(status)
+		}
+	}
+	if in.Status.Tablets != nil {
+		out.Status.Tablets = map[string]*VitessTabletStatus{}
+		for name, status := range in.Status.Tablets {
+			out.Status.Tablets[name] = nil
+			if status != nil {
+				out.Status.Tablets[name] = tabletStatusFromV1alpha2(status)
+			}
+		}
+	}
+
+	return out
+}
+
+func cellToV1alpha2(in *VitessCell) *v1alpha2.VitessCell {
+	if in == nil {
+		return nil
+	}
+
+	out := &v1alpha2.VitessCell{
+		TypeMeta:   typeMetaToV1alpha2(in.TypeMeta),
+		ObjectMeta: in.ObjectMeta,
+		Spec: v1alpha2.VitessCellSpec{
+			LockserverRef: in.Spec.LockserverRef,
+			Defaults:      (*v1alpha2.VitessCellDefaults)(in.Spec.Defaults),
+			VTGate:        componentsToV1alpha2(in.Spec.VTGate),
+			VTWorker:      componentsToV1alpha2(in.Spec.VTWorker),
+			VTCtld:        componentsToV1alpha2(in.Spec.VTCtld),
+			Orchestrator:  componentsToV1alpha2(in.Spec.Orchestrator),
+		},
+	}
+
+	if in.Spec.Lockserver != nil {
+		out.Spec.Lockserver = lockserverToV1alpha2(in.Spec.Lockserver)
+	}
+	if in.Spec.MySQLProtocol != nil {
+		out.Spec.MySQLProtocol = &v1alpha2.VitessCellMySQLProtocol{
+			AuthType:          v1alpha2.VitessMySQLAuthType(in.Spec.MySQLProtocol.AuthType),
+			Username:          in.Spec.MySQLProtocol.Username,
+			PasswordSecretRef: in.Spec.MySQLProtocol.PasswordSecretRef,
+		}
+	}
+
+	return out
+}
+
+func cellFromV1alpha2(in *v1alpha2.VitessCell) *VitessCell {
+	if in == nil {
+		return nil
+	}
+
+	out := &VitessCell{
+		TypeMeta:   typeMetaFromV1alpha2(in.TypeMeta),
+		ObjectMeta: in.ObjectMeta,
+		Spec: VitessCellSpec{
+			LockserverRef: in.Spec.LockserverRef,
+			Defaults:      (*VitessCellDefaults)(in.Spec.Defaults),
+			VTGate:        componentsFromV1alpha2(in.Spec.VTGate),
+			VTWorker:      componentsFromV1alpha2(in.Spec.VTWorker),
+			VTCtld:        componentsFromV1alpha2(in.Spec.VTCtld),
+			Orchestrator:  componentsFromV1alpha2(in.Spec.Orchestrator),
+		},
+	}
+
+	if in.Spec.Lockserver != nil {
+		out.Spec.Lockserver = lockserverFromV1alpha2(in.Spec.Lockserver)
+	}
+	if in.Spec.MySQLProtocol != nil {
+		out.Spec.MySQLProtocol = &VitessCellMySQLProtocol{
+			AuthType:          VitessMySQLAuthType(in.Spec.MySQLProtocol.AuthType),
+			Username:          in.Spec.MySQLProtocol.Username,
+			PasswordSecretRef: in.Spec.MySQLProtocol.PasswordSecretRef,
+		}
+	}
+
+	return out
+}
+
+func componentsToV1alpha2(in []VTComponent) []v1alpha2.VTComponent {
+	if in == nil {
+		return nil
+	}
+
+	out := []v1alpha2.VTComponent{}
+	for _, component := range in {
+		out = append(out, v1alpha2.VTComponent(component))
+	}
+	return out
+}
+
+func componentsFromV1alpha2(in []v1alpha2.VTComponent) []VTComponent {
+	if in == nil {
+		return nil
+	}
+
+	out := []VTComponent{}
+	for _, component := range in {
+		out = append(out, VTComponent(component))
+	}
+	return out
+}
+
+func keyspaceToV1alpha2(in *VitessKeyspace) *v1alpha2.VitessKeyspace {
+	if in == nil {
+		return nil
+	}
+
+	out := &v1alpha2.VitessKeyspace{
+		TypeMeta:   typeMetaToV1alpha2(in.TypeMeta),
+		ObjectMeta: in.ObjectMeta,
+		Spec: v1alpha2.VitessKeyspaceSpec{
+			Defaults:      shardOptionsToV1alpha2(in.Spec.Defaults),
+			ShardSelector: selectorsToV1alpha2(in.Spec.ShardSelector),
+		},
+		Status: v1alpha2.VitessKeyspaceStatus(in.Status),
+	}
+
+	if in.Spec.Shards != nil {
+		out.Spec.Shards = []*v1alpha2.VitessShard{}
+		for _, shard := range in.Spec.Shards {
+			out.Spec.Shards = append(out.Spec.Shards, shardToV1alpha2(shard))
+		}
+	}
+
+	return out
+}
+
+func keyspaceFromV1alpha2(in *v1alpha2.VitessKeyspace) *VitessKeyspace {
+	if in == nil {
+		return nil
+	}
+
+	out := &VitessKeyspace{
+		TypeMeta:   typeMetaFromV1alpha2(in.TypeMeta),
+		ObjectMeta: in.ObjectMeta,
+		Spec: VitessKeyspaceSpec{
+			Defaults:      shardOptionsFromV1alpha2(in.Spec.Defaults),
+			ShardSelector: selectorsFromV1alpha2(in.Spec.ShardSelector),
+		},
+		Status: VitessKeyspaceStatus(in.Status),
+	}
+
+	if in.Spec.Shards != nil {
+		out.Spec.Shards = []*VitessShard{}
+		for _, shard := range in.Spec.Shards {
+			out.Spec.Shards = append(out.Spec.Shards, shardFromV1alpha2(shard))
+		}
+	}
+
+	return out
+}
+
+func shardToV1alpha2(in *VitessShard) *v1alpha2.VitessShard {
+	if in == nil {
+		return nil
+	}
+
+	out := &v1alpha2.VitessShard{
+		TypeMeta:   typeMetaToV1alpha2(in.TypeMeta),
+		ObjectMeta: in.ObjectMeta,
+		Spec: v1alpha2.VitessShardSpec{
+			Defaults:       shardOptionsToV1alpha2(in.Spec.Defaults),
+			KeyRange:       v1alpha2.KeyRange(in.Spec.KeyRange),
+			TabletSelector: selectorsToV1alpha2(in.Spec.TabletSelector),
+		},
+		Status: v1alpha2.VitessShardStatus(in.Status),
+	}
+
+	if in.Spec.Tablets != nil {
+		out.Spec.Tablets = []*v1alpha2.VitessTablet{}
+		for _, tablet := range in.Spec.Tablets {
+			out.Spec.Tablets = append(out.Spec.Tablets, tabletToV1alpha2(tablet))
+		}
+	}
+
+	return out
+}
+
+func shardFromV1alpha2(in *v1alpha2.VitessShard) *VitessShard {
+	if in == nil {
+		return nil
+	}
+
+	out := &VitessShard{
+		TypeMeta:   typeMetaFromV1alpha2(in.TypeMeta),
+		ObjectMeta: in.ObjectMeta,
+		Spec: VitessShardSpec{
+			Defaults:       shardOptionsFromV1alpha2(in.Spec.Defaults),
+			KeyRange:       KeyRange(in.Spec.KeyRange),
+			TabletSelector: selectorsFromV1alpha2(in.Spec.TabletSelector),
+		},
+		Status: VitessShardStatus(in.Status),
+	}
+
+	if in.Spec.Tablets != nil {
+		out.Spec.Tablets = []*VitessTablet{}
+		for _, tablet := range in.Spec.Tablets {
+			out.Spec.Tablets = append(out.Spec.Tablets, tabletFromV1alpha2(tablet))
+		}
+	}
+
+	return out
+}
+
+func shardOptionsToV1alpha2(in *VitessShardOptions) *v1alpha2.VitessShardOptions {
+	if in == nil {
+		return nil
+	}
+
+	return &v1alpha2.VitessShardOptions{
+		Replicas:       in.Replicas,
+		Batch:          v1alpha2.VitessBatchOptions(in.Batch),
+		Containers:     containersToV1alpha2(in.Containers),
+		VolumeClaim:    (*v1alpha2.TabletVolumeClaim)(in.VolumeClaim),
+		BackupStorage:  backupStorageToV1alpha2(in.BackupStorage),
+		Restore:        (*v1alpha2.TabletRestorePolicy)(in.Restore),
+		Cells:          in.Cells,
+		CellSelector:   selectorsToV1alpha2(in.CellSelector),
+		MasterElection: (*v1alpha2.MasterElectionPolicy)(in.MasterElection),
+	}
+}
+
+func shardOptionsFromV1alpha2(in *v1alpha2.VitessShardOptions) *VitessShardOptions {
+	if in == nil {
+		return nil
+	}
+
+	return &VitessShardOptions{
+		Replicas:       in.Replicas,
+		Batch:          VitessBatchOptions(in.Batch),
+		Containers:     containersFromV1alpha2(in.Containers),
+		VolumeClaim:    (*TabletVolumeClaim)(in.VolumeClaim),
+		BackupStorage:  backupStorageFromV1alpha2(in.BackupStorage),
+		Restore:        (*TabletRestorePolicy)(in.Restore),
+		Cells:          in.Cells,
+		CellSelector:   selectorsFromV1alpha2(in.CellSelector),
+		MasterElection: (*MasterElectionPolicy)(in.MasterElection),
+	}
+}
+
+func tabletToV1alpha2(in *VitessTablet) *v1alpha2.VitessTablet {
+	if in == nil {
+		return nil
+	}
+
+	return &v1alpha2.VitessTablet{
+		TypeMeta:   typeMetaToV1alpha2(in.TypeMeta),
+		ObjectMeta: in.ObjectMeta,
+		Spec: v1alpha2.VitessTabletSpec{
+			TabletID:    in.Spec.TabletID,
+			Replicas:    in.Spec.Replicas,
+			CellID:      in.Spec.Cell,
+			Type:        v1alpha2.TabletType(in.Spec.Type),
+			Datastore:   v1alpha2.TabletDatastore{Type: v1alpha2.TabletDatastoreType(in.Spec.Datastore.Type)},
+			Containers:  containersToV1alpha2(in.Spec.Containers),
+			VolumeClaim: (*v1alpha2.TabletVolumeClaim)(in.Spec.VolumeClaim),
+			Credentials: (*v1alpha2.TabletCredentials)(in.Spec.Credentials),
+			Restore:     (*v1alpha2.TabletRestorePolicy)(in.Spec.Restore),
+		},
+		Status: *tabletStatusToV1alpha2(&in.Status),
+	}
+}
+
+func tabletFromV1alpha2(in *v1alpha2.VitessTablet) *VitessTablet {
+	if in == nil {
+		return nil
+	}
+
+	return &VitessTablet{
+		TypeMeta:   typeMetaFromV1alpha2(in.TypeMeta),
+		ObjectMeta: in.ObjectMeta,
+		Spec: VitessTabletSpec{
+			TabletID:    in.Spec.TabletID,
+			Replicas:    in.Spec.Replicas,
+			Cell:        in.Spec.CellID,
+			Type:        TabletType(in.Spec.Type),
+			Datastore:   TabletDatastore{Type: TabletDatastoreType(in.Spec.Datastore.Type)},
+			Containers:  containersFromV1alpha2(in.Spec.Containers),
+			VolumeClaim: (*TabletVolumeClaim)(in.Spec.VolumeClaim),
+			Credentials: (*TabletCredentials)(in.Spec.Credentials),
+			Restore:     (*TabletRestorePolicy)(in.Spec.Restore),
+		},
+		Status: *tabletStatusFromV1alpha2(&in.Status),
+	}
+}
+
+func tabletStatusToV1alpha2(in *VitessTabletStatus) *v1alpha2.VitessTabletStatus {
+	out := &v1alpha2.VitessTabletStatus{
+		Phase:           v1alpha2.TabletPhase(in.Phase),
+		Replicas:        in.Replicas,
+		ReadyReplicas:   in.ReadyReplicas,
+		UpdatedReplicas: in.UpdatedReplicas,
+		Volume:          (*v1alpha2.TabletVolumeStatus)(in.Volume),
+	}
+
+	if in.Restores != nil {
+		out.Restores = []v1alpha2.TabletRestoreStatus{}
+		for _, restore := range in.Restores {
+			out.Restores = append(out.Restores, v1alpha2.TabletRestoreStatus(restore))
+		}
+	}
+	if in.Pods != nil {
+		out.Pods = []v1alpha2.TabletPodStatus{}
+		for _, pod := range in.Pods {
+			out.Pods = append(out.Pods, v1alpha2.TabletPodStatus(pod))
+		}
+	}
+
+	return out
+}
+
+func tabletStatusFromV1alpha2(in *v1alpha2.VitessTabletStatus) *VitessTabletStatus {
+	out := &VitessTabletStatus{
+		Phase:           TabletPhase(in.Phase),
+		Replicas:        in.Replicas,
+		ReadyReplicas:   in.ReadyReplicas,
+		UpdatedReplicas: in.UpdatedReplicas,
+		Volume:          (*TabletVolumeStatus)(in.Volume),
+	}
+
+	if in.Restores != nil {
+		out.Restores = []TabletRestoreStatus{}
+		for _, restore := range in.Restores {
+			out.Restores = append(out.Restores, TabletRestoreStatus(restore))
+		}
+	}
+	if in.Pods != nil {
+		out.Pods = []TabletPodStatus{}
+		for _, pod := range in.Pods {
+			out.Pods = append(out.Pods, TabletPodStatus(pod))
+		}
+	}
+
+	return out
+}
+
+func containersToV1alpha2(in *TabletContainers) *v1alpha2.TabletContainers {
+	if in == nil {
+		return nil
+	}
+
+	return &v1alpha2.TabletContainers{
+		DBFlavor: in.DBFlavor,
+		MySQL:    (*v1alpha2.MySQLContainer)(in.MySQL),
+		VTTablet: (*v1alpha2.VTTabletContainer)(in.VTTablet),
+	}
+}
+
+func containersFromV1alpha2(in *v1alpha2.TabletContainers) *TabletContainers {
+	if in == nil {
+		return nil
+	}
+
+	return &TabletContainers{
+		DBFlavor: in.DBFlavor,
+		MySQL:    (*TabletContainer)(in.MySQL),
+		VTTablet: (*TabletContainer)(in.VTTablet),
+	}
+}
+
+func lockserverToV1alpha2(in *VitessLockserver) *v1alpha2.VitessLockserver {
+	out := &v1alpha2.VitessLockserver{
+		TypeMeta:   typeMetaToV1alpha2(in.TypeMeta),
+		ObjectMeta: in.ObjectMeta,
+		Spec: v1alpha2.VitessLockserverSpec{
+			Provision: in.Spec.Provision,
+			Type:      v1alpha2.LockserverType(in.Spec.Type),
+			Zk2:       (*v1alpha2.Zk2Lockserver)(in.Spec.Zk2),
+			Consul:    (*v1alpha2.ConsulLockserver)(in.Spec.Consul),
+		},
+		Status: *lockserverStatusToV1alpha2(&in.Status),
+	}
+
+	if in.Spec.Etcd2 != nil {
+		out.Spec.Etcd2 = &v1alpha2.Etcd2Lockserver{
+			Address:  in.Spec.Etcd2.Address,
+			Path:     in.Spec.Etcd2.Path,
+			TLS:      (*v1alpha2.LockserverTLS)(in.Spec.Etcd2.TLS),
+			Replicas: in.Spec.Etcd2.Replicas,
+			Image:    in.Spec.Etcd2.Image,
+		}
+	}
+
+	return out
+}
+
+func lockserverFromV1alpha2(in *v1alpha2.VitessLockserver) *VitessLockserver {
+	out := &VitessLockserver{
+		TypeMeta:   typeMetaFromV1alpha2(in.TypeMeta),
+		ObjectMeta: in.ObjectMeta,
+		Spec: VitessLockserverSpec{
+			Provision: in.Spec.Provision,
+			Type:      LockserverType(in.Spec.Type),
+			Zk2:       (*Zk2Lockserver)(in.Spec.Zk2),
+			Consul:    (*ConsulLockserver)(in.Spec.Consul),
+		},
+		Status: *lockserverStatusFromV1alpha2(&in.Status),
+	}
+
+	if in.Spec.Etcd2 != nil {
+		out.Spec.Etcd2 = &Etcd2Lockserver{
+			Address:  in.Spec.Etcd2.Address,
+			Path:     in.Spec.Etcd2.Path,
+			TLS:      (*LockserverTLS)(in.Spec.Etcd2.TLS),
+			Replicas: in.Spec.Etcd2.Replicas,
+			Image:    in.Spec.Etcd2.Image,
+		}
+	}
+
+	return out
+}
+
+func lockserverStatusToV1alpha2(in *VitessLockserverStatus) *v1alpha2.VitessLockserverStatus {
+	if in == nil {
+		return nil
+	}
+
+	out := &v1alpha2.VitessLockserverStatus{
+		Reachable:      in.Reachable,
+		Leader:         in.Leader,
+		RootPathExists: in.RootPathExists,
+	}
+
+	if in.Members != nil {
+		out.Members = []v1alpha2.LockserverMember{}
+		for _, member := range in.Members {
+			out.Members = append(out.Members, v1alpha2.LockserverMember(member))
+		}
+	}
+	if in.Conditions != nil {
+		out.Conditions = []v1alpha2.VitessLockserverCondition{}
+		for _, condition := range in.Conditions {
+			out.Conditions = append(out.Conditions, v1alpha2.VitessLockserverCondition{
+				Type:               v1alpha2.LockserverConditionType(condition.Type),
+				Status:             condition.Status,
+				LastTransitionTime: condition.LastTransitionTime,
+				Reason:             condition.Reason,
+				Message:            condition.Message,
+			})
+		}
+	}
+
+	return out
+}
+
+func lockserverStatusFromV1alpha2(in *v1alpha2.VitessLockserverStatus) *VitessLockserverStatus {
+	if in == nil {
+		return nil
+	}
+
+	out := &VitessLockserverStatus{
+		Reachable:      in.Reachable,
+		Leader:         in.Leader,
+		RootPathExists: in.RootPathExists,
+	}
+
+	if in.Members != nil {
+		out.Members = []LockserverMember{}
+		for _, member := range in.Members {
+			out.Members = append(out.Members, LockserverMember(member))
+		}
+	}
+	if in.Conditions != nil {
+		out.Conditions = []VitessLockserverCondition{}
+		for _, condition := range in.Conditions {
+			out.Conditions = append(out.Conditions, VitessLockserverCondition{
+				Type:               LockserverConditionType(condition.Type),
+				Status:             condition.Status,
+				LastTransitionTime: condition.LastTransitionTime,
+				Reason:             condition.Reason,
+				Message:            condition.Message,
+			})
+		}
+	}
+
+	return out
+}
+
+func backupStorageToV1alpha2(in *VitessBackupStorage) *v1alpha2.VitessBackupStorage {
+	if in == nil {
+		return nil
+	}
+
+	return &v1alpha2.VitessBackupStorage{
+		File: (*v1alpha2.FileBackupStorage)(in.File),
+		S3:   (*v1alpha2.S3BackupStorage)(in.S3),
+		GCS:  (*v1alpha2.GCSBackupStorage)(in.GCS),
+	}
+}
+
+func backupStorageFromV1alpha2(in *v1alpha2.VitessBackupStorage) *VitessBackupStorage {
+	if in == nil {
+		return nil
+	}
+
+	return &VitessBackupStorage{
+		File: (*FileBackupStorage)(in.File),
+		S3:   (*S3BackupStorage)(in.S3),
+		GCS:  (*GCSBackupStorage)(in.GCS),
+	}
+}
+
+func selectorsToV1alpha2(in []ResourceSelector) []v1alpha2.ResourceSelector {
+	if in == nil {
+		return nil
+	}
+
+	out := []v1alpha2.ResourceSelector{}
+	for _, selector := range in {
+		out = append(out, v1alpha2.ResourceSelector{
+			Key:      selector.Key,
+			Operator: v1alpha2.ResourceSelectorOperator(selector.Operator),
+			Values:   selector.Values,
+		})
+	}
+	return out
+}
+
+func selectorsFromV1alpha2(in []v1alpha2.ResourceSelector) []ResourceSelector {
+	if in == nil {
+		return nil
+	}
+
+	out := []ResourceSelector{}
+	for _, selector := range in {
+		out = append(out, ResourceSelector{
+			Key:      selector.Key,
+			Operator: ResourceSelectorOperator(selector.Operator),
+			Values:   selector.Values,
+		})
+	}
+	return out
+}
@@ -0,0 +1,125 @@
+package v1beta1
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	"k8s.io/apimachinery/pkg/util/diff"
+
+	"vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	"vitess.io/vitess-operator/pkg/util/conversion"
+)
+
+// fuzzIterations is how many random objects of every kind are converted
+const fuzzIterations = 100
+
+// roundTrips are pairs of empty objects of the same kind in both versions
+var roundTrips = []struct {
+	beta  conversion.Convertible
+	alpha conversion.Hub
+}{
+	{&VitessCluster{}, &v1alpha2.VitessCluster{}},
+	{&VitessCell{}, &v1alpha2.VitessCell{}},
+	{&VitessKeyspace{}, &v1alpha2.VitessKeyspace{}},
+	{&VitessShard{}, &v1alpha2.VitessShard{}},
+	{&VitessTablet{}, &v1alpha2.VitessTablet{}},
+	{&VitessLockserver{}, &v1alpha2.VitessLockserver{}},
+	{&VitessBackupSchedule{}, &v1alpha2.VitessBackupSchedule{}},
+	{&VitessRestore{}, &v1alpha2.VitessRestore{}},
+}
+
+func newFuzzer() *fuzz.Fuzzer {
+	return fuzz.New().NilChance(0.3).NumElements(0, 2)
+}
+
+// TestRoundTripFromV1beta1 checks that v1beta1 objects don't lose anything in v1alpha2
+func TestRoundTripFromV1beta1(t *testing.T) {
+	f := newFuzzer()
+
+	for _, rt := range roundTrips {
+		for i := 0; i < fuzzIterations; i++ {
+			original := rt.beta.DeepCopyObject().(conversion.Convertible)
+			f.Fuzz(original)
+
+			hub := rt.alpha.DeepCopyObject().(conversion.Hub)
+			if err := original.DeepCopyObject().(conversion.Convertible).ConvertTo(hub); err != nil {
+				t.Fatalf("Error converting %T to v1alpha2: %s", original, err)
+			}
+
+			converted := rt.beta.DeepCopyObject().(conversion.Convertible)
+			if err := converted.ConvertFrom(hub); err != nil {
+				t.Fatalf("Error converting %T from v1alpha2: %s", original, err)
+			}
+
+			if !reflect.DeepEqual(original, converted) {
+				t.Fatalf("%T changed in a round trip through v1alpha2: %s", original, diff.ObjectReflectDiff(original, converted))
+			}
+		}
+	}
+}
+
+// TestRoundTripFromV1alpha2 checks that v1alpha2 objects don't lose anything in v1beta1. The parent pointers
+// that v1beta1 dropped are unexported, so they are neither fuzzed nor encoded.
+func TestRoundTripFromV1alpha2(t *testing.T) {
+	f := newFuzzer()
+
+	for _, rt := range roundTrips {
+		for i := 0; i < fuzzIterations; i++ {
+			original := rt.alpha.DeepCopyObject().(conversion.Hub)
+			f.Fuzz(original)
+
+			spoke := rt.beta.DeepCopyObject().(conversion.Convertible)
+			if err := spoke.ConvertFrom(original.DeepCopyObject().(conversion.Hub)); err != nil {
+				t.Fatalf("Error converting %T to v1beta1: %s", original, err)
+			}
+
+			converted := rt.alpha.DeepCopyObject().(conversion.Hub)
+			if err := spoke.ConvertTo(converted); err != nil {
+				t.Fatalf("Error converting %T from v1beta1: %s", original, err)
+			}
+
+			if !reflect.DeepEqual(original, converted) {
+				t.Fatalf("%T changed in a round trip through v1beta1: %s", original, diff.ObjectReflectDiff(original, converted))
+			}
+		}
+	}
+}
+
+// TestMySQLUsernameEncoding makes sure that the MySQL user of a cell keeps the image key that stored v1alpha2
+// objects use, and that only v1beta1 encodes it as username
+func TestMySQLUsernameEncoding(t *testing.T) {
+	stored := []byte(`{"apiVersion":"vitess.io/v1alpha2","kind":"VitessCell","metadata":{"name":"zone1"},"spec":{"mysqlProtocol":{"image":"vt_app"}}}`)
+
+	alpha := &v1alpha2.VitessCell{}
+	if err := json.Unmarshal(stored, alpha); err != nil {
+		t.Fatalf("Error decoding stored cell: %s", err)
+	}
+
+	if alpha.Spec.MySQLProtocol == nil || alpha.Spec.MySQLProtocol.Username != "vt_app" {
+		t.Fatalf("MySQL user of the stored cell lost: %+v", alpha.Spec.MySQLProtocol)
+	}
+
+	encoded, err := json.Marshal(alpha)
+	if err != nil {
+		t.Fatalf("Error encoding v1alpha2 cell: %s", err)
+	}
+	if !strings.Contains(string(encoded), `"mysqlProtocol":{"image":"vt_app"}`) {
+		t.Errorf("v1alpha2 cell doesn't encode the MySQL user as image: %s", encoded)
+	}
+
+	beta := &VitessCell{}
+	if err := beta.ConvertFrom(alpha); err != nil {
+		t.Fatalf("Error converting cell to v1beta1: %s", err)
+	}
+
+	encoded, err = json.Marshal(beta)
+	if err != nil {
+		t.Fatalf("Error encoding v1beta1 cell: %s", err)
+	}
+	if !strings.Contains(string(encoded), `"mysqlProtocol":{"username":"vt_app"}`) {
+		t.Errorf("v1beta1 cell doesn't encode the MySQL user as username: %s", encoded)
+	}
+}
@@ -0,0 +1,115 @@
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
+
+type ResourceSelector struct {
+	// The label key that the selector applies to.
+	Key string `json:"key"`
+	// Represents a key's relationship to a set of values.
+	// Valid operators are In, NotIn, Exists, DoesNotExist
+	Operator ResourceSelectorOperator `json:"operator"`
+	// An array of string values. If the operator is In or NotIn,
+	// the values array must be non-empty. If the operator is Exists or DoesNotExist,
+	// the values array must be empty.
+	Values []string `json:"values,omitempty"`
+}
+
+type ResourceSelectorOperator string
+
+const (
+	ResourceSelectorOpIn           ResourceSelectorOperator = "In"
+	ResourceSelectorOpNotIn        ResourceSelectorOperator = "NotIn"
+	ResourceSelectorOpExists       ResourceSelectorOperator = "Exists"
+	ResourceSelectorOpDoesNotExist ResourceSelectorOperator = "DoesNotExist"
+)
+
+// TabletContainers configures the containers of the tablet pods
+type TabletContainers struct {
+	// DBFlavor is the DB flavor of the containers that don't set their own. Defaults to mysql56.
+	DBFlavor string `json:"dbFlavor,omitempty"`
+
+	MySQL *TabletContainer `json:"mysql,omitempty"`
+
+	VTTablet *TabletContainer `json:"vttablet,omitempty"`
+}
+
+// TabletContainer configures the mysql or the vttablet container of the tablet pods
+type TabletContainer struct {
+	Image string `json:"image"`
+
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	DBFlavor string `json:"dbFlavor,omitempty"`
+}
+
+// ComponentStatus is the observed state of the pods of a Deployment
+type ComponentStatus struct {
+	// Replicas is the number of pods the component should have
+	Replicas int32 `json:"replicas"`
+
+	// ReadyReplicas is the number of pods of the component that are ready
+	ReadyReplicas int32 `json:"readyReplicas"`
+
+	// UpdatedReplicas is the number of pods of the component that run the current pod template
+	UpdatedReplicas int32 `json:"updatedReplicas"`
+}
+
+type KeyRange struct {
+	From string `json:"from,omitempty"`
+
+	To string `json:"to,omitempty"`
+}
+
+// VitessBackupStorage configures where vttablet stores its backups and where vtctld finds them.
+// Only one storage implementation can be set.
+type VitessBackupStorage struct {
+	File *FileBackupStorage `json:"file,omitempty"`
+
+	S3 *S3BackupStorage `json:"s3,omitempty"`
+
+	GCS *GCSBackupStorage `json:"gcs,omitempty"`
+}
+
+// FileBackupStorage stores backups in a PersistentVolumeClaim that is mounted into every tablet and vtctld pod.
+// The claim must be ReadWriteMany unless all of them run on a single node, so this is mostly useful for testing.
+type FileBackupStorage struct {
+	ClaimName string `json:"claimName"`
+}
+
+// S3BackupStorage stores backups in an S3 bucket, or in a bucket of any S3-compatible object store
+// when Endpoint is set
+type S3BackupStorage struct {
+	Bucket string `json:"bucket"`
+	Region string `json:"region,omitempty"`
+
+	// Endpoint is the URL of an S3-compatible object store, e.g. Minio or Ceph
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Root is the prefix of the backup objects within the bucket
+	Root string `json:"root,omitempty"`
+
+	// ForcePathStyle puts the bucket name in the path instead of the host name, which most S3-compatible stores need
+	ForcePathStyle bool `json:"forcePathStyle,omitempty"`
+
+	// AccessKeyIDSecretRef and SecretAccessKeySecretRef select the Secret keys holding the credentials.
+	// Without them the AWS SDK falls back to its default credential chain, e.g. the instance profile of the node.
+	AccessKeyIDSecretRef     *corev1.SecretKeySelector `json:"accessKeyIDSecretRef,omitempty"`
+	SecretAccessKeySecretRef *corev1.SecretKeySelector `json:"secretAccessKeySecretRef,omitempty"`
+}
+
+// GCSBackupStorage stores backups in a Google Cloud Storage bucket
+type GCSBackupStorage struct {
+	Bucket string `json:"bucket"`
+
+	// Root is the prefix of the backup objects within the bucket
+	Root string `json:"root,omitempty"`
+
+	// CredentialsSecretRef selects the Secret key holding a service account JSON key.
+	// Without it the default credentials of the node are used.
+	CredentialsSecretRef *corev1.SecretKeySelector `json:"credentialsSecretRef,omitempty"`
+}
@@ -0,0 +1,86 @@
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
+
+// VitessCellSpec defines the desired state of VitessCell
+type VitessCellSpec struct {
+	Lockserver *VitessLockserver `json:"lockserver,omitempty"`
+
+	LockserverRef *corev1.LocalObjectReference `json:"lockserverRef,omitempty"`
+
+	Defaults *VitessCellDefaults `json:"defaults,omitempty"`
+
+	MySQLProtocol *VitessCellMySQLProtocol `json:"mysqlProtocol,omitempty"`
+
+	VTGate []VTComponent `json:"vtgate,omitempty"`
+
+	VTWorker []VTComponent `json:"vtworker,omitempty"`
+
+	VTCtld []VTComponent `json:"vtctld,omitempty"`
+
+	Orchestrator []VTComponent `json:"orchestrator,omitempty"`
+}
+
+// VitessCellStatus is the observed state of the components of a cell.
+// It is recorded in the VitessCluster status.
+type VitessCellStatus struct {
+	VTCtld ComponentStatus `json:"vtctld,omitempty"`
+
+	VTGate ComponentStatus `json:"vtgate,omitempty"`
+}
+
+type VitessCellDefaults struct {
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	Image string `json:"image,omitempty"`
+}
+
+type VitessCellMySQLProtocol struct {
+	AuthType VitessMySQLAuthType `json:"authType,omitempty"`
+
+	Username string `json:"username,omitempty"`
+
+	PasswordSecretRef *corev1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+}
+
+type VitessMySQLAuthType string
+
+const (
+	VitessMySQLAuthTypeNone VitessMySQLAuthType = "none"
+)
+
+type VTComponent struct {
+	Replicas int64 `json:"replicas,omitempty"`
+
+	ContainerSpec []*corev1.Container `json:"containerSpec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VitessCell is the Schema for the vitesscells API
+// +k8s:openapi-gen=true
+type VitessCell struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec VitessCellSpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VitessCellList contains a list of VitessCell
+type VitessCellList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VitessCell `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VitessCell{}, &VitessCellList{})
+}
@@ -0,0 +1,153 @@
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
+
+// VitessClusterSpec defines the desired state of VitessCluster
+type VitessClusterSpec struct {
+	Lockserver *VitessLockserver `json:"lockserver,omitempty"`
+
+	LockserverRef *corev1.LocalObjectReference `json:"lockserverRef,omitempty"`
+
+	Cells []*VitessCell `json:"cells,omitempty"`
+
+	CellSelector []ResourceSelector `json:"cellSelector,omitempty"`
+
+	Keyspaces []*VitessKeyspace `json:"keyspaces,omitempty"`
+
+	KeyspaceSelector []ResourceSelector `json:"keyspaceSelector,omitempty"`
+
+	// BackupStorage is where the tablets of the cluster store backups. Backups can't be taken without it.
+	BackupStorage *VitessBackupStorage `json:"backupStorage,omitempty"`
+
+	// Monitoring configures the metrics exporters and Prometheus Operator objects of the cluster
+	Monitoring *VitessMonitoring `json:"monitoring,omitempty"`
+}
+
+// VitessMonitoring configures how the cluster is monitored. The vttablet, vtgate and vtctld pods always
+// carry Prometheus scrape annotations for their /metrics endpoint.
+type VitessMonitoring struct {
+	// MySQLExporter adds a mysqld_exporter sidecar to every tablet pod, serving the MySQL metrics on the
+	// mysql-metrics port of the tablet Service
+	MySQLExporter *MySQLExporter `json:"mysqlExporter,omitempty"`
+
+	// ServiceMonitor creates a Prometheus Operator ServiceMonitor for the vttablet, vtgate and vtctld Services.
+	// The ServiceMonitor CRD must be installed.
+	ServiceMonitor *VitessServiceMonitor `json:"serviceMonitor,omitempty"`
+}
+
+// MySQLExporter configures the mysqld_exporter sidecar of the tablet pods
+type MySQLExporter struct {
+	// Image defaults to prom/mysqld-exporter:v0.11.0
+	Image string `json:"image,omitempty"`
+
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// VitessServiceMonitor configures the generated ServiceMonitor
+type VitessServiceMonitor struct {
+	// Labels are added to the ServiceMonitor, so that the serviceMonitorSelector of a Prometheus can select it
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Interval is how often the endpoints are scraped, e.g. 30s. Defaults to the interval of the Prometheus.
+	Interval string `json:"interval,omitempty"`
+}
+
+// VitessClusterStatus defines the observed state of VitessCluster
+type VitessClusterStatus struct {
+	Phase ClusterPhase `json:"phase,omitempty"`
+
+	Reason string `json:"reason,omitempty"`
+
+	Message string `json:"message,omitempty"`
+
+	// Conditions summarize whether the cluster is available and whether it is scaling, upgrading or recovering
+	Conditions []VitessClusterCondition `json:"conditions,omitempty"`
+
+	Lockserver *VitessLockserverStatus `json:"lockserver,omitempty"`
+
+	// RegisteredCells lists the cells that the operator has registered in the global topology
+	RegisteredCells []string `json:"registeredCells,omitempty"`
+
+	// Cells holds the status of the vtctld and vtgate of every cell in the cluster, keyed by cell name
+	Cells map[string]*VitessCellStatus `json:"cells,omitempty"`
+
+	// Keyspaces holds a summary of every keyspace in the cluster, keyed by keyspace name
+	Keyspaces map[string]*VitessKeyspaceStatus `json:"keyspaces,omitempty"`
+
+	// Shards holds the status of every shard in the cluster, keyed by keyspace/shard
+	Shards map[string]*VitessShardStatus `json:"shards,omitempty"`
+
+	// Tablets holds the status of every tablet in the cluster, keyed by StatefulSet name
+	Tablets map[string]*VitessTabletStatus `json:"tablets,omitempty"`
+
+	// ReadyTablets is the number of tablets of the cluster with all their pods ready
+	ReadyTablets int32 `json:"readyTablets"`
+}
+
+type ClusterPhase string
+
+const (
+	ClusterPhaseNone     ClusterPhase = ""
+	ClusterPhaseCreating ClusterPhase = "Creating"
+	ClusterPhaseReady    ClusterPhase = "Ready"
+)
+
+type VitessClusterCondition struct {
+	// Type of cluster condition.
+	Type ClusterConditionType `json:"type"`
+
+	// Status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+
+	// The last time this condition was updated.
+	LastUpdateTime string `json:"lastUpdateTime,omitempty"`
+
+	// Last time the condition transitioned from one status to another.
+	LastTransitionTime string `json:"lastTransitionTime,omitempty"`
+
+	// The reason for the condition's last transition.
+	Reason string `json:"reason,omitempty"`
+
+	// A human readable message indicating details about the transition.
+	Message string `json:"message,omitempty"`
+}
+
+type ClusterConditionType string
+
+const (
+	VitessClusterConditionAvailable  ClusterConditionType = "Available"
+	VitessClusterConditionRecovering ClusterConditionType = "Recovering"
+	VitessClusterConditionScaling    ClusterConditionType = "Scaling"
+	VitessClusterConditionUpgrading  ClusterConditionType = "Upgrading"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VitessCluster is the Schema for the vitessclusters API
+// +k8s:openapi-gen=true
+type VitessCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VitessClusterSpec   `json:"spec,omitempty"`
+	Status VitessClusterStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VitessClusterList contains a list of VitessCluster
+type VitessClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VitessCluster `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VitessCluster{}, &VitessClusterList{})
+}
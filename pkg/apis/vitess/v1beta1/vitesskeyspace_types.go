@@ -0,0 +1,52 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
+
+// VitessKeyspaceSpec defines the desired state of VitessKeyspace
+type VitessKeyspaceSpec struct {
+	Defaults *VitessShardOptions `json:"defaults,omitempty"`
+
+	Shards []*VitessShard `json:"shards,omitempty"`
+
+	ShardSelector []ResourceSelector `json:"shardSelector,omitempty"`
+}
+
+// VitessKeyspaceStatus defines the observed state of VitessKeyspace. It is recorded in the VitessCluster status
+// and in the VitessKeyspace object of keyspaces matched by a keyspaceSelector.
+type VitessKeyspaceStatus struct {
+	// Shards is the number of shards in the keyspace
+	Shards int32 `json:"shards,omitempty"`
+
+	// ReadyShards is the number of shards with a master and all of their tablets ready
+	ReadyShards int32 `json:"readyShards"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VitessKeyspace is the Schema for the vitesskeyspaces API
+// +k8s:openapi-gen=true
+type VitessKeyspace struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VitessKeyspaceSpec   `json:"spec,omitempty"`
+	Status VitessKeyspaceStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VitessKeyspaceList contains a list of VitessKeyspace
+type VitessKeyspaceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VitessKeyspace `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VitessKeyspace{}, &VitessKeyspaceList{})
+}
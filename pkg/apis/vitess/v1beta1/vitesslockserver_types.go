@@ -0,0 +1,142 @@
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
+
+// VitessLockserverSpec defines the desired state of VitessLockserver
+type VitessLockserverSpec struct {
+	Provision bool `json:"provision,omitempty"`
+
+	Type LockserverType `json:"type,omitempty"`
+
+	Etcd2 *Etcd2Lockserver `json:"etcd2,omitempty"`
+
+	Zk2 *Zk2Lockserver `json:"zk2,omitempty"`
+
+	Consul *ConsulLockserver `json:"consul,omitempty"`
+}
+
+type LockserverType string
+
+const (
+	LockserverTypeEtcd2  LockserverType = "etcd2"
+	LockserverTypeZk2    LockserverType = "zk2"
+	LockserverTypeConsul LockserverType = "consul"
+)
+
+type Etcd2Lockserver struct {
+	Address string `json:"address"`
+	Path    string `json:"path"`
+
+	TLS *LockserverTLS `json:"tls,omitempty"`
+
+	// Replicas and Image are only used when the operator provisions the etcd cluster. Replicas is fixed once
+	// the etcd cluster is created.
+	Replicas *int32 `json:"replicas,omitempty"`
+	Image    string `json:"image,omitempty"`
+}
+
+// LockserverTLS selects the Secret keys holding the certificates used to connect to the lockserver.
+// They are mounted into every pod that talks to the lockserver.
+type LockserverTLS struct {
+	CASecretRef   *corev1.SecretKeySelector `json:"caSecretRef,omitempty"`
+	CertSecretRef *corev1.SecretKeySelector `json:"certSecretRef,omitempty"`
+	KeySecretRef  *corev1.SecretKeySelector `json:"keySecretRef,omitempty"`
+}
+
+type Zk2Lockserver struct {
+	// Servers is the list of ZooKeeper host:port addresses
+	Servers []string `json:"servers,omitempty"`
+	Path    string   `json:"path"`
+}
+
+type ConsulLockserver struct {
+	// Address is the host:port of a Consul agent
+	Address string `json:"address"`
+	Path    string `json:"path"`
+
+	// ACLTokenSecretRef optionally selects a Secret key holding the Consul ACL token
+	ACLTokenSecretRef *corev1.SecretKeySelector `json:"aclTokenSecretRef,omitempty"`
+}
+
+// VitessLockserverStatus defines the observed state of VitessLockserver
+type VitessLockserverStatus struct {
+	// Reachable is true if the lockserver answered the last probe
+	Reachable bool `json:"reachable"`
+
+	Members []LockserverMember `json:"members,omitempty"`
+
+	// Leader is the name of the member that is currently the leader
+	Leader string `json:"leader,omitempty"`
+
+	// RootPathExists is true if any keys exist under the configured root path
+	RootPathExists bool `json:"rootPathExists"`
+
+	Conditions []VitessLockserverCondition `json:"conditions,omitempty"`
+}
+
+type LockserverMember struct {
+	Name string `json:"name"`
+
+	ID string `json:"id,omitempty"`
+
+	ClientURLs []string `json:"clientURLs,omitempty"`
+
+	PeerURLs []string `json:"peerURLs,omitempty"`
+}
+
+type VitessLockserverCondition struct {
+	// Type of lockserver condition.
+	Type LockserverConditionType `json:"type"`
+
+	// Status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+
+	// Last time the condition transitioned from one status to another.
+	LastTransitionTime string `json:"lastTransitionTime,omitempty"`
+
+	// The reason for the condition's last transition.
+	Reason string `json:"reason,omitempty"`
+
+	// A human readable message indicating details about the transition.
+	Message string `json:"message,omitempty"`
+}
+
+type LockserverConditionType string
+
+const (
+	// The lockserver is reachable and has a leader
+	LockserverConditionAvailable LockserverConditionType = "Available"
+	// The lockserver is available but has fewer members than expected
+	LockserverConditionDegraded LockserverConditionType = "Degraded"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VitessLockserver is the Schema for the vitesslockservers API
+// +k8s:openapi-gen=true
+type VitessLockserver struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VitessLockserverSpec   `json:"spec,omitempty"`
+	Status VitessLockserverStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VitessLockserverList contains a list of VitessLockserver
+type VitessLockserverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VitessLockserver `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VitessLockserver{}, &VitessLockserverList{})
+}
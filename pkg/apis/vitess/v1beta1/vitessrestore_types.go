@@ -0,0 +1,105 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
+
+// VitessRestoreSpec defines the desired state of VitessRestore
+type VitessRestoreSpec struct {
+	// Cluster is the name of the VitessCluster in the same namespace that is restored into.
+	// The tablets of the restored keyspaces must not have been created yet.
+	Cluster string `json:"cluster"`
+
+	// Keyspace limits the restore to one keyspace of the cluster. Every keyspace is restored when it is empty.
+	// Tablets look up backups by their keyspace and shard names, so the source must hold backups of
+	// keyspaces and shards with the same names.
+	Keyspace string `json:"keyspace,omitempty"`
+
+	// Source is the backup storage that the tablets restore from. It replaces the backup storage of the
+	// restored tablets for as long as the restore exists.
+	Source VitessBackupStorage `json:"source"`
+
+	// RestoreToTime is the point in time to restore to. The restored backups must have been taken before it.
+	// It can't be combined with RestoreToPosition.
+	RestoreToTime *metav1.Time `json:"restoreToTime,omitempty"`
+
+	// RestoreToPosition is the GTID set to restore to, e.g. "MySQL56/<server uuid>:1-1234"
+	RestoreToPosition string `json:"restoreToPosition,omitempty"`
+
+	// BinlogSourceCluster is the VitessCluster in the same namespace whose shard masters serve the binlogs
+	// that are replayed on top of the restored backups to reach RestoreToTime or RestoreToPosition.
+	// Every restored shard replays the binlogs of the shard with the same keyspace and shard name.
+	BinlogSourceCluster string `json:"binlogSourceCluster,omitempty"`
+}
+
+type RestorePhase string
+
+const (
+	RestorePhaseNone      RestorePhase = ""
+	RestorePhasePending   RestorePhase = "Pending"
+	RestorePhaseRestoring RestorePhase = "Restoring"
+	RestorePhaseComplete  RestorePhase = "Complete"
+	RestorePhaseFailed    RestorePhase = "Failed"
+)
+
+// VitessRestoreStatus defines the observed state of VitessRestore
+type VitessRestoreStatus struct {
+	// Phase is Pending until the tablets of the restored keyspaces are created, Restoring while they
+	// restore, replay binlogs up to the point in time if there is one and catch up, and then either Complete or Failed
+	Phase RestorePhase `json:"phase,omitempty"`
+
+	// Message explains why the restore is pending or failed
+	Message string `json:"message,omitempty"`
+
+	// Shards holds the progress of every restored shard, keyed by keyspace/shard
+	Shards map[string]*VitessRestoreShardStatus `json:"shards,omitempty"`
+
+	// CompletionTime is when every restored shard finished restoring
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// VitessRestoreShardStatus is the observed state of the restore of a shard
+type VitessRestoreShardStatus struct {
+	Phase RestorePhase `json:"phase,omitempty"`
+
+	// Backup is the name of the backup the tablets of the shard restored from
+	Backup string `json:"backup,omitempty"`
+
+	// RestoredTablets is the number of tablet pods of the shard that finished restoring
+	RestoredTablets int32 `json:"restoredTablets"`
+
+	// ReplayedTablets is the number of tablet pods of the shard that replayed the binlogs up to the point in time
+	// of the restore
+	ReplayedTablets int32 `json:"replayedTablets,omitempty"`
+
+	// Tablets is the number of tablet pods of the shard
+	Tablets int32 `json:"tablets"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VitessRestore is the Schema for the vitessrestores API
+// +k8s:openapi-gen=true
+type VitessRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VitessRestoreSpec   `json:"spec,omitempty"`
+	Status VitessRestoreStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VitessRestoreList contains a list of VitessRestore
+type VitessRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VitessRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VitessRestore{}, &VitessRestoreList{})
+}
@@ -0,0 +1,97 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
+
+// VitessShardSpec defines the desired state of VitessShard
+type VitessShardSpec struct {
+	Defaults *VitessShardOptions `json:"defaults,omitempty"`
+
+	KeyRange KeyRange `json:"keyRange,omitempty"`
+
+	Tablets []*VitessTablet `json:"tablets,omitempty"`
+
+	TabletSelector []ResourceSelector `json:"tabletSelector,omitempty"`
+}
+
+type VitessShardOptions struct {
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	Batch VitessBatchOptions `json:"batch"`
+
+	Containers *TabletContainers `json:"containers,omitempty"`
+
+	VolumeClaim *TabletVolumeClaim `json:"volumeClaim,omitempty"`
+
+	// BackupStorage replaces the backup storage of the cluster for these tablets
+	BackupStorage *VitessBackupStorage `json:"backupStorage,omitempty"`
+
+	Restore *TabletRestorePolicy `json:"restore,omitempty"`
+
+	Cells []string `json:"cells,omitempty"`
+
+	CellSelector []ResourceSelector `json:"cellSelector,omitempty"`
+
+	MasterElection *MasterElectionPolicy `json:"masterElection,omitempty"`
+}
+
+type VitessBatchOptions struct {
+	Count int64 `json:"count"`
+}
+
+// MasterElectionPolicy controls how the operator picks the master of a shard, both when the shard is
+// first set up and when the master pod is about to be removed
+type MasterElectionPolicy struct {
+	// PreferredCells are tried in order when picking a master candidate.
+	// Candidates in other cells are only used when none of these cells has one.
+	PreferredCells []string `json:"preferredCells,omitempty"`
+
+	// MaxReplicationLagSeconds is how far behind the master a replica may be and still
+	// take over in a planned reparent. Defaults to 10.
+	MaxReplicationLagSeconds *int32 `json:"maxReplicationLagSeconds,omitempty"`
+}
+
+// VitessShardStatus defines the observed state of VitessShard. Like the tablet status, it is recorded in
+// the VitessCluster status and in the VitessShard object of shards matched by a shardSelector.
+type VitessShardStatus struct {
+	// MasterAlias is the alias of the shard master tablet
+	MasterAlias string `json:"masterAlias,omitempty"`
+
+	// MasterPodName is the name of the pod the shard master tablet runs in
+	MasterPodName string `json:"masterPodName,omitempty"`
+
+	// Replicas is the number of tablet pods the shard should have
+	Replicas int32 `json:"replicas"`
+
+	// ReadyReplicas is the number of tablet pods of the shard that are ready
+	ReadyReplicas int32 `json:"readyReplicas"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VitessShard is the Schema for the vitessshards API
+// +k8s:openapi-gen=true
+type VitessShard struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VitessShardSpec   `json:"spec,omitempty"`
+	Status VitessShardStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VitessShardList contains a list of VitessShard
+type VitessShardList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VitessShard `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VitessShard{}, &VitessShardList{})
+}
@@ -0,0 +1,193 @@
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
+
+// VitessTabletSpec defines the desired state of VitessTablet
+type VitessTabletSpec struct {
+	// TabletID sets the UIDs of the tablet pods to TabletID*100 plus the pod ordinal.
+	// When it is not set the UIDs are derived from a hash of the cell and StatefulSet names.
+	// Tablets that already exist keep the UIDs they were created with.
+	TabletID int64 `json:"tabletID,omitempty"`
+
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Cell is the name of the cell the tablet runs in
+	Cell string `json:"cell"`
+
+	Type TabletType `json:"type,omitempty"`
+
+	Datastore TabletDatastore `json:"datastore,omitempty"`
+
+	Containers *TabletContainers `json:"containers,omitempty"`
+
+	VolumeClaim *TabletVolumeClaim `json:"volumeClaim,omitempty"`
+
+	Credentials *TabletCredentials `json:"credentials,omitempty"`
+
+	Restore *TabletRestorePolicy `json:"restore,omitempty"`
+}
+
+type TabletType string
+
+const (
+	TabletTypeMaster   TabletType = "master"
+	TabletTypeReplica  TabletType = "replica"
+	TabletTypeReadOnly TabletType = "readonly"
+	TabletTypeBackup   TabletType = "backup"
+	TabletTypeRestore  TabletType = "restore"
+	TabletTypeDrained  TabletType = "drained"
+)
+
+type TabletDatastore struct {
+	Type TabletDatastoreType `json:"type,omitempty"`
+}
+
+type TabletDatastoreType string
+
+const (
+	TabletDatastoreTypeLocal TabletDatastoreType = "local"
+)
+
+// TabletVolumeClaim configures the vtdataroot PersistentVolumeClaim of every tablet pod
+type TabletVolumeClaim struct {
+	// Labels are added to the claim
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are added to the claim
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Spec is the claim spec. The access modes default to ReadWriteOnce and the storage request to 10Gi.
+	Spec corev1.PersistentVolumeClaimSpec `json:"spec,omitempty"`
+}
+
+// TabletRestorePolicy controls how new tablet pods are bootstrapped
+type TabletRestorePolicy struct {
+	// FromBackup starts new tablet pods from the latest backup of their shard instead of empty.
+	// Pods of a shard that has no backup yet still start empty.
+	FromBackup bool `json:"fromBackup,omitempty"`
+
+	// MaxReplicationLagSeconds is how far behind the master a restored tablet may be for its restore to be complete.
+	// Defaults to 30.
+	MaxReplicationLagSeconds *int32 `json:"maxReplicationLagSeconds,omitempty"`
+}
+
+type TabletCredentials struct {
+	// SecretRef points a Secret resource which contains the credentials
+	SecretRef *corev1.SecretReference `json:"secretRef,omitempty"`
+}
+
+// VitessTabletStatus defines the observed state of VitessTablet. The status of every tablet is recorded
+// in the VitessCluster status, and also in the VitessTablet object of tablets matched by a tabletSelector.
+// The status of tablets embedded in their parent is ignored.
+type VitessTabletStatus struct {
+	// Phase is Ready once all of the tablet pods are ready
+	Phase TabletPhase `json:"phase,omitempty"`
+
+	// Replicas is the number of pods the tablet should have
+	Replicas int32 `json:"replicas"`
+
+	// ReadyReplicas is the number of pods of the tablet that are ready
+	ReadyReplicas int32 `json:"readyReplicas"`
+
+	// UpdatedReplicas is the number of pods of the tablet that run the current StatefulSet revision
+	UpdatedReplicas int32 `json:"updatedReplicas"`
+
+	// Volume tracks the resizing of the tablet vtdataroot claims
+	Volume *TabletVolumeStatus `json:"volume,omitempty"`
+
+	// Restores lists the tablet pods that were bootstrapped from a backup
+	Restores []TabletRestoreStatus `json:"restores,omitempty"`
+
+	// Pods lists the tablet pods registered in the topology
+	Pods []TabletPodStatus `json:"pods,omitempty"`
+}
+
+// TabletPodStatus is the state of a tablet pod as reported by vtctld
+type TabletPodStatus struct {
+	// PodName is the name of the pod
+	PodName string `json:"podName"`
+
+	// Alias is the alias of the tablet in the topology
+	Alias string `json:"alias"`
+
+	// Type is the tablet type recorded in the topology, e.g. master, replica or restore
+	Type string `json:"type"`
+
+	// Serving is set when the tablet serves queries
+	Serving bool `json:"serving"`
+
+	// ReplicationLagSeconds is how far behind the master the tablet is. It is unset when the health is unknown.
+	ReplicationLagSeconds *int32 `json:"replicationLagSeconds,omitempty"`
+
+	// HealthError is why the tablet is unhealthy, or why its health couldn't be checked
+	HealthError string `json:"healthError,omitempty"`
+}
+
+// TabletVolumeStatus is the observed state of the vtdataroot claims of a tablet
+type TabletVolumeStatus struct {
+	// RequestedSize is the storage the claims are being resized to
+	RequestedSize string `json:"requestedSize,omitempty"`
+
+	// Resizing lists the claims whose capacity hasn't reached the requested size yet
+	Resizing []string `json:"resizing,omitempty"`
+
+	// Error is set when the claims can't be expanded, e.g. because their storage class doesn't allow it
+	Error string `json:"error,omitempty"`
+}
+
+// TabletRestoreStatus is the observed state of a tablet pod that restored from a backup when it was created
+type TabletRestoreStatus struct {
+	// PodName is the name of the restored pod
+	PodName string `json:"podName"`
+
+	// Backup is the name of the backup the pod restored from. It is empty if the shard had no backup
+	// and the pod started empty.
+	Backup string `json:"backup,omitempty"`
+
+	// BinlogsReplayed is set once the pod replayed the binlogs up to the point in time of its VitessRestore
+	BinlogsReplayed bool `json:"binlogsReplayed,omitempty"`
+
+	// Complete is set once the pod finished restoring and caught up on replication
+	Complete bool `json:"complete,omitempty"`
+
+	// Error explains why the restore of the pod can't complete
+	Error string `json:"error,omitempty"`
+}
+
+type TabletPhase string
+
+const (
+	TabletPhaseNone  TabletPhase = ""
+	TabletPhaseReady TabletPhase = "Ready"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VitessTablet is the Schema for the vitesstablets API
+// +k8s:openapi-gen=true
+type VitessTablet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VitessTabletSpec   `json:"spec,omitempty"`
+	Status VitessTabletStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VitessTabletList contains a list of VitessTablet
+type VitessTabletList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VitessTablet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VitessTablet{}, &VitessTabletList{})
+}
@@ -0,0 +1,10 @@
+package controller
+
+import (
+	"vitess.io/vitess-operator/pkg/controller/vitessbackupschedule"
+)
+
+func init() {
+	// AddToManagerFuncs is a list of functions to create controllers and add them to a manager.
+	AddToManagerFuncs = append(AddToManagerFuncs, vitessbackupschedule.Add)
+}
@@ -0,0 +1,10 @@
+package controller
+
+import (
+	"vitess.io/vitess-operator/pkg/controller/vitessrestore"
+)
+
+func init() {
+	// AddToManagerFuncs is a list of functions to create controllers and add them to a manager.
+	AddToManagerFuncs = append(AddToManagerFuncs, vitessrestore.Add)
+}
@@ -0,0 +1,205 @@
+package vitessbackupschedule
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	"vitess.io/vitess-operator/pkg/util/vtctld"
+)
+
+// BackupTimeout bounds how long vtctlclient waits for a backup to finish
+const BackupTimeout = 12 * time.Hour
+
+// MaxScheduleNameLength is the longest schedule name whose backup Job names, which append the scheduled time
+// in minutes, stay valid label values. CronJob has the same limit for the same reason.
+const MaxScheduleNameLength = 52
+
+// backupNameTimeFormat is the timestamp that Vitess starts backup names with, followed by the tablet alias
+const backupNameTimeFormat = "2006-01-02.150405"
+
+// Tablet types as listed by vtctl that can be backed up, in order of preference.
+// Serving rdonly tablets are preferred over replicas since backing up takes the tablet out of serving.
+var backupTabletTypes = []string{"rdonly", string(vitessv1alpha2.TabletTypeReplica)}
+
+// BackupCandidate is a tablet that could be backed up
+type BackupCandidate struct {
+	Tablet vtctld.Tablet
+
+	// Lag is the replication lag of the tablet in seconds
+	Lag uint32
+}
+
+// findBackupTablet returns the tablet of the shard that should be backed up
+func findBackupTablet(client vtctld.Client, keyspaceShard string) (*vtctld.Tablet, error) {
+	master, err := client.GetShardMaster(keyspaceShard)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get shard %s from vtctld: %s", keyspaceShard, err)
+	}
+
+	tablets, err := client.ListShardTablets(keyspaceShard)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to list tablets of shard %s from vtctld: %s", keyspaceShard, err)
+	}
+
+	candidates := []BackupCandidate{}
+	for _, tablet := range tablets {
+		if tablet.Alias == master || getBackupTypeRank(tablet.Type) < 0 {
+			continue
+		}
+
+		// The health check doubles as a check that the tablet is replicating
+		lag, err := client.GetReplicationLag(tablet.Alias)
+		if err != nil {
+			log.Info("Unable to get replication lag, not backing up tablet", "Shard", keyspaceShard, "Tablet", tablet.Alias, "Error", err.Error())
+			continue
+		}
+
+		candidates = append(candidates, BackupCandidate{Tablet: tablet, Lag: lag})
+	}
+
+	tablet := ChooseBackupTablet(candidates)
+	if tablet == nil {
+		return nil, fmt.Errorf("No healthy replica or rdonly tablet to back up in shard %s", keyspaceShard)
+	}
+
+	return tablet, nil
+}
+
+// ChooseBackupTablet picks the tablet to back up. Rdonly tablets win over replicas, then the least lagging
+// tablet wins. Ties are broken by alias so that the choice is stable.
+func ChooseBackupTablet(candidates []BackupCandidate) *vtctld.Tablet {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	sorted := append([]BackupCandidate{}, candidates...)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if rankA, rankB := getBackupTypeRank(a.Tablet.Type), getBackupTypeRank(b.Tablet.Type); rankA != rankB {
+			return rankA < rankB
+		}
+		if a.Lag != b.Lag {
+			return a.Lag < b.Lag
+		}
+		return a.Tablet.Alias < b.Tablet.Alias
+	})
+
+	return &sorted[0].Tablet
+}
+
+// getBackupTypeRank returns the preference of the tablet type for backups, or -1 if it can't be backed up
+func getBackupTypeRank(tabletType string) int {
+	for i, t := range backupTabletTypes {
+		if t == tabletType {
+			return i
+		}
+	}
+	return -1
+}
+
+// GetBackupJob returns the Job that backs up the given tablet through vtctld for the run scheduled at the given time
+func GetBackupJob(instance *vitessv1alpha2.VitessBackupSchedule, cluster *vitessv1alpha2.VitessCluster, tabletAlias string, scheduled time.Time) *batchv1.Job {
+	// Like CronJob, the scheduled time in minutes keeps the name unique and short
+	jobName := fmt.Sprintf("%s-%d", instance.GetName(), scheduled.Unix()/60)
+
+	jobLabels := map[string]string{
+		"app":             "vitess",
+		"cluster":         cluster.GetName(),
+		"component":       "backup",
+		"backup-schedule": instance.GetName(),
+		"job-name":        jobName,
+	}
+
+	// A failed backup is not retried. The next scheduled run will try again, possibly on another tablet.
+	backoffLimit := int32(0)
+	activeDeadlineSeconds := int64((BackupTimeout + 10*time.Minute).Seconds())
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: instance.GetNamespace(),
+			Labels:    jobLabels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          &backoffLimit,
+			ActiveDeadlineSeconds: &activeDeadlineSeconds,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: jobLabels,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:    "backup",
+							Image:   "vitess/vtctlclient:helm-1.0.3", // TODO use CRD w/default
+							Command: []string{"/vt/bin/vtctlclient"},
+							Args: []string{
+								"-server", cluster.GetVtctldGRPCAddress(),
+								"-action_timeout", BackupTimeout.String(),
+								"Backup", tabletAlias,
+							},
+						},
+					},
+					RestartPolicy: corev1.RestartPolicyNever,
+				},
+			},
+		},
+	}
+}
+
+type backup struct {
+	name string
+	time time.Time
+}
+
+// GetBackupsToPrune returns the backups that the retention policy removes, oldest first.
+// Backups whose names don't start with a Vitess timestamp are left alone.
+func GetBackupsToPrune(names []string, policy *vitessv1alpha2.BackupRetentionPolicy, now time.Time) []string {
+	backups := []backup{}
+	for _, name := range names {
+		if len(name) < len(backupNameTimeFormat) {
+			continue
+		}
+
+		t, err := time.Parse(backupNameTimeFormat, name[:len(backupNameTimeFormat)])
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, backup{name: name, time: t})
+	}
+
+	// Newest first
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].time.After(backups[j].time)
+	})
+
+	pruned := []string{}
+	for i := len(backups) - 1; i > 0; i-- {
+		expired := policy.MaxAge != nil && now.Sub(backups[i].time) > policy.MaxAge.Duration
+		if i >= int(policy.GetKeepLast()) || expired {
+			pruned = append(pruned, backups[i].name)
+		}
+	}
+
+	return pruned
+}
+
+// getLatestBackupOfTablet returns the newest of the backups taken from the given tablet.
+// The backup names end with the tablet alias and sort by time.
+func getLatestBackupOfTablet(names []string, tabletAlias string) string {
+	latest := ""
+	for _, name := range names {
+		if strings.HasSuffix(name, "."+tabletAlias) && name > latest {
+			latest = name
+		}
+	}
+	return latest
+}
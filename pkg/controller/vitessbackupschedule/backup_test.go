@@ -0,0 +1,100 @@
+package vitessbackupschedule
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	"vitess.io/vitess-operator/pkg/util/vtctld"
+)
+
+func TestChooseBackupTablet(t *testing.T) {
+	candidates := []BackupCandidate{
+		{Tablet: vtctld.Tablet{Alias: "zone1-0000000101", Type: "replica"}, Lag: 0},
+		{Tablet: vtctld.Tablet{Alias: "zone1-0000000202", Type: "rdonly"}, Lag: 4},
+		{Tablet: vtctld.Tablet{Alias: "zone1-0000000201", Type: "rdonly"}, Lag: 2},
+	}
+
+	if got := ChooseBackupTablet(candidates); got.Alias != "zone1-0000000201" {
+		t.Errorf("Least lagging rdonly tablet not chosen. Got: %s; Expected: zone1-0000000201", got.Alias)
+	}
+
+	if got := ChooseBackupTablet(candidates[:1]); got.Alias != "zone1-0000000101" {
+		t.Errorf("Replica not chosen without rdonly tablets. Got: %s; Expected: zone1-0000000101", got.Alias)
+	}
+
+	if got := ChooseBackupTablet(nil); got != nil {
+		t.Errorf("Tablet chosen from an empty list: %s", got.Alias)
+	}
+}
+
+func TestGetBackupsToPrune(t *testing.T) {
+	now := time.Date(2019, time.February, 1, 12, 0, 0, 0, time.UTC)
+	backups := []string{
+		"2019-01-27.030000.zone1-0000000101",
+		"2019-01-28.030000.zone1-0000000101",
+		"2019-01-29.030000.zone1-0000000101",
+		"2019-01-30.030000.zone1-0000000101",
+		"2019-01-31.030000.zone1-0000000101",
+		"manual",
+	}
+
+	keepLast := func(n int32) *int32 { return &n }
+
+	tests := []struct {
+		name   string
+		policy *vitessv1alpha2.BackupRetentionPolicy
+		want   []string
+	}{
+		{
+			name:   "default keeps seven",
+			policy: &vitessv1alpha2.BackupRetentionPolicy{},
+			want:   []string{},
+		},
+		{
+			name:   "keep last",
+			policy: &vitessv1alpha2.BackupRetentionPolicy{KeepLast: keepLast(3)},
+			want:   []string{"2019-01-27.030000.zone1-0000000101", "2019-01-28.030000.zone1-0000000101"},
+		},
+		{
+			name:   "max age",
+			policy: &vitessv1alpha2.BackupRetentionPolicy{MaxAge: &metav1.Duration{Duration: 72 * time.Hour}},
+			want:   []string{"2019-01-27.030000.zone1-0000000101", "2019-01-28.030000.zone1-0000000101", "2019-01-29.030000.zone1-0000000101"},
+		},
+		{
+			name:   "newest is always kept",
+			policy: &vitessv1alpha2.BackupRetentionPolicy{KeepLast: keepLast(0), MaxAge: &metav1.Duration{Duration: time.Hour}},
+			want: []string{
+				"2019-01-27.030000.zone1-0000000101",
+				"2019-01-28.030000.zone1-0000000101",
+				"2019-01-29.030000.zone1-0000000101",
+				"2019-01-30.030000.zone1-0000000101",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		if got := GetBackupsToPrune(backups, test.policy, now); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: wrong backups pruned. Got: %v; Expected: %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestGetLatestBackupOfTablet(t *testing.T) {
+	backups := []string{
+		"2019-01-30.030000.zone1-0000000101",
+		"2019-01-31.030000.zone1-0000000201",
+		"2019-01-31.030000.zone1-0000000101",
+	}
+
+	if got := getLatestBackupOfTablet(backups, "zone1-0000000101"); got != "2019-01-31.030000.zone1-0000000101" {
+		t.Errorf("Wrong latest backup. Got: %s; Expected: 2019-01-31.030000.zone1-0000000101", got)
+	}
+
+	if got := getLatestBackupOfTablet(backups, "zone1-0000000301"); got != "" {
+		t.Errorf("Backup found for a tablet without backups: %s", got)
+	}
+}
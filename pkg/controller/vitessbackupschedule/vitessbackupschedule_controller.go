@@ -0,0 +1,398 @@
+package vitessbackupschedule
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	"vitess.io/vitess-operator/pkg/normalizer"
+	"vitess.io/vitess-operator/pkg/util/cron"
+	"vitess.io/vitess-operator/pkg/util/metrics"
+	"vitess.io/vitess-operator/pkg/util/vtctld"
+)
+
+var log = logf.Log.WithName("controller_vitessbackupschedule")
+
+// RetryInterval is how often a backup that couldn't be started is retried
+const RetryInterval = time.Minute
+
+// newVtctldClient and timeNow are variables so that tests can stub out vtctld and the clock
+var (
+	newVtctldClient = vtctld.NewClient
+	timeNow         = time.Now
+)
+
+// Add creates a new VitessBackupSchedule Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileVitessBackupSchedule{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("vitessbackupschedule-controller", mgr, controller.Options{Reconciler: metrics.InstrumentReconciler("vitessbackupschedule-controller", r)})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource VitessBackupSchedule
+	err = c.Watch(&source.Kind{Type: &vitessv1alpha2.VitessBackupSchedule{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Watch the backup Jobs so that their results are recorded as soon as they finish
+	err = c.Watch(&source.Kind{Type: &batchv1.Job{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &vitessv1alpha2.VitessBackupSchedule{},
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var _ reconcile.Reconciler = &ReconcileVitessBackupSchedule{}
+
+// ReconcileVitessBackupSchedule reconciles a VitessBackupSchedule object
+type ReconcileVitessBackupSchedule struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client client.Client
+	scheme *runtime.Scheme
+}
+
+// Reconcile starts a backup Job when the schedule is due, and records the result and prunes old backups
+// once the Job has finished.
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileVitessBackupSchedule) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+
+	// Fetch the VitessBackupSchedule instance
+	instance := &vitessv1alpha2.VitessBackupSchedule{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// Return and don't requeue
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+
+	oldStatus := instance.Status.DeepCopy()
+
+	rr, err := r.ReconcileSchedule(instance)
+	if err != nil {
+		return rr, err
+	}
+
+	if !reflect.DeepEqual(oldStatus, &instance.Status) {
+		if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+			reqLogger.Error(err, "Failed to update VitessBackupSchedule status")
+			return reconcile.Result{}, err
+		}
+	}
+
+	return rr, nil
+}
+
+// ReconcileSchedule does the actual reconcile work, recording the outcome in the status of the instance.
+// Problems that need a change to the schedule or the cluster are reported in the status rather than returned.
+func (r *ReconcileVitessBackupSchedule) ReconcileSchedule(instance *vitessv1alpha2.VitessBackupSchedule) (reconcile.Result, error) {
+	if len(instance.GetName()) > MaxScheduleNameLength {
+		instance.Status.Error = fmt.Sprintf("Schedule name %s is longer than %d characters", instance.GetName(), MaxScheduleNameLength)
+		return reconcile.Result{}, nil
+	}
+
+	schedule, err := cron.Parse(instance.Spec.Schedule)
+	if err != nil {
+		instance.Status.Error = err.Error()
+		return reconcile.Result{}, nil
+	}
+
+	cluster := &vitessv1alpha2.VitessCluster{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: instance.Spec.Cluster, Namespace: instance.GetNamespace()}, cluster)
+	if err != nil && errors.IsNotFound(err) {
+		instance.Status.Error = fmt.Sprintf("VitessCluster %s not found", instance.Spec.Cluster)
+		return reconcile.Result{Requeue: true, RequeueAfter: RetryInterval}, nil
+	} else if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err := normalizer.New(r.client).NormalizeCluster(cluster); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err := checkClusterCanBackup(cluster, instance.GetKeyspaceShard()); err != nil {
+		instance.Status.Error = err.Error()
+		return reconcile.Result{Requeue: true, RequeueAfter: RetryInterval}, nil
+	}
+
+	client := newVtctldClient(cluster.GetVtctldAddress())
+
+	if instance.Status.ActiveJob != "" {
+		finished, err := r.ReconcileActiveJob(instance, client)
+		if err != nil || !finished {
+			// The Job watch triggers a reconcile when it finishes
+			return reconcile.Result{}, err
+		}
+	}
+
+	if err := r.PruneJobs(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if instance.Spec.Suspend {
+		return reconcile.Result{}, nil
+	}
+
+	now := timeNow().UTC()
+
+	last := instance.GetCreationTimestamp().Time
+	if instance.Status.LastScheduleTime != nil {
+		last = instance.Status.LastScheduleTime.Time
+	}
+
+	scheduled := schedule.Next(last.UTC())
+	if scheduled.IsZero() {
+		instance.Status.Error = fmt.Sprintf("Schedule %q never runs", instance.Spec.Schedule)
+		return reconcile.Result{}, nil
+	}
+
+	if !scheduled.After(now) {
+		// Runs that were missed while the operator was down are skipped, only the latest one is started
+		for next := schedule.Next(scheduled); !next.IsZero() && !next.After(now); next = schedule.Next(next) {
+			scheduled = next
+		}
+
+		if started, err := r.StartBackup(instance, cluster, client, scheduled); err != nil {
+			return reconcile.Result{}, err
+		} else if !started {
+			return reconcile.Result{Requeue: true, RequeueAfter: RetryInterval}, nil
+		}
+
+		scheduled = schedule.Next(now)
+	}
+
+	return reconcile.Result{Requeue: true, RequeueAfter: scheduled.Sub(now)}, nil
+}
+
+// StartBackup creates the Job that backs up a tablet of the shard. It returns false if no tablet can be backed up.
+func (r *ReconcileVitessBackupSchedule) StartBackup(instance *vitessv1alpha2.VitessBackupSchedule, cluster *vitessv1alpha2.VitessCluster, client vtctld.Client, scheduled time.Time) (bool, error) {
+	keyspaceShard := instance.GetKeyspaceShard()
+
+	tablet, err := findBackupTablet(client, keyspaceShard)
+	if err != nil {
+		log.Info("Unable to pick a tablet to back up, will retry", "Shard", keyspaceShard, "Error", err.Error())
+		instance.Status.Error = err.Error()
+		return false, nil
+	}
+
+	job := GetBackupJob(instance, cluster, tablet.Alias, scheduled)
+	controllerutil.SetControllerReference(instance, job, r.scheme)
+
+	log.Info("Starting backup", "Shard", keyspaceShard, "Tablet", tablet.Alias, "Job.Name", job.GetName())
+	if err := r.client.Create(context.TODO(), job); err != nil && !errors.IsAlreadyExists(err) {
+		return false, err
+	}
+
+	instance.Status.LastScheduleTime = &metav1.Time{Time: scheduled}
+	instance.Status.ActiveJob = job.GetName()
+	instance.Status.LastBackupTablet = tablet.Alias
+	instance.Status.Error = ""
+
+	return true, nil
+}
+
+// ReconcileActiveJob records the result of the running backup Job and prunes old backups once it succeeded.
+// It returns false while the Job is still running.
+func (r *ReconcileVitessBackupSchedule) ReconcileActiveJob(instance *vitessv1alpha2.VitessBackupSchedule, client vtctld.Client) (bool, error) {
+	keyspaceShard := instance.GetKeyspaceShard()
+	jobName := instance.Status.ActiveJob
+
+	job := &batchv1.Job{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: jobName, Namespace: instance.GetNamespace()}, job)
+	if err != nil && errors.IsNotFound(err) {
+		instance.Status.ActiveJob = ""
+		instance.Status.Error = fmt.Sprintf("Backup job %s was deleted before it finished", jobName)
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	switch {
+	case isJobConditionTrue(job, batchv1.JobFailed):
+		log.Info("Backup failed", "Shard", keyspaceShard, "Job.Name", jobName)
+		instance.Status.ActiveJob = ""
+		instance.Status.Error = fmt.Sprintf("Backup job %s failed", jobName)
+		return true, nil
+	case !isJobConditionTrue(job, batchv1.JobComplete):
+		return false, nil
+	}
+
+	log.Info("Backup finished", "Shard", keyspaceShard, "Job.Name", jobName)
+	instance.Status.ActiveJob = ""
+	instance.Status.Error = ""
+	instance.Status.LastSuccessfulTime = job.Status.CompletionTime
+	if instance.Status.LastSuccessfulTime == nil {
+		instance.Status.LastSuccessfulTime = &metav1.Time{Time: timeNow()}
+	}
+
+	backups, err := client.ListBackups(keyspaceShard)
+	if err != nil {
+		instance.Status.Error = fmt.Sprintf("Unable to list backups: %s", err)
+		return true, nil
+	}
+
+	instance.Status.LastBackup = getLatestBackupOfTablet(backups, instance.Status.LastBackupTablet)
+
+	removed := map[string]bool{}
+	for _, name := range GetBackupsToPrune(backups, instance.GetRetention(), timeNow()) {
+		log.Info("Removing backup", "Shard", keyspaceShard, "Backup", name)
+		if err := client.RemoveBackup(keyspaceShard, name); err != nil {
+			instance.Status.Error = fmt.Sprintf("Unable to remove backup %s: %s", name, err)
+			break
+		}
+		removed[name] = true
+	}
+
+	kept := []string{}
+	for _, name := range backups {
+		if !removed[name] {
+			kept = append(kept, name)
+		}
+	}
+	instance.Status.Backups = kept
+
+	return true, nil
+}
+
+// PruneJobs deletes the oldest finished backup Jobs of the schedule beyond its history limits, like CronJob.
+// Their pods are deleted with them.
+func (r *ReconcileVitessBackupSchedule) PruneJobs(instance *vitessv1alpha2.VitessBackupSchedule) error {
+	jobs := &batchv1.JobList{}
+	err := r.client.List(context.TODO(), &client.ListOptions{
+		Namespace:     instance.GetNamespace(),
+		LabelSelector: labels.SelectorFromSet(map[string]string{"backup-schedule": instance.GetName()}),
+	}, jobs)
+	if err != nil {
+		return err
+	}
+
+	succeeded := []*batchv1.Job{}
+	failed := []*batchv1.Job{}
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if !metav1.IsControlledBy(job, instance) || job.GetName() == instance.Status.ActiveJob {
+			continue
+		}
+
+		switch {
+		case isJobConditionTrue(job, batchv1.JobComplete):
+			succeeded = append(succeeded, job)
+		case isJobConditionTrue(job, batchv1.JobFailed):
+			failed = append(failed, job)
+		}
+	}
+
+	for _, history := range []struct {
+		jobs  []*batchv1.Job
+		limit int32
+	}{
+		{succeeded, instance.GetSuccessfulJobsHistoryLimit()},
+		{failed, instance.GetFailedJobsHistoryLimit()},
+	} {
+		if len(history.jobs) <= int(history.limit) {
+			continue
+		}
+
+		// Newest first
+		sort.Slice(history.jobs, func(i, j int) bool {
+			return getJobStartTime(history.jobs[j]).Before(getJobStartTime(history.jobs[i]))
+		})
+
+		for _, job := range history.jobs[history.limit:] {
+			log.Info("Removing finished backup job", "Namespace", job.GetNamespace(), "Job.Name", job.GetName())
+			if err := r.client.Delete(context.TODO(), job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// getJobStartTime returns when the Job started, or when it was created if it never started
+func getJobStartTime(job *batchv1.Job) *metav1.Time {
+	if job.Status.StartTime != nil {
+		return job.Status.StartTime
+	}
+	creationTimestamp := job.GetCreationTimestamp()
+	return &creationTimestamp
+}
+
+// checkClusterCanBackup returns an error if the cluster doesn't have the shard or the shard has no backup storage.
+// Backups are listed and removed through vtctld, which only knows the cluster storage, so shards that store
+// their backups elsewhere can't be backed up on a schedule either.
+func checkClusterCanBackup(cluster *vitessv1alpha2.VitessCluster, keyspaceShard string) error {
+	if len(cluster.Cells()) == 0 {
+		return fmt.Errorf("VitessCluster %s has no cells", cluster.GetName())
+	}
+
+	for _, shard := range cluster.Shards() {
+		if shard.GetKeyspaceShard() != keyspaceShard {
+			continue
+		}
+
+		if shard.GetBackupStorage() == nil {
+			return fmt.Errorf("Shard %s has no backup storage", keyspaceShard)
+		}
+
+		if !reflect.DeepEqual(shard.GetBackupStorage(), cluster.BackupStorage()) {
+			return fmt.Errorf("Shard %s has other backup storage than VitessCluster %s, which vtctld can't list backups of", keyspaceShard, cluster.GetName())
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("Shard %s not found in VitessCluster %s", keyspaceShard, cluster.GetName())
+}
+
+func isJobConditionTrue(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == conditionType {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
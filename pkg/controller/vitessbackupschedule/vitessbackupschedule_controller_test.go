@@ -0,0 +1,358 @@
+package vitessbackupschedule
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	"vitess.io/vitess-operator/pkg/util/vtctld"
+)
+
+// TestBackupSchedule makes sure that a due backup is started on a non-master tablet,
+// and that its result is recorded and old backups are pruned once it finished
+func TestBackupSchedule(t *testing.T) {
+	var (
+		namespace = "vitess"
+		name      = "main-0-daily"
+	)
+
+	keepLast := int32(2)
+	schedule := &vitessv1alpha2.VitessBackupSchedule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         namespace,
+			CreationTimestamp: metav1.Time{Time: time.Date(2019, time.January, 30, 2, 30, 0, 0, time.UTC)},
+		},
+		Spec: vitessv1alpha2.VitessBackupScheduleSpec{
+			Cluster:   "vt",
+			Keyspace:  "main",
+			Shard:     "0",
+			Schedule:  "0 3 * * *",
+			Retention: &vitessv1alpha2.BackupRetentionPolicy{KeepLast: &keepLast},
+		},
+	}
+
+	cluster := &vitessv1alpha2.VitessCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "vt",
+			Namespace: namespace,
+		},
+		Spec: vitessv1alpha2.VitessClusterSpec{
+			BackupStorage: &vitessv1alpha2.VitessBackupStorage{
+				File: &vitessv1alpha2.FileBackupStorage{ClaimName: "backups"},
+			},
+			Cells: []*vitessv1alpha2.VitessCell{
+				{ObjectMeta: metav1.ObjectMeta{Name: "zone1"}},
+			},
+			Keyspaces: []*vitessv1alpha2.VitessKeyspace{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "main"},
+					Spec: vitessv1alpha2.VitessKeyspaceSpec{
+						Shards: []*vitessv1alpha2.VitessShard{{}},
+					},
+				},
+			},
+		},
+	}
+
+	objs := []runtime.Object{
+		schedule,
+		cluster,
+	}
+
+	// Register operator types with the runtime scheme.
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessBackupSchedule{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessBackupScheduleList{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessCluster{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessClusterList{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessShard{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessShardList{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessTablet{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessTabletList{})
+
+	vtctldClient := &vtctld.FakeClient{
+		Master: "zone1-0000000100",
+		Tablets: []vtctld.Tablet{
+			{Alias: "zone1-0000000100", Type: "master"},
+			{Alias: "zone1-0000000101", Type: "replica"},
+			{Alias: "zone1-0000000201", Type: "rdonly"},
+		},
+	}
+
+	now := time.Date(2019, time.January, 30, 3, 0, 30, 0, time.UTC)
+
+	defer func() { newVtctldClient = vtctld.NewClient }()
+	newVtctldClient = func(string) vtctld.Client { return vtctldClient }
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return now }
+
+	cl := fake.NewFakeClient(objs...)
+	r := &ReconcileVitessBackupSchedule{client: cl, scheme: s}
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: namespace}}
+	getSchedule := func() *vitessv1alpha2.VitessBackupSchedule {
+		found := &vitessv1alpha2.VitessBackupSchedule{}
+		if err := cl.Get(context.TODO(), req.NamespacedName, found); err != nil {
+			t.Fatalf("Error getting backup schedule: %s", err)
+		}
+		return found
+	}
+
+	res, err := r.Reconcile(req)
+	if err != nil {
+		t.Fatalf("Error reconciling backup schedule: %s", err)
+	}
+
+	if want := 24*time.Hour - 30*time.Second; res.RequeueAfter != want {
+		t.Errorf("Wrong requeue interval. Got: %s; Expected: %s", res.RequeueAfter, want)
+	}
+
+	status := getSchedule().Status
+	if status.ActiveJob == "" || status.LastBackupTablet != "zone1-0000000201" || status.Error != "" {
+		t.Fatalf("Backup not started on the rdonly tablet: %+v", status)
+	}
+
+	job := &batchv1.Job{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: status.ActiveJob, Namespace: namespace}, job); err != nil {
+		t.Fatalf("Backup job was not created: %s", err)
+	}
+
+	if args := strings.Join(job.Spec.Template.Spec.Containers[0].Args, " "); !strings.Contains(args, "Backup zone1-0000000201") || !strings.Contains(args, "vt-zone1-vtctld.vitess:15999") {
+		t.Errorf("Wrong backup job arguments: %s", args)
+	}
+
+	// Nothing changes while the job runs
+	now = now.Add(time.Hour)
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("Error reconciling backup schedule: %s", err)
+	}
+
+	if getSchedule().Status.ActiveJob != job.GetName() {
+		t.Error("Running backup job is no longer active")
+	}
+
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	if err := cl.Update(context.TODO(), job); err != nil {
+		t.Fatalf("Error updating backup job: %s", err)
+	}
+
+	vtctldClient.Backups = []string{
+		"2019-01-28.030000.zone1-0000000201",
+		"2019-01-29.030000.zone1-0000000201",
+		"2019-01-30.030001.zone1-0000000201",
+	}
+
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("Error reconciling backup schedule: %s", err)
+	}
+
+	status = getSchedule().Status
+	if status.ActiveJob != "" || status.LastSuccessfulTime == nil || status.Error != "" {
+		t.Errorf("Backup result not recorded: %+v", status)
+	}
+
+	if status.LastBackup != "2019-01-30.030001.zone1-0000000201" {
+		t.Errorf("Wrong last backup. Got: %s; Expected: 2019-01-30.030001.zone1-0000000201", status.LastBackup)
+	}
+
+	if len(vtctldClient.Removed) != 1 || vtctldClient.Removed[0] != "2019-01-28.030000.zone1-0000000201" {
+		t.Errorf("Wrong backups removed: %v", vtctldClient.Removed)
+	}
+
+	if len(status.Backups) != 2 {
+		t.Errorf("Wrong backups kept: %v", status.Backups)
+	}
+}
+
+// TestBackupScheduleErrors makes sure that problems with the schedule are reported in its status
+func TestBackupScheduleErrors(t *testing.T) {
+	schedule := &vitessv1alpha2.VitessBackupSchedule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "broken",
+			Namespace: "vitess",
+		},
+		Spec: vitessv1alpha2.VitessBackupScheduleSpec{
+			Cluster:  "missing",
+			Keyspace: "main",
+			Shard:    "0",
+			Schedule: "every day",
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessBackupSchedule{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessBackupScheduleList{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessCluster{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessClusterList{})
+
+	r := &ReconcileVitessBackupSchedule{client: fake.NewFakeClient(), scheme: s}
+
+	if _, err := r.ReconcileSchedule(schedule); err != nil || !strings.Contains(schedule.Status.Error, "must have 5 fields") {
+		t.Errorf("Invalid schedule not reported. Error: %v; Status: %s", err, schedule.Status.Error)
+	}
+
+	// Job names append the scheduled time to the schedule name and have to stay valid label values
+	long := schedule.DeepCopy()
+	long.SetName(strings.Repeat("b", MaxScheduleNameLength+1))
+	long.Spec.Schedule = "@daily"
+	if _, err := r.ReconcileSchedule(long); err != nil || !strings.Contains(long.Status.Error, "longer than 52 characters") {
+		t.Errorf("Too long schedule name not reported. Error: %v; Status: %s", err, long.Status.Error)
+	}
+
+	cluster := &vitessv1alpha2.VitessCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "vt", Namespace: "vitess"},
+		Spec: vitessv1alpha2.VitessClusterSpec{
+			Cells: []*vitessv1alpha2.VitessCell{{ObjectMeta: metav1.ObjectMeta{Name: "zone1"}}},
+		},
+	}
+	cluster.Cells()[0].SetParentCluster(cluster)
+
+	long.SetName(strings.Repeat("b", MaxScheduleNameLength))
+	if job := GetBackupJob(long, cluster, "zone1-0000000101", time.Now()); len(job.GetName()) > 63 {
+		t.Errorf("Job name of the longest schedule name is not a valid label value: %s", job.GetName())
+	}
+
+	schedule.Spec.Schedule = "@daily"
+	res, err := r.ReconcileSchedule(schedule)
+	if err != nil || !strings.Contains(schedule.Status.Error, "VitessCluster missing not found") {
+		t.Errorf("Missing cluster not reported. Error: %v; Status: %s", err, schedule.Status.Error)
+	}
+
+	if res.RequeueAfter != RetryInterval {
+		t.Errorf("Missing cluster not retried. Got: %s; Expected: %s", res.RequeueAfter, RetryInterval)
+	}
+}
+
+// TestPruneBackupJobs makes sure that only the newest finished backup Jobs are kept
+func TestPruneBackupJobs(t *testing.T) {
+	failedLimit := int32(0)
+	schedule := &vitessv1alpha2.VitessBackupSchedule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "main-0-daily",
+			Namespace: "vitess",
+			UID:       "schedule-uid",
+		},
+		Spec: vitessv1alpha2.VitessBackupScheduleSpec{
+			FailedJobsHistoryLimit: &failedLimit,
+		},
+		Status: vitessv1alpha2.VitessBackupScheduleStatus{
+			ActiveJob: "main-0-daily-6",
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessBackupSchedule{})
+
+	isController := true
+	start := time.Date(2019, time.January, 30, 3, 0, 0, 0, time.UTC)
+	newJob := func(index int, condition batchv1.JobConditionType) *batchv1.Job {
+		job := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("main-0-daily-%d", index),
+				Namespace: "vitess",
+				Labels:    map[string]string{"backup-schedule": "main-0-daily"},
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "vitess.io/v1alpha2", Kind: "VitessBackupSchedule", Name: "main-0-daily", UID: "schedule-uid", Controller: &isController},
+				},
+			},
+			Status: batchv1.JobStatus{
+				StartTime: &metav1.Time{Time: start.Add(time.Duration(index) * time.Hour)},
+			},
+		}
+		if condition != "" {
+			job.Status.Conditions = []batchv1.JobCondition{{Type: condition, Status: corev1.ConditionTrue}}
+		}
+		return job
+	}
+
+	cl := fake.NewFakeClient(
+		newJob(0, batchv1.JobComplete),
+		newJob(1, batchv1.JobFailed),
+		newJob(2, batchv1.JobComplete),
+		newJob(3, batchv1.JobComplete),
+		newJob(4, batchv1.JobComplete),
+		newJob(5, ""),
+		newJob(6, batchv1.JobComplete),
+	)
+	r := &ReconcileVitessBackupSchedule{client: cl, scheme: s}
+
+	if err := r.PruneJobs(schedule); err != nil {
+		t.Fatalf("Error pruning backup jobs: %s", err)
+	}
+
+	// The active job hasn't been recorded yet, and the running job is never removed
+	for index, kept := range []bool{false, false, true, true, true, true, true} {
+		err := cl.Get(context.TODO(), types.NamespacedName{Name: fmt.Sprintf("main-0-daily-%d", index), Namespace: "vitess"}, &batchv1.Job{})
+		if kept && err != nil {
+			t.Errorf("Backup job %d was removed: %s", index, err)
+		} else if !kept && !errors.IsNotFound(err) {
+			t.Errorf("Backup job %d was not removed: %v", index, err)
+		}
+	}
+}
+
+// TestCheckClusterCanBackup makes sure that only shards whose backups vtctld can list are backed up
+func TestCheckClusterCanBackup(t *testing.T) {
+	clusterStorage := &vitessv1alpha2.VitessBackupStorage{File: &vitessv1alpha2.FileBackupStorage{ClaimName: "backups"}}
+	otherStorage := &vitessv1alpha2.VitessBackupStorage{File: &vitessv1alpha2.FileBackupStorage{ClaimName: "other"}}
+
+	for _, tc := range []struct {
+		name            string
+		clusterStorage  *vitessv1alpha2.VitessBackupStorage
+		keyspaceStorage *vitessv1alpha2.VitessBackupStorage
+		shardStorage    *vitessv1alpha2.VitessBackupStorage
+		expectedError   string
+	}{
+		{name: "cluster storage", clusterStorage: clusterStorage},
+		{name: "same shard storage", clusterStorage: clusterStorage, shardStorage: clusterStorage.DeepCopy()},
+		{name: "no storage", expectedError: "has no backup storage"},
+		{name: "shard storage only", shardStorage: otherStorage, expectedError: "other backup storage"},
+		{name: "keyspace storage", clusterStorage: clusterStorage, keyspaceStorage: otherStorage, expectedError: "other backup storage"},
+	} {
+		cluster := &vitessv1alpha2.VitessCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "vt"},
+			Spec: vitessv1alpha2.VitessClusterSpec{
+				BackupStorage: tc.clusterStorage,
+				Cells:         []*vitessv1alpha2.VitessCell{{ObjectMeta: metav1.ObjectMeta{Name: "zone1"}}},
+			},
+		}
+		keyspace := &vitessv1alpha2.VitessKeyspace{
+			ObjectMeta: metav1.ObjectMeta{Name: "main"},
+			Spec: vitessv1alpha2.VitessKeyspaceSpec{
+				Defaults: &vitessv1alpha2.VitessShardOptions{BackupStorage: tc.keyspaceStorage},
+			},
+		}
+		shard := &vitessv1alpha2.VitessShard{
+			Spec: vitessv1alpha2.VitessShardSpec{
+				Defaults: &vitessv1alpha2.VitessShardOptions{BackupStorage: tc.shardStorage},
+			},
+		}
+
+		keyspace.Spec.Shards = []*vitessv1alpha2.VitessShard{shard}
+		cluster.Spec.Keyspaces = []*vitessv1alpha2.VitessKeyspace{keyspace}
+		keyspace.SetParentCluster(cluster)
+		shard.SetParentCluster(cluster)
+		shard.SetParentKeyspace(keyspace)
+
+		err := checkClusterCanBackup(cluster, "main/0")
+		if tc.expectedError == "" && err != nil {
+			t.Errorf("%s: Unexpected error: %s", tc.name, err)
+		} else if tc.expectedError != "" && (err == nil || !strings.Contains(err.Error(), tc.expectedError)) {
+			t.Errorf("%s: Wrong error. Got: %v; Expected: %s", tc.name, err, tc.expectedError)
+		}
+	}
+}
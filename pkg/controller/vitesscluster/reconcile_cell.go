@@ -2,6 +2,7 @@ package vitesscluster
 
 import (
 	"context"
+	"reflect"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -13,12 +14,28 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	lockserver_controller "vitess.io/vitess-operator/pkg/controller/vitesslockserver"
 	"vitess.io/vitess-operator/pkg/util/scripts"
 )
 
 func (r *ReconcileVitessCluster) ReconcileCell(cell *vitessv1alpha2.VitessCell) (reconcile.Result, error) {
 	log.Info("Reconciling Cell", "Namespace", cell.GetNamespace(), "VitessCluster.Name", cell.Cluster().GetName(), "Cell.Name", cell.GetName())
 
+	if r, err := r.ReconcileCellLockserver(cell); err != nil {
+		log.Error(err, "Failed to reconcile lockserver", "Namespace", cell.GetName(), "VitessCluster.Name", cell.Cluster().GetName(), "Cell.Name", cell.GetName())
+		return r, err
+	} else if r.Requeue {
+		return r, err
+	}
+
+	// Nothing in the cell can start until the cell is registered in the global topology
+	if r, err := r.ReconcileCellRegistration(cell); err != nil {
+		log.Error(err, "Failed to register cell", "Namespace", cell.GetName(), "VitessCluster.Name", cell.Cluster().GetName(), "Cell.Name", cell.GetName())
+		return r, err
+	} else if r.Requeue {
+		return r, err
+	}
+
 	if r, err := r.ReconcileCellVTctld(cell); err != nil {
 		log.Error(err, "Failed to reconcile vtctl", "Namespace", cell.GetName(), "VitessCluster.Name", cell.Cluster().GetName(), "Cell.Name", cell.GetName())
 		return r, err
@@ -36,6 +53,16 @@ func (r *ReconcileVitessCluster) ReconcileCell(cell *vitessv1alpha2.VitessCell)
 	return reconcile.Result{}, nil
 }
 
+func (r *ReconcileVitessCluster) ReconcileCellLockserver(cell *vitessv1alpha2.VitessCell) (reconcile.Result, error) {
+	// Referenced lockservers are reconciled by their own controller
+	if cell.Spec.LockserverRef != nil || cell.Lockserver() == nil {
+		return reconcile.Result{}, nil
+	}
+
+	// Embedded cell lockservers are owned by the cluster
+	return lockserver_controller.ReconcileObject(r.client, r.scheme, r.recorder, cell.Lockserver(), cell.Cluster(), log)
+}
+
 func (r *ReconcileVitessCluster) ReconcileCellVTctld(cell *vitessv1alpha2.VitessCell) (reconcile.Result, error) {
 	deploy, service, deployErr := GetCellVTctldResources(cell)
 	if deployErr != nil {
@@ -51,9 +78,26 @@ func (r *ReconcileVitessCluster) ReconcileCellVTctld(cell *vitessv1alpha2.Vitess
 		if err != nil {
 			return reconcile.Result{}, err
 		}
+		r.recorder.Eventf(cell.Cluster(), corev1.EventTypeNormal, "Created", "Created Deployment %s", deploy.GetName())
 	} else if err != nil {
 		log.Error(err, "failed to get Deployment")
 		return reconcile.Result{}, err
+	} else if !reflect.DeepEqual(foundDeployment.Spec.Template, deploy.Spec.Template) {
+		// The backup storage and lockserver credentials end up in the pod template, so it follows the cluster.
+		// Like the tablet StatefulSets, the defaults the API server adds make this update most of the time,
+		// and the generation tells the updates that change something apart.
+		log.Info("Updating vtctld Deployment for cell", "Namespace", cell.GetNamespace(), "VitessCluster.Name", cell.Cluster().GetName(), "Cell.Name", cell.GetName())
+
+		deploy.Spec.Template.DeepCopyInto(&foundDeployment.Spec.Template)
+
+		generation := foundDeployment.GetGeneration()
+		if err := r.client.Update(context.TODO(), foundDeployment); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		if foundDeployment.GetGeneration() != generation {
+			r.recorder.Eventf(cell.Cluster(), corev1.EventTypeNormal, "Updated", "Updated Deployment %s", foundDeployment.GetName())
+		}
 	}
 
 	foundService := &corev1.Service{}
@@ -102,7 +146,8 @@ func GetCellVTctldResources(cell *vitessv1alpha2.VitessCell) (*appsv1.Deployment
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: labels,
+					Labels:      labels,
+					Annotations: getScrapeAnnotations(15000),
 				},
 				Spec: corev1.PodSpec{
 					Containers: []corev1.Container{
@@ -116,6 +161,9 @@ func GetCellVTctldResources(cell *vitessv1alpha2.VitessCell) (*appsv1.Deployment
 								"-c",
 								scripts.Start,
 							},
+							// vtctld lists and removes backups in the cluster backup storage
+							Env:          append(cell.TopoLockserver().GetTopoEnv(), cell.Cluster().BackupStorage().GetBackupEnv()...),
+							VolumeMounts: append(cell.TopoLockserver().GetTopoVolumeMounts(), cell.Cluster().BackupStorage().GetBackupVolumeMounts()...),
 							LivenessProbe: &corev1.Probe{
 								Handler: corev1.Handler{
 									HTTPGet: &corev1.HTTPGetAction{
@@ -150,6 +198,7 @@ func GetCellVTctldResources(cell *vitessv1alpha2.VitessCell) (*appsv1.Deployment
 						FSGroup:   getInt64Ptr(2000),
 						RunAsUser: getInt64Ptr(1000),
 					},
+					Volumes: append(cell.TopoLockserver().GetTopoVolumes(), cell.Cluster().BackupStorage().GetBackupVolumes()...),
 				},
 			},
 		},
@@ -195,9 +244,25 @@ func (r *ReconcileVitessCluster) ReconcileCellVTGate(cell *vitessv1alpha2.Vitess
 		if err != nil {
 			return reconcile.Result{}, err
 		}
+		r.recorder.Eventf(cell.Cluster(), corev1.EventTypeNormal, "Created", "Created Deployment %s", deploy.GetName())
 	} else if err != nil {
 		log.Error(err, "failed to get Deployment")
 		return reconcile.Result{}, err
+	} else if !reflect.DeepEqual(foundDeployment.Spec.Template, deploy.Spec.Template) {
+		// The lockserver credentials and the monitoring settings end up in the pod template, so it follows
+		// the cluster the same way the vtctld Deployment does
+		log.Info("Updating vtgate Deployment for cell", "Namespace", cell.GetNamespace(), "VitessCluster.Name", cell.Cluster().GetName(), "Cell.Name", cell.GetName())
+
+		deploy.Spec.Template.DeepCopyInto(&foundDeployment.Spec.Template)
+
+		generation := foundDeployment.GetGeneration()
+		if err := r.client.Update(context.TODO(), foundDeployment); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		if foundDeployment.GetGeneration() != generation {
+			r.recorder.Eventf(cell.Cluster(), corev1.EventTypeNormal, "Updated", "Updated Deployment %s", foundDeployment.GetName())
+		}
 	}
 
 	foundService := &corev1.Service{}
@@ -284,7 +349,8 @@ func GetCellVTGateResources(cell *vitessv1alpha2.VitessCell) (*appsv1.Deployment
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: vtgateLabels,
+					Labels:      vtgateLabels,
+					Annotations: getScrapeAnnotations(15001),
 				},
 				Spec: corev1.PodSpec{
 					Affinity: affinity,
@@ -299,6 +365,7 @@ func GetCellVTGateResources(cell *vitessv1alpha2.VitessCell) (*appsv1.Deployment
 								"-c",
 								scriptGen.Start,
 							},
+							Env: cell.TopoLockserver().GetTopoEnv(),
 							LivenessProbe: &corev1.Probe{
 								Handler: corev1.Handler{
 									HTTPGet: &corev1.HTTPGetAction{
@@ -327,26 +394,26 @@ func GetCellVTGateResources(cell *vitessv1alpha2.VitessCell) (*appsv1.Deployment
 								SuccessThreshold:    1,
 								FailureThreshold:    3,
 							},
-							VolumeMounts: []corev1.VolumeMount{
+							VolumeMounts: append([]corev1.VolumeMount{
 								{
 									MountPath: "/mysqlcreds",
 									Name:      "creds",
 								},
-							},
+							}, cell.TopoLockserver().GetTopoVolumeMounts()...),
 						},
 					},
 					SecurityContext: &corev1.PodSecurityContext{
 						FSGroup:   getInt64Ptr(2000),
 						RunAsUser: getInt64Ptr(1000),
 					},
-					Volumes: []corev1.Volume{
+					Volumes: append([]corev1.Volume{
 						{
 							Name: "creds",
 							VolumeSource: corev1.VolumeSource{
 								EmptyDir: &corev1.EmptyDirVolumeSource{},
 							},
 						},
-					},
+					}, cell.TopoLockserver().GetTopoVolumes()...),
 				},
 			},
 		},
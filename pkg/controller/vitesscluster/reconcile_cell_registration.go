@@ -0,0 +1,266 @@
+package vitesscluster
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	"vitess.io/vitess-operator/pkg/util/scripts"
+)
+
+// CellRegistrationRequeueInterval is how often a cell is re-checked while its registration Job runs
+const CellRegistrationRequeueInterval = 10 * time.Second
+
+const (
+	cellRegisterJob   = "register_cell"
+	cellDeregisterJob = "deregister_cell"
+)
+
+var cellRegistrationComponents = map[string]string{
+	cellRegisterJob:   "register-cell",
+	cellDeregisterJob: "deregister-cell",
+}
+
+// ReconcileCellRegistration registers the cell in the global topology with a Job and holds off
+// the rest of the cell until the Job has succeeded
+func (r *ReconcileVitessCluster) ReconcileCellRegistration(cell *vitessv1alpha2.VitessCell) (reconcile.Result, error) {
+	job, err := GetCellRegistrationJob(cell, cellRegisterJob)
+	if err != nil {
+		log.Error(err, "failed to generate cell registration Job", "VitessCell.Name", cell.GetName())
+		return reconcile.Result{}, err
+	}
+
+	// A cell that is re-added while it is still being deregistered has to be registered again from scratch
+	deregisterJob, err := GetCellRegistrationJob(cell, cellDeregisterJob)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if deleted, err := r.deleteJobIfExists(deregisterJob); err != nil {
+		return reconcile.Result{}, err
+	} else if deleted {
+		if _, err := r.deleteJobIfExists(job); err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	found := &batchv1.Job{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: job.GetName(), Namespace: job.GetNamespace()}, found)
+	if err != nil && errors.IsNotFound(err) {
+		controllerutil.SetControllerReference(cell.Cluster(), job, r.scheme)
+		if err := r.client.Create(context.TODO(), job); err != nil {
+			return reconcile.Result{}, err
+		}
+		r.recorder.Eventf(cell.Cluster(), corev1.EventTypeNormal, "Created", "Created Job %s to register cell %s", job.GetName(), cell.GetName())
+		return reconcile.Result{Requeue: true, RequeueAfter: CellRegistrationRequeueInterval}, nil
+	} else if err != nil {
+		log.Error(err, "failed to get Job")
+		return reconcile.Result{}, err
+	}
+
+	// Job templates are immutable, so a Job for an outdated cell lockserver is replaced
+	if !reflect.DeepEqual(found.Spec.Template.Spec.Containers[0].Args, job.Spec.Template.Spec.Containers[0].Args) {
+		log.Info("Cell lockserver changed, registering the cell again", "VitessCell.Name", cell.GetName())
+		if _, err := r.deleteJobIfExists(found); err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	// A Job that ran out of retries never succeeds, so it is replaced to try again
+	if failed, message := getJobFailure(found); failed {
+		r.recorder.Eventf(cell.Cluster(), corev1.EventTypeWarning, "CellRegistrationFailed", "Job %s to register cell %s failed, retrying: %s", found.GetName(), cell.GetName(), message)
+		if _, err := r.deleteJobIfExists(found); err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{Requeue: true, RequeueAfter: CellRegistrationRequeueInterval}, nil
+	}
+
+	if found.Status.Succeeded == 0 {
+		log.Info("Waiting for cell to be registered in the global topology", "VitessCell.Name", cell.GetName())
+		return reconcile.Result{Requeue: true, RequeueAfter: CellRegistrationRequeueInterval}, nil
+	}
+
+	if !cell.Cluster().IsCellRegistered(cell.GetName()) {
+		return reconcile.Result{}, r.setCellRegistered(cell.Cluster(), cell.GetName(), true)
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// ReconcileClusterCellDeregistration removes cells that are no longer in the cluster from the global topology.
+// It never blocks the rest of the cluster; the Jobs are checked again on the next reconcile.
+func (r *ReconcileVitessCluster) ReconcileClusterCellDeregistration(cluster *vitessv1alpha2.VitessCluster) (reconcile.Result, error) {
+	for _, name := range cluster.Status.RegisteredCells {
+		if cluster.GetCellByID(name) != nil {
+			continue
+		}
+
+		// The cell is gone from the spec, so a stand-in is enough to generate the Jobs
+		cell := &vitessv1alpha2.VitessCell{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+		}
+		cell.SetParentCluster(cluster)
+
+		job, err := GetCellRegistrationJob(cell, cellDeregisterJob)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+
+		found := &batchv1.Job{}
+		err = r.client.Get(context.TODO(), types.NamespacedName{Name: job.GetName(), Namespace: job.GetNamespace()}, found)
+		if err != nil && errors.IsNotFound(err) {
+			log.Info("Deregistering cell from the global topology", "VitessCell.Name", name)
+			controllerutil.SetControllerReference(cluster, job, r.scheme)
+			if err := r.client.Create(context.TODO(), job); err != nil {
+				return reconcile.Result{}, err
+			}
+			r.recorder.Eventf(cluster, corev1.EventTypeNormal, "Created", "Created Job %s to deregister cell %s", job.GetName(), name)
+			continue
+		} else if err != nil {
+			log.Error(err, "failed to get Job")
+			return reconcile.Result{}, err
+		}
+
+		// The Job is created again on the next reconcile
+		if failed, message := getJobFailure(found); failed {
+			r.recorder.Eventf(cluster, corev1.EventTypeWarning, "CellDeregistrationFailed", "Job %s to deregister cell %s failed, retrying: %s", found.GetName(), name, message)
+			if _, err := r.deleteJobIfExists(found); err != nil {
+				return reconcile.Result{}, err
+			}
+			continue
+		}
+
+		if found.Status.Succeeded == 0 {
+			continue
+		}
+
+		// Clean up both Jobs so that the cell can be registered again later
+		registerJob := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      cell.GetScopedName(cellRegistrationComponents[cellRegisterJob]),
+				Namespace: cluster.GetNamespace(),
+			},
+		}
+		for _, j := range []*batchv1.Job{registerJob, found} {
+			if _, err := r.deleteJobIfExists(j); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+
+		if err := r.setCellRegistered(cluster, name, false); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// setCellRegistered records the registration in the status of the normalized cluster and of the stored one
+func (r *ReconcileVitessCluster) setCellRegistered(cluster *vitessv1alpha2.VitessCluster, name string, registered bool) error {
+	cluster.SetCellRegistered(name, registered)
+
+	if err := r.updateClusterStatus(cluster, func(foundCluster *vitessv1alpha2.VitessCluster) (bool, error) {
+		foundCluster.SetCellRegistered(name, registered)
+		return true, nil
+	}); err != nil {
+		log.Error(err, "Failed to update VitessCluster registered cells")
+		return err
+	}
+
+	return nil
+}
+
+// deleteJobIfExists deletes the Job along with its pods and reports whether there was one to delete
+func (r *ReconcileVitessCluster) deleteJobIfExists(job *batchv1.Job) (bool, error) {
+	err := r.client.Delete(context.TODO(), job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	if err != nil && errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		log.Error(err, "failed to delete Job", "Job.Name", job.GetName())
+		return false, err
+	}
+
+	return true, nil
+}
+
+// getJobFailure reports whether the Job has failed for good, along with why
+func getJobFailure(job *batchv1.Job) (bool, string) {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			return true, cond.Message
+		}
+	}
+	return false, ""
+}
+
+// GetCellRegistrationJob returns the Job that registers or deregisters the cell in the global topology
+func GetCellRegistrationJob(cell *vitessv1alpha2.VitessCell, jobType string) (*batchv1.Job, error) {
+	scriptGen := scripts.NewContainerScriptGenerator(jobType, cell)
+	if err := scriptGen.Generate(); err != nil {
+		return nil, err
+	}
+
+	component := cellRegistrationComponents[jobType]
+	jobName := cell.GetScopedName(component)
+
+	jobLabels := map[string]string{
+		"app":       "vitess",
+		"cluster":   cell.Cluster().GetName(),
+		"cell":      cell.GetName(),
+		"component": component,
+		"job-name":  jobName,
+	}
+
+	// The topo flags of the cell scripts take the credentials of both lockservers
+	topoLockserver := cell.TopoLockserver()
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: cell.Cluster().GetNamespace(),
+			Labels:    jobLabels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: getInt32Ptr(6),
+			Completions:  getInt32Ptr(1),
+			Parallelism:  getInt32Ptr(1),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: jobLabels,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  component,
+							Image: "vitess/vtctl:helm-1.0.3", // TODO use CRD w/default
+							Command: []string{
+								"bash",
+							},
+							Args: []string{
+								"-c",
+								scriptGen.Start,
+							},
+							Env:          topoLockserver.GetTopoEnv(),
+							VolumeMounts: topoLockserver.GetTopoVolumeMounts(),
+						},
+					},
+					Volumes:       topoLockserver.GetTopoVolumes(),
+					RestartPolicy: corev1.RestartPolicyOnFailure,
+				},
+			},
+		},
+	}, nil
+}
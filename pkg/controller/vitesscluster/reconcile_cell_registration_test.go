@@ -0,0 +1,220 @@
+package vitesscluster
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+)
+
+// TestCellRegistration makes sure that cells are registered in the global topology before anything else
+// in the cell starts, and deregistered once they are removed from the cluster
+func TestCellRegistration(t *testing.T) {
+	cluster := &vitessv1alpha2.VitessCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "vt",
+			Namespace: "vitess",
+		},
+		Spec: vitessv1alpha2.VitessClusterSpec{
+			Lockserver: &vitessv1alpha2.VitessLockserver{
+				Spec: vitessv1alpha2.VitessLockserverSpec{
+					Type: vitessv1alpha2.LockserverTypeEtcd2,
+					Etcd2: &vitessv1alpha2.Etcd2Lockserver{
+						Address: "global-etcd:2379",
+						Path:    "/vitess/global",
+					},
+				},
+			},
+		},
+	}
+
+	cell := &vitessv1alpha2.VitessCell{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "zone1",
+		},
+		Spec: vitessv1alpha2.VitessCellSpec{
+			Lockserver: &vitessv1alpha2.VitessLockserver{
+				Spec: vitessv1alpha2.VitessLockserverSpec{
+					Type: vitessv1alpha2.LockserverTypeEtcd2,
+					Etcd2: &vitessv1alpha2.Etcd2Lockserver{
+						Address: "zone1-etcd:2379",
+						Path:    "/vitess/zone1",
+					},
+				},
+			},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessCluster{})
+
+	cl := fake.NewFakeClient(cluster.DeepCopy())
+	r := &ReconcileVitessCluster{client: cl, scheme: s, recorder: &record.FakeRecorder{}}
+
+	cluster.Spec.Cells = []*vitessv1alpha2.VitessCell{cell}
+	cell.SetParentCluster(cluster)
+
+	res, err := r.ReconcileCellRegistration(cell)
+	if err != nil {
+		t.Fatalf("Error registering cell: %s", err)
+	}
+
+	if !res.Requeue {
+		t.Error("Cell reconcile did not wait for the registration Job")
+	}
+
+	registerJob := &batchv1.Job{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: "vt-zone1-register-cell", Namespace: "vitess"}, registerJob); err != nil {
+		t.Fatalf("Cell registration Job was not created: %s", err)
+	}
+
+	script := registerJob.Spec.Template.Spec.Containers[0].Args[1]
+	for _, flag := range []string{
+		`-topo_global_server_address="global-etcd:2379"`,
+		`-server_address="zone1-etcd:2379"`,
+		`-root="/vitess/zone1"`,
+		`UpdateCellInfo`,
+	} {
+		if !strings.Contains(script, flag) {
+			t.Errorf("Cell registration script missing %s", flag)
+		}
+	}
+
+	setJobSucceeded(t, cl, registerJob)
+
+	if res, err := r.ReconcileCellRegistration(cell); err != nil || res.Requeue {
+		t.Fatalf("Cell registration did not finish after the Job succeeded: %v, %v", res, err)
+	}
+
+	found := &vitessv1alpha2.VitessCluster{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: "vt", Namespace: "vitess"}, found); err != nil {
+		t.Fatalf("Error getting cluster: %s", err)
+	}
+
+	if !found.IsCellRegistered("zone1") {
+		t.Errorf("Registered cell not recorded in the cluster status: %v", found.Status.RegisteredCells)
+	}
+
+	// Remove the cell and make sure it is deregistered
+	found.Spec.Cells = nil
+	if _, err := r.ReconcileClusterCellDeregistration(found); err != nil {
+		t.Fatalf("Error deregistering cell: %s", err)
+	}
+
+	deregisterJob := &batchv1.Job{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: "vt-zone1-deregister-cell", Namespace: "vitess"}, deregisterJob); err != nil {
+		t.Fatalf("Cell deregistration Job was not created: %s", err)
+	}
+
+	if !strings.Contains(deregisterJob.Spec.Template.Spec.Containers[0].Args[1], `DeleteCellInfo`) {
+		t.Error("Cell deregistration script does not delete the cell")
+	}
+
+	setJobSucceeded(t, cl, deregisterJob)
+
+	if _, err := r.ReconcileClusterCellDeregistration(found); err != nil {
+		t.Fatalf("Error deregistering cell: %s", err)
+	}
+
+	if found.IsCellRegistered("zone1") {
+		t.Errorf("Deregistered cell still recorded in the cluster status: %v", found.Status.RegisteredCells)
+	}
+
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: "vt-zone1-register-cell", Namespace: "vitess"}, &batchv1.Job{}); err == nil {
+		t.Error("Cell registration Job was not cleaned up after deregistration")
+	}
+}
+
+// TestCellRegistrationJobFailed makes sure that a registration Job that ran out of retries is reported and replaced
+func TestCellRegistrationJobFailed(t *testing.T) {
+	cluster := &vitessv1alpha2.VitessCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "vt",
+			Namespace: "vitess",
+		},
+		Spec: vitessv1alpha2.VitessClusterSpec{
+			Lockserver: &vitessv1alpha2.VitessLockserver{
+				Spec: vitessv1alpha2.VitessLockserverSpec{
+					Type: vitessv1alpha2.LockserverTypeEtcd2,
+					Etcd2: &vitessv1alpha2.Etcd2Lockserver{
+						Address: "global-etcd:2379",
+						Path:    "/vitess/global",
+					},
+				},
+			},
+		},
+	}
+
+	cell := &vitessv1alpha2.VitessCell{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "zone1",
+		},
+		Spec: vitessv1alpha2.VitessCellSpec{
+			Lockserver: cluster.Spec.Lockserver.DeepCopy(),
+		},
+	}
+	cluster.Spec.Cells = []*vitessv1alpha2.VitessCell{cell}
+	cell.SetParentCluster(cluster)
+
+	job, err := GetCellRegistrationJob(cell, cellRegisterJob)
+	if err != nil {
+		t.Fatalf("Error generating cell registration Job: %s", err)
+	}
+	job.Status.Conditions = []batchv1.JobCondition{
+		{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded", Message: "Job has reached the specified backoff limit"},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessCluster{})
+
+	cl := fake.NewFakeClient(job)
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileVitessCluster{client: cl, scheme: s, recorder: recorder}
+
+	if res, err := r.ReconcileCellRegistration(cell); err != nil || !res.Requeue {
+		t.Fatalf("Cell registration did not wait for the failed Job to be replaced: %v, %v", res, err)
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if event != "Warning CellRegistrationFailed Job vt-zone1-register-cell to register cell zone1 failed, retrying: Job has reached the specified backoff limit" {
+			t.Errorf("Wrong event recorded: %s", event)
+		}
+	default:
+		t.Error("No event recorded for the failed Job")
+	}
+
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: job.GetName(), Namespace: "vitess"}, &batchv1.Job{}); err == nil {
+		t.Fatal("Failed cell registration Job was not deleted")
+	}
+
+	if _, err := r.ReconcileCellRegistration(cell); err != nil {
+		t.Fatalf("Error registering cell: %s", err)
+	}
+
+	found := &batchv1.Job{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: job.GetName(), Namespace: "vitess"}, found); err != nil {
+		t.Fatalf("Cell registration Job was not created again: %s", err)
+	}
+
+	if len(found.Status.Conditions) != 0 {
+		t.Errorf("Cell registration Job was not replaced: %v", found.Status.Conditions)
+	}
+}
+
+func setJobSucceeded(t *testing.T, cl client.Client, job *batchv1.Job) {
+	job.Status.Succeeded = 1
+	if err := cl.Update(context.TODO(), job); err != nil {
+		t.Fatalf("Error updating Job: %s", err)
+	}
+}
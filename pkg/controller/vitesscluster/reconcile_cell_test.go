@@ -1,12 +1,17 @@
 package vitesscluster
 
 import (
+	"context"
 	"strings"
 	"testing"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
 	// "vitess.io/vitess-operator/pkg/normalizer"
@@ -133,3 +138,380 @@ func vtGateDeploymentHasMySQLOpts(deployment *appsv1.Deployment, optstr string)
 
 	return false
 }
+
+func TestGetCellVTctldResourcesConsul(t *testing.T) {
+	cluster := &vitessv1alpha2.VitessCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testcluster",
+			Namespace: "vitess",
+		},
+	}
+
+	tokenRef := &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "consul-acl"},
+		Key:                  "token",
+	}
+
+	cell := &vitessv1alpha2.VitessCell{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "zone0",
+			Namespace: "vitess",
+		},
+		Spec: vitessv1alpha2.VitessCellSpec{
+			Lockserver: &vitessv1alpha2.VitessLockserver{
+				Spec: vitessv1alpha2.VitessLockserverSpec{
+					Type: vitessv1alpha2.LockserverTypeConsul,
+					Consul: &vitessv1alpha2.ConsulLockserver{
+						Address:           "consul:8500",
+						Path:              "vitess/zone0",
+						ACLTokenSecretRef: tokenRef,
+					},
+				},
+			},
+		},
+	}
+
+	// Components get their topo flags and credentials from the global lockserver
+	cluster.Spec.Lockserver = cell.Lockserver().DeepCopy()
+	cell.SetParentCluster(cluster)
+
+	deployment, _, err := GetCellVTctldResources(cell)
+	if err != nil {
+		t.Fatalf("Got error generating vtctld resources for cell: %s", err)
+	}
+
+	container := deployment.Spec.Template.Spec.Containers[0]
+	for _, flag := range []string{`-topo_implementation="consul"`, `-topo_global_server_address="consul:8500"`, `-topo_global_root="vitess/zone0"`} {
+		if !strings.Contains(container.Args[1], flag) {
+			t.Errorf("vtctld script missing consul topo flag %s", flag)
+		}
+	}
+
+	if len(container.Env) != 1 || container.Env[0].Name != "CONSUL_HTTP_TOKEN" || container.Env[0].ValueFrom.SecretKeyRef.Name != tokenRef.Name {
+		t.Errorf("vtctld container did not get the consul ACL token: %v", container.Env)
+	}
+}
+
+func TestGetCellVTctldResourcesEtcdTLS(t *testing.T) {
+	cluster := &vitessv1alpha2.VitessCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testcluster",
+			Namespace: "vitess",
+		},
+	}
+
+	cell := &vitessv1alpha2.VitessCell{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "zone0",
+			Namespace: "vitess",
+		},
+		Spec: vitessv1alpha2.VitessCellSpec{
+			Lockserver: &vitessv1alpha2.VitessLockserver{
+				Spec: vitessv1alpha2.VitessLockserverSpec{
+					Type: vitessv1alpha2.LockserverTypeEtcd2,
+					Etcd2: &vitessv1alpha2.Etcd2Lockserver{
+						Address: "etcd:2379",
+						Path:    "/vitess/zone0",
+						TLS: &vitessv1alpha2.LockserverTLS{
+							CASecretRef: &corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "etcd-ca"},
+								Key:                  "ca.crt",
+							},
+							CertSecretRef: &corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "etcd-client"},
+								Key:                  "tls.crt",
+							},
+							KeySecretRef: &corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "etcd-client"},
+								Key:                  "tls.key",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Components get their topo flags and credentials from the global lockserver
+	cluster.Spec.Lockserver = cell.Lockserver().DeepCopy()
+	cell.SetParentCluster(cluster)
+
+	deployment, _, err := GetCellVTctldResources(cell)
+	if err != nil {
+		t.Fatalf("Got error generating vtctld resources for cell: %s", err)
+	}
+
+	podSpec := deployment.Spec.Template.Spec
+	for _, flag := range []string{
+		`-topo_etcd_tls_ca="/vt/topo-tls/ca/ca.pem"`,
+		`-topo_etcd_tls_cert="/vt/topo-tls/cert/cert.pem"`,
+		`-topo_etcd_tls_key="/vt/topo-tls/key/key.pem"`,
+	} {
+		if !strings.Contains(podSpec.Containers[0].Args[1], flag) {
+			t.Errorf("vtctld script missing etcd TLS flag %s", flag)
+		}
+	}
+
+	if len(podSpec.Volumes) != 3 || podSpec.Volumes[1].Secret == nil || podSpec.Volumes[1].Secret.SecretName != "etcd-client" {
+		t.Errorf("vtctld pod did not get the etcd TLS Secret volumes: %v", podSpec.Volumes)
+	}
+
+	if len(podSpec.Containers[0].VolumeMounts) != 3 {
+		t.Errorf("vtctld container did not mount the etcd TLS Secrets: %v", podSpec.Containers[0].VolumeMounts)
+	}
+}
+
+func TestGetCellVTctldResourcesCellLockserverCredentials(t *testing.T) {
+	cluster := &vitessv1alpha2.VitessCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testcluster",
+			Namespace: "vitess",
+		},
+		Spec: vitessv1alpha2.VitessClusterSpec{
+			Lockserver: &vitessv1alpha2.VitessLockserver{
+				Spec: vitessv1alpha2.VitessLockserverSpec{
+					Type: vitessv1alpha2.LockserverTypeEtcd2,
+					Etcd2: &vitessv1alpha2.Etcd2Lockserver{
+						Address: "etcd-global:2379",
+						Path:    "/vitess/global",
+					},
+				},
+			},
+		},
+	}
+
+	// Only the cell lockserver uses TLS
+	cell := &vitessv1alpha2.VitessCell{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "zone0",
+			Namespace: "vitess",
+		},
+		Spec: vitessv1alpha2.VitessCellSpec{
+			Lockserver: &vitessv1alpha2.VitessLockserver{
+				Spec: vitessv1alpha2.VitessLockserverSpec{
+					Type: vitessv1alpha2.LockserverTypeEtcd2,
+					Etcd2: &vitessv1alpha2.Etcd2Lockserver{
+						Address: "etcd-zone0:2379",
+						Path:    "/vitess/zone0",
+						TLS: &vitessv1alpha2.LockserverTLS{
+							CASecretRef: &corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "etcd-ca"},
+								Key:                  "ca.crt",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	cell.SetParentCluster(cluster)
+
+	deployment, _, err := GetCellVTctldResources(cell)
+	if err != nil {
+		t.Fatalf("Got error generating vtctld resources for cell: %s", err)
+	}
+
+	podSpec := deployment.Spec.Template.Spec
+	for _, flag := range []string{`-topo_global_server_address="etcd-global:2379"`, `-topo_etcd_tls_ca="/vt/topo-tls/ca/ca.pem"`} {
+		if !strings.Contains(podSpec.Containers[0].Args[1], flag) {
+			t.Errorf("vtctld script missing topo flag %s", flag)
+		}
+	}
+
+	if len(podSpec.Volumes) != 1 || podSpec.Volumes[0].Secret == nil || podSpec.Volumes[0].Secret.SecretName != "etcd-ca" {
+		t.Errorf("vtctld pod did not get the cell lockserver CA volume: %v", podSpec.Volumes)
+	}
+
+	if len(podSpec.Containers[0].VolumeMounts) != 1 {
+		t.Errorf("vtctld container did not mount the cell lockserver CA: %v", podSpec.Containers[0].VolumeMounts)
+	}
+
+	// The global lockserver itself is left alone
+	if cluster.Lockserver().GetEtcdTLS() != nil {
+		t.Error("Cell lockserver credentials were merged into the global lockserver")
+	}
+}
+
+// TestVTctldDeploymentUpdated makes sure that an existing vtctld Deployment picks up changes to the cell,
+// like backup storage added after it was created
+func TestVTctldDeploymentUpdated(t *testing.T) {
+	cluster := &vitessv1alpha2.VitessCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testcluster",
+			Namespace: "vitess",
+		},
+		Spec: vitessv1alpha2.VitessClusterSpec{
+			Lockserver: &vitessv1alpha2.VitessLockserver{
+				Spec: vitessv1alpha2.VitessLockserverSpec{
+					Type: vitessv1alpha2.LockserverTypeEtcd2,
+					Etcd2: &vitessv1alpha2.Etcd2Lockserver{
+						Address: "global-lockserver:8080",
+						Path:    "/global",
+					},
+				},
+			},
+		},
+	}
+
+	cell := &vitessv1alpha2.VitessCell{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "zone0",
+			Namespace: "vitess",
+		},
+		Spec: vitessv1alpha2.VitessCellSpec{
+			Lockserver: cluster.Spec.Lockserver.DeepCopy(),
+		},
+	}
+	cell.SetParentCluster(cluster)
+
+	deployment, _, err := GetCellVTctldResources(cell)
+	if err != nil {
+		t.Fatalf("Got error generating vtctld resources for cell: %s", err)
+	}
+
+	cl := fake.NewFakeClient(deployment)
+	r := &ReconcileVitessCluster{client: cl, scheme: scheme.Scheme, recorder: &record.FakeRecorder{}}
+
+	cluster.Spec.BackupStorage = &vitessv1alpha2.VitessBackupStorage{
+		File: &vitessv1alpha2.FileBackupStorage{ClaimName: "backups"},
+	}
+
+	if _, err := r.ReconcileCellVTctld(cell); err != nil {
+		t.Fatalf("Error reconciling vtctld: %s", err)
+	}
+
+	found := &appsv1.Deployment{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: deployment.GetName(), Namespace: "vitess"}, found); err != nil {
+		t.Fatalf("Error getting vtctld Deployment: %s", err)
+	}
+
+	if !strings.Contains(found.Spec.Template.Spec.Containers[0].Args[1], "-backup_storage_implementation") {
+		t.Error("vtctld Deployment did not get the backup storage added to the cluster")
+	}
+}
+
+// TestVTGateDeploymentUpdated makes sure that an existing vtgate Deployment picks up changes to the cell,
+// like etcd client certificates added to the lockserver after it was created
+func TestVTGateDeploymentUpdated(t *testing.T) {
+	cluster := &vitessv1alpha2.VitessCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testcluster",
+			Namespace: "vitess",
+		},
+		Spec: vitessv1alpha2.VitessClusterSpec{
+			Lockserver: &vitessv1alpha2.VitessLockserver{
+				Spec: vitessv1alpha2.VitessLockserverSpec{
+					Type: vitessv1alpha2.LockserverTypeEtcd2,
+					Etcd2: &vitessv1alpha2.Etcd2Lockserver{
+						Address: "global-lockserver:8080",
+						Path:    "/global",
+					},
+				},
+			},
+		},
+	}
+
+	cell := &vitessv1alpha2.VitessCell{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "zone0",
+			Namespace: "vitess",
+		},
+		Spec: vitessv1alpha2.VitessCellSpec{
+			Lockserver: cluster.Spec.Lockserver.DeepCopy(),
+		},
+	}
+	cell.SetParentCluster(cluster)
+
+	deployment, _, err := GetCellVTGateResources(cell)
+	if err != nil {
+		t.Fatalf("Got error generating vtgate resources for cell: %s", err)
+	}
+
+	cl := fake.NewFakeClient(deployment)
+	r := &ReconcileVitessCluster{client: cl, scheme: scheme.Scheme, recorder: &record.FakeRecorder{}}
+
+	cluster.Spec.Lockserver.Spec.Etcd2.TLS = &vitessv1alpha2.LockserverTLS{
+		CASecretRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "etcd-ca"},
+			Key:                  "ca.crt",
+		},
+	}
+
+	if _, err := r.ReconcileCellVTGate(cell); err != nil {
+		t.Fatalf("Error reconciling vtgate: %s", err)
+	}
+
+	found := &appsv1.Deployment{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: deployment.GetName(), Namespace: "vitess"}, found); err != nil {
+		t.Fatalf("Error getting vtgate Deployment: %s", err)
+	}
+
+	podSpec := found.Spec.Template.Spec
+	if !strings.Contains(podSpec.Containers[0].Args[1], `-topo_etcd_tls_ca="/vt/topo-tls/ca/ca.pem"`) {
+		t.Errorf("vtgate script did not get the etcd TLS flags added to the lockserver: %s", podSpec.Containers[0].Args[1])
+	}
+
+	if len(podSpec.Volumes) == 0 || podSpec.Volumes[len(podSpec.Volumes)-1].Secret == nil || podSpec.Volumes[len(podSpec.Volumes)-1].Secret.SecretName != "etcd-ca" {
+		t.Errorf("vtgate pod did not get the etcd TLS Secret volume added to the lockserver: %v", podSpec.Volumes)
+	}
+}
+
+// TestVTGateDeploymentConsulToken makes sure that a Consul ACL token added to the lockserver reaches the
+// existing vtgate pods
+func TestVTGateDeploymentConsulToken(t *testing.T) {
+	cluster := &vitessv1alpha2.VitessCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testcluster",
+			Namespace: "vitess",
+		},
+		Spec: vitessv1alpha2.VitessClusterSpec{
+			Lockserver: &vitessv1alpha2.VitessLockserver{
+				Spec: vitessv1alpha2.VitessLockserverSpec{
+					Type: vitessv1alpha2.LockserverTypeConsul,
+					Consul: &vitessv1alpha2.ConsulLockserver{
+						Address: "consul:8500",
+						Path:    "vitess/global",
+					},
+				},
+			},
+		},
+	}
+
+	cell := &vitessv1alpha2.VitessCell{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "zone0",
+			Namespace: "vitess",
+		},
+		Spec: vitessv1alpha2.VitessCellSpec{
+			Lockserver: cluster.Spec.Lockserver.DeepCopy(),
+		},
+	}
+	cell.SetParentCluster(cluster)
+
+	deployment, _, err := GetCellVTGateResources(cell)
+	if err != nil {
+		t.Fatalf("Got error generating vtgate resources for cell: %s", err)
+	}
+
+	cl := fake.NewFakeClient(deployment)
+	r := &ReconcileVitessCluster{client: cl, scheme: scheme.Scheme, recorder: &record.FakeRecorder{}}
+
+	cluster.Spec.Lockserver.Spec.Consul.ACLTokenSecretRef = &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "consul-acl"},
+		Key:                  "token",
+	}
+
+	if _, err := r.ReconcileCellVTGate(cell); err != nil {
+		t.Fatalf("Error reconciling vtgate: %s", err)
+	}
+
+	found := &appsv1.Deployment{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: deployment.GetName(), Namespace: "vitess"}, found); err != nil {
+		t.Fatalf("Error getting vtgate Deployment: %s", err)
+	}
+
+	container := found.Spec.Template.Spec.Containers[0]
+	if len(container.Env) != 1 || container.Env[0].Name != "CONSUL_HTTP_TOKEN" || container.Env[0].ValueFrom.SecretKeyRef.Name != "consul-acl" {
+		t.Errorf("vtgate container did not get the consul ACL token added to the lockserver: %v", container.Env)
+	}
+}
@@ -2,6 +2,7 @@ package vitesscluster
 
 import (
 	"context"
+	"reflect"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -20,23 +21,35 @@ func (r *ReconcileVitessCluster) ReconcileClusterResources(cluster *vitessv1alph
 		return r, err
 	}
 
+	if r, err := r.ReconcileClusterCellDeregistration(cluster); err != nil || r.Requeue {
+		return r, err
+	}
+
 	if r, err := r.ReconcileClusterTabletService(cluster); err != nil || r.Requeue {
 		return r, err
 	}
 
+	if r, err := r.ReconcileClusterServiceMonitor(cluster); err != nil || r.Requeue {
+		return r, err
+	}
+
 	for _, cell := range cluster.Cells() {
 		if r, err := r.ReconcileCell(cell); err != nil || r.Requeue {
 			return r, err
 		}
 	}
 
+	// A keyspace that is waiting on something, e.g. a shard master election, doesn't hold up the others
+	keyspacesResult := reconcile.Result{}
 	for _, keyspace := range cluster.Keyspaces() {
-		if r, err := r.ReconcileKeyspace(keyspace); err != nil || r.Requeue {
-			return r, err
+		result, err := r.ReconcileKeyspace(keyspace)
+		if err != nil {
+			return result, err
 		}
+		keyspacesResult = mergeResults(keyspacesResult, result)
 	}
 
-	return reconcile.Result{}, nil
+	return keyspacesResult, nil
 }
 
 func (r *ReconcileVitessCluster) ReconcileClusterLockserver(cluster *vitessv1alpha2.VitessCluster) (reconcile.Result, error) {
@@ -45,27 +58,41 @@ func (r *ReconcileVitessCluster) ReconcileClusterLockserver(cluster *vitessv1alp
 	// Build a complete VitessLockserver
 	lockserver := cluster.Spec.Lockserver.DeepCopy()
 
-	if cluster.Status.Lockserver != nil {
-		// If status is not empty, deepcopy it into the tmp object
-		cluster.Status.Lockserver.DeepCopyInto(&lockserver.Status)
-	}
+	// Referenced lockservers are reconciled by their own controller, and the normalizer fetched them along with
+	// their current status, which is only mirrored into the cluster status. Embedded lockservers are reconciled
+	// here with the cluster as their owner, and keep their status in the cluster status.
+	var recResult reconcile.Result
+	var recErr error
+	if cluster.Spec.LockserverRef == nil {
+		if cluster.Status.Lockserver != nil {
+			// If status is not empty, deepcopy it into the tmp object
+			cluster.Status.Lockserver.DeepCopyInto(&lockserver.Status)
+		}
 
-	// Run it through the controller's reconcile func
-	recResult, recErr := lockserver_controller.ReconcileObject(lockserver, log)
+		// Run it through the controller's reconcile func
+		recResult, recErr = lockserver_controller.ReconcileObject(r.client, r.scheme, r.recorder, lockserver, cluster, log)
+	}
 
 	// Split and store the spec and status in the parent VitessCluster
 	cluster.Spec.Lockserver = lockserver.DeepCopy()
 	cluster.Status.Lockserver = lockserver.Status.DeepCopy()
 
-	// Using the  split client here breaks the cluster normalization
-	// TODO Fix and re-enable
+	if recErr != nil {
+		return recResult, recErr
+	}
 
-	// if err := r.client.Status().Update(context.TODO(), cluster); err != nil {
-	// 	log.Error(err, "Failed to update VitessCluster status after lockserver change.")
-	// 	return reconcile.Result{}, err
-	// }
+	if err := r.updateClusterStatus(cluster, func(foundCluster *vitessv1alpha2.VitessCluster) (bool, error) {
+		if reflect.DeepEqual(foundCluster.Status.Lockserver, cluster.Status.Lockserver) {
+			return false, nil
+		}
+		foundCluster.Status.Lockserver = cluster.Status.Lockserver.DeepCopy()
+		return true, nil
+	}); err != nil {
+		log.Error(err, "Failed to update VitessCluster status after lockserver change.")
+		return reconcile.Result{}, err
+	}
 
-	return recResult, recErr
+	return recResult, nil
 }
 
 func (r *ReconcileVitessCluster) ReconcileClusterTabletService(cluster *vitessv1alpha2.VitessCluster) (reconcile.Result, error) {
@@ -85,11 +112,40 @@ func (r *ReconcileVitessCluster) ReconcileClusterTabletService(cluster *vitessv1
 	} else if err != nil {
 		log.Error(err, "failed to get Service")
 		return reconcile.Result{}, err
+	} else if updateScrapeAnnotations(foundService, service) {
+		// The mysqld_exporter sidecar can be enabled and disabled on an existing cluster
+		if err := r.client.Update(context.TODO(), foundService); err != nil {
+			return reconcile.Result{}, err
+		}
 	}
 
 	return reconcile.Result{}, nil
 }
 
+// updateScrapeAnnotations copies the Prometheus scrape annotations of the generated Service to the found one,
+// leaving any other annotations alone. It returns true if the annotations changed.
+func updateScrapeAnnotations(found *corev1.Service, generated *corev1.Service) bool {
+	changed := false
+	for _, key := range []string{scrapeAnnotation, scrapePortAnnotation, scrapePathAnnotation} {
+		value, ok := generated.Annotations[key]
+		foundValue, foundOk := found.Annotations[key]
+		if ok == foundOk && value == foundValue {
+			continue
+		}
+
+		changed = true
+		if !ok {
+			delete(found.Annotations, key)
+		} else {
+			if found.Annotations == nil {
+				found.Annotations = map[string]string{}
+			}
+			found.Annotations[key] = value
+		}
+	}
+	return changed
+}
+
 // getServiceForClusterTablets takes a vitess cluster and returns a headless service that will point to all of the cluster's tablets
 func getServiceForClusterTablets(cluster *vitessv1alpha2.VitessCluster) (*corev1.Service, error) {
 	labels := map[string]string{
@@ -98,14 +154,23 @@ func getServiceForClusterTablets(cluster *vitessv1alpha2.VitessCluster) (*corev1
 		"component": "vttablet",
 	}
 
+	annotations := map[string]string{
+		"service.alpha.kubernetes.io/tolerate-unready-endpoints": "true",
+	}
+
+	// The vttablet metrics are scraped through the pod annotations, and the MySQL metrics through the Service
+	if cluster.MySQLExporter() != nil {
+		for k, v := range getScrapeAnnotations(MySQLExporterPort) {
+			annotations[k] = v
+		}
+	}
+
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      cluster.GetTabletServiceName(),
-			Namespace: cluster.GetNamespace(),
-			Labels:    labels,
-			Annotations: map[string]string{
-				"service.alpha.kubernetes.io/tolerate-unready-endpoints": "true",
-			},
+			Name:        cluster.GetTabletServiceName(),
+			Namespace:   cluster.GetNamespace(),
+			Labels:      labels,
+			Annotations: annotations,
 		},
 		Spec: corev1.ServiceSpec{
 			ClusterIP:                corev1.ClusterIPNone,
@@ -121,14 +186,10 @@ func getServiceForClusterTablets(cluster *vitessv1alpha2.VitessCluster) (*corev1
 					Name: "grpc",
 					Port: 16002,
 				},
-				// TODO: Configure ports below only if if ppm is enabled
-				{
-					Name: "query-data",
-					Port: 42001,
-				},
+				// Served by the mysqld_exporter sidecar when it is enabled
 				{
 					Name: "mysql-metrics",
-					Port: 42002,
+					Port: MySQLExporterPort,
 				},
 			},
 		},
@@ -0,0 +1,368 @@
+package vitesscluster
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	"vitess.io/vitess-operator/pkg/util/metrics"
+	"vitess.io/vitess-operator/pkg/util/vtctld"
+)
+
+// maxConditionMessageItems caps how many components are named in a condition message
+const maxConditionMessageItems = 3
+
+// clusterStatusSummary collects the components of a cluster that keep it from being available and steady
+type clusterStatusSummary struct {
+	notReady  []string
+	scaling   []string
+	upgrading []string
+}
+
+// observe records the state of a component given its status and the number of pods it currently has
+func (s *clusterStatusSummary) observe(component string, status vitessv1alpha2.ComponentStatus, current int32) {
+	if !status.IsReady() {
+		s.notReady = append(s.notReady, fmt.Sprintf("%s has %d/%d ready replicas", component, status.ReadyReplicas, status.Replicas))
+	}
+	if current != status.Replicas {
+		s.scaling = append(s.scaling, fmt.Sprintf("%s is scaling from %d to %d replicas", component, current, status.Replicas))
+	}
+	if status.UpdatedReplicas < current {
+		s.upgrading = append(s.upgrading, fmt.Sprintf("%s has %d/%d updated replicas", component, status.UpdatedReplicas, current))
+	}
+}
+
+// ReconcileClusterStatus records the replicas of every cell, keyspace, shard and tablet of the cluster in its
+// status, and maintains the Available, Scaling, Upgrading and Recovering conditions from them.
+// The tablet pods are listed as vtctld reports them once a vtctld is ready. The status of standalone
+// keyspaces, shards and tablets is also written back to their own objects.
+// It is called after every reconcile of the cluster resources, including those that requeue.
+func (r *ReconcileVitessCluster) ReconcileClusterStatus(cluster *vitessv1alpha2.VitessCluster) error {
+	var status *vitessv1alpha2.VitessClusterStatus
+	if err := r.updateClusterStatus(cluster, func(foundCluster *vitessv1alpha2.VitessCluster) (bool, error) {
+		oldStatus := foundCluster.Status.DeepCopy()
+		if err := r.observeCluster(cluster, foundCluster); err != nil {
+			return false, err
+		}
+		status = &foundCluster.Status
+		return !reflect.DeepEqual(oldStatus, status), nil
+	}); err != nil {
+		log.Error(err, "Failed to update VitessCluster status summary")
+		return err
+	}
+
+	return r.updateStandaloneStatuses(cluster, status)
+}
+
+// observeCluster records the state of the components of the normalized cluster in the status of the stored one
+func (r *ReconcileVitessCluster) observeCluster(cluster *vitessv1alpha2.VitessCluster, foundCluster *vitessv1alpha2.VitessCluster) error {
+	summary := &clusterStatusSummary{}
+
+	vtctldReady := false
+	cells := map[string]*vitessv1alpha2.VitessCellStatus{}
+	for _, cell := range cluster.Cells() {
+		vtctldDeployment, _, err := GetCellVTctldResources(cell)
+		if err != nil {
+			return err
+		}
+
+		vtgateDeployment, _, err := GetCellVTGateResources(cell)
+		if err != nil {
+			return err
+		}
+
+		status := &vitessv1alpha2.VitessCellStatus{}
+		if status.VTCtld, err = r.observeDeployment(summary, fmt.Sprintf("vtctld of cell %s", cell.GetName()), vtctldDeployment); err != nil {
+			return err
+		}
+		if status.VTGate, err = r.observeDeployment(summary, fmt.Sprintf("vtgate of cell %s", cell.GetName()), vtgateDeployment); err != nil {
+			return err
+		}
+
+		vtctldReady = vtctldReady || status.VTCtld.ReadyReplicas > 0
+		cells[cell.GetName()] = status
+	}
+
+	tablets := map[vitessv1alpha2.TabletPhase]int{}
+	keyspaces := map[string]*vitessv1alpha2.VitessKeyspaceStatus{}
+	for _, keyspace := range cluster.Keyspaces() {
+		keyspaceStatus := &vitessv1alpha2.VitessKeyspaceStatus{}
+
+		for _, shard := range keyspace.Shards() {
+			shardStatus := foundCluster.GetShardStatus(shard).DeepCopy()
+			shardStatus.Replicas = 0
+			shardStatus.ReadyReplicas = 0
+
+			var vtctldClient vtctld.Client
+			var topoTablets []vtctld.Tablet
+			if vtctldReady {
+				vtctldClient = newVtctldClient(cluster.GetVtctldAddress())
+
+				var err error
+				if topoTablets, err = vtctldClient.ListShardTablets(shard.GetKeyspaceShard()); err != nil {
+					log.Info("Unable to list shard tablets with vtctld, keeping the previous tablet pods", "Shard", shard.GetKeyspaceShard(), "Error", err.Error())
+					vtctldClient = nil
+				}
+			}
+
+			for _, tablet := range shard.Tablets() {
+				tabletStatus, err := r.observeTablet(summary, foundCluster, tablet)
+				if err != nil {
+					return err
+				}
+
+				if vtctldClient != nil {
+					tabletStatus.Pods = getTabletPodStatuses(vtctldClient, tablet, topoTablets)
+				}
+
+				tablets[tabletStatus.Phase]++
+				shardStatus.Replicas += tabletStatus.Replicas
+				shardStatus.ReadyReplicas += tabletStatus.ReadyReplicas
+			}
+
+			if shardStatus.MasterAlias == "" {
+				summary.notReady = append(summary.notReady, fmt.Sprintf("shard %s has no master", shard.GetKeyspaceShard()))
+			}
+
+			keyspaceStatus.Shards++
+			if shardStatus.IsReady() {
+				keyspaceStatus.ReadyShards++
+			}
+
+			foundCluster.SetShardStatus(shard, shardStatus)
+		}
+
+		keyspaces[keyspace.GetName()] = keyspaceStatus
+	}
+
+	foundCluster.Status.Cells = cells
+	foundCluster.Status.Keyspaces = keyspaces
+	foundCluster.Status.ReadyTablets = int32(tablets[vitessv1alpha2.TabletPhaseReady])
+
+	setClusterConditions(foundCluster, summary)
+
+	for _, phase := range tabletPhases {
+		metrics.SetClusterTablets(cluster.GetNamespace(), cluster.GetName(), tabletPhaseLabel(phase), tablets[phase])
+	}
+
+	return nil
+}
+
+// tabletPhases are all the phases of a tablet, so that the tablet metrics report the phases without tablets too
+var tabletPhases = []vitessv1alpha2.TabletPhase{vitessv1alpha2.TabletPhaseNone, vitessv1alpha2.TabletPhaseReady}
+
+// tabletPhaseLabel returns the metric label of a tablet phase, since the phase of an unready tablet is empty
+func tabletPhaseLabel(phase vitessv1alpha2.TabletPhase) string {
+	if phase == vitessv1alpha2.TabletPhaseNone {
+		return "None"
+	}
+	return string(phase)
+}
+
+// tabletPhaseLabels returns the metric labels of all the phases of a tablet
+func tabletPhaseLabels() []string {
+	labels := []string{}
+	for _, phase := range tabletPhases {
+		labels = append(labels, tabletPhaseLabel(phase))
+	}
+	return labels
+}
+
+// observeDeployment returns the status of the given generated Deployment from the one that exists, if any
+func (r *ReconcileVitessCluster) observeDeployment(summary *clusterStatusSummary, component string, deployment *appsv1.Deployment) (vitessv1alpha2.ComponentStatus, error) {
+	status := vitessv1alpha2.ComponentStatus{Replicas: *deployment.Spec.Replicas}
+
+	found := &appsv1.Deployment{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: deployment.GetName(), Namespace: deployment.GetNamespace()}, found)
+	if err != nil && !errors.IsNotFound(err) {
+		return status, err
+	}
+
+	status.ReadyReplicas = found.Status.ReadyReplicas
+	status.UpdatedReplicas = found.Status.UpdatedReplicas
+
+	summary.observe(component, status, found.Status.Replicas)
+
+	return status, nil
+}
+
+// observeTablet records the replicas of the tablet StatefulSet, if it exists, in the tablet status of the cluster
+func (r *ReconcileVitessCluster) observeTablet(summary *clusterStatusSummary, cluster *vitessv1alpha2.VitessCluster, tablet *vitessv1alpha2.VitessTablet) (*vitessv1alpha2.VitessTabletStatus, error) {
+	found := &appsv1.StatefulSet{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: tablet.GetStatefulSetName(), Namespace: cluster.GetNamespace()}, found)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	status := cluster.GetTabletStatus(tablet).DeepCopy()
+	status.Phase = vitessv1alpha2.TabletPhaseNone
+	if err == nil && isStatefulSetReady(found) && !status.IsRestoring() {
+		status.Phase = vitessv1alpha2.TabletPhaseReady
+	}
+	status.Replicas = *tablet.GetReplicas()
+	status.ReadyReplicas = found.Status.ReadyReplicas
+	status.UpdatedReplicas = found.Status.UpdatedReplicas
+
+	summary.observe(fmt.Sprintf("tablet %s", tablet.GetStatefulSetName()), vitessv1alpha2.ComponentStatus{
+		Replicas:        status.Replicas,
+		ReadyReplicas:   status.ReadyReplicas,
+		UpdatedReplicas: status.UpdatedReplicas,
+	}, found.Status.Replicas)
+
+	cluster.SetTabletStatus(tablet, status)
+
+	return status, nil
+}
+
+// getTabletPodStatuses returns the state of the pods of the tablet that are registered in the topology
+func getTabletPodStatuses(client vtctld.Client, tablet *vitessv1alpha2.VitessTablet, topoTablets []vtctld.Tablet) []vitessv1alpha2.TabletPodStatus {
+	var pods []vitessv1alpha2.TabletPodStatus
+	for _, topoTablet := range topoTablets {
+		if _, ok := getPodOrdinal(tablet.GetStatefulSetName(), topoTablet.PodName()); !ok {
+			continue
+		}
+
+		pod := vitessv1alpha2.TabletPodStatus{
+			PodName: topoTablet.PodName(),
+			Alias:   topoTablet.Alias,
+			Type:    topoTablet.Type,
+		}
+
+		health, err := client.GetTabletHealth(topoTablet.Alias)
+		if err != nil {
+			pod.HealthError = err.Error()
+		} else {
+			lag := int32(health.ReplicationLagSeconds)
+			pod.Serving = health.Serving
+			pod.ReplicationLagSeconds = &lag
+			pod.HealthError = health.HealthError
+		}
+
+		pods = append(pods, pod)
+	}
+
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].PodName < pods[j].PodName
+	})
+
+	return pods
+}
+
+// updateStandaloneStatuses writes the status recorded in the cluster status to the standalone keyspaces,
+// shards and tablets of the cluster. Objects embedded in their parent have nowhere to write it to.
+func (r *ReconcileVitessCluster) updateStandaloneStatuses(cluster *vitessv1alpha2.VitessCluster, status *vitessv1alpha2.VitessClusterStatus) error {
+	for _, keyspace := range cluster.Keyspaces() {
+		if keyspaceStatus, ok := status.Keyspaces[keyspace.GetName()]; ok && keyspace.IsStandalone() {
+			found := &vitessv1alpha2.VitessKeyspace{}
+			if err := r.updateStandaloneStatus(keyspace.GetName(), keyspace.GetNamespace(), found, func() bool {
+				if reflect.DeepEqual(&found.Status, keyspaceStatus) {
+					return false
+				}
+				found.Status = *keyspaceStatus.DeepCopy()
+				return true
+			}); err != nil {
+				return err
+			}
+		}
+
+		for _, shard := range keyspace.Shards() {
+			if shardStatus, ok := status.Shards[shard.GetKeyspaceShard()]; ok && shard.IsStandalone() {
+				found := &vitessv1alpha2.VitessShard{}
+				if err := r.updateStandaloneStatus(shard.GetName(), shard.GetNamespace(), found, func() bool {
+					if reflect.DeepEqual(&found.Status, shardStatus) {
+						return false
+					}
+					found.Status = *shardStatus.DeepCopy()
+					return true
+				}); err != nil {
+					return err
+				}
+			}
+
+			for _, tablet := range shard.Tablets() {
+				if tabletStatus, ok := status.Tablets[tablet.GetStatefulSetName()]; ok && tablet.IsStandalone() {
+					found := &vitessv1alpha2.VitessTablet{}
+					if err := r.updateStandaloneStatus(tablet.GetName(), tablet.GetNamespace(), found, func() bool {
+						if reflect.DeepEqual(&found.Status, tabletStatus) {
+							return false
+						}
+						found.Status = *tabletStatus.DeepCopy()
+						return true
+					}); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// updateStandaloneStatus fetches the named object and updates its status if setStatus changed it.
+// Objects that were deleted since the cluster was normalized are skipped.
+func (r *ReconcileVitessCluster) updateStandaloneStatus(name string, namespace string, obj runtime.Object, setStatus func() bool) error {
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, obj)
+	if err != nil && errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if !setStatus() {
+		return nil
+	}
+
+	if err := r.client.Status().Update(context.TODO(), obj); err != nil {
+		log.Error(err, "Failed to update standalone object status", "Namespace", namespace, "Name", name)
+		return err
+	}
+
+	return nil
+}
+
+// setClusterConditions sets the cluster conditions from the summary of its components.
+// A cluster is only recovering if it was ready before and its components aren't just scaling or upgrading.
+func setClusterConditions(cluster *vitessv1alpha2.VitessCluster, summary *clusterStatusSummary) {
+	if len(summary.notReady) == 0 {
+		cluster.SetCondition(vitessv1alpha2.VitessClusterConditionAvailable, corev1.ConditionTrue, "ComponentsReady", "")
+	} else {
+		cluster.SetCondition(vitessv1alpha2.VitessClusterConditionAvailable, corev1.ConditionFalse, "ComponentsNotReady", conditionMessage(summary.notReady))
+	}
+
+	if len(summary.scaling) > 0 {
+		cluster.SetCondition(vitessv1alpha2.VitessClusterConditionScaling, corev1.ConditionTrue, "ReplicasChanging", conditionMessage(summary.scaling))
+	} else {
+		cluster.SetCondition(vitessv1alpha2.VitessClusterConditionScaling, corev1.ConditionFalse, "", "")
+	}
+
+	if len(summary.upgrading) > 0 {
+		cluster.SetCondition(vitessv1alpha2.VitessClusterConditionUpgrading, corev1.ConditionTrue, "RolloutInProgress", conditionMessage(summary.upgrading))
+	} else {
+		cluster.SetCondition(vitessv1alpha2.VitessClusterConditionUpgrading, corev1.ConditionFalse, "", "")
+	}
+
+	recovering := len(summary.notReady) > 0 && len(summary.scaling) == 0 && len(summary.upgrading) == 0 && cluster.InPhase(vitessv1alpha2.ClusterPhaseReady)
+	if recovering {
+		cluster.SetCondition(vitessv1alpha2.VitessClusterConditionRecovering, corev1.ConditionTrue, "ComponentsNotReady", conditionMessage(summary.notReady))
+	} else {
+		cluster.SetCondition(vitessv1alpha2.VitessClusterConditionRecovering, corev1.ConditionFalse, "", "")
+	}
+}
+
+// conditionMessage joins the first few of the given problems into a condition message
+func conditionMessage(items []string) string {
+	if len(items) <= maxConditionMessageItems {
+		return strings.Join(items, "; ")
+	}
+	return fmt.Sprintf("%s; and %d more", strings.Join(items[:maxConditionMessageItems], "; "), len(items)-maxConditionMessageItems)
+}
@@ -0,0 +1,310 @@
+package vitesscluster
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	"vitess.io/vitess-operator/pkg/util/metrics"
+	"vitess.io/vitess-operator/pkg/util/vtctld"
+)
+
+// newStatusTestDeployment returns a Deployment with the given replicas, all of them ready and updated
+func newStatusTestDeployment(name string, replicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "vitess"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			Replicas:        replicas,
+			ReadyReplicas:   replicas,
+			UpdatedReplicas: replicas,
+		},
+	}
+}
+
+// TestClusterStatus makes sure that the replicas of every component are summarized in the cluster status,
+// and that the conditions follow the components as they become unready, scale and upgrade
+func TestClusterStatus(t *testing.T) {
+	var replicas int32 = 2
+
+	cluster, shard, tablet := newReparentTestShard(replicas, "vt-zone1-main-0-replica-0")
+	keyspace := tablet.Keyspace()
+	keyspace.Spec.Shards = []*vitessv1alpha2.VitessShard{shard}
+	cluster.Spec.Keyspaces = []*vitessv1alpha2.VitessKeyspace{keyspace}
+	cluster.SetPhase(vitessv1alpha2.ClusterPhaseReady)
+
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: tablet.GetStatefulSetName(), Namespace: "vitess"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+		Status: appsv1.StatefulSetStatus{
+			Replicas:        replicas,
+			ReadyReplicas:   replicas,
+			UpdatedReplicas: replicas,
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessCluster{})
+
+	cl := fake.NewFakeClient(
+		&vitessv1alpha2.VitessCluster{ObjectMeta: *cluster.ObjectMeta.DeepCopy(), Status: *cluster.Status.DeepCopy()},
+		newStatusTestDeployment("vt-zone1-vtctld", 1),
+		newStatusTestDeployment("vt-zone1-vtgate", 2),
+		statefulSet,
+	)
+	r := &ReconcileVitessCluster{client: cl, scheme: s, recorder: &record.FakeRecorder{}}
+
+	defer func() { newVtctldClient = vtctld.NewClient }()
+	newVtctldClient = func(address string) vtctld.Client { return &vtctld.FakeClient{} }
+
+	getCluster := func() *vitessv1alpha2.VitessCluster {
+		if err := r.ReconcileClusterStatus(cluster); err != nil {
+			t.Fatalf("Error reconciling cluster status: %s", err)
+		}
+
+		found := &vitessv1alpha2.VitessCluster{}
+		if err := cl.Get(context.TODO(), types.NamespacedName{Name: "vt", Namespace: "vitess"}, found); err != nil {
+			t.Fatalf("Error getting cluster: %s", err)
+		}
+		return found
+	}
+
+	updateStatefulSet := func(status appsv1.StatefulSetStatus) {
+		statefulSet.Status = status
+		if err := cl.Update(context.TODO(), statefulSet); err != nil {
+			t.Fatalf("Error updating StatefulSet: %s", err)
+		}
+	}
+
+	found := getCluster()
+
+	if cell := found.Status.Cells["zone1"]; cell == nil || cell.VTCtld.ReadyReplicas != 1 || cell.VTGate.Replicas != 2 || !cell.VTGate.IsReady() {
+		t.Errorf("Wrong cell status: %+v", cell)
+	}
+
+	if keyspace := found.Status.Keyspaces["main"]; keyspace == nil || keyspace.Shards != 1 || keyspace.ReadyShards != 1 {
+		t.Errorf("Wrong keyspace status: %+v", keyspace)
+	}
+
+	if shardStatus := found.GetShardStatus(shard); shardStatus.Replicas != 2 || shardStatus.ReadyReplicas != 2 || shardStatus.MasterAlias != "zone1-0000000100" {
+		t.Errorf("Wrong shard status: %+v", shardStatus)
+	}
+
+	if tabletStatus := found.GetTabletStatus(tablet); tabletStatus.Replicas != 2 || tabletStatus.ReadyReplicas != 2 {
+		t.Errorf("Wrong tablet status: %+v", tabletStatus)
+	}
+
+	if found.Status.ReadyTablets != 1 {
+		t.Errorf("Wrong number of ready tablets. Got: %d; Expected: 1", found.Status.ReadyTablets)
+	}
+
+	if ready := testutil.ToFloat64(metrics.Tablets.WithLabelValues("vitess", "vt", "Ready")); ready != 1 {
+		t.Errorf("Wrong number of ready tablets reported. Got: %v; Expected: 1", ready)
+	}
+
+	if !found.IsConditionTrue(vitessv1alpha2.VitessClusterConditionAvailable) {
+		t.Errorf("Cluster not available: %+v", found.GetCondition(vitessv1alpha2.VitessClusterConditionAvailable))
+	}
+
+	for _, condition := range []vitessv1alpha2.ClusterConditionType{
+		vitessv1alpha2.VitessClusterConditionScaling,
+		vitessv1alpha2.VitessClusterConditionUpgrading,
+		vitessv1alpha2.VitessClusterConditionRecovering,
+	} {
+		if found.IsConditionTrue(condition) {
+			t.Errorf("Steady cluster has the %s condition", condition)
+		}
+	}
+
+	// A tablet pod that goes down in a ready cluster is being recovered
+	updateStatefulSet(appsv1.StatefulSetStatus{Replicas: 2, ReadyReplicas: 1, UpdatedReplicas: 2})
+	found = getCluster()
+
+	available := found.GetCondition(vitessv1alpha2.VitessClusterConditionAvailable)
+	if available.Status != corev1.ConditionFalse || !strings.Contains(available.Message, "tablet vt-zone1-main-0-replica has 1/2 ready replicas") || available.LastTransitionTime == "" {
+		t.Errorf("Unready tablet not reported: %+v", available)
+	}
+
+	if !found.IsConditionTrue(vitessv1alpha2.VitessClusterConditionRecovering) {
+		t.Error("Cluster with an unready tablet is not recovering")
+	}
+
+	if keyspace := found.Status.Keyspaces["main"]; keyspace.ReadyShards != 0 {
+		t.Errorf("Shard with an unready tablet counted as ready: %+v", keyspace)
+	}
+
+	// A pod that is replaced during a rollout isn't a recovery
+	updateStatefulSet(appsv1.StatefulSetStatus{Replicas: 2, ReadyReplicas: 1, UpdatedReplicas: 1})
+	found = getCluster()
+
+	if !found.IsConditionTrue(vitessv1alpha2.VitessClusterConditionUpgrading) || found.IsConditionTrue(vitessv1alpha2.VitessClusterConditionRecovering) {
+		t.Errorf("Rollout not reported as an upgrade: %+v", found.Status.Conditions)
+	}
+
+	// Neither is a pod that is being added
+	*tablet.Spec.Replicas = 3
+	updateStatefulSet(appsv1.StatefulSetStatus{Replicas: 2, ReadyReplicas: 2, UpdatedReplicas: 2})
+	found = getCluster()
+
+	if !found.IsConditionTrue(vitessv1alpha2.VitessClusterConditionScaling) || found.IsConditionTrue(vitessv1alpha2.VitessClusterConditionRecovering) {
+		t.Errorf("Added replica not reported as scaling: %+v", found.Status.Conditions)
+	}
+}
+
+// TestStandaloneTabletStatus makes sure that the pods of a tablet are reported as vtctld sees them,
+// and that the status of a tablet matched by a tabletSelector is written back to it
+func TestStandaloneTabletStatus(t *testing.T) {
+	var replicas int32 = 2
+
+	cluster, shard, tablet := newReparentTestShard(replicas, "vt-zone1-main-0-replica-0")
+	keyspace := tablet.Keyspace()
+	keyspace.Spec.Shards = []*vitessv1alpha2.VitessShard{shard}
+	cluster.Spec.Keyspaces = []*vitessv1alpha2.VitessKeyspace{keyspace}
+
+	// Replace the embedded tablet with the copy of a selected one
+	selected := &vitessv1alpha2.VitessTablet{
+		ObjectMeta: metav1.ObjectMeta{Name: "replica", Namespace: "vitess"},
+		Spec: vitessv1alpha2.VitessTabletSpec{
+			Replicas:   &replicas,
+			Type:       vitessv1alpha2.TabletTypeReplica,
+			Containers: tablet.Spec.Containers.DeepCopy(),
+		},
+	}
+	shard.Spec.Tablets = nil
+	shard.EmbedTabletCopy(selected)
+	standalone := shard.Tablets()[0]
+	standalone.SetParentCluster(cluster)
+	standalone.SetParentCell(tablet.Cell())
+	standalone.SetParentKeyspace(keyspace)
+	standalone.SetParentShard(shard)
+
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: standalone.GetStatefulSetName(), Namespace: "vitess"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+		Status:     appsv1.StatefulSetStatus{Replicas: replicas, ReadyReplicas: replicas, UpdatedReplicas: replicas},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessCluster{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessTablet{})
+
+	cl := fake.NewFakeClient(
+		&vitessv1alpha2.VitessCluster{ObjectMeta: *cluster.ObjectMeta.DeepCopy(), Status: *cluster.Status.DeepCopy()},
+		selected,
+		newStatusTestDeployment("vt-zone1-vtctld", 1),
+		newStatusTestDeployment("vt-zone1-vtgate", 2),
+		statefulSet,
+	)
+	r := &ReconcileVitessCluster{client: cl, scheme: s, recorder: &record.FakeRecorder{}}
+
+	vtctldClient := &vtctld.FakeClient{
+		Tablets: []vtctld.Tablet{
+			{Alias: "zone1-0000000101", Type: "replica", Hostname: "vt-zone1-main-0-replica-1.vt-tab"},
+			{Alias: "zone1-0000000100", Type: "master", Hostname: "vt-zone1-main-0-replica-0.vt-tab"},
+			{Alias: "zone1-0000000200", Type: "rdonly", Hostname: "vt-zone1-main-0-rdonly-0.vt-tab"},
+		},
+		Lags: map[string]uint32{"zone1-0000000101": 3},
+	}
+
+	defer func() { newVtctldClient = vtctld.NewClient }()
+	newVtctldClient = func(address string) vtctld.Client { return vtctldClient }
+
+	if err := r.ReconcileClusterStatus(cluster); err != nil {
+		t.Fatalf("Error reconciling cluster status: %s", err)
+	}
+
+	found := &vitessv1alpha2.VitessTablet{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: "replica", Namespace: "vitess"}, found); err != nil {
+		t.Fatalf("Error getting tablet: %s", err)
+	}
+
+	if found.Status.Phase != vitessv1alpha2.TabletPhaseReady || found.Status.ReadyReplicas != 2 {
+		t.Errorf("Tablet readiness not written back: %+v", found.Status)
+	}
+
+	if len(found.Status.Pods) != 2 {
+		t.Fatalf("Wrong tablet pods. Got: %+v; Expected the 2 pods of the tablet", found.Status.Pods)
+	}
+
+	master, replica := found.Status.Pods[0], found.Status.Pods[1]
+	if master.PodName != "vt-zone1-main-0-replica-0" || master.Alias != "zone1-0000000100" || master.Type != "master" || !master.Serving {
+		t.Errorf("Wrong master pod status: %+v", master)
+	}
+
+	if replica.ReplicationLagSeconds == nil || *replica.ReplicationLagSeconds != 3 {
+		t.Errorf("Replication lag not recorded: %+v", replica)
+	}
+}
+
+// TestRestoringTabletStatus makes sure that a tablet isn't counted as ready while a restored pod is catching up
+func TestRestoringTabletStatus(t *testing.T) {
+	var replicas int32 = 2
+
+	cluster, shard, tablet := newReparentTestShard(replicas, "vt-zone1-main-0-replica-0")
+	keyspace := tablet.Keyspace()
+	keyspace.Spec.Shards = []*vitessv1alpha2.VitessShard{shard}
+	cluster.Spec.Keyspaces = []*vitessv1alpha2.VitessKeyspace{keyspace}
+	cluster.SetTabletStatus(tablet, &vitessv1alpha2.VitessTabletStatus{
+		Restores: []vitessv1alpha2.TabletRestoreStatus{
+			{PodName: "vt-zone1-main-0-replica-1", Backup: "2019-01-30.030000.zone1-0000000101"},
+		},
+	})
+
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: tablet.GetStatefulSetName(), Namespace: "vitess"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+		Status:     appsv1.StatefulSetStatus{Replicas: replicas, ReadyReplicas: replicas, UpdatedReplicas: replicas},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessCluster{})
+
+	cl := fake.NewFakeClient(
+		&vitessv1alpha2.VitessCluster{ObjectMeta: *cluster.ObjectMeta.DeepCopy(), Status: *cluster.Status.DeepCopy()},
+		newStatusTestDeployment("vt-zone1-vtctld", 1),
+		newStatusTestDeployment("vt-zone1-vtgate", 2),
+		statefulSet,
+	)
+	r := &ReconcileVitessCluster{client: cl, scheme: s, recorder: &record.FakeRecorder{}}
+
+	defer func() { newVtctldClient = vtctld.NewClient }()
+	newVtctldClient = func(address string) vtctld.Client { return &vtctld.FakeClient{} }
+
+	getCluster := func() *vitessv1alpha2.VitessCluster {
+		if err := r.ReconcileClusterStatus(cluster); err != nil {
+			t.Fatalf("Error reconciling cluster status: %s", err)
+		}
+
+		found := &vitessv1alpha2.VitessCluster{}
+		if err := cl.Get(context.TODO(), types.NamespacedName{Name: "vt", Namespace: "vitess"}, found); err != nil {
+			t.Fatalf("Error getting cluster: %s", err)
+		}
+		return found
+	}
+
+	// Every pod is ready, but the restored one hasn't caught up yet
+	found := getCluster()
+	if phase := found.GetTabletStatus(tablet).Phase; phase != vitessv1alpha2.TabletPhaseNone || found.Status.ReadyTablets != 0 {
+		t.Errorf("Restoring tablet counted as ready. Phase: %q; Ready tablets: %d", phase, found.Status.ReadyTablets)
+	}
+
+	found.GetTabletStatus(tablet).Restores[0].Complete = true
+	if err := cl.Update(context.TODO(), found); err != nil {
+		t.Fatalf("Error updating cluster: %s", err)
+	}
+
+	found = getCluster()
+	if phase := found.GetTabletStatus(tablet).Phase; phase != vitessv1alpha2.TabletPhaseReady || found.Status.ReadyTablets != 1 {
+		t.Errorf("Restored tablet not counted as ready. Phase: %q; Ready tablets: %d", phase, found.Status.ReadyTablets)
+	}
+}
@@ -9,12 +9,16 @@ import (
 func (r *ReconcileVitessCluster) ReconcileKeyspace(keyspace *vitessv1alpha2.VitessKeyspace) (reconcile.Result, error) {
 	log.Info("Reconciling Keyspace", "Namespace", keyspace.GetNamespace(), "VitessCluster.Name", keyspace.Cluster().GetName(), "Keyspace.Name", keyspace.GetName())
 
-	// Reconcile all shards
+	// Reconcile all shards. A shard that is waiting on something doesn't hold up the others,
+	// but the keyspace is still requeued for it
+	shardsResult := reconcile.Result{}
 	for _, shard := range keyspace.Shards() {
-		if result, err := r.ReconcileShard(shard); err != nil {
+		result, err := r.ReconcileShard(shard)
+		if err != nil {
 			return result, err
 		}
+		shardsResult = mergeResults(shardsResult, result)
 	}
 
-	return reconcile.Result{}, nil
+	return shardsResult, nil
 }
@@ -0,0 +1,143 @@
+package vitesscluster
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+)
+
+// MySQLExporterPort is where the mysqld_exporter sidecar of the tablet pods serves the MySQL metrics
+const MySQLExporterPort = 42002
+
+// serviceMonitorGVK is the kind of the Prometheus Operator ServiceMonitor. It is handled as an unstructured
+// object, so that the operator doesn't depend on the Prometheus Operator types nor on its CRD being installed.
+var serviceMonitorGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+
+// The annotations of the Prometheus kubernetes_sd example configuration
+const (
+	scrapeAnnotation     = "prometheus.io/scrape"
+	scrapePortAnnotation = "prometheus.io/port"
+	scrapePathAnnotation = "prometheus.io/path"
+)
+
+// getScrapeAnnotations returns the annotations that let Prometheus scrape the /metrics endpoint on the given port
+func getScrapeAnnotations(port int) map[string]string {
+	return map[string]string{
+		scrapeAnnotation:     "true",
+		scrapePortAnnotation: strconv.Itoa(port),
+		scrapePathAnnotation: "/metrics",
+	}
+}
+
+// ReconcileClusterServiceMonitor creates or updates the ServiceMonitor of the cluster if one is configured,
+// and deletes it if it isn't configured anymore. A missing ServiceMonitor CRD is reported with an event
+// rather than failing the reconcile.
+func (r *ReconcileVitessCluster) ReconcileClusterServiceMonitor(cluster *vitessv1alpha2.VitessCluster) (reconcile.Result, error) {
+	found := &unstructured.Unstructured{}
+	found.SetGroupVersionKind(serviceMonitorGVK)
+	// ServiceMonitors aren't watched, so the role only allows reading them from the apiserver
+	err := r.getReader().Get(context.TODO(), types.NamespacedName{Name: cluster.GetName(), Namespace: cluster.GetNamespace()}, found)
+	if err != nil && meta.IsNoMatchError(err) {
+		if cluster.ServiceMonitor() != nil {
+			r.recorder.Event(cluster, corev1.EventTypeWarning, "ServiceMonitorUnavailable", "Not creating a ServiceMonitor: the ServiceMonitor CRD isn't installed")
+		}
+		return reconcile.Result{}, nil
+	} else if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "failed to get ServiceMonitor")
+		return reconcile.Result{}, err
+	}
+	exists := err == nil
+
+	if cluster.ServiceMonitor() == nil {
+		// A ServiceMonitor that only happens to have the name of the cluster is left alone
+		if exists && metav1.IsControlledBy(found, cluster) {
+			return reconcile.Result{}, r.client.Delete(context.TODO(), found)
+		}
+		return reconcile.Result{}, nil
+	}
+
+	serviceMonitor := getServiceMonitorForCluster(cluster)
+
+	if !exists {
+		controllerutil.SetControllerReference(cluster, serviceMonitor, r.scheme)
+		if err := r.client.Create(context.TODO(), serviceMonitor); err != nil {
+			return reconcile.Result{}, err
+		}
+		r.recorder.Eventf(cluster, corev1.EventTypeNormal, "Created", "Created ServiceMonitor %s", serviceMonitor.GetName())
+		return reconcile.Result{}, nil
+	}
+
+	if !reflect.DeepEqual(found.Object["spec"], serviceMonitor.Object["spec"]) || !reflect.DeepEqual(found.GetLabels(), serviceMonitor.GetLabels()) {
+		found.Object["spec"] = serviceMonitor.Object["spec"]
+		found.SetLabels(serviceMonitor.GetLabels())
+		if err := r.client.Update(context.TODO(), found); err != nil {
+			return reconcile.Result{}, err
+		}
+		r.recorder.Eventf(cluster, corev1.EventTypeNormal, "Updated", "Updated ServiceMonitor %s", serviceMonitor.GetName())
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// getServiceMonitorForCluster returns a ServiceMonitor that scrapes the web port of the vttablet, vtgate
+// and vtctld Services of the cluster, and the mysql-metrics port of the tablet Service if the mysqld_exporter
+// sidecar is enabled
+func getServiceMonitorForCluster(cluster *vitessv1alpha2.VitessCluster) *unstructured.Unstructured {
+	config := cluster.ServiceMonitor()
+
+	labels := map[string]string{}
+	for k, v := range config.Labels {
+		labels[k] = v
+	}
+	labels["app"] = "vitess"
+	labels["cluster"] = cluster.GetName()
+
+	ports := []string{"web"}
+	if cluster.MySQLExporter() != nil {
+		ports = append(ports, "mysql-metrics")
+	}
+
+	endpoints := []interface{}{}
+	for _, port := range ports {
+		endpoint := map[string]interface{}{
+			"port": port,
+			"path": "/metrics",
+		}
+		if config.Interval != "" {
+			endpoint["interval"] = config.Interval
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	serviceMonitor := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"selector": map[string]interface{}{
+					"matchLabels": map[string]interface{}{
+						"app":     "vitess",
+						"cluster": cluster.GetName(),
+					},
+				},
+				"endpoints": endpoints,
+			},
+		},
+	}
+	serviceMonitor.SetGroupVersionKind(serviceMonitorGVK)
+	serviceMonitor.SetName(cluster.GetName())
+	serviceMonitor.SetNamespace(cluster.GetNamespace())
+	serviceMonitor.SetLabels(labels)
+
+	return serviceMonitor
+}
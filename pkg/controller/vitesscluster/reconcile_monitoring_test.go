@@ -0,0 +1,157 @@
+package vitesscluster
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+)
+
+// TestMySQLExporter makes sure that the mysqld_exporter sidecar is only added to the tablet pods when it is
+// enabled, and that it serves the port declared by the tablet Service
+func TestMySQLExporter(t *testing.T) {
+	cluster, _, tablet := newReparentTestShard(1, "")
+
+	statefulSet, err := getStatefulSetForTablet(tablet)
+	if err != nil {
+		t.Fatalf("Error generating tablet StatefulSet: %s", err)
+	}
+
+	if statefulSet.Spec.Template.Annotations[scrapePortAnnotation] != "15002" {
+		t.Errorf("Tablet pods not annotated for scraping vttablet: %v", statefulSet.Spec.Template.Annotations)
+	}
+
+	for _, container := range statefulSet.Spec.Template.Spec.Containers {
+		if container.Name == "mysqld-exporter" {
+			t.Error("mysqld_exporter added to the tablet pods without being enabled")
+		}
+	}
+
+	cluster.Spec.Monitoring = &vitessv1alpha2.VitessMonitoring{MySQLExporter: &vitessv1alpha2.MySQLExporter{}}
+
+	statefulSet, err = getStatefulSetForTablet(tablet)
+	if err != nil {
+		t.Fatalf("Error generating tablet StatefulSet: %s", err)
+	}
+
+	service, _ := getServiceForClusterTablets(cluster)
+
+	found := false
+	for _, container := range statefulSet.Spec.Template.Spec.Containers {
+		if container.Name != "mysqld-exporter" {
+			continue
+		}
+
+		found = true
+		if container.Image != vitessv1alpha2.MySQLExporterImageDefault || container.Ports[0].ContainerPort != service.Spec.Ports[2].Port {
+			t.Errorf("Wrong mysqld_exporter container: %+v", container)
+		}
+	}
+
+	if !found {
+		t.Error("mysqld_exporter not added to the tablet pods")
+	}
+
+	if service.Annotations[scrapePortAnnotation] != "42002" {
+		t.Errorf("Tablet Service not annotated for scraping mysqld_exporter: %v", service.Annotations)
+	}
+}
+
+// TestVTGateScrapeAnnotations makes sure that vtgate Deployments created before the pods were annotated for
+// scraping get the annotations
+func TestVTGateScrapeAnnotations(t *testing.T) {
+	_, _, tablet := newReparentTestShard(1, "")
+
+	deployment, _, err := GetCellVTGateResources(tablet.Cell())
+	if err != nil {
+		t.Fatalf("Error generating vtgate resources: %s", err)
+	}
+	deployment.Spec.Template.Annotations = nil
+
+	cl := fake.NewFakeClient(deployment)
+	r := &ReconcileVitessCluster{client: cl, scheme: scheme.Scheme, recorder: &record.FakeRecorder{}}
+
+	if _, err := r.ReconcileCellVTGate(tablet.Cell()); err != nil {
+		t.Fatalf("Error reconciling vtgate: %s", err)
+	}
+
+	found := &appsv1.Deployment{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: deployment.GetName(), Namespace: deployment.GetNamespace()}, found); err != nil {
+		t.Fatalf("Error getting vtgate Deployment: %s", err)
+	}
+
+	if found.Spec.Template.Annotations[scrapePortAnnotation] != "15001" {
+		t.Errorf("Existing vtgate pods not annotated for scraping: %v", found.Spec.Template.Annotations)
+	}
+}
+
+// TestServiceMonitor makes sure that the ServiceMonitor follows the monitoring configuration of the cluster
+func TestServiceMonitor(t *testing.T) {
+	cluster, _, _ := newReparentTestShard(1, "")
+	cluster.UID = "cluster-uid"
+	cluster.Spec.Monitoring = &vitessv1alpha2.VitessMonitoring{
+		ServiceMonitor: &vitessv1alpha2.VitessServiceMonitor{
+			Labels: map[string]string{"prometheus": "vitess"},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessCluster{})
+
+	cl := fake.NewFakeClient()
+	r := &ReconcileVitessCluster{client: cl, scheme: s, recorder: &record.FakeRecorder{}}
+
+	getServiceMonitor := func() (*unstructured.Unstructured, error) {
+		if _, err := r.ReconcileClusterServiceMonitor(cluster); err != nil {
+			t.Fatalf("Error reconciling ServiceMonitor: %s", err)
+		}
+
+		found := &unstructured.Unstructured{}
+		found.SetGroupVersionKind(serviceMonitorGVK)
+		err := cl.Get(context.TODO(), types.NamespacedName{Name: "vt", Namespace: "vitess"}, found)
+		return found, err
+	}
+
+	found, err := getServiceMonitor()
+	if err != nil {
+		t.Fatalf("ServiceMonitor not created: %s", err)
+	}
+
+	if found.GetLabels()["prometheus"] != "vitess" || found.GetLabels()["cluster"] != "vt" {
+		t.Errorf("Wrong ServiceMonitor labels: %v", found.GetLabels())
+	}
+
+	endpoints, _, _ := unstructured.NestedSlice(found.Object, "spec", "endpoints")
+	if len(endpoints) != 1 {
+		t.Errorf("Wrong ServiceMonitor endpoints. Got: %v; Expected only the web port", endpoints)
+	}
+
+	// Enabling the exporter adds its port
+	cluster.Spec.Monitoring.MySQLExporter = &vitessv1alpha2.MySQLExporter{}
+	cluster.Spec.Monitoring.ServiceMonitor.Interval = "30s"
+
+	found, err = getServiceMonitor()
+	if err != nil {
+		t.Fatalf("Error getting ServiceMonitor: %s", err)
+	}
+
+	endpoints, _, _ = unstructured.NestedSlice(found.Object, "spec", "endpoints")
+	if len(endpoints) != 2 || endpoints[1].(map[string]interface{})["port"] != "mysql-metrics" || endpoints[1].(map[string]interface{})["interval"] != "30s" {
+		t.Errorf("Wrong ServiceMonitor endpoints. Got: %v; Expected the web and mysql-metrics ports", endpoints)
+	}
+
+	// Disabling the ServiceMonitor deletes it
+	cluster.Spec.Monitoring.ServiceMonitor = nil
+
+	if _, err := getServiceMonitor(); !errors.IsNotFound(err) {
+		t.Errorf("ServiceMonitor not deleted: %v", err)
+	}
+}
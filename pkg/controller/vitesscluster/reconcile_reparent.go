@@ -0,0 +1,271 @@
+package vitesscluster
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	"vitess.io/vitess-operator/pkg/util/vtctld"
+)
+
+// Reasons for a tablet pod to be about to be removed
+const (
+	PodLeavingTerminating = "Terminating"
+	PodLeavingScaleDown   = "ScaleDown"
+	PodLeavingOutdated    = "Outdated"
+)
+
+// ReparentCandidate is a replica tablet that could take over from the shard master
+type ReparentCandidate struct {
+	Tablet vtctld.Tablet
+
+	// Lag is the replication lag of the tablet in seconds
+	Lag uint32
+}
+
+// ReconcileShardReparent moves mastership of the shard to another replica with a planned reparent when the master
+// pod is about to be removed, because it is terminating, is being scaled down or has to be replaced by a rollout
+func (r *ReconcileVitessCluster) ReconcileShardReparent(shard *vitessv1alpha2.VitessShard) (reconcile.Result, error) {
+	cluster := shard.Cluster()
+	keyspaceShard := shard.GetKeyspaceShard()
+	status := cluster.GetShardStatus(shard)
+
+	if status.MasterPodName == "" {
+		return reconcile.Result{}, nil
+	}
+
+	reason, err := r.getPodLeavingReason(shard, status.MasterPodName)
+	if err != nil || reason == "" {
+		return reconcile.Result{}, err
+	}
+
+	log.Info("Shard master pod is about to be removed", "Shard", keyspaceShard, "Pod", status.MasterPodName, "Reason", reason)
+
+	client := newVtctldClient(cluster.GetVtctldAddress())
+
+	// The master may have been moved outside of the operator, in which case the status only needs to catch up
+	master, err := client.GetShardMaster(keyspaceShard)
+	if err != nil {
+		log.Info("Unable to get shard from vtctld, will retry", "Shard", keyspaceShard, "Error", err.Error())
+		return reconcile.Result{Requeue: true, RequeueAfter: ShardMasterRequeueInterval}, nil
+	}
+
+	tablets, err := client.ListShardTablets(keyspaceShard)
+	if err != nil {
+		log.Info("Unable to list shard tablets from vtctld, will retry", "Shard", keyspaceShard, "Error", err.Error())
+		return reconcile.Result{Requeue: true, RequeueAfter: ShardMasterRequeueInterval}, nil
+	}
+
+	if master != status.MasterAlias {
+		newStatus := status.DeepCopy()
+		newStatus.MasterAlias = master
+		newStatus.MasterPodName = ""
+		for _, tablet := range tablets {
+			if tablet.Alias == master {
+				newStatus.MasterPodName = tablet.PodName()
+			}
+		}
+
+		return reconcile.Result{Requeue: true}, r.setShardStatus(shard, newStatus)
+	}
+
+	policy := shard.GetMasterElectionPolicy()
+
+	// Replicas that can't take over yet, but may once they are back or caught up, are waited for
+	candidates := []ReparentCandidate{}
+	waitForReplicas := false
+	for _, tablet := range tablets {
+		if tablet.Alias == master || tablet.Type != string(vitessv1alpha2.TabletTypeReplica) {
+			continue
+		}
+
+		// Handing mastership to a pod that is going away too would only mean reparenting again
+		if leaving, err := r.getPodLeavingReason(shard, tablet.PodName()); err != nil || leaving != "" {
+			waitForReplicas = waitForReplicas || leaving != PodLeavingScaleDown
+			continue
+		}
+
+		lag, err := client.GetReplicationLag(tablet.Alias)
+		if err != nil {
+			log.Info("Unable to get replication lag, not considering tablet", "Shard", keyspaceShard, "Tablet", tablet.Alias, "Error", err.Error())
+			waitForReplicas = true
+			continue
+		}
+
+		if lag > uint32(policy.GetMaxReplicationLagSeconds()) {
+			log.Info("Replica is lagging too far behind to take over", "Shard", keyspaceShard, "Tablet", tablet.Alias, "Lag", lag)
+			waitForReplicas = true
+			continue
+		}
+
+		candidates = append(candidates, ReparentCandidate{Tablet: tablet, Lag: lag})
+	}
+
+	candidate := ChooseReparentCandidate(candidates, policy)
+	if candidate == nil && !waitForReplicas && reason != PodLeavingTerminating {
+		// Without any replica to take over, e.g. in a shard with a single replica, waiting would hold up the
+		// rollout or scale down for good, so the master pod is replaced or removed without a reparent instead
+		return r.releaseShardMasterPod(shard, status, reason)
+	}
+
+	if candidate == nil {
+		log.Info("No replica can take over from the shard master, will retry", "Shard", keyspaceShard, "Master", master)
+		r.recorder.Eventf(cluster, corev1.EventTypeWarning, "ReparentBlocked", "No replica can take over from master %s of shard %s, whose pod is leaving (%s)", master, keyspaceShard, reason)
+		return reconcile.Result{Requeue: true, RequeueAfter: ShardMasterRequeueInterval}, nil
+	}
+
+	log.Info("Reparenting shard", "Shard", keyspaceShard, "From", master, "To", candidate.Alias, "Reason", reason)
+	if err := client.PlannedReparentShard(keyspaceShard, candidate.Alias); err != nil {
+		log.Error(err, "Failed to reparent shard", "Shard", keyspaceShard, "Tablet", candidate.Alias)
+		r.recorder.Eventf(cluster, corev1.EventTypeWarning, "ReparentFailed", "Failed to reparent shard %s from %s to %s: %s", keyspaceShard, master, candidate.Alias, err)
+		return reconcile.Result{Requeue: true, RequeueAfter: ShardMasterRequeueInterval}, nil
+	}
+	r.recorder.Eventf(cluster, corev1.EventTypeNormal, "Reparented", "Reparented shard %s from %s to %s because the master pod is leaving (%s)", keyspaceShard, master, candidate.Alias, reason)
+
+	newStatus := status.DeepCopy()
+	newStatus.MasterAlias = candidate.Alias
+	newStatus.MasterPodName = candidate.PodName()
+
+	// Requeue so that the old master pod can be replaced or removed
+	return reconcile.Result{Requeue: true, RequeueAfter: ShardMasterRequeueInterval}, r.setShardStatus(shard, newStatus)
+}
+
+// releaseShardMasterPod lets the master pod of the shard go without a reparent. An outdated pod is replaced
+// once the rest of its tablet is rolled out, and a pod that is being scaled down is no longer kept by its StatefulSet.
+func (r *ReconcileVitessCluster) releaseShardMasterPod(shard *vitessv1alpha2.VitessShard, status *vitessv1alpha2.VitessShardStatus, reason string) (reconcile.Result, error) {
+	cluster := shard.Cluster()
+	keyspaceShard := shard.GetKeyspaceShard()
+
+	if reason == PodLeavingScaleDown {
+		log.Info("No replica can take over from the shard master, scaling down its pod anyway", "Shard", keyspaceShard, "Pod", status.MasterPodName)
+		r.recorder.Eventf(cluster, corev1.EventTypeWarning, "ReparentSkipped", "No replica can take over from master %s of shard %s, removing its pod %s anyway (%s)",
+			status.MasterAlias, keyspaceShard, status.MasterPodName, reason)
+
+		// The StatefulSet only keeps the pod that is recorded as the master pod
+		newStatus := status.DeepCopy()
+		newStatus.MasterPodName = ""
+		return reconcile.Result{Requeue: true}, r.setShardStatus(shard, newStatus)
+	}
+
+	tablet := getPodTablet(shard, status.MasterPodName)
+	if tablet == nil {
+		return reconcile.Result{}, nil
+	}
+
+	statefulSet := &appsv1.StatefulSet{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: tablet.GetStatefulSetName(), Namespace: cluster.GetNamespace()}, statefulSet); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	replaced, result, err := r.replaceOutdatedTabletPod(tablet, statefulSet, "")
+	if err == nil && replaced != nil && replaced.GetName() == status.MasterPodName {
+		r.recorder.Eventf(cluster, corev1.EventTypeWarning, "ReparentSkipped", "No replica can take over from master %s of shard %s, replacing its pod %s anyway (%s)",
+			status.MasterAlias, keyspaceShard, status.MasterPodName, reason)
+	}
+
+	return result, err
+}
+
+// ChooseReparentCandidate picks the replica that should take over from the shard master. Tablets in the
+// preferred cells win in the order the cells are listed, then the least lagging tablet wins.
+// Ties are broken by pod name so that the choice is stable.
+func ChooseReparentCandidate(candidates []ReparentCandidate, policy *vitessv1alpha2.MasterElectionPolicy) *vtctld.Tablet {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	sorted := append([]ReparentCandidate{}, candidates...)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if rankA, rankB := preferredCellRank(policy, a.Tablet.Cell()), preferredCellRank(policy, b.Tablet.Cell()); rankA != rankB {
+			return rankA < rankB
+		}
+		if a.Lag != b.Lag {
+			return a.Lag < b.Lag
+		}
+		return a.Tablet.PodName() < b.Tablet.PodName()
+	})
+
+	return &sorted[0].Tablet
+}
+
+// getPodLeavingReason returns why the given tablet pod is about to be removed, or an empty string if it isn't
+func (r *ReconcileVitessCluster) getPodLeavingReason(shard *vitessv1alpha2.VitessShard, podName string) (string, error) {
+	pod := &corev1.Pod{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: podName, Namespace: shard.Cluster().GetNamespace()}, pod)
+	if err != nil && errors.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	// This covers deletes and evictions, e.g. during a node drain
+	if pod.GetDeletionTimestamp() != nil {
+		return PodLeavingTerminating, nil
+	}
+
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil || owner.Kind != "StatefulSet" {
+		return "", nil
+	}
+
+	for _, tablet := range shard.Tablets() {
+		if tablet.GetStatefulSetName() != owner.Name {
+			continue
+		}
+
+		if ordinal, ok := getPodOrdinal(owner.Name, podName); ok && ordinal >= *tablet.GetReplicas() {
+			return PodLeavingScaleDown, nil
+		}
+	}
+
+	statefulSet := &appsv1.StatefulSet{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: owner.Name, Namespace: pod.GetNamespace()}, statefulSet); err != nil {
+		return "", err
+	}
+
+	if isPodOutdated(pod, statefulSet) {
+		return PodLeavingOutdated, nil
+	}
+
+	return "", nil
+}
+
+// getPodTablet returns the tablet of the shard whose StatefulSet runs the given pod, or nil if there is none
+func getPodTablet(shard *vitessv1alpha2.VitessShard, podName string) *vitessv1alpha2.VitessTablet {
+	for _, tablet := range shard.Tablets() {
+		if _, ok := getPodOrdinal(tablet.GetStatefulSetName(), podName); ok {
+			return tablet
+		}
+	}
+	return nil
+}
+
+// getPodOrdinal returns the ordinal of a pod of the given StatefulSet
+func getPodOrdinal(statefulSetName string, podName string) (int32, bool) {
+	if !strings.HasPrefix(podName, statefulSetName+"-") {
+		return 0, false
+	}
+
+	ordinal, err := strconv.ParseInt(strings.TrimPrefix(podName, statefulSetName+"-"), 10, 32)
+	if err != nil {
+		return 0, false
+	}
+
+	return int32(ordinal), true
+}
+
+// isPodOutdated returns true if the pod doesn't run the current revision of its StatefulSet
+func isPodOutdated(pod *corev1.Pod, statefulSet *appsv1.StatefulSet) bool {
+	updateRevision := statefulSet.Status.UpdateRevision
+	return updateRevision != "" && pod.GetLabels()[appsv1.StatefulSetRevisionLabel] != updateRevision
+}
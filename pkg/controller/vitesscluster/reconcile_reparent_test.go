@@ -0,0 +1,435 @@
+package vitesscluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	"vitess.io/vitess-operator/pkg/util/vtctld"
+)
+
+// newReparentTestShard returns a shard with a single replica tablet whose master runs in the given pod
+func newReparentTestShard(replicas int32, masterPodName string) (*vitessv1alpha2.VitessCluster, *vitessv1alpha2.VitessShard, *vitessv1alpha2.VitessTablet) {
+	lockserver := &vitessv1alpha2.VitessLockserver{
+		Spec: vitessv1alpha2.VitessLockserverSpec{
+			Type: vitessv1alpha2.LockserverTypeEtcd2,
+			Etcd2: &vitessv1alpha2.Etcd2Lockserver{
+				Address: "etcd:2379",
+				Path:    "/vitess/global",
+			},
+		},
+	}
+
+	cluster := &vitessv1alpha2.VitessCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "vt",
+			Namespace: "vitess",
+		},
+		Spec: vitessv1alpha2.VitessClusterSpec{
+			Lockserver: lockserver,
+		},
+	}
+
+	cell := &vitessv1alpha2.VitessCell{
+		ObjectMeta: metav1.ObjectMeta{Name: "zone1"},
+		Spec: vitessv1alpha2.VitessCellSpec{
+			Lockserver: lockserver.DeepCopy(),
+		},
+	}
+	keyspace := &vitessv1alpha2.VitessKeyspace{ObjectMeta: metav1.ObjectMeta{Name: "main"}}
+	shard := &vitessv1alpha2.VitessShard{ObjectMeta: metav1.ObjectMeta{Name: "0"}}
+	tablet := &vitessv1alpha2.VitessTablet{
+		ObjectMeta: metav1.ObjectMeta{Name: "replica"},
+		Spec: vitessv1alpha2.VitessTabletSpec{
+			Replicas: &replicas,
+			Type:     vitessv1alpha2.TabletTypeReplica,
+			Containers: &vitessv1alpha2.TabletContainers{
+				VTTablet: &vitessv1alpha2.VTTabletContainer{Image: "test"},
+				MySQL:    &vitessv1alpha2.MySQLContainer{Image: "test"},
+			},
+		},
+	}
+
+	cluster.Spec.Cells = []*vitessv1alpha2.VitessCell{cell}
+	shard.Spec.Tablets = []*vitessv1alpha2.VitessTablet{tablet}
+	cell.SetParentCluster(cluster)
+	keyspace.SetParentCluster(cluster)
+	shard.SetParentCluster(cluster)
+	shard.SetParentKeyspace(keyspace)
+	tablet.SetParentCluster(cluster)
+	tablet.SetParentCell(cell)
+	tablet.SetParentKeyspace(keyspace)
+	tablet.SetParentShard(shard)
+
+	cluster.SetShardStatus(shard, &vitessv1alpha2.VitessShardStatus{
+		MasterAlias:   "zone1-0000000100",
+		MasterPodName: masterPodName,
+	})
+
+	return cluster, shard, tablet
+}
+
+// newTabletPod returns a ready tablet pod owned by the given StatefulSet
+func newTabletPod(statefulSetName string, name string, revision string) *corev1.Pod {
+	isController := true
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "vitess",
+			Labels:    map[string]string{appsv1.StatefulSetRevisionLabel: revision},
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "StatefulSet", Name: statefulSetName, Controller: &isController},
+			},
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+}
+
+func TestChooseReparentCandidate(t *testing.T) {
+	candidates := []ReparentCandidate{
+		{Tablet: vtctld.Tablet{Alias: "zone1-0000000102", Hostname: "vt-zone1-main-0-replica-2.vt-tab"}, Lag: 3},
+		{Tablet: vtctld.Tablet{Alias: "zone1-0000000101", Hostname: "vt-zone1-main-0-replica-1.vt-tab"}, Lag: 1},
+		{Tablet: vtctld.Tablet{Alias: "zone2-0000000200", Hostname: "vt-zone2-main-0-replica-0.vt-tab"}, Lag: 5},
+	}
+
+	if got := ChooseReparentCandidate(candidates, &vitessv1alpha2.MasterElectionPolicy{}); got.Alias != "zone1-0000000101" {
+		t.Errorf("Least lagging replica not chosen. Got: %s; Expected: zone1-0000000101", got.Alias)
+	}
+
+	policy := &vitessv1alpha2.MasterElectionPolicy{PreferredCells: []string{"zone2"}}
+	if got := ChooseReparentCandidate(candidates, policy); got.Alias != "zone2-0000000200" {
+		t.Errorf("Preferred cell not used. Got: %s; Expected: zone2-0000000200", got.Alias)
+	}
+
+	if got := ChooseReparentCandidate(nil, policy); got != nil {
+		t.Errorf("Candidate chosen from an empty list: %s", got.Alias)
+	}
+}
+
+// TestShardReparent makes sure that an outdated master pod is reparented away from to a replica that is caught up
+func TestShardReparent(t *testing.T) {
+	cluster, shard, tablet := newReparentTestShard(2, "vt-zone1-main-0-replica-0")
+	statefulSetName := tablet.GetStatefulSetName()
+
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: statefulSetName, Namespace: "vitess"},
+		Status:     appsv1.StatefulSetStatus{UpdateRevision: "rev2"},
+	}
+
+	// The embedded objects point back to their parents, so only the metadata of the cluster is stored
+	objs := []runtime.Object{
+		&vitessv1alpha2.VitessCluster{ObjectMeta: *cluster.ObjectMeta.DeepCopy()},
+		statefulSet,
+		newTabletPod(statefulSetName, statefulSetName+"-0", "rev1"),
+		newTabletPod(statefulSetName, statefulSetName+"-1", "rev2"),
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessCluster{})
+
+	cl := fake.NewFakeClient(objs...)
+	r := &ReconcileVitessCluster{client: cl, scheme: s, recorder: &record.FakeRecorder{}}
+
+	fakeVtctld := &vtctld.FakeClient{
+		Master: "zone1-0000000100",
+		Tablets: []vtctld.Tablet{
+			{Alias: "zone1-0000000100", Type: "master", Hostname: "vt-zone1-main-0-replica-0.vt-tab"},
+			{Alias: "zone1-0000000101", Type: "replica", Hostname: "vt-zone1-main-0-replica-1.vt-tab"},
+		},
+		Lags: map[string]uint32{"zone1-0000000101": 60},
+	}
+
+	defer func() { newVtctldClient = vtctld.NewClient }()
+	newVtctldClient = func(address string) vtctld.Client {
+		return fakeVtctld
+	}
+
+	// The only replica is lagging too far behind to take over
+	res, err := r.ReconcileShardReparent(shard)
+	if err != nil {
+		t.Fatalf("Error reconciling shard reparent: %s", err)
+	}
+
+	if !res.Requeue || len(fakeVtctld.Reparented) != 0 {
+		t.Fatalf("Shard reparented to a lagging replica: %v", fakeVtctld.Reparented)
+	}
+
+	fakeVtctld.Lags["zone1-0000000101"] = 2
+
+	if _, err := r.ReconcileShardReparent(shard); err != nil {
+		t.Fatalf("Error reconciling shard reparent: %s", err)
+	}
+
+	if len(fakeVtctld.Reparented) != 1 || fakeVtctld.Reparented[0] != "main/0 zone1-0000000101" {
+		t.Fatalf("Wrong PlannedReparentShard calls: %v", fakeVtctld.Reparented)
+	}
+
+	found := &vitessv1alpha2.VitessCluster{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: "vt", Namespace: "vitess"}, found); err != nil {
+		t.Fatalf("Error getting cluster: %s", err)
+	}
+
+	if status := found.GetShardStatus(shard); status.MasterAlias != "zone1-0000000101" || status.MasterPodName != statefulSetName+"-1" {
+		t.Errorf("New master not recorded in the cluster status: %+v", status)
+	}
+
+	// The new master is up to date, so there is nothing left to do
+	if res, err := r.ReconcileShardReparent(shard); err != nil || res.Requeue || len(fakeVtctld.Reparented) != 1 {
+		t.Errorf("Shard reparented again: %v, %v", fakeVtctld.Reparented, err)
+	}
+}
+
+func TestGetPodLeavingReason(t *testing.T) {
+	_, shard, tablet := newReparentTestShard(1, "")
+	statefulSetName := tablet.GetStatefulSetName()
+
+	terminating := newTabletPod(statefulSetName, statefulSetName+"-0", "rev1")
+	terminating.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+	cl := fake.NewFakeClient(
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: statefulSetName, Namespace: "vitess"},
+			Status:     appsv1.StatefulSetStatus{UpdateRevision: "rev1"},
+		},
+		terminating,
+		newTabletPod(statefulSetName, statefulSetName+"-1", "rev1"),
+	)
+	r := &ReconcileVitessCluster{client: cl, scheme: scheme.Scheme, recorder: &record.FakeRecorder{}}
+
+	tests := map[string]string{
+		statefulSetName + "-0": PodLeavingTerminating,
+		statefulSetName + "-1": PodLeavingScaleDown,
+		statefulSetName + "-2": "",
+	}
+
+	for podName, expected := range tests {
+		reason, err := r.getPodLeavingReason(shard, podName)
+		if err != nil {
+			t.Fatalf("Error getting leaving reason of %s: %s", podName, err)
+		}
+
+		if reason != expected {
+			t.Errorf("Wrong leaving reason for %s. Got: %q; Expected: %q", podName, reason, expected)
+		}
+	}
+}
+
+// TestStatefulSetKeepsMaster makes sure that a scale-down doesn't remove the master pod before it is reparented
+func TestStatefulSetKeepsMaster(t *testing.T) {
+	_, _, tablet := newReparentTestShard(1, "vt-zone1-main-0-replica-2")
+
+	statefulSet, err := getStatefulSetForTablet(tablet)
+	if err != nil {
+		t.Fatalf("Error generating tablet StatefulSet: %s", err)
+	}
+
+	if *statefulSet.Spec.Replicas != 3 {
+		t.Errorf("StatefulSet does not keep the master pod. Got: %d replicas; Expected: 3", *statefulSet.Spec.Replicas)
+	}
+
+	if statefulSet.Spec.UpdateStrategy.Type != appsv1.OnDeleteStatefulSetStrategyType {
+		t.Errorf("Tablet pods are not replaced by the operator. Got: %s; Expected: %s", statefulSet.Spec.UpdateStrategy.Type, appsv1.OnDeleteStatefulSetStrategyType)
+	}
+}
+
+// TestTabletRollout makes sure that outdated pods are replaced one at a time and the master pod is left alone
+func TestTabletRollout(t *testing.T) {
+	_, _, tablet := newReparentTestShard(3, "vt-zone1-main-0-replica-0")
+	statefulSetName := tablet.GetStatefulSetName()
+
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: statefulSetName, Namespace: "vitess"},
+		Spec: appsv1.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tabletname": tablet.GetName()}},
+		},
+		Status: appsv1.StatefulSetStatus{UpdateRevision: "rev2"},
+	}
+
+	cl := fake.NewFakeClient(
+		newTabletPod(statefulSetName, statefulSetName+"-0", "rev1"),
+		newTabletPod(statefulSetName, statefulSetName+"-1", "rev1"),
+		newTabletPod(statefulSetName, statefulSetName+"-2", "rev1"),
+	)
+	r := &ReconcileVitessCluster{client: cl, scheme: scheme.Scheme, recorder: &record.FakeRecorder{}}
+
+	for _, expected := range []string{statefulSetName + "-2", statefulSetName + "-1"} {
+		res, err := r.ReconcileTabletRollout(tablet, statefulSet)
+		if err != nil {
+			t.Fatalf("Error reconciling tablet rollout: %s", err)
+		}
+
+		if !res.Requeue {
+			t.Error("Tablet rollout in progress was not requeued")
+		}
+
+		err = cl.Get(context.TODO(), types.NamespacedName{Name: expected, Namespace: "vitess"}, &corev1.Pod{})
+		if !errors.IsNotFound(err) {
+			t.Fatalf("Outdated pod %s was not replaced: %v", expected, err)
+		}
+	}
+
+	if res, err := r.ReconcileTabletRollout(tablet, statefulSet); err != nil || res.Requeue {
+		t.Errorf("Tablet rollout did not stop at the master pod: %v, %v", res, err)
+	}
+
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: statefulSetName + "-0", Namespace: "vitess"}, &corev1.Pod{}); err != nil {
+		t.Errorf("Master pod was replaced before being reparented: %s", err)
+	}
+}
+
+// TestTabletRolloutUnreadyPod makes sure that an outdated pod that isn't ready is replaced first instead of
+// holding up the rollout, and that ready pods wait for it
+func TestTabletRolloutUnreadyPod(t *testing.T) {
+	_, _, tablet := newReparentTestShard(3, "vt-zone1-main-0-replica-0")
+	statefulSetName := tablet.GetStatefulSetName()
+
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: statefulSetName, Namespace: "vitess"},
+		Spec: appsv1.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tabletname": tablet.GetName()}},
+		},
+		Status: appsv1.StatefulSetStatus{UpdateRevision: "rev2"},
+	}
+
+	broken := newTabletPod(statefulSetName, statefulSetName+"-1", "rev1")
+	broken.Status.Conditions[0].Status = corev1.ConditionFalse
+
+	cl := fake.NewFakeClient(
+		newTabletPod(statefulSetName, statefulSetName+"-0", "rev1"),
+		broken,
+		newTabletPod(statefulSetName, statefulSetName+"-2", "rev1"),
+	)
+	r := &ReconcileVitessCluster{client: cl, scheme: scheme.Scheme, recorder: &record.FakeRecorder{}}
+
+	if _, err := r.ReconcileTabletRollout(tablet, statefulSet); err != nil {
+		t.Fatalf("Error reconciling tablet rollout: %s", err)
+	}
+
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: statefulSetName + "-1", Namespace: "vitess"}, &corev1.Pod{}); !errors.IsNotFound(err) {
+		t.Fatalf("Outdated pod that isn't ready was not replaced: %v", err)
+	}
+
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: statefulSetName + "-2", Namespace: "vitess"}, &corev1.Pod{}); err != nil {
+		t.Fatalf("Ready pod replaced along with the pod that isn't ready: %s", err)
+	}
+
+	// The replacement runs the new revision but isn't ready yet
+	replacement := newTabletPod(statefulSetName, statefulSetName+"-1", "rev2")
+	replacement.Status.Conditions[0].Status = corev1.ConditionFalse
+	if err := cl.Create(context.TODO(), replacement); err != nil {
+		t.Fatalf("Error creating replacement pod: %s", err)
+	}
+
+	if res, err := r.ReconcileTabletRollout(tablet, statefulSet); err != nil || !res.Requeue {
+		t.Fatalf("Tablet rollout did not wait for the replacement pod: %v, %v", res, err)
+	}
+
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: statefulSetName + "-2", Namespace: "vitess"}, &corev1.Pod{}); err != nil {
+		t.Errorf("Ready pod replaced while another pod isn't ready: %s", err)
+	}
+}
+
+// TestShardReparentSingleReplica makes sure that the master pod of a shard without another replica to take over
+// is still replaced by a rollout and removed by a scale down
+func TestShardReparentSingleReplica(t *testing.T) {
+	cluster, shard, tablet := newReparentTestShard(1, "vt-zone1-main-0-replica-0")
+	statefulSetName := tablet.GetStatefulSetName()
+
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: statefulSetName, Namespace: "vitess"},
+		Spec: appsv1.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tabletname": tablet.GetName()}},
+		},
+		Status: appsv1.StatefulSetStatus{UpdateRevision: "rev2"},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessCluster{})
+
+	cl := fake.NewFakeClient(
+		&vitessv1alpha2.VitessCluster{ObjectMeta: *cluster.ObjectMeta.DeepCopy()},
+		statefulSet,
+		newTabletPod(statefulSetName, statefulSetName+"-0", "rev1"),
+	)
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileVitessCluster{client: cl, scheme: s, recorder: recorder}
+
+	fakeVtctld := &vtctld.FakeClient{
+		Master: "zone1-0000000100",
+		Tablets: []vtctld.Tablet{
+			{Alias: "zone1-0000000100", Type: "master", Hostname: "vt-zone1-main-0-replica-0.vt-tab"},
+		},
+	}
+
+	defer func() { newVtctldClient = vtctld.NewClient }()
+	newVtctldClient = func(address string) vtctld.Client {
+		return fakeVtctld
+	}
+
+	// The rollout leaves the outdated master pod alone, so it is replaced here
+	if _, err := r.ReconcileShardReparent(shard); err != nil {
+		t.Fatalf("Error reconciling shard reparent: %s", err)
+	}
+
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: statefulSetName + "-0", Namespace: "vitess"}, &corev1.Pod{}); !errors.IsNotFound(err) {
+		t.Fatalf("Outdated master pod without a replica to take over was not replaced: %v", err)
+	}
+
+	if len(fakeVtctld.Reparented) != 0 {
+		t.Errorf("Shard reparented without a replica to take over: %v", fakeVtctld.Reparented)
+	}
+
+	expected := "Warning ReparentSkipped No replica can take over from master zone1-0000000100 of shard main/0, replacing its pod vt-zone1-main-0-replica-0 anyway (Outdated)"
+	select {
+	case event := <-recorder.Events:
+		if event != expected {
+			t.Errorf("Wrong event recorded. Got: %s; Expected: %s", event, expected)
+		}
+	default:
+		t.Errorf("Event not recorded: %s", expected)
+	}
+
+	// The tablet is scaled down while its master pod is current
+	var replicas int32
+	tablet.Spec.Replicas = &replicas
+	if err := cl.Create(context.TODO(), newTabletPod(statefulSetName, statefulSetName+"-0", "rev2")); err != nil {
+		t.Fatalf("Error creating replacement pod: %s", err)
+	}
+
+	if _, err := r.ReconcileShardReparent(shard); err != nil {
+		t.Fatalf("Error reconciling shard reparent: %s", err)
+	}
+
+	found := &vitessv1alpha2.VitessCluster{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: "vt", Namespace: "vitess"}, found); err != nil {
+		t.Fatalf("Error getting cluster: %s", err)
+	}
+
+	if status := found.GetShardStatus(shard); status.MasterAlias != "zone1-0000000100" || status.MasterPodName != "" {
+		t.Errorf("Master pod of the scale down still recorded in the cluster status: %+v", status)
+	}
+
+	generated, err := getStatefulSetForTablet(tablet)
+	if err != nil {
+		t.Fatalf("Error generating tablet StatefulSet: %s", err)
+	}
+
+	if *generated.Spec.Replicas != 0 {
+		t.Errorf("StatefulSet keeps the master pod without a replica to take over. Got: %d replicas; Expected: 0", *generated.Spec.Replicas)
+	}
+}
@@ -1,20 +1,179 @@
 package vitesscluster
 
 import (
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	"vitess.io/vitess-operator/pkg/util/vtctld"
 )
 
+// ShardMasterRequeueInterval is how often shard master election is retried while the shard isn't ready for it
+const ShardMasterRequeueInterval = 10 * time.Second
+
+// newVtctldClient is a variable so that tests can stub out vtctld
+var newVtctldClient = vtctld.NewClient
+
 func (r *ReconcileVitessCluster) ReconcileShard(shard *vitessv1alpha2.VitessShard) (reconcile.Result, error) {
 	log.Info("Reconciling Shard", "Namespace", shard.GetNamespace(), "VitessCluster.Name", shard.Cluster().GetName(), "Shard.Name", shard.GetName())
 
-	// Reconcile all shard tablets
+	// Reconcile all shard tablets. A tablet that is waiting doesn't hold up the others, and the
+	// shard master steps guard themselves, e.g. a rollout relies on the reparent below.
+	tabletsResult := reconcile.Result{}
 	for _, tablet := range shard.Tablets() {
-		if result, err := r.ReconcileTablet(tablet); err != nil {
+		result, err := r.ReconcileTablet(tablet)
+		if err != nil {
 			return result, err
 		}
+		tabletsResult = mergeResults(tabletsResult, result)
+	}
+
+	if result, err := r.ReconcileShardMaster(shard); err != nil || result.Requeue {
+		return mergeResults(tabletsResult, result), err
+	}
+
+	if result, err := r.ReconcileShardReparent(shard); err != nil || result.Requeue {
+		return mergeResults(tabletsResult, result), err
+	}
+
+	return tabletsResult, nil
+}
+
+// ReconcileShardMaster elects the first master of the shard once every expected tablet has registered itself,
+// and records the master in the cluster status
+func (r *ReconcileVitessCluster) ReconcileShardMaster(shard *vitessv1alpha2.VitessShard) (reconcile.Result, error) {
+	cluster := shard.Cluster()
+	keyspaceShard := shard.GetKeyspaceShard()
+
+	if cluster.GetShardStatus(shard).MasterAlias != "" || shard.GetExpectedTabletCount() == 0 {
+		return reconcile.Result{}, nil
+	}
+
+	client := newVtctldClient(cluster.GetVtctldAddress())
+
+	// The shard may already have a master, e.g. if it was elected before the status was recorded
+	master, err := client.GetShardMaster(keyspaceShard)
+	if err != nil {
+		log.Info("Unable to get shard from vtctld, will retry", "Shard", keyspaceShard, "Error", err.Error())
+		return reconcile.Result{Requeue: true, RequeueAfter: ShardMasterRequeueInterval}, nil
+	}
+
+	tablets, err := client.ListShardTablets(keyspaceShard)
+	if err != nil {
+		log.Info("Unable to list shard tablets from vtctld, will retry", "Shard", keyspaceShard, "Error", err.Error())
+		return reconcile.Result{Requeue: true, RequeueAfter: ShardMasterRequeueInterval}, nil
+	}
+
+	status := cluster.GetShardStatus(shard).DeepCopy()
+
+	if master != "" {
+		status.MasterAlias = master
+		for _, tablet := range tablets {
+			if tablet.Alias == master {
+				status.MasterPodName = tablet.PodName()
+			}
+		}
+
+		return reconcile.Result{}, r.setShardStatus(shard, status)
+	}
+
+	if expected := shard.GetExpectedTabletCount(); len(tablets) < expected {
+		log.Info("Waiting for shard tablets to register", "Shard", keyspaceShard, "Registered", len(tablets), "Expected", expected)
+		return reconcile.Result{Requeue: true, RequeueAfter: ShardMasterRequeueInterval}, nil
+	}
+
+	// Tablets that are restoring from a backup only take on their type once they are done
+	for _, tablet := range tablets {
+		if tablet.Type == string(vitessv1alpha2.TabletTypeRestore) {
+			log.Info("Waiting for shard tablets to restore", "Shard", keyspaceShard, "Tablet", tablet.Alias)
+			return reconcile.Result{Requeue: true, RequeueAfter: ShardMasterRequeueInterval}, nil
+		}
+	}
+
+	// A restored tablet may still be replaying binlogs after it took on its type. InitShardMaster
+	// resets the replication positions, so the election waits for the replay to finish.
+	for _, tablet := range shard.Tablets() {
+		if cluster.GetTabletStatus(tablet).IsRestoring() {
+			log.Info("Waiting for shard tablets to finish restoring", "Shard", keyspaceShard, "Tablet", tablet.GetName())
+			return reconcile.Result{Requeue: true, RequeueAfter: ShardMasterRequeueInterval}, nil
+		}
+	}
+
+	candidate := ChooseMasterCandidate(tablets, shard.GetMasterElectionPolicy())
+	if candidate == nil {
+		return reconcile.Result{}, fmt.Errorf("No master candidate among the tablets of shard %s", keyspaceShard)
+	}
+
+	log.Info("Electing shard master", "Shard", keyspaceShard, "Tablet", candidate.Alias)
+	if err := client.InitShardMaster(keyspaceShard, candidate.Alias); err != nil {
+		log.Error(err, "Failed to elect shard master", "Shard", keyspaceShard, "Tablet", candidate.Alias)
+		r.recorder.Eventf(cluster, corev1.EventTypeWarning, "MasterElectionFailed", "Failed to elect %s as master of shard %s: %s", candidate.Alias, keyspaceShard, err)
+		return reconcile.Result{Requeue: true, RequeueAfter: ShardMasterRequeueInterval}, nil
+	}
+	r.recorder.Eventf(cluster, corev1.EventTypeNormal, "MasterElected", "Elected %s as master of shard %s", candidate.Alias, keyspaceShard)
+
+	status.MasterAlias = candidate.Alias
+	status.MasterPodName = candidate.PodName()
+
+	return reconcile.Result{}, r.setShardStatus(shard, status)
+}
+
+// ChooseMasterCandidate picks the replica tablet that should become the first master of the shard.
+// Tablets in the preferred cells win in the order the cells are listed. Ties are broken by pod name,
+// then alias, so that the choice is stable.
+func ChooseMasterCandidate(tablets []vtctld.Tablet, policy *vitessv1alpha2.MasterElectionPolicy) *vtctld.Tablet {
+	candidates := []vtctld.Tablet{}
+	for _, tablet := range tablets {
+		if tablet.Type == string(vitessv1alpha2.TabletTypeReplica) {
+			candidates = append(candidates, tablet)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if rankA, rankB := preferredCellRank(policy, a.Cell()), preferredCellRank(policy, b.Cell()); rankA != rankB {
+			return rankA < rankB
+		}
+		if a.PodName() != b.PodName() {
+			return a.PodName() < b.PodName()
+		}
+		return a.Alias < b.Alias
+	})
+
+	return &candidates[0]
+}
+
+// preferredCellRank returns the position of the cell in the preferred cells of the policy,
+// with every other cell ranked after all of them
+func preferredCellRank(policy *vitessv1alpha2.MasterElectionPolicy, cell string) int {
+	for i, preferred := range policy.PreferredCells {
+		if preferred == cell {
+			return i
+		}
+	}
+	return len(policy.PreferredCells)
+}
+
+// setShardStatus records the shard status in the normalized cluster and in the stored one
+func (r *ReconcileVitessCluster) setShardStatus(shard *vitessv1alpha2.VitessShard, status *vitessv1alpha2.VitessShardStatus) error {
+	cluster := shard.Cluster()
+	cluster.SetShardStatus(shard, status)
+
+	if err := r.updateClusterStatus(cluster, func(foundCluster *vitessv1alpha2.VitessCluster) (bool, error) {
+		foundCluster.SetShardStatus(shard, status.DeepCopy())
+		return true, nil
+	}); err != nil {
+		log.Error(err, "Failed to update VitessCluster shard status")
+		return err
 	}
 
-	return reconcile.Result{}, nil
+	return nil
 }
@@ -0,0 +1,215 @@
+package vitesscluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	"vitess.io/vitess-operator/pkg/util/vtctld"
+)
+
+func TestChooseMasterCandidate(t *testing.T) {
+	tablets := []vtctld.Tablet{
+		{Alias: "zone1-0000000102", Type: "replica", Hostname: "vt-zone1-main-0-replica-1.vt-tab"},
+		{Alias: "zone1-0000000101", Type: "rdonly", Hostname: "vt-zone1-main-0-rdonly-0.vt-tab"},
+		{Alias: "zone1-0000000100", Type: "replica", Hostname: "vt-zone1-main-0-replica-0.vt-tab"},
+		{Alias: "zone2-0000000200", Type: "replica", Hostname: "vt-zone2-main-0-replica-0.vt-tab"},
+	}
+
+	if got := ChooseMasterCandidate(tablets, &vitessv1alpha2.MasterElectionPolicy{}); got.Alias != "zone1-0000000100" {
+		t.Errorf("Wrong default master candidate. Got: %s; Expected: zone1-0000000100", got.Alias)
+	}
+
+	policy := &vitessv1alpha2.MasterElectionPolicy{PreferredCells: []string{"zone2"}}
+	if got := ChooseMasterCandidate(tablets, policy); got.Alias != "zone2-0000000200" {
+		t.Errorf("Preferred cell not used. Got: %s; Expected: zone2-0000000200", got.Alias)
+	}
+
+	if got := ChooseMasterCandidate(tablets[1:2], policy); got != nil {
+		t.Errorf("Non-replica tablet chosen as master candidate: %s", got.Alias)
+	}
+}
+
+func TestMergeResults(t *testing.T) {
+	for _, tc := range []struct {
+		a, b, expected reconcile.Result
+	}{
+		{reconcile.Result{}, reconcile.Result{}, reconcile.Result{}},
+		{reconcile.Result{Requeue: true, RequeueAfter: time.Minute}, reconcile.Result{}, reconcile.Result{Requeue: true, RequeueAfter: time.Minute}},
+		{reconcile.Result{}, reconcile.Result{RequeueAfter: time.Minute}, reconcile.Result{RequeueAfter: time.Minute}},
+		{reconcile.Result{Requeue: true, RequeueAfter: time.Minute}, reconcile.Result{Requeue: true, RequeueAfter: time.Second}, reconcile.Result{Requeue: true, RequeueAfter: time.Second}},
+		{reconcile.Result{Requeue: true}, reconcile.Result{Requeue: true, RequeueAfter: time.Second}, reconcile.Result{Requeue: true}},
+	} {
+		if got := mergeResults(tc.a, tc.b); got != tc.expected {
+			t.Errorf("Wrong merged result of %v and %v. Got: %v; Expected: %v", tc.a, tc.b, got, tc.expected)
+		}
+	}
+}
+
+// TestShardMasterElection makes sure that a master is only elected once every tablet has registered
+func TestShardMasterElection(t *testing.T) {
+	var replicas int32 = 2
+
+	cluster := &vitessv1alpha2.VitessCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "vt",
+			Namespace: "vitess",
+		},
+	}
+
+	cell := &vitessv1alpha2.VitessCell{ObjectMeta: metav1.ObjectMeta{Name: "zone1"}}
+	keyspace := &vitessv1alpha2.VitessKeyspace{ObjectMeta: metav1.ObjectMeta{Name: "main"}}
+	tablet := &vitessv1alpha2.VitessTablet{
+		ObjectMeta: metav1.ObjectMeta{Name: "replica"},
+		Spec: vitessv1alpha2.VitessTabletSpec{
+			Replicas: &replicas,
+			Type:     vitessv1alpha2.TabletTypeReplica,
+		},
+	}
+	shard := &vitessv1alpha2.VitessShard{
+		Spec: vitessv1alpha2.VitessShardSpec{
+			Tablets: []*vitessv1alpha2.VitessTablet{tablet},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessCluster{})
+
+	cl := fake.NewFakeClient(cluster.DeepCopy())
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileVitessCluster{client: cl, scheme: s, recorder: recorder}
+
+	cluster.Spec.Cells = []*vitessv1alpha2.VitessCell{cell}
+	cell.SetParentCluster(cluster)
+	keyspace.SetParentCluster(cluster)
+	shard.SetParentCluster(cluster)
+	shard.SetParentKeyspace(keyspace)
+	tablet.SetParentCluster(cluster)
+	tablet.SetParentCell(cell)
+	tablet.SetParentKeyspace(keyspace)
+	tablet.SetParentShard(shard)
+
+	fakeVtctld := &vtctld.FakeClient{
+		Tablets: []vtctld.Tablet{
+			{Alias: "zone1-0000000101", Type: "replica", Hostname: "vt-zone1-main-0-replica-0.vt-tab"},
+		},
+	}
+
+	var vtctldAddress string
+	defer func() { newVtctldClient = vtctld.NewClient }()
+	newVtctldClient = func(address string) vtctld.Client {
+		vtctldAddress = address
+		return fakeVtctld
+	}
+
+	// Only one of the two tablets has registered
+	res, err := r.ReconcileShardMaster(shard)
+	if err != nil {
+		t.Fatalf("Error reconciling shard master: %s", err)
+	}
+
+	if !res.Requeue || len(fakeVtctld.Initialized) != 0 {
+		t.Fatal("Shard master elected before every tablet registered")
+	}
+
+	if vtctldAddress != "vt-zone1-vtctld.vitess:15000" {
+		t.Errorf("Wrong vtctld address: %s", vtctldAddress)
+	}
+
+	fakeVtctld.Tablets = append(fakeVtctld.Tablets, vtctld.Tablet{Alias: "zone1-0000000102", Type: "replica", Hostname: "vt-zone1-main-0-replica-1.vt-tab"})
+
+	if res, err := r.ReconcileShardMaster(shard); err != nil || res.Requeue {
+		t.Fatalf("Shard master not elected once every tablet registered: %v, %v", res, err)
+	}
+
+	if len(fakeVtctld.Initialized) != 1 || fakeVtctld.Initialized[0] != "main/0 zone1-0000000101" {
+		t.Errorf("Wrong InitShardMaster calls: %v", fakeVtctld.Initialized)
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if event != "Normal MasterElected Elected zone1-0000000101 as master of shard main/0" {
+			t.Errorf("Wrong event recorded: %s", event)
+		}
+	default:
+		t.Error("No event recorded for the master election")
+	}
+
+	found := &vitessv1alpha2.VitessCluster{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: "vt", Namespace: "vitess"}, found); err != nil {
+		t.Fatalf("Error getting cluster: %s", err)
+	}
+
+	if master := found.GetShardStatus(shard).MasterAlias; master != "zone1-0000000101" {
+		t.Errorf("Elected master not recorded in the cluster status. Got: %s; Expected: zone1-0000000101", master)
+	}
+
+	if pod := found.GetShardStatus(shard).MasterPodName; pod != "vt-zone1-main-0-replica-0" {
+		t.Errorf("Elected master pod not recorded in the cluster status. Got: %s; Expected: vt-zone1-main-0-replica-0", pod)
+	}
+
+	// The master is never elected twice
+	if _, err := r.ReconcileShardMaster(shard); err != nil || len(fakeVtctld.Initialized) != 1 {
+		t.Errorf("Shard master elected again: %v, %v", fakeVtctld.Initialized, err)
+	}
+}
+
+func TestShardMasterElectionWaitsForRestores(t *testing.T) {
+	cluster, shard, tablet := newReparentTestShard(2, "")
+	cluster.SetShardStatus(shard, &vitessv1alpha2.VitessShardStatus{})
+	statefulSetName := tablet.GetStatefulSetName()
+
+	// The second pod restored its backup but hasn't replayed the binlogs up to the restore point yet
+	backup := "2019-01-30.030000.zone1-0000000100"
+	cluster.SetTabletStatus(tablet, &vitessv1alpha2.VitessTabletStatus{
+		Restores: []vitessv1alpha2.TabletRestoreStatus{
+			{PodName: statefulSetName + "-0", Backup: backup, BinlogsReplayed: true, Complete: true},
+			{PodName: statefulSetName + "-1", Backup: backup},
+		},
+	})
+
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessCluster{})
+
+	cl := fake.NewFakeClient(&vitessv1alpha2.VitessCluster{ObjectMeta: *cluster.ObjectMeta.DeepCopy()})
+	r := &ReconcileVitessCluster{client: cl, scheme: s, recorder: record.NewFakeRecorder(10)}
+
+	fakeVtctld := &vtctld.FakeClient{
+		Tablets: []vtctld.Tablet{
+			{Alias: "zone1-0000000101", Type: "replica", Hostname: "vt-zone1-main-0-replica-0.vt-tab"},
+			{Alias: "zone1-0000000102", Type: "replica", Hostname: "vt-zone1-main-0-replica-1.vt-tab"},
+		},
+	}
+
+	defer func() { newVtctldClient = vtctld.NewClient }()
+	newVtctldClient = func(address string) vtctld.Client { return fakeVtctld }
+
+	res, err := r.ReconcileShardMaster(shard)
+	if err != nil {
+		t.Fatalf("Error reconciling shard master: %s", err)
+	}
+
+	if !res.Requeue || len(fakeVtctld.Initialized) != 0 {
+		t.Fatalf("Shard master elected before the binlogs were replayed: %v", fakeVtctld.Initialized)
+	}
+
+	restores := cluster.GetTabletStatus(tablet).Restores
+	restores[1].BinlogsReplayed = true
+	restores[1].Complete = true
+
+	if res, err := r.ReconcileShardMaster(shard); err != nil || res.Requeue {
+		t.Fatalf("Shard master not elected once the restores completed: %v, %v", res, err)
+	}
+
+	if len(fakeVtctld.Initialized) != 1 {
+		t.Errorf("Wrong InitShardMaster calls: %v", fakeVtctld.Initialized)
+	}
+}
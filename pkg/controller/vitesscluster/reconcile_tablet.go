@@ -4,15 +4,17 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strconv"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
-	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -20,6 +22,15 @@ import (
 	"vitess.io/vitess-operator/pkg/util/scripts"
 )
 
+const (
+	// TabletTerminationGracePeriodSeconds gives the operator time to reparent away from a master tablet
+	// whose pod is being deleted, while the vttablet preStop hook holds the pod up
+	TabletTerminationGracePeriodSeconds int64 = 120
+
+	// TabletRolloutRequeueInterval is how often a tablet rollout is checked on while it is in progress
+	TabletRolloutRequeueInterval = 10 * time.Second
+)
+
 func (r *ReconcileVitessCluster) ReconcileTablet(tablet *vitessv1alpha2.VitessTablet) (reconcile.Result, error) {
 	log.Info("Reconciling Tablet", "Namespace", tablet.GetNamespace(), "VitessCluster.Name", tablet.Cluster().GetName(), "Tablet.Name", tablet.GetName())
 
@@ -30,17 +41,6 @@ func (r *ReconcileVitessCluster) ReconcileTablet(tablet *vitessv1alpha2.VitessTa
 		return r, err
 	}
 
-	// Create an init job for tablets of type replica
-	// TODO replace this with direct election via the operator
-	if tablet.Spec.Type == vitessv1alpha2.TabletTypeReplica {
-		if r, err := r.ReconcileReplicaTabletInitJob(tablet); err != nil {
-			log.Error(err, "Failed to reconcile replica tablet master init job", "Namespace", tablet.GetName(), "VitessCluster.Name", tablet.Cluster().GetName(), "Tablet.Name", tablet.GetName())
-			return r, err
-		} else if r.Requeue {
-			return r, err
-		}
-	}
-
 	return reconcile.Result{}, nil
 }
 
@@ -54,12 +54,25 @@ func (r *ReconcileVitessCluster) ReconcileTabletResources(tablet *vitessv1alpha2
 	foundStatefulSet := &appsv1.StatefulSet{}
 	err := r.client.Get(context.TODO(), types.NamespacedName{Name: statefulSet.GetName(), Namespace: tablet.Cluster().GetNamespace()}, foundStatefulSet)
 	if err != nil && errors.IsNotFound(err) {
+		// Tablets can't register themselves in a lockserver that is down, so hold off on creating them.
+		// Cells without a lockserver of their own only depend on the cluster lockserver.
+		for _, lockserver := range []*vitessv1alpha2.VitessLockserver{tablet.Cluster().Lockserver(), tablet.Lockserver()} {
+			if lockserver == nil {
+				continue
+			}
+			if !lockserver.IsAvailable() {
+				log.Info("Lockserver is not available, not creating tablet", "Namespace", tablet.GetNamespace(), "VitessCluster.Name", tablet.Cluster().GetName(), "Tablet.Name", tablet.GetName(), "Lockserver.Name", lockserver.GetName())
+				return reconcile.Result{Requeue: true, RequeueAfter: 30 * time.Second}, nil
+			}
+		}
+
 		controllerutil.SetControllerReference(tablet.Cluster(), statefulSet, r.scheme)
 
 		err = r.client.Create(context.TODO(), statefulSet)
 		if err != nil {
 			return reconcile.Result{}, err
 		}
+		r.recorder.Eventf(tablet.Cluster(), corev1.EventTypeNormal, "Created", "Created StatefulSet %s", statefulSet.GetName())
 	} else if err != nil {
 		log.Error(err, "failed to get StatefulSet")
 		return reconcile.Result{}, err
@@ -70,34 +83,158 @@ func (r *ReconcileVitessCluster) ReconcileTabletResources(tablet *vitessv1alpha2
 		// generated statefulset so it is always different. The extra updates are harmless and don't actually
 		// trigger statefulset upgrades.
 		// TODO more exact diff detection
+		uidBase := statefulSet.GetAnnotations()[vitessv1alpha2.TabletUIDBaseAnnotation]
 		if !reflect.DeepEqual(foundStatefulSet.Spec.Template, statefulSet.Spec.Template) ||
 			!reflect.DeepEqual(foundStatefulSet.Spec.Replicas, statefulSet.Spec.Replicas) ||
-			!reflect.DeepEqual(foundStatefulSet.Spec.UpdateStrategy, statefulSet.Spec.UpdateStrategy) {
+			!reflect.DeepEqual(foundStatefulSet.Spec.UpdateStrategy, statefulSet.Spec.UpdateStrategy) ||
+			foundStatefulSet.GetAnnotations()[vitessv1alpha2.TabletUIDBaseAnnotation] != uidBase {
 			log.Info("Updating statefulSet for tablet", "Namespace", tablet.GetNamespace(), "VitessCluster.Name", tablet.Cluster().GetName(), "Tablet.Name", tablet.GetName())
 
 			// Update foundStatefulSet with changable fields from the generated StatefulSet
 
 			// Only Template, replicas and updateStrategy may be updated on existing StatefulSet spec
 			statefulSet.Spec.Template.DeepCopyInto(&foundStatefulSet.Spec.Template)
-			statefulSet.Spec.Replicas = foundStatefulSet.Spec.Replicas
+			foundStatefulSet.Spec.Replicas = statefulSet.Spec.Replicas
 			statefulSet.Spec.UpdateStrategy.DeepCopyInto(&foundStatefulSet.Spec.UpdateStrategy)
 
+			// StatefulSets created before the UID base was recorded get the one NormalizeClusterTabletUIDs derived
+			if foundStatefulSet.Annotations == nil {
+				foundStatefulSet.Annotations = map[string]string{}
+			}
+			foundStatefulSet.Annotations[vitessv1alpha2.TabletUIDBaseAnnotation] = uidBase
+
+			generation := foundStatefulSet.GetGeneration()
 			err = r.client.Update(context.TODO(), foundStatefulSet)
 			if err != nil {
 				return reconcile.Result{}, err
 			}
+
+			// Most of these updates don't change anything, which the generation tells apart
+			if foundStatefulSet.GetGeneration() != generation {
+				r.recorder.Eventf(tablet.Cluster(), corev1.EventTypeNormal, "Updated", "Updated StatefulSet %s", foundStatefulSet.GetName())
+			}
+		}
+
+		volumeResult, err := r.ReconcileTabletVolumes(tablet, foundStatefulSet)
+		if err != nil {
+			return volumeResult, err
+		}
+
+		restoreResult, err := r.ReconcileTabletRestores(tablet, foundStatefulSet)
+		if err != nil {
+			return restoreResult, err
 		}
 
 		// Set the tablet status based on the StatefulSet status
-		// this is for use by the VitessCluster controller later
-		if foundStatefulSet.Status.Replicas == foundStatefulSet.Status.ReadyReplicas {
+		// this is for use by the VitessCluster controller later.
+		// Standalone tablets come with their stored phase, so it is reset as well.
+		// Restored pods are ready before they caught up, so the tablet isn't ready until its restores complete.
+		if isStatefulSetReady(foundStatefulSet) && !tablet.Cluster().GetTabletStatus(tablet).IsRestoring() {
 			tablet.SetPhase(vitessv1alpha2.TabletPhaseReady)
+		} else {
+			tablet.SetPhase(vitessv1alpha2.TabletPhaseNone)
+		}
+
+		if result, err := r.ReconcileTabletRollout(tablet, foundStatefulSet); err != nil || result.Requeue {
+			return result, err
+		}
+
+		if restoreResult.Requeue {
+			return restoreResult, nil
 		}
+
+		return volumeResult, nil
 	}
 
 	return reconcile.Result{}, nil
 }
 
+// ReconcileTabletRollout replaces the tablet pods that don't run the current StatefulSet revision, one at a time.
+// The StatefulSet uses the OnDelete update strategy so that the shard master pod is never replaced before
+// the operator has reparented away from it. The master is left to ReconcileShardReparent.
+func (r *ReconcileVitessCluster) ReconcileTabletRollout(tablet *vitessv1alpha2.VitessTablet, statefulSet *appsv1.StatefulSet) (reconcile.Result, error) {
+	masterPodName := tablet.Cluster().GetShardStatus(tablet.Shard()).MasterPodName
+
+	_, result, err := r.replaceOutdatedTabletPod(tablet, statefulSet, masterPodName)
+	return result, err
+}
+
+// replaceOutdatedTabletPod deletes the next outdated pod of the tablet, if no other pod of the tablet is down, and
+// returns the deleted pod. The pod named keepPodName is never replaced.
+func (r *ReconcileVitessCluster) replaceOutdatedTabletPod(tablet *vitessv1alpha2.VitessTablet, statefulSet *appsv1.StatefulSet, keepPodName string) (*corev1.Pod, reconcile.Result, error) {
+	pods := &corev1.PodList{}
+	err := r.client.List(context.TODO(), &client.ListOptions{
+		Namespace:     statefulSet.GetNamespace(),
+		LabelSelector: labels.SelectorFromSet(statefulSet.Spec.Selector.MatchLabels),
+	}, pods)
+	if err != nil {
+		return nil, reconcile.Result{}, err
+	}
+
+	var (
+		next        *corev1.Pod
+		nextIndex   int32 = -1
+		nextReady         = true
+		terminating       = false
+		unready           = false
+	)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+
+		ordinal, ok := getPodOrdinal(statefulSet.GetName(), pod.GetName())
+		if !ok {
+			continue
+		}
+
+		if pod.GetDeletionTimestamp() != nil {
+			terminating = true
+			continue
+		}
+
+		ready := isPodReady(pod)
+		if !ready {
+			unready = true
+		}
+
+		if !isPodOutdated(pod, statefulSet) || pod.GetName() == keepPodName {
+			continue
+		}
+
+		// Replace outdated pods that aren't ready first, then from the highest ordinal down,
+		// like the StatefulSet controller does. Waiting on a pod of a broken revision would stall the rollout.
+		if (nextReady && !ready) || (nextReady == ready && ordinal > nextIndex) {
+			next, nextIndex, nextReady = pod, ordinal, ready
+		}
+	}
+
+	if next == nil {
+		return nil, reconcile.Result{}, nil
+	}
+
+	// Only one pod of the tablet is down at a time: a ready pod is only replaced once every other pod is ready,
+	// and a pod that isn't ready is only replaced once the previous one is gone.
+	if terminating || (nextReady && unready) {
+		return nil, reconcile.Result{Requeue: true, RequeueAfter: TabletRolloutRequeueInterval}, nil
+	}
+
+	log.Info("Replacing outdated tablet pod", "Namespace", next.GetNamespace(), "VitessCluster.Name", tablet.Cluster().GetName(), "Tablet.Name", tablet.GetName(), "Pod.Name", next.GetName())
+	if err := r.client.Delete(context.TODO(), next); err != nil && !errors.IsNotFound(err) {
+		return nil, reconcile.Result{}, err
+	}
+
+	return next, reconcile.Result{Requeue: true, RequeueAfter: TabletRolloutRequeueInterval}, nil
+}
+
+// isPodReady returns true if the pod has the Ready condition
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
 func getStatefulSetForTablet(tablet *vitessv1alpha2.VitessTablet) (*appsv1.StatefulSet, error) {
 	selfLabels := map[string]string{
 		"tabletname": tablet.GetName(),
@@ -107,7 +244,7 @@ func getStatefulSetForTablet(tablet *vitessv1alpha2.VitessTablet) (*appsv1.State
 		"keyspace":   tablet.Keyspace().GetName(),
 		"shard":      tablet.Shard().GetName(),
 		"component":  "vttablet",
-		"type":       string(tablet.Spec.Type),
+		"type":       string(tablet.GetType()),
 	}
 
 	vtgateLabels := map[string]string{
@@ -188,67 +325,86 @@ func getStatefulSetForTablet(tablet *vitessv1alpha2.VitessTablet) (*appsv1.State
 	containers := []corev1.Container{}
 	containers = append(containers, dbContainers...)
 	containers = append(containers, vttabletContainers...)
+	containers = append(containers, GetTabletMySQLExporterContainers(tablet)...)
+
+	binlogReplayContainers, err := GetTabletBinlogReplayContainers(tablet)
+	if err != nil {
+		return nil, err
+	}
+	containers = append(containers, binlogReplayContainers...)
 
 	// build initcontainers
 	initContainers := []corev1.Container{}
 	initContainers = append(initContainers, dbInitContainers...)
 	initContainers = append(initContainers, vttabletInitContainers...)
 
-	// setup volume requests
-	volumeRequests := make(corev1.ResourceList)
-	volumeRequests[corev1.ResourceStorage] = resource.MustParse("10Gi")
+	volumeClaim := tablet.GetVolumeClaim()
+
+	// The StatefulSet must keep the shard master pod until the operator has reparented away from it
+	replicas := tablet.GetReplicas()
+	if ordinal, ok := getPodOrdinal(tablet.GetStatefulSetName(), tablet.Cluster().GetShardStatus(tablet.Shard()).MasterPodName); ok && ordinal >= *replicas {
+		replicas = getInt32Ptr(ordinal + 1)
+	}
+
+	// Tablets are pinned to their UID base and restored tablets to their restore,
+	// see NormalizeClusterTabletUIDs and NormalizeClusterRestores
+	annotations := map[string]string{
+		vitessv1alpha2.TabletUIDBaseAnnotation: strconv.FormatUint(uint64(tablet.GetTabletUIDBase()), 10),
+	}
+	if restore := tablet.Restore(); restore != nil {
+		annotations[vitessv1alpha2.TabletRestoreAnnotation] = restore.GetName()
+	}
 
 	return &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      tablet.GetStatefulSetName(),
-			Namespace: tablet.Cluster().GetNamespace(),
-			Labels:    selfLabels,
+			Name:        tablet.GetStatefulSetName(),
+			Namespace:   tablet.Cluster().GetNamespace(),
+			Labels:      selfLabels,
+			Annotations: annotations,
 		},
 		Spec: appsv1.StatefulSetSpec{
 			//PodManagementPolicy: appsv1.PodManagementPolicyParallel{},
 			PodManagementPolicy: appsv1.ParallelPodManagement,
-			Replicas:            tablet.GetReplicas(),
+			Replicas:            replicas,
 			Selector: &metav1.LabelSelector{
 				MatchLabels: selfLabels,
 			},
 			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
-				Type: appsv1.RollingUpdateStatefulSetStrategyType,
+				Type: appsv1.OnDeleteStatefulSetStrategyType,
 			},
 			ServiceName: tablet.Cluster().GetTabletServiceName(),
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: selfLabels,
+					Labels:      selfLabels,
+					Annotations: getScrapeAnnotations(15002),
 				},
 				Spec: corev1.PodSpec{
 					Affinity:       affinity,
 					Containers:     containers,
 					InitContainers: initContainers,
-					Volumes: []corev1.Volume{
+					Volumes: append([]corev1.Volume{
 						{
 							Name: "vt",
 							VolumeSource: corev1.VolumeSource{
 								EmptyDir: &corev1.EmptyDirVolumeSource{},
 							},
 						},
-					},
+					}, append(append(tablet.Cell().TopoLockserver().GetTopoVolumes(), tablet.GetBackupStorage().GetBackupVolumes()...), getTabletBinlogReplayVolumes(tablet)...)...),
 					SecurityContext: &corev1.PodSecurityContext{
 						FSGroup:   getInt64Ptr(2000),
 						RunAsUser: getInt64Ptr(1000),
 					},
-					TerminationGracePeriodSeconds: getInt64Ptr(60000000),
+					TerminationGracePeriodSeconds: getInt64Ptr(TabletTerminationGracePeriodSeconds),
 				},
 			},
 			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
 				{
 					ObjectMeta: metav1.ObjectMeta{
-						Name: "vtdataroot",
-					},
-					Spec: corev1.PersistentVolumeClaimSpec{
-						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
-						Resources: corev1.ResourceRequirements{
-							Requests: volumeRequests,
-						},
+						Name:        "vtdataroot",
+						Labels:      volumeClaim.Labels,
+						Annotations: volumeClaim.Annotations,
 					},
+					Spec: volumeClaim.Spec,
 				},
 			},
 		},
@@ -326,7 +482,7 @@ func GetTabletMysqlContainers(tablet *vitessv1alpha2.VitessTablet) (containers [
 			FailureThreshold:    3,
 		},
 		Resources: mysql.Resources,
-		VolumeMounts: []corev1.VolumeMount{
+		VolumeMounts: append([]corev1.VolumeMount{
 			{
 				Name:      "vtdataroot",
 				MountPath: "/vtdataroot",
@@ -335,8 +491,8 @@ func GetTabletMysqlContainers(tablet *vitessv1alpha2.VitessTablet) (containers [
 				Name:      "vt",
 				MountPath: "/vt",
 			},
-		},
-		Env: []corev1.EnvVar{
+		}, tablet.GetBackupStorage().GetBackupVolumeMounts()...),
+		Env: append([]corev1.EnvVar{
 			{
 				Name:  "VTROOT",
 				Value: "/vt",
@@ -361,7 +517,7 @@ func GetTabletMysqlContainers(tablet *vitessv1alpha2.VitessTablet) (containers [
 				Name:  "VT_DB_FLAVOR",
 				Value: mysql.DBFlavor,
 			},
-		},
+		}, tablet.GetBackupStorage().GetBackupEnv()...),
 	})
 
 	return
@@ -419,6 +575,8 @@ func GetTabletVTTabletContainers(tablet *vitessv1alpha2.VitessTablet) (container
 					},
 				},
 			},
+			// vttablet isn't healthy while it restores from a backup, so a restoring pod doesn't become ready
+			// until the restore is done
 			ReadinessProbe: &corev1.Probe{
 				Handler: corev1.Handler{
 					HTTPGet: &corev1.HTTPGetAction{
@@ -463,13 +621,19 @@ func GetTabletVTTabletContainers(tablet *vitessv1alpha2.VitessTablet) (container
 				// Limits:   corev1.ResourceList{},
 				// Requests: corev1.ResourceList{},
 			},
-			VolumeMounts: []corev1.VolumeMount{
+			VolumeMounts: append([]corev1.VolumeMount{
 				{
 					Name:      "vtdataroot",
 					MountPath: "/vtdataroot",
 				},
-			},
-			Env: []corev1.EnvVar{
+				{
+					// The preStop hook uses the busybox copied here by init-mysql
+					Name:      "vt",
+					MountPath: "/vttmp",
+					ReadOnly:  true,
+				},
+			}, append(tablet.Cell().TopoLockserver().GetTopoVolumeMounts(), tablet.GetBackupStorage().GetBackupVolumeMounts()...)...),
+			Env: append([]corev1.EnvVar{
 				{
 					Name:  "VTROOT",
 					Value: "/vt",
@@ -494,7 +658,7 @@ func GetTabletVTTabletContainers(tablet *vitessv1alpha2.VitessTablet) (container
 					Name:  "VT_DB_FLAVOR",
 					Value: vttablet.DBFlavor,
 				},
-			},
+			}, append(tablet.Cell().TopoLockserver().GetTopoEnv(), tablet.GetBackupStorage().GetBackupEnv()...)...),
 		},
 		corev1.Container{
 			Name:            "logrotate",
@@ -536,80 +700,126 @@ func GetTabletVTTabletContainers(tablet *vitessv1alpha2.VitessTablet) (container
 	return
 }
 
-func (r *ReconcileVitessCluster) ReconcileReplicaTabletInitJob(tablet *vitessv1alpha2.VitessTablet) (reconcile.Result, error) {
-	job, jobErr := GetReplicaTabletInitMasterJob(tablet)
-	if jobErr != nil {
-		log.Error(jobErr, "failed to generate master elect job for replica VitessTablet", "VitessTablet.Namespace", tablet.GetNamespace(), "VitessTablet.Name", tablet.GetNamespace())
-		return reconcile.Result{}, jobErr
+// GetTabletMySQLExporterContainers returns the mysqld_exporter sidecar of the tablet pods if the cluster enables it.
+// The exporter connects as vt_dba through the mysqld socket, since vt_dba only has a password-less local account.
+func GetTabletMySQLExporterContainers(tablet *vitessv1alpha2.VitessTablet) (containers []corev1.Container) {
+	exporter := tablet.Cluster().MySQLExporter()
+	if exporter == nil {
+		return
 	}
 
-	found := &batchv1.Job{}
-	err := r.client.Get(context.TODO(), types.NamespacedName{Name: job.GetName(), Namespace: job.GetNamespace()}, found)
-	if err != nil && errors.IsNotFound(err) {
-		controllerutil.SetControllerReference(tablet.Cluster(), job, r.scheme)
-		err = r.client.Create(context.TODO(), job)
-		if err != nil {
-			return reconcile.Result{}, err
-		}
-		// Job created successfully - return and requeue
-		return reconcile.Result{Requeue: true}, nil
-	} else if err != nil {
-		log.Error(err, "failed to get Job")
-		return reconcile.Result{}, err
-	}
+	containers = append(containers, corev1.Container{
+		Name:            "mysqld-exporter",
+		Image:           exporter.GetImage(),
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Args: []string{
+			fmt.Sprintf("--web.listen-address=:%d", MySQLExporterPort),
+		},
+		Ports: []corev1.ContainerPort{
+			{
+				ContainerPort: MySQLExporterPort,
+				Name:          "mysql-metrics",
+				Protocol:      corev1.ProtocolTCP,
+			},
+		},
+		Resources: exporter.Resources,
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      "vtdataroot",
+				MountPath: "/vtdataroot",
+			},
+		},
+		Env: []corev1.EnvVar{
+			{
+				Name:  "DATA_SOURCE_NAME",
+				Value: "vt_dba@unix(/vtdataroot/tabletdata/mysql.sock)/",
+			},
+		},
+	})
 
-	return reconcile.Result{}, nil
+	return
 }
 
-func GetReplicaTabletInitMasterJob(tablet *vitessv1alpha2.VitessTablet) (*batchv1.Job, error) {
-	jobName := tablet.GetScopedName("init-replica-master")
+// GetTabletBinlogReplayContainers returns the sidecar that replays binlogs on top of the restored backup when the tablet
+// restores to a point in time. It connects as vt_dba through the mysqld socket, like the mysqld_exporter sidecar.
+func GetTabletBinlogReplayContainers(tablet *vitessv1alpha2.VitessTablet) (containers []corev1.Container, err error) {
+	restore := tablet.Restore()
+	if restore == nil || !restore.IsPointInTime() {
+		return
+	}
 
-	scripts := scripts.NewContainerScriptGenerator("init_replica_master", tablet)
-	if err := scripts.Generate(); err != nil {
-		return nil, err
+	mysql := tablet.GetMySQLContainer()
+	if mysql == nil {
+		err = fmt.Errorf("No database container configuration found")
+		return
 	}
 
-	jobLabels := map[string]string{
-		"app":                "vitess",
-		"cluster":            tablet.Cluster().GetName(),
-		"keyspace":           tablet.Keyspace().GetName(),
-		"shard":              tablet.Shard().GetName(),
-		"component":          "vttablet-replica-elector",
-		"initShardMasterJob": "true",
-		"job-name":           jobName,
+	replayScripts := scripts.NewContainerScriptGenerator("binlog-replay", tablet)
+	if err = replayScripts.Generate(); err != nil {
+		err = fmt.Errorf("Error generating binlog replay container scripts: %s", err)
+		return
 	}
 
-	return &batchv1.Job{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      jobName,
-			Namespace: tablet.Cluster().GetNamespace(),
-			Labels:    jobLabels,
+	// The mysql image comes with the mysql client and mysqlbinlog
+	containers = append(containers, corev1.Container{
+		Name:            BinlogReplayContainerName,
+		Image:           mysql.Image,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Command:         []string{"bash"},
+		Args: []string{
+			"-c",
+			replayScripts.Start,
 		},
-		Spec: batchv1.JobSpec{
-			BackoffLimit: getInt32Ptr(1),
-			Completions:  getInt32Ptr(1),
-			Parallelism:  getInt32Ptr(1),
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: jobLabels,
-				},
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      "vtdataroot",
+				MountPath: "/vtdataroot",
+			},
+			{
+				Name:      "podinfo",
+				MountPath: "/podinfo",
+				ReadOnly:  true,
+			},
+		},
+		Env: []corev1.EnvVar{
+			{
+				// mysqlbinlog reads restoreToTime in the local time zone
+				Name:  "TZ",
+				Value: "UTC",
+			},
+		},
+	})
+
+	return
+}
+
+// getTabletBinlogReplayVolumes returns the volume that hands the binlog source annotation of the pod to the binlog
+// replay sidecar. Annotations that change are updated in the volume, unlike in environment variables.
+func getTabletBinlogReplayVolumes(tablet *vitessv1alpha2.VitessTablet) []corev1.Volume {
+	if restore := tablet.Restore(); restore == nil || !restore.IsPointInTime() {
+		return nil
+	}
+
+	return []corev1.Volume{
+		{
+			Name: "podinfo",
+			VolumeSource: corev1.VolumeSource{
+				DownwardAPI: &corev1.DownwardAPIVolumeSource{
+					Items: []corev1.DownwardAPIVolumeFile{
 						{
-							Name:  "init-master",
-							Image: "vitess/vtctlclient:helm-1.0.3", // TODO use CRD w/default
-							Command: []string{
-								"bash",
-							},
-							Args: []string{
-								"-c",
-								scripts.Start,
+							Path: "binlog-source",
+							FieldRef: &corev1.ObjectFieldSelector{
+								FieldPath: fmt.Sprintf("metadata.annotations['%s']", vitessv1alpha2.BinlogSourceAnnotation),
 							},
 						},
 					},
-					RestartPolicy: corev1.RestartPolicyOnFailure,
 				},
 			},
 		},
-	}, nil
+	}
+}
+
+// isStatefulSetReady returns true if every pod of the StatefulSet is ready
+func isStatefulSetReady(statefulSet *appsv1.StatefulSet) bool {
+	return statefulSet.Status.Replicas == statefulSet.Status.ReadyReplicas
 }
@@ -0,0 +1,305 @@
+package vitesscluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	"vitess.io/vitess-operator/pkg/normalizer"
+)
+
+// TabletRestoreRequeueInterval is how often new tablet pods are checked on while they may be restoring
+const TabletRestoreRequeueInterval = 30 * time.Second
+
+// ReconcileTabletRestores records which backup every new pod of a tablet restored from. A pod is
+// restoring while vtctld lists its tablet with the restore type. vttablet picks the backup itself, so
+// the backup is taken from the line vttablet logs when it finds it rather than guessed from the backups
+// of the shard, which may have changed since. The restore is complete once the pod is ready and its
+// replication lag is below the maxReplicationLagSeconds of the restore policy.
+func (r *ReconcileVitessCluster) ReconcileTabletRestores(tablet *vitessv1alpha2.VitessTablet, statefulSet *appsv1.StatefulSet) (reconcile.Result, error) {
+	if !tablet.RestoresFromBackup() {
+		return reconcile.Result{}, nil
+	}
+
+	pods := &corev1.PodList{}
+	err := r.client.List(context.TODO(), &client.ListOptions{
+		Namespace:     statefulSet.GetNamespace(),
+		LabelSelector: labels.SelectorFromSet(statefulSet.Spec.Selector.MatchLabels),
+	}, pods)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	cluster := tablet.Cluster()
+	keyspaceShard := tablet.Shard().GetKeyspaceShard()
+
+	status := cluster.GetTabletStatus(tablet).DeepCopy()
+	known := map[string]vitessv1alpha2.TabletRestoreStatus{}
+	for _, restore := range status.Restores {
+		known[restore.PodName] = restore
+	}
+
+	var (
+		restores []vitessv1alpha2.TabletRestoreStatus
+		restored = map[string]*corev1.Pod{}
+		starting = []*corev1.Pod{}
+	)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+
+		if _, ok := getPodOrdinal(statefulSet.GetName(), pod.GetName()); !ok {
+			continue
+		}
+
+		if restore, ok := known[pod.GetName()]; ok {
+			restores = append(restores, restore)
+			restored[pod.GetName()] = pod
+			delete(known, pod.GetName())
+		} else if !isPodReady(pod) {
+			starting = append(starting, pod)
+		}
+	}
+
+	// Pods that are being replaced keep their restore, since the claim with their data is kept
+	for _, restore := range known {
+		if ordinal, ok := getPodOrdinal(statefulSet.GetName(), restore.PodName); ok && ordinal < *statefulSet.Spec.Replicas {
+			restores = append(restores, restore)
+		}
+	}
+
+	if !areTabletRestoresComplete(restores, restored, starting) {
+		restores, err = r.updateTabletRestores(tablet, keyspaceShard, restores, restored, starting)
+		if err != nil {
+			log.Info("Unable to check tablet restores, will retry", "Shard", keyspaceShard, "Error", err.Error())
+		}
+	}
+
+	sort.Slice(restores, func(i, j int) bool {
+		return restores[i].PodName < restores[j].PodName
+	})
+
+	if !reflect.DeepEqual(status.Restores, restores) {
+		status.Restores = restores
+		if err := r.setTabletStatus(tablet, status); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if !areTabletRestoresComplete(restores, restored, starting) {
+		return reconcile.Result{Requeue: true, RequeueAfter: TabletRestoreRequeueInterval}, nil
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// areTabletRestoresComplete returns false while a restore isn't complete or failed, or a starting pod may still
+// begin restoring
+func areTabletRestoresComplete(restores []vitessv1alpha2.TabletRestoreStatus, restored map[string]*corev1.Pod, starting []*corev1.Pod) bool {
+	for _, restore := range restores {
+		if !restore.Complete && restore.Error == "" {
+			return false
+		}
+	}
+
+	for _, pod := range starting {
+		if restored[pod.GetName()] == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// updateTabletRestores adds a restore for each of the starting pods whose tablet is restoring from a backup,
+// and records the backup and completion of the restores that aren't complete yet. The restores are returned
+// as far as they got if vtctld or the pod logs can't be reached.
+func (r *ReconcileVitessCluster) updateTabletRestores(tablet *vitessv1alpha2.VitessTablet, keyspaceShard string, restores []vitessv1alpha2.TabletRestoreStatus, restored map[string]*corev1.Pod, starting []*corev1.Pod) ([]vitessv1alpha2.TabletRestoreStatus, error) {
+	vtctldClient := newVtctldClient(tablet.Cluster().GetVtctldAddress())
+
+	tablets, err := vtctldClient.ListShardTablets(keyspaceShard)
+	if err != nil {
+		return restores, err
+	}
+
+	aliases := map[string]string{}
+	for _, t := range tablets {
+		aliases[t.PodName()] = t.Alias
+		if t.Type != string(vitessv1alpha2.TabletTypeRestore) {
+			continue
+		}
+
+		for _, pod := range starting {
+			if pod.GetName() == t.PodName() {
+				restores = append(restores, vitessv1alpha2.TabletRestoreStatus{PodName: pod.GetName()})
+				restored[pod.GetName()] = pod
+			}
+		}
+	}
+
+	maxLag := tablet.GetRestorePolicy().GetMaxReplicationLagSeconds()
+	for i := range restores {
+		restore := &restores[i]
+		pod := restored[restore.PodName]
+		if restore.Complete || restore.Error != "" || pod == nil {
+			continue
+		}
+
+		// vttablet logs the backup before it starts restoring it, so a ready pod whose logs don't have it
+		// started empty because the shard had no backup
+		if restore.Backup == "" {
+			logs, err := readPodLogs(r.config, pod, "vttablet", restoreLogLimitBytes)
+			if err != nil {
+				return restores, err
+			}
+
+			restore.Backup = getRestoredBackup(logs)
+		}
+
+		if !isPodReady(pod) || aliases[pod.GetName()] == "" {
+			continue
+		}
+
+		// Pods that started empty have nothing to replay binlogs on top of, and fail the VitessRestore
+		if pointInTime := tablet.Restore(); pointInTime != nil && pointInTime.IsPointInTime() && restore.Backup != "" {
+			if err := r.updateTabletBinlogReplay(pointInTime, keyspaceShard, pod, restore); err != nil {
+				return restores, err
+			}
+
+			if !restore.BinlogsReplayed {
+				continue
+			}
+		}
+
+		lag, err := vtctldClient.GetReplicationLag(aliases[pod.GetName()])
+		if err != nil {
+			return restores, err
+		}
+
+		restore.Complete = lag <= uint32(maxLag)
+	}
+
+	return restores, nil
+}
+
+// BinlogReplayContainerName is the name of the sidecar of restored tablet pods that replays binlogs up to the
+// point in time of their VitessRestore
+const BinlogReplayContainerName = "binlog-replay"
+
+// updateTabletBinlogReplay hands the host of the binlog source to a pod that restored its backup, and records the
+// outcome of the binlog replay once the sidecar logs it. The source is the master of the shard with the same
+// name in the binlog source cluster, and is kept once set so that a reparent there doesn't restart the replay.
+func (r *ReconcileVitessCluster) updateTabletBinlogReplay(pointInTime *vitessv1alpha2.VitessRestore, keyspaceShard string, pod *corev1.Pod, restore *vitessv1alpha2.TabletRestoreStatus) error {
+	if pod.GetAnnotations()[vitessv1alpha2.BinlogSourceAnnotation] == "" {
+		host, err := r.getBinlogSourceHost(pointInTime, keyspaceShard)
+		if err != nil {
+			return err
+		}
+
+		pod = pod.DeepCopy()
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[vitessv1alpha2.BinlogSourceAnnotation] = host
+
+		log.Info("Replaying binlogs on restored tablet pod", "Pod", pod.GetName(), "Source", host)
+		return r.client.Update(context.TODO(), pod)
+	}
+
+	logs, err := readPodLogs(r.config, pod, BinlogReplayContainerName, restoreLogLimitBytes)
+	if err != nil {
+		return err
+	}
+
+	restore.BinlogsReplayed, restore.Error = getBinlogReplayOutcome(logs)
+	return nil
+}
+
+// getBinlogSourceHost returns the host of the master of the given shard in the binlog source cluster of the restore
+func (r *ReconcileVitessCluster) getBinlogSourceHost(pointInTime *vitessv1alpha2.VitessRestore, keyspaceShard string) (string, error) {
+	source := &vitessv1alpha2.VitessCluster{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: pointInTime.Spec.BinlogSourceCluster, Namespace: pointInTime.GetNamespace()}, source)
+	if err != nil {
+		return "", err
+	}
+
+	if err := normalizer.New(r.client).NormalizeCluster(source); err != nil {
+		return "", err
+	}
+
+	if len(source.Cells()) == 0 {
+		return "", fmt.Errorf("Binlog source cluster %s has no cells", source.GetName())
+	}
+
+	tablets, err := newVtctldClient(source.GetVtctldAddress()).ListShardTablets(keyspaceShard)
+	if err != nil {
+		return "", err
+	}
+
+	for _, t := range tablets {
+		if t.Type == "master" {
+			return t.Hostname, nil
+		}
+	}
+
+	return "", fmt.Errorf("Shard %s of binlog source cluster %s has no master", keyspaceShard, source.GetName())
+}
+
+// binlogReplayFailedPattern matches the line the binlog replay sidecar logs when it can't replay the binlogs
+var binlogReplayFailedPattern = regexp.MustCompile(`Binlog replay failed: (.*)`)
+
+// getBinlogReplayOutcome returns whether the binlog replay sidecar finished according to its logs, and why it
+// failed if it did. mysqlbinlog or mysql exiting with an error restarts the sidecar, which retries the replay.
+func getBinlogReplayOutcome(logs []byte) (bool, string) {
+	if match := binlogReplayFailedPattern.FindSubmatch(logs); match != nil {
+		return false, string(match[1])
+	}
+
+	return bytes.Contains(logs, []byte("Binlog replay complete")), ""
+}
+
+// restoreLogLimitBytes bounds how much of the log of a vttablet container is read to find the restored backup.
+// vttablet restores before it does anything else, so the backup is logged early on.
+const restoreLogLimitBytes = 1 << 20
+
+// restoredBackupPattern matches the line vttablet logs when it found the backup to restore, e.g.
+// "Restore: found backup main/0 2019-01-31.030000.zone1-0000000100 to restore"
+var restoredBackupPattern = regexp.MustCompile(`Restore: found backup \S+ (\S+) to restore`)
+
+// getRestoredBackup returns the backup that vttablet restored according to its logs, or an empty string if it
+// didn't restore any
+func getRestoredBackup(logs []byte) string {
+	if match := restoredBackupPattern.FindSubmatch(logs); match != nil {
+		return string(match[1])
+	}
+	return ""
+}
+
+// readPodLogs returns the start of the logs of a container of the pod. It is a variable so that tests
+// can stub out the API server, which the cached client can't read logs from.
+var readPodLogs = defaultReadPodLogs
+
+func defaultReadPodLogs(config *rest.Config, pod *corev1.Pod, container string, limitBytes int64) ([]byte, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return clientset.CoreV1().Pods(pod.GetNamespace()).GetLogs(pod.GetName(), &corev1.PodLogOptions{
+		Container:  container,
+		LimitBytes: &limitBytes,
+	}).DoRaw()
+}
@@ -0,0 +1,349 @@
+package vitesscluster
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	"vitess.io/vitess-operator/pkg/util/vtctld"
+)
+
+func TestTabletRestoreFlags(t *testing.T) {
+	cluster, shard, tablet := newReparentTestShard(1, "")
+
+	getScript := func() string {
+		containers, _, err := GetTabletVTTabletContainers(tablet)
+		if err != nil {
+			t.Fatalf("Error generating vttablet containers: %s", err)
+		}
+		return containers[0].Args[len(containers[0].Args)-1]
+	}
+
+	shard.Spec.Defaults = &vitessv1alpha2.VitessShardOptions{
+		Restore: &vitessv1alpha2.TabletRestorePolicy{FromBackup: true},
+	}
+
+	// Without a backup storage there is nothing to restore from
+	if script := getScript(); strings.Contains(script, "-restore_from_backup") {
+		t.Errorf("Tablet restores without a backup storage: %s", script)
+	}
+
+	cluster.Spec.BackupStorage = &vitessv1alpha2.VitessBackupStorage{
+		File: &vitessv1alpha2.FileBackupStorage{ClaimName: "backups"},
+	}
+
+	script := getScript()
+	if !strings.Contains(script, "-restore_from_backup") {
+		t.Errorf("Restore flags missing from the vttablet start script: %s", script)
+	}
+
+	// The replication lag of restored tablets is checked by the operator, vttablet keeps its own health threshold
+	if strings.Contains(script, "-unhealthy_threshold") {
+		t.Errorf("Restore policy changed the vttablet health threshold: %s", script)
+	}
+
+	// The tablet policy replaces the shard policy
+	tablet.Spec.Restore = &vitessv1alpha2.TabletRestorePolicy{}
+	if script := getScript(); strings.Contains(script, "-restore_from_backup") {
+		t.Errorf("Tablet restore policy did not replace the shard policy: %s", script)
+	}
+
+	// A VitessRestore overrides both the policy and the backup storage
+	tablet.SetRestore(&vitessv1alpha2.VitessRestore{
+		Spec: vitessv1alpha2.VitessRestoreSpec{
+			Source: vitessv1alpha2.VitessBackupStorage{
+				S3: &vitessv1alpha2.S3BackupStorage{Bucket: "prod-backups"},
+			},
+		},
+	})
+
+	script = getScript()
+	if !strings.Contains(script, "-restore_from_backup") || !strings.Contains(script, `-s3_backup_storage_bucket="prod-backups"`) {
+		t.Errorf("Keyspace restore not applied to the vttablet start script: %s", script)
+	}
+}
+
+// TestTabletRestores makes sure that the backup a new pod restores from is recorded until the pod is ready
+func TestTabletRestores(t *testing.T) {
+	cluster, shard, tablet := newReparentTestShard(2, "vt-zone1-main-0-replica-0")
+	cluster.Spec.BackupStorage = &vitessv1alpha2.VitessBackupStorage{
+		File: &vitessv1alpha2.FileBackupStorage{ClaimName: "backups"},
+	}
+	shard.Spec.Defaults = &vitessv1alpha2.VitessShardOptions{
+		Restore: &vitessv1alpha2.TabletRestorePolicy{FromBackup: true},
+	}
+	statefulSetName := tablet.GetStatefulSetName()
+
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: statefulSetName, Namespace: "vitess"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: tablet.GetReplicas(),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tabletname": tablet.GetName()}},
+		},
+	}
+
+	newPod := newTabletPod(statefulSetName, statefulSetName+"-1", "rev1")
+	newPod.Status.Conditions[0].Status = corev1.ConditionFalse
+
+	// The embedded objects point back to their parents, so only the metadata of the cluster is stored
+	objs := []runtime.Object{
+		&vitessv1alpha2.VitessCluster{ObjectMeta: *cluster.ObjectMeta.DeepCopy()},
+		newTabletPod(statefulSetName, statefulSetName+"-0", "rev1"),
+		newPod,
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessCluster{})
+
+	cl := fake.NewFakeClient(objs...)
+	r := &ReconcileVitessCluster{client: cl, scheme: s, recorder: &record.FakeRecorder{}}
+
+	fakeVtctld := &vtctld.FakeClient{
+		Tablets: []vtctld.Tablet{
+			{Alias: "zone1-0000000100", Type: "master", Hostname: "vt-zone1-main-0-replica-0.vt-tab"},
+			{Alias: "zone1-0000000101", Type: "restore", Hostname: "vt-zone1-main-0-replica-1.vt-tab"},
+		},
+		Lags: map[string]uint32{"zone1-0000000101": 60},
+	}
+
+	defer func() { newVtctldClient = vtctld.NewClient }()
+	newVtctldClient = func(address string) vtctld.Client {
+		return fakeVtctld
+	}
+
+	// A newer backup than the one the pod restored may have finished since, so only the logs tell
+	logs := ""
+	defer func() { readPodLogs = defaultReadPodLogs }()
+	readPodLogs = func(config *rest.Config, pod *corev1.Pod, container string, limitBytes int64) ([]byte, error) {
+		if pod.GetName() != statefulSetName+"-1" || container != "vttablet" {
+			t.Errorf("Wrong logs read: %s/%s", pod.GetName(), container)
+		}
+		return []byte(logs), nil
+	}
+
+	res, err := r.ReconcileTabletRestores(tablet, statefulSet)
+	if err != nil {
+		t.Fatalf("Error reconciling tablet restores: %s", err)
+	}
+
+	if !res.Requeue {
+		t.Error("Restoring tablet was not requeued")
+	}
+
+	expected := []vitessv1alpha2.TabletRestoreStatus{
+		{PodName: statefulSetName + "-1"},
+	}
+	if restores := cluster.GetTabletStatus(tablet).Restores; len(restores) != 1 || restores[0] != expected[0] {
+		t.Fatalf("Wrong tablet restores. Got: %+v; Expected: %+v", restores, expected)
+	}
+
+	logs = "I0131 03:10:00.000000 1 backup.go:236] Restore: found backup main/0 2019-01-30.030000.zone1-0000000100 to restore with 12 files\n"
+	if _, err := r.ReconcileTabletRestores(tablet, statefulSet); err != nil {
+		t.Fatalf("Error reconciling tablet restores: %s", err)
+	}
+
+	expected[0].Backup = "2019-01-30.030000.zone1-0000000100"
+	if restores := cluster.GetTabletStatus(tablet).Restores; len(restores) != 1 || restores[0] != expected[0] {
+		t.Fatalf("Wrong tablet restores. Got: %+v; Expected: %+v", restores, expected)
+	}
+
+	// The pod is ready but still lagging behind
+	fakeVtctld.Tablets[1].Type = "replica"
+	newPod.Status.Conditions[0].Status = corev1.ConditionTrue
+	if err := cl.Update(context.TODO(), newPod); err != nil {
+		t.Fatalf("Error updating pod: %s", err)
+	}
+
+	if res, err := r.ReconcileTabletRestores(tablet, statefulSet); err != nil || !res.Requeue {
+		t.Errorf("Lagging restore was not requeued: %v, %v", res, err)
+	}
+
+	if restores := cluster.GetTabletStatus(tablet).Restores; len(restores) != 1 || restores[0].Complete {
+		t.Fatalf("Lagging restore marked complete: %+v", restores)
+	}
+
+	fakeVtctld.Lags["zone1-0000000101"] = 2
+	if res, err := r.ReconcileTabletRestores(tablet, statefulSet); err != nil || res.Requeue {
+		t.Errorf("Completed restore was requeued: %v, %v", res, err)
+	}
+
+	expected[0].Complete = true
+	if restores := cluster.GetTabletStatus(tablet).Restores; len(restores) != 1 || restores[0] != expected[0] {
+		t.Errorf("Wrong tablet restores. Got: %+v; Expected: %+v", restores, expected)
+	}
+}
+
+func TestTabletBinlogReplayContainers(t *testing.T) {
+	_, _, tablet := newReparentTestShard(1, "")
+	restore := &vitessv1alpha2.VitessRestore{
+		Spec: vitessv1alpha2.VitessRestoreSpec{
+			Source: vitessv1alpha2.VitessBackupStorage{
+				S3: &vitessv1alpha2.S3BackupStorage{Bucket: "prod-backups"},
+			},
+		},
+	}
+	tablet.SetRestore(restore)
+
+	// Restoring the latest backup doesn't replay anything
+	if containers, err := GetTabletBinlogReplayContainers(tablet); err != nil || len(containers) != 0 {
+		t.Errorf("Binlog replay sidecar added without a point in time: %v, %v", containers, err)
+	}
+
+	restore.Spec.RestoreToPosition = "MySQL56/00000000-0000-0000-0000-000000000000:1-100"
+	restore.Spec.BinlogSourceCluster = "prod"
+
+	containers, err := GetTabletBinlogReplayContainers(tablet)
+	if err != nil || len(containers) != 1 {
+		t.Fatalf("Binlog replay sidecar missing: %v, %v", containers, err)
+	}
+
+	// mysqlbinlog takes the GTID set without the flavor
+	if script := containers[0].Args[len(containers[0].Args)-1]; !strings.Contains(script, `--include-gtids="00000000-0000-0000-0000-000000000000:1-100"`) {
+		t.Errorf("Restore position not passed to mysqlbinlog: %s", script)
+	}
+
+	if volumes := getTabletBinlogReplayVolumes(tablet); len(volumes) != 1 || volumes[0].DownwardAPI == nil {
+		t.Errorf("Binlog source volume missing: %+v", volumes)
+	}
+}
+
+// TestTabletBinlogReplay makes sure that a pod restored to a point in time gets the master of the binlog source
+// cluster to replay binlogs from, and only completes its restore once the replay is done
+func TestTabletBinlogReplay(t *testing.T) {
+	cluster, _, tablet := newReparentTestShard(2, "vt-zone1-main-0-replica-0")
+	tablet.SetRestore(&vitessv1alpha2.VitessRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "main-from-prod", Namespace: "vitess"},
+		Spec: vitessv1alpha2.VitessRestoreSpec{
+			Source: vitessv1alpha2.VitessBackupStorage{
+				S3: &vitessv1alpha2.S3BackupStorage{Bucket: "prod-backups"},
+			},
+			RestoreToPosition:   "MySQL56/00000000-0000-0000-0000-000000000000:1-100",
+			BinlogSourceCluster: "prod",
+		},
+	})
+	statefulSetName := tablet.GetStatefulSetName()
+
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: statefulSetName, Namespace: "vitess"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: tablet.GetReplicas(),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tabletname": tablet.GetName()}},
+		},
+	}
+
+	backup := "2019-01-30.030000.zone1-0000000100"
+	cluster.Status.Tablets = map[string]*vitessv1alpha2.VitessTabletStatus{
+		statefulSetName: {
+			Restores: []vitessv1alpha2.TabletRestoreStatus{
+				{PodName: statefulSetName + "-0", Backup: backup, BinlogsReplayed: true, Complete: true},
+				{PodName: statefulSetName + "-1", Backup: backup},
+			},
+		},
+	}
+
+	objs := []runtime.Object{
+		&vitessv1alpha2.VitessCluster{ObjectMeta: *cluster.ObjectMeta.DeepCopy()},
+		&vitessv1alpha2.VitessCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "vitess"},
+			Spec: vitessv1alpha2.VitessClusterSpec{
+				Cells: []*vitessv1alpha2.VitessCell{{ObjectMeta: metav1.ObjectMeta{Name: "zone1"}}},
+			},
+		},
+		newTabletPod(statefulSetName, statefulSetName+"-0", "rev1"),
+		newTabletPod(statefulSetName, statefulSetName+"-1", "rev1"),
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessCluster{})
+
+	cl := fake.NewFakeClient(objs...)
+	r := &ReconcileVitessCluster{client: cl, scheme: s, recorder: &record.FakeRecorder{}}
+
+	restoredVtctld := &vtctld.FakeClient{
+		Tablets: []vtctld.Tablet{
+			{Alias: "zone1-0000000100", Type: "master", Hostname: "vt-zone1-main-0-replica-0.vt-tab"},
+			{Alias: "zone1-0000000101", Type: "replica", Hostname: "vt-zone1-main-0-replica-1.vt-tab"},
+		},
+		Lags: map[string]uint32{},
+	}
+	sourceVtctld := &vtctld.FakeClient{
+		Tablets: []vtctld.Tablet{
+			{Alias: "zone1-0000000200", Type: "replica", Hostname: "prod-zone1-main-0-replica-0.prod-tab"},
+			{Alias: "zone1-0000000201", Type: "master", Hostname: "prod-zone1-main-0-replica-1.prod-tab"},
+		},
+	}
+
+	defer func() { newVtctldClient = vtctld.NewClient }()
+	newVtctldClient = func(address string) vtctld.Client {
+		if strings.HasPrefix(address, "prod-") {
+			return sourceVtctld
+		}
+		return restoredVtctld
+	}
+
+	logs := ""
+	defer func() { readPodLogs = defaultReadPodLogs }()
+	readPodLogs = func(config *rest.Config, pod *corev1.Pod, container string, limitBytes int64) ([]byte, error) {
+		if pod.GetName() != statefulSetName+"-1" || container != BinlogReplayContainerName {
+			t.Errorf("Wrong logs read: %s/%s", pod.GetName(), container)
+		}
+		return []byte(logs), nil
+	}
+
+	if res, err := r.ReconcileTabletRestores(tablet, statefulSet); err != nil || !res.Requeue {
+		t.Fatalf("Replaying restore was not requeued: %v, %v", res, err)
+	}
+
+	pod := &corev1.Pod{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: statefulSetName + "-1", Namespace: "vitess"}, pod); err != nil {
+		t.Fatalf("Error getting pod: %s", err)
+	}
+
+	if source := pod.GetAnnotations()[vitessv1alpha2.BinlogSourceAnnotation]; source != "prod-zone1-main-0-replica-1.prod-tab" {
+		t.Fatalf("Wrong binlog source. Got: %q; Expected: the master of the source shard", source)
+	}
+
+	// Caught up on replication, but still replaying
+	if _, err := r.ReconcileTabletRestores(tablet, statefulSet); err != nil {
+		t.Fatalf("Error reconciling tablet restores: %s", err)
+	}
+
+	if restores := cluster.GetTabletStatus(tablet).Restores; restores[1].BinlogsReplayed || restores[1].Complete {
+		t.Fatalf("Restore complete before the binlogs were replayed: %+v", restores[1])
+	}
+
+	logs = "Binlog replay complete\n"
+	if res, err := r.ReconcileTabletRestores(tablet, statefulSet); err != nil || res.Requeue {
+		t.Fatalf("Completed restore was requeued: %v, %v", res, err)
+	}
+
+	if restores := cluster.GetTabletStatus(tablet).Restores; !restores[1].BinlogsReplayed || !restores[1].Complete {
+		t.Errorf("Restore not complete after the binlogs were replayed: %+v", restores[1])
+	}
+}
+
+func TestGetBinlogReplayOutcome(t *testing.T) {
+	if replayed, failure := getBinlogReplayOutcome([]byte("")); replayed || failure != "" {
+		t.Errorf("Replay in progress reported as done: %v, %q", replayed, failure)
+	}
+
+	if replayed, failure := getBinlogReplayOutcome([]byte("Binlog replay complete\n")); !replayed || failure != "" {
+		t.Errorf("Replay not reported as done: %v, %q", replayed, failure)
+	}
+
+	logs := []byte("Binlog replay failed: the restored backup is past the restore position\n")
+	if replayed, failure := getBinlogReplayOutcome(logs); replayed || failure != "the restored backup is past the restore position" {
+		t.Errorf("Replay failure not reported: %v, %q", replayed, failure)
+	}
+}
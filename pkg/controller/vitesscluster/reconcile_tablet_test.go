@@ -0,0 +1,331 @@
+package vitesscluster
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	"vitess.io/vitess-operator/pkg/normalizer"
+)
+
+// TestTabletVolumeClaim makes sure that the vtdataroot claim is inherited from the keyspace and shard defaults
+func TestTabletVolumeClaim(t *testing.T) {
+	_, shard, tablet := newReparentTestShard(1, "")
+
+	getClaim := func() corev1.PersistentVolumeClaim {
+		statefulSet, err := getStatefulSetForTablet(tablet)
+		if err != nil {
+			t.Fatalf("Error generating tablet StatefulSet: %s", err)
+		}
+		return statefulSet.Spec.VolumeClaimTemplates[0]
+	}
+
+	claim := getClaim()
+	if size := claim.Spec.Resources.Requests[corev1.ResourceStorage]; size.String() != vitessv1alpha2.TabletVolumeSizeDefault {
+		t.Errorf("Wrong default claim size. Got: %s; Expected: %s", size.String(), vitessv1alpha2.TabletVolumeSizeDefault)
+	}
+
+	if len(claim.Spec.AccessModes) != 1 || claim.Spec.AccessModes[0] != corev1.ReadWriteOnce {
+		t.Errorf("Wrong default claim access modes: %v", claim.Spec.AccessModes)
+	}
+
+	storageClass := "fast"
+	tablet.Keyspace().Spec.Defaults = &vitessv1alpha2.VitessShardOptions{
+		VolumeClaim: &vitessv1alpha2.TabletVolumeClaim{
+			Labels: map[string]string{"backup": "daily"},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				StorageClassName: &storageClass,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("50Gi")},
+				},
+			},
+		},
+	}
+
+	claim = getClaim()
+	if claim.Spec.StorageClassName == nil || *claim.Spec.StorageClassName != storageClass {
+		t.Errorf("Storage class not inherited from the keyspace: %v", claim.Spec.StorageClassName)
+	}
+
+	if size := claim.Spec.Resources.Requests[corev1.ResourceStorage]; size.String() != "50Gi" {
+		t.Errorf("Wrong claim size. Got: %s; Expected: 50Gi", size.String())
+	}
+
+	if claim.GetLabels()["backup"] != "daily" || claim.GetName() != "vtdataroot" {
+		t.Errorf("Claim metadata not set: %v", claim.ObjectMeta)
+	}
+
+	// The shard defaults are more specific than the keyspace defaults
+	shard.Spec.Defaults = &vitessv1alpha2.VitessShardOptions{
+		VolumeClaim: &vitessv1alpha2.TabletVolumeClaim{
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+			},
+		},
+	}
+
+	claim = getClaim()
+	if claim.Spec.StorageClassName != nil || claim.Spec.AccessModes[0] != corev1.ReadWriteMany {
+		t.Errorf("Shard claim did not override the keyspace claim: %v", claim.Spec)
+	}
+
+	if tablet.Keyspace().Spec.Defaults.VolumeClaim.Spec.AccessModes != nil {
+		t.Error("Defaults were written back to the keyspace claim")
+	}
+}
+
+// TestTabletBackupStorage makes sure that the cluster backup storage is passed to vttablet and vtctld
+func TestTabletBackupStorage(t *testing.T) {
+	cluster, _, tablet := newReparentTestShard(1, "")
+	cluster.Spec.BackupStorage = &vitessv1alpha2.VitessBackupStorage{
+		File: &vitessv1alpha2.FileBackupStorage{ClaimName: "backups"},
+	}
+
+	statefulSet, err := getStatefulSetForTablet(tablet)
+	if err != nil {
+		t.Fatalf("Error generating tablet StatefulSet: %s", err)
+	}
+
+	hasBackupVolume := func(volumes []corev1.Volume) bool {
+		for _, volume := range volumes {
+			if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == "backups" {
+				return true
+			}
+		}
+		return false
+	}
+
+	hasBackupMount := func(mounts []corev1.VolumeMount) bool {
+		for _, mount := range mounts {
+			if mount.MountPath == vitessv1alpha2.BackupMountPath {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !hasBackupVolume(statefulSet.Spec.Template.Spec.Volumes) {
+		t.Error("Backup claim is not a volume of the tablet pods")
+	}
+
+	for _, container := range statefulSet.Spec.Template.Spec.Containers {
+		if container.Name != "vttablet" {
+			continue
+		}
+
+		script := container.Args[len(container.Args)-1]
+		if !strings.Contains(script, `-backup_storage_implementation="file"`) || !strings.Contains(script, `-file_backup_storage_root="/vt/backups"`) {
+			t.Errorf("Backup flags missing from the vttablet start script: %s", script)
+		}
+
+		if !hasBackupMount(container.VolumeMounts) {
+			t.Error("Backup volume is not mounted in the vttablet container")
+		}
+	}
+
+	deployment, _, err := GetCellVTctldResources(tablet.Cell())
+	if err != nil {
+		t.Fatalf("Error generating vtctld resources: %s", err)
+	}
+
+	if !hasBackupVolume(deployment.Spec.Template.Spec.Volumes) || !hasBackupMount(deployment.Spec.Template.Spec.Containers[0].VolumeMounts) {
+		t.Error("Backup volume is not mounted in vtctld")
+	}
+
+	if script := deployment.Spec.Template.Spec.Containers[0].Args[1]; !strings.Contains(script, `-backup_storage_implementation="file"`) {
+		t.Errorf("Backup flags missing from the vtctld start script: %s", script)
+	}
+
+	// Without backup storage no flags are passed
+	cluster.Spec.BackupStorage = nil
+	statefulSet, err = getStatefulSetForTablet(tablet)
+	if err != nil {
+		t.Fatalf("Error generating tablet StatefulSet: %s", err)
+	}
+
+	if hasBackupVolume(statefulSet.Spec.Template.Spec.Volumes) {
+		t.Error("Backup volume added without backup storage")
+	}
+
+	for _, container := range statefulSet.Spec.Template.Spec.Containers {
+		if strings.Contains(strings.Join(container.Args, " "), "backup_storage_implementation") {
+			t.Errorf("Backup flags added to %s without backup storage", container.Name)
+		}
+	}
+}
+
+// TestTabletBackupStorageInheritance makes sure that keyspaces and shards can replace the cluster backup storage
+func TestTabletBackupStorageInheritance(t *testing.T) {
+	cluster, shard, tablet := newReparentTestShard(1, "")
+	cluster.Spec.BackupStorage = &vitessv1alpha2.VitessBackupStorage{
+		File: &vitessv1alpha2.FileBackupStorage{ClaimName: "backups"},
+	}
+	tablet.Keyspace().Spec.Defaults = &vitessv1alpha2.VitessShardOptions{
+		BackupStorage: &vitessv1alpha2.VitessBackupStorage{
+			S3: &vitessv1alpha2.S3BackupStorage{
+				Bucket:                   "vitess-backups",
+				Region:                   "us-east-1",
+				Endpoint:                 "https://minio:9000",
+				ForcePathStyle:           true,
+				AccessKeyIDSecretRef:     &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "s3"}, Key: "id"},
+				SecretAccessKeySecretRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "s3"}, Key: "secret"},
+			},
+		},
+	}
+
+	getContainers := func() map[string]corev1.Container {
+		statefulSet, err := getStatefulSetForTablet(tablet)
+		if err != nil {
+			t.Fatalf("Error generating tablet StatefulSet: %s", err)
+		}
+
+		containers := map[string]corev1.Container{}
+		for _, container := range statefulSet.Spec.Template.Spec.Containers {
+			containers[container.Name] = container
+		}
+		return containers
+	}
+
+	hasEnv := func(container corev1.Container, name string) bool {
+		for _, env := range container.Env {
+			if env.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	containers := getContainers()
+	script := containers["vttablet"].Args[len(containers["vttablet"].Args)-1]
+	for _, flag := range []string{
+		`-backup_storage_implementation="s3"`,
+		`-s3_backup_storage_bucket="vitess-backups"`,
+		`-s3_backup_aws_region="us-east-1"`,
+		`-s3_backup_aws_endpoint="https://minio:9000"`,
+		`-s3_backup_force_path_style=true`,
+	} {
+		if !strings.Contains(script, flag) {
+			t.Errorf("Flag %s missing from the vttablet start script: %s", flag, script)
+		}
+	}
+
+	if strings.Contains(script, "file_backup_storage_root") {
+		t.Error("Keyspace backup storage did not replace the cluster backup storage")
+	}
+
+	for _, name := range []string{"vttablet", "mysql"} {
+		if !hasEnv(containers[name], "AWS_ACCESS_KEY_ID") || !hasEnv(containers[name], "AWS_SECRET_ACCESS_KEY") {
+			t.Errorf("S3 credentials not passed to the %s container", name)
+		}
+	}
+
+	// mysqlctld gets the same backup flags as vttablet
+	script = containers["mysql"].Args[len(containers["mysql"].Args)-1]
+	if !strings.Contains(script, `-backup_storage_implementation="s3"`) || !strings.Contains(script, `-s3_backup_storage_bucket="vitess-backups"`) {
+		t.Errorf("Wrong backup flags in the mysql start script: %s", script)
+	}
+
+	shard.Spec.Defaults = &vitessv1alpha2.VitessShardOptions{
+		BackupStorage: &vitessv1alpha2.VitessBackupStorage{
+			GCS: &vitessv1alpha2.GCSBackupStorage{
+				Bucket:               "vitess-backups",
+				CredentialsSecretRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "gcs"}, Key: "key.json"},
+			},
+		},
+	}
+
+	containers = getContainers()
+	script = containers["vttablet"].Args[len(containers["vttablet"].Args)-1]
+	if !strings.Contains(script, `-gcs_backup_storage_bucket="vitess-backups"`) {
+		t.Errorf("Shard backup storage did not replace the keyspace backup storage: %s", script)
+	}
+
+	if !hasEnv(containers["vttablet"], "GOOGLE_APPLICATION_CREDENTIALS") || hasEnv(containers["vttablet"], "AWS_ACCESS_KEY_ID") {
+		t.Errorf("Wrong backup credentials in the vttablet container: %v", containers["vttablet"].Env)
+	}
+
+	mounted := false
+	for _, mount := range containers["vttablet"].VolumeMounts {
+		if mount.MountPath == vitessv1alpha2.BackupCredentialsMountPath {
+			mounted = true
+		}
+	}
+
+	if !mounted {
+		t.Error("GCS credentials are not mounted in the vttablet container")
+	}
+}
+
+// TestTabletUIDKeptOnUpgrade makes sure that tablets created before the operator computed their UIDs keep them
+func TestTabletUIDKeptOnUpgrade(t *testing.T) {
+	cluster, shard, tablet := newReparentTestShard(1, "")
+	tablet.Keyspace().Spec.Shards = []*vitessv1alpha2.VitessShard{shard}
+	cluster.Spec.Keyspaces = []*vitessv1alpha2.VitessKeyspace{tablet.Keyspace()}
+
+	// The StatefulSet of an older operator has no UID base annotation
+	statefulSet, err := getStatefulSetForTablet(tablet)
+	if err != nil {
+		t.Fatalf("Error generating tablet StatefulSet: %s", err)
+	}
+	statefulSet.Namespace = cluster.GetNamespace()
+	statefulSet.Annotations = nil
+
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessCluster{})
+
+	cl := fake.NewFakeClient(&vitessv1alpha2.VitessCluster{ObjectMeta: *cluster.ObjectMeta.DeepCopy()}, statefulSet)
+	r := &ReconcileVitessCluster{client: cl, scheme: s, recorder: record.NewFakeRecorder(10)}
+
+	if err := normalizer.New(cl).NormalizeClusterTabletUIDs(cluster); err != nil {
+		t.Fatalf("Error normalizing tablet UIDs: %s", err)
+	}
+
+	// The old init script hashed zone1-<StatefulSet name>
+	if base := tablet.GetTabletUIDBase(); base != tablet.GetLegacyTabletUIDBase() {
+		t.Errorf("Existing tablet changed UID base: Got: %d; Expected: %d", base, tablet.GetLegacyTabletUIDBase())
+	}
+
+	if _, err := r.ReconcileTabletResources(tablet); err != nil {
+		t.Fatalf("Error reconciling tablet: %s", err)
+	}
+
+	found := &appsv1.StatefulSet{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: statefulSet.GetName(), Namespace: cluster.GetNamespace()}, found); err != nil {
+		t.Fatalf("Error getting tablet StatefulSet: %s", err)
+	}
+
+	expected := strconv.FormatUint(uint64(tablet.GetLegacyTabletUIDBase()), 10)
+	if base := found.GetAnnotations()[vitessv1alpha2.TabletUIDBaseAnnotation]; base != expected {
+		t.Errorf("UID base not recorded on the existing StatefulSet: Got: %q; Expected: %q", base, expected)
+	}
+
+	for _, container := range found.Spec.Template.Spec.InitContainers {
+		if script := container.Args[len(container.Args)-1]; strings.Contains(script, "tablet_uid=") && !strings.Contains(script, "tablet_uid=$(("+expected+" + $pod_index))") {
+			t.Errorf("Init script does not use the recorded UID base: %s", script)
+		}
+	}
+
+	// Later reconciles read the recorded UID base, even once the tablet is given an ID
+	cluster, shard, tablet = newReparentTestShard(1, "")
+	tablet.Keyspace().Spec.Shards = []*vitessv1alpha2.VitessShard{shard}
+	cluster.Spec.Keyspaces = []*vitessv1alpha2.VitessKeyspace{tablet.Keyspace()}
+	tablet.Spec.TabletID = 7
+
+	if err := normalizer.New(cl).NormalizeClusterTabletUIDs(cluster); err != nil {
+		t.Fatalf("Error normalizing tablet UIDs: %s", err)
+	}
+
+	if uid := tablet.GetTabletUID(0); strconv.FormatUint(uint64(uid), 10) != expected {
+		t.Errorf("Existing tablet changed UID: Got: %d; Expected: %s", uid, expected)
+	}
+}
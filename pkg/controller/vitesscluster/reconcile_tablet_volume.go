@@ -0,0 +1,149 @@
+package vitesscluster
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+)
+
+// TabletVolumeRequeueInterval is how often claims are checked on while they are being resized
+const TabletVolumeRequeueInterval = 30 * time.Second
+
+// ReconcileTabletVolumes grows the vtdataroot claims of the existing tablet pods when the requested storage
+// is raised. The claim templates of a StatefulSet can't be changed, so the claims are resized in place,
+// which needs a storage class that allows volume expansion. Claims are never shrunk.
+func (r *ReconcileVitessCluster) ReconcileTabletVolumes(tablet *vitessv1alpha2.VitessTablet, statefulSet *appsv1.StatefulSet) (reconcile.Result, error) {
+	requested := tablet.GetVolumeClaim().Spec.Resources.Requests[corev1.ResourceStorage]
+
+	volumeStatus := &vitessv1alpha2.TabletVolumeStatus{
+		RequestedSize: requested.String(),
+	}
+
+	// The StatefulSet keeps the claims of pods that were scaled down, and they come back with their pods,
+	// so every claim of the StatefulSet is resized and not only the claims of the current replicas
+	claims, err := r.getTabletClaims(statefulSet)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	for i := range claims {
+		claim := &claims[i]
+		name := claim.GetName()
+
+		current := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+		if current.Cmp(requested) < 0 {
+			if err := r.checkClaimExpandable(claim); err != nil {
+				log.Error(err, "Unable to resize tablet claim", "Namespace", claim.GetNamespace(), "VitessCluster.Name", tablet.Cluster().GetName(), "Tablet.Name", tablet.GetName(), "Claim.Name", name)
+				volumeStatus.Error = err.Error()
+				break
+			}
+
+			log.Info("Resizing tablet claim", "Namespace", claim.GetNamespace(), "VitessCluster.Name", tablet.Cluster().GetName(), "Tablet.Name", tablet.GetName(), "Claim.Name", name, "From", current.String(), "To", requested.String())
+
+			if claim.Spec.Resources.Requests == nil {
+				claim.Spec.Resources.Requests = corev1.ResourceList{}
+			}
+			claim.Spec.Resources.Requests[corev1.ResourceStorage] = requested
+			if err := r.client.Update(context.TODO(), claim); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+
+		// The capacity is only known once the claim is bound, and catches up once the volume and filesystem are resized
+		if capacity, ok := claim.Status.Capacity[corev1.ResourceStorage]; ok && capacity.Cmp(requested) < 0 {
+			volumeStatus.Resizing = append(volumeStatus.Resizing, name)
+		}
+	}
+
+	status := tablet.Cluster().GetTabletStatus(tablet).DeepCopy()
+	if !reflect.DeepEqual(status.Volume, volumeStatus) {
+		status.Volume = volumeStatus
+		if err := r.setTabletStatus(tablet, status); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if len(volumeStatus.Resizing) > 0 && volumeStatus.Error == "" {
+		return reconcile.Result{Requeue: true, RequeueAfter: TabletVolumeRequeueInterval}, nil
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// checkClaimExpandable returns an error if the storage class of the claim doesn't allow volume expansion
+func (r *ReconcileVitessCluster) checkClaimExpandable(claim *corev1.PersistentVolumeClaim) error {
+	if claim.Spec.StorageClassName == nil || *claim.Spec.StorageClassName == "" {
+		return fmt.Errorf("Claim %s has no storage class and can't be expanded", claim.GetName())
+	}
+
+	// Storage classes are cluster-scoped and not in the cache, which only watches the operator namespace
+	storageClass := &storagev1.StorageClass{}
+	if err := r.getReader().Get(context.TODO(), types.NamespacedName{Name: *claim.Spec.StorageClassName}, storageClass); err != nil {
+		return fmt.Errorf("Unable to get storage class %s: %s", *claim.Spec.StorageClassName, err)
+	}
+
+	if storageClass.AllowVolumeExpansion == nil || !*storageClass.AllowVolumeExpansion {
+		return fmt.Errorf("Storage class %s does not allow volume expansion", storageClass.GetName())
+	}
+
+	return nil
+}
+
+// setTabletStatus records the tablet status in the normalized cluster and in the stored one
+func (r *ReconcileVitessCluster) setTabletStatus(tablet *vitessv1alpha2.VitessTablet, status *vitessv1alpha2.VitessTabletStatus) error {
+	cluster := tablet.Cluster()
+	cluster.SetTabletStatus(tablet, status)
+
+	if err := r.updateClusterStatus(cluster, func(foundCluster *vitessv1alpha2.VitessCluster) (bool, error) {
+		foundCluster.SetTabletStatus(tablet, status.DeepCopy())
+		return true, nil
+	}); err != nil {
+		log.Error(err, "Failed to update VitessCluster tablet status")
+		return err
+	}
+
+	return nil
+}
+
+// getTabletClaims returns the vtdataroot claims the StatefulSet created, sorted by ordinal
+func (r *ReconcileVitessCluster) getTabletClaims(statefulSet *appsv1.StatefulSet) ([]corev1.PersistentVolumeClaim, error) {
+	list := &corev1.PersistentVolumeClaimList{}
+	if err := r.client.List(context.TODO(), &client.ListOptions{Namespace: statefulSet.GetNamespace()}, list); err != nil {
+		return nil, err
+	}
+
+	ordinals := map[string]int32{}
+	claims := []corev1.PersistentVolumeClaim{}
+	for _, claim := range list.Items {
+		if !strings.HasPrefix(claim.GetName(), tabletClaimPrefix) {
+			continue
+		}
+
+		// Claims are named after the pod they belong to
+		if ordinal, ok := getPodOrdinal(statefulSet.GetName(), strings.TrimPrefix(claim.GetName(), tabletClaimPrefix)); ok {
+			ordinals[claim.GetName()] = ordinal
+			claims = append(claims, claim)
+		}
+	}
+
+	sort.Slice(claims, func(i, j int) bool {
+		return ordinals[claims[i].GetName()] < ordinals[claims[j].GetName()]
+	})
+
+	return claims, nil
+}
+
+// tabletClaimPrefix is the prefix the StatefulSet gives the names of the claims of its vtdataroot claim template
+const tabletClaimPrefix = "vtdataroot-"
@@ -0,0 +1,163 @@
+package vitesscluster
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+)
+
+// newTabletClaim returns a bound vtdataroot claim of the given size
+func newTabletClaim(name string, storageClass string, size string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "vitess"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &storageClass,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+		},
+	}
+}
+
+// TestTabletVolumeExpansion makes sure that existing claims are resized when the requested storage grows
+func TestTabletVolumeExpansion(t *testing.T) {
+	var (
+		replicas       int32 = 2
+		expand               = true
+		noExpand             = false
+		claimName            = "vtdataroot-vt-zone1-main-0-replica-0"
+		scaledDownName       = "vtdataroot-vt-zone1-main-0-replica-3"
+		otherName            = "vtdataroot-vt-zone1-main-0-rdonly-0"
+	)
+
+	for _, tc := range []struct {
+		allowExpansion *bool
+		resized        bool
+	}{
+		{&expand, true},
+		{&noExpand, false},
+		{nil, false},
+	} {
+		cluster, _, tablet := newReparentTestShard(replicas, "")
+		tablet.Spec.VolumeClaim = &vitessv1alpha2.TabletVolumeClaim{
+			Spec: corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("20Gi")},
+				},
+			},
+		}
+
+		statefulSet := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: tablet.GetStatefulSetName(), Namespace: "vitess"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+		}
+
+		s := scheme.Scheme
+		s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessCluster{})
+
+		// The second pod hasn't been created yet, so it has no claim, and the fourth pod was scaled down
+		// but kept its claim
+		cl := fake.NewFakeClient(
+			&vitessv1alpha2.VitessCluster{ObjectMeta: *cluster.ObjectMeta.DeepCopy()},
+			newTabletClaim(claimName, "ssd", "10Gi"),
+			newTabletClaim(scaledDownName, "ssd", "10Gi"),
+			newTabletClaim(otherName, "ssd", "10Gi"),
+		)
+
+		// The storage class is cluster-scoped, so only the reader finds it and not the namespaced cache
+		reader := fake.NewFakeClient(
+			&vitessv1alpha2.VitessCluster{ObjectMeta: *cluster.ObjectMeta.DeepCopy()},
+			&storagev1.StorageClass{
+				ObjectMeta:           metav1.ObjectMeta{Name: "ssd"},
+				AllowVolumeExpansion: tc.allowExpansion,
+			},
+		)
+		r := &ReconcileVitessCluster{client: cl, reader: reader, scheme: s, recorder: &record.FakeRecorder{}}
+
+		res, err := r.ReconcileTabletVolumes(tablet, statefulSet)
+		if err != nil {
+			t.Fatalf("Error reconciling tablet volumes: %s", err)
+		}
+
+		claim := &corev1.PersistentVolumeClaim{}
+		if err := cl.Get(context.TODO(), types.NamespacedName{Name: claimName, Namespace: "vitess"}, claim); err != nil {
+			t.Fatalf("Error getting claim: %s", err)
+		}
+
+		found := &vitessv1alpha2.VitessCluster{}
+		if err := cl.Get(context.TODO(), types.NamespacedName{Name: "vt", Namespace: "vitess"}, found); err != nil {
+			t.Fatalf("Error getting cluster: %s", err)
+		}
+
+		volumeStatus := found.GetTabletStatus(tablet).Volume
+		if volumeStatus == nil {
+			t.Fatal("Tablet volume status was not recorded")
+		}
+
+		size := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+		if !tc.resized {
+			if size.String() != "10Gi" {
+				t.Errorf("Claim resized with a storage class that doesn't allow expansion: %s", size.String())
+			}
+
+			if !strings.Contains(volumeStatus.Error, "does not allow volume expansion") {
+				t.Errorf("Expansion error not recorded in the tablet status: %+v", volumeStatus)
+			}
+			continue
+		}
+
+		if size.String() != "20Gi" {
+			t.Errorf("Claim not resized. Got: %s; Expected: 20Gi", size.String())
+		}
+
+		if !res.Requeue || !reflect.DeepEqual(volumeStatus.Resizing, []string{claimName, scaledDownName}) || volumeStatus.RequestedSize != "20Gi" {
+			t.Errorf("Resize progress not tracked: %+v", volumeStatus)
+		}
+
+		other := &corev1.PersistentVolumeClaim{}
+		if err := cl.Get(context.TODO(), types.NamespacedName{Name: otherName, Namespace: "vitess"}, other); err != nil {
+			t.Fatalf("Error getting claim: %s", err)
+		}
+
+		if size := other.Spec.Resources.Requests[corev1.ResourceStorage]; size.String() != "10Gi" {
+			t.Errorf("Claim of another tablet resized: %s", size.String())
+		}
+
+		// The volumes have been resized
+		for _, name := range []string{claimName, scaledDownName} {
+			claim := &corev1.PersistentVolumeClaim{}
+			if err := cl.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "vitess"}, claim); err != nil {
+				t.Fatalf("Error getting claim: %s", err)
+			}
+
+			claim.Status.Capacity[corev1.ResourceStorage] = resource.MustParse("20Gi")
+			if err := cl.Update(context.TODO(), claim); err != nil {
+				t.Fatalf("Error updating claim: %s", err)
+			}
+		}
+
+		if res, err := r.ReconcileTabletVolumes(tablet, statefulSet); err != nil || res.Requeue {
+			t.Errorf("Tablet volumes still reconciling after the resize finished: %v, %v", res, err)
+		}
+
+		if status := cluster.GetTabletStatus(tablet).Volume; len(status.Resizing) != 0 {
+			t.Errorf("Finished resize still tracked: %+v", status)
+		}
+	}
+}
@@ -1,5 +1,9 @@
 package vitesscluster
 
+import (
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
 func getInt32Ptr(id int32) *int32 {
 	return &id
 }
@@ -7,3 +11,19 @@ func getInt32Ptr(id int32) *int32 {
 func getInt64Ptr(id int64) *int64 {
 	return &id
 }
+
+// mergeResults returns a result that requeues if either result does, after the shorter of their delays
+func mergeResults(a, b reconcile.Result) reconcile.Result {
+	if !a.Requeue && a.RequeueAfter == 0 {
+		return b
+	}
+	if !b.Requeue && b.RequeueAfter == 0 {
+		return a
+	}
+
+	merged := reconcile.Result{Requeue: a.Requeue || b.Requeue, RequeueAfter: a.RequeueAfter}
+	if b.RequeueAfter < merged.RequeueAfter {
+		merged.RequeueAfter = b.RequeueAfter
+	}
+	return merged
+}
@@ -3,46 +3,78 @@ package vitesscluster
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"time"
 
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
 	"vitess.io/vitess-operator/pkg/normalizer"
+	"vitess.io/vitess-operator/pkg/util/metrics"
 )
 
 var log = logf.Log.WithName("controller_vitesscluster")
 
+// ClusterStatusRequeueInterval is how often the status of a cluster is refreshed when nothing else requeues it.
+// The replication lag and serving state of the tablet pods only change in vtctld, which can't be watched.
+const ClusterStatusRequeueInterval = time.Minute
+
+// specChangedPredicate skips updates of the operator's own resources that only change their status or
+// annotations. The operator writes the status of the cluster and of its standalone keyspaces, shards and
+// tablets on every reconcile, which would otherwise requeue the cluster right away. Labels are still
+// compared since selectors match on them.
+var specChangedPredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return e.MetaOld.GetGeneration() != e.MetaNew.GetGeneration() ||
+			!reflect.DeepEqual(e.MetaOld.GetLabels(), e.MetaNew.GetLabels())
+	},
+}
+
 // Add creates a new VitessCluster Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
-	return add(mgr, newReconciler(mgr))
+	r, err := newReconciler(mgr)
+	if err != nil {
+		return err
+	}
+	return add(mgr, r)
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcileVitessCluster{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+func newReconciler(mgr manager.Manager) (reconcile.Reconciler, error) {
+	reader, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return nil, err
+	}
+	return &ReconcileVitessCluster{client: mgr.GetClient(), reader: reader, scheme: mgr.GetScheme(), recorder: mgr.GetRecorder("vitesscluster-controller"), config: mgr.GetConfig()}, nil
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	// Create a new controller
-	c, err := controller.New("vitesscluster-controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New("vitesscluster-controller", mgr, controller.Options{Reconciler: metrics.InstrumentReconciler("vitesscluster-controller", r)})
 	if err != nil {
 		return err
 	}
 
 	// Watch for changes to primary resource VitessCluster
-	err = c.Watch(&source.Kind{Type: &vitessv1alpha2.VitessCluster{}}, &handler.EnqueueRequestForObject{})
+	err = c.Watch(&source.Kind{Type: &vitessv1alpha2.VitessCluster{}}, &handler.EnqueueRequestForObject{}, specChangedPredicate)
 	if err != nil {
 		return err
 	}
@@ -58,12 +90,56 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		err = c.Watch(&source.Kind{Type: childType}, &handler.EnqueueRequestForOwner{
 			IsController: true,
 			OwnerType:    &vitessv1alpha2.VitessCluster{},
-		})
+		}, specChangedPredicate)
 		if err != nil {
 			return err
 		}
 	}
 
+	// The cluster waits on the status of its cell registration Jobs, so every change to them is watched
+	err = c.Watch(&source.Kind{Type: &batchv1.Job{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &vitessv1alpha2.VitessCluster{},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Tablet pods are owned by their StatefulSets, so they are mapped back to the VitessCluster by label.
+	// This lets the operator reparent as soon as a master pod starts terminating.
+	err = c.Watch(&source.Kind{Type: &corev1.Pod{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+			podLabels := obj.Meta.GetLabels()
+			if podLabels["app"] != "vitess" || podLabels["component"] != "vttablet" || podLabels["cluster"] == "" {
+				return nil
+			}
+
+			return []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Name: podLabels["cluster"], Namespace: obj.Meta.GetNamespace()}},
+			}
+		}),
+	})
+	if err != nil {
+		return err
+	}
+
+	// A restore replaces the backup storage of the tablets it restores for as long as it exists
+	err = c.Watch(&source.Kind{Type: &vitessv1alpha2.VitessRestore{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+			restore, ok := obj.Object.(*vitessv1alpha2.VitessRestore)
+			if !ok {
+				return nil
+			}
+
+			return []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Name: restore.Spec.Cluster, Namespace: restore.GetNamespace()}},
+			}
+		}),
+	})
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -73,70 +149,114 @@ var _ reconcile.Reconciler = &ReconcileVitessCluster{}
 type ReconcileVitessCluster struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client client.Client
-	scheme *runtime.Scheme
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+
+	// reader reads from the apiserver directly. The cluster status is written several times per reconcile,
+	// and the cache lags behind those writes. Cluster-scoped objects, like storage classes, are read with it
+	// too, since the cache only watches the operator namespace. It falls back to the client when it isn't set.
+	reader client.Reader
+
+	// config is used for the pod logs, which the client can't read
+	config *rest.Config
 }
 
 // Reconcile reads that state of the cluster for a VitessCluster object and makes changes based on the state read
 // and what is in the VitessCluster.Spec
 // The Controller will requeue the Request to be processed again if the returned error is non-nil or
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
-func (r *ReconcileVitessCluster) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+func (r *ReconcileVitessCluster) Reconcile(request reconcile.Request) (result reconcile.Result, err error) {
 	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
 	reqLogger.Info("Reconciling VitessCluster")
 
 	// Fetch the VitessCluster instance
 	cluster := &vitessv1alpha2.VitessCluster{}
-	err := r.client.Get(context.TODO(), request.NamespacedName, cluster)
+	err = r.client.Get(context.TODO(), request.NamespacedName, cluster)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
+			metrics.ForgetCluster(request.Namespace, request.Name, tabletPhaseLabels())
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
 		return reconcile.Result{}, err
 	}
 
+	// Record successful reconciles so that a cluster the operator is stuck on can be alerted on
+	defer func() {
+		if err == nil {
+			metrics.ClusterReconciled(cluster.GetNamespace(), cluster.GetName())
+		}
+	}()
+
 	n := normalizer.New(r.client)
 
 	// Check
 	if err := n.TestClusterSanity(cluster); err != nil {
 		reqLogger.Error(err, "Cluster failed sanity test")
+		r.recorder.Event(cluster, corev1.EventTypeWarning, "SanityCheckFailed", err.Error())
 		return reconcile.Result{Requeue: false}, err
 	}
 
 	// Normalize
 	if err := n.NormalizeCluster(cluster); err != nil {
+		r.recorder.Event(cluster, corev1.EventTypeWarning, "NormalizationFailed", err.Error())
+		return reconcile.Result{Requeue: false}, err
+	}
+
+	if err := n.NormalizeClusterRestores(cluster); err != nil {
+		r.recorder.Event(cluster, corev1.EventTypeWarning, "NormalizationFailed", err.Error())
+		return reconcile.Result{Requeue: false}, err
+	}
+
+	if err := n.NormalizeClusterTabletUIDs(cluster); err != nil {
+		r.recorder.Event(cluster, corev1.EventTypeWarning, "NormalizationFailed", err.Error())
 		return reconcile.Result{Requeue: false}, err
 	}
 
 	// Validate
 	if err := n.ValidateCluster(cluster); err != nil {
 		reqLogger.Error(err, "Cluster failed validation")
+		r.recorder.Event(cluster, corev1.EventTypeWarning, "ValidationFailed", err.Error())
+		metrics.RecordValidationError(normalizer.GetValidationErrorName(err))
 		return reconcile.Result{Requeue: false}, err
 	}
 
 	// Reconcile
-	if result, err := r.ReconcileClusterResources(cluster); err != nil {
+	result, err = r.ReconcileClusterResources(cluster)
+	if err != nil {
 		reqLogger.Info("Error reconciling cluster member resources")
 		return result, err
-	} else if result.Requeue {
-		reqLogger.Info("Requeue after reconciling cluster member resources")
-		return result, nil
 	}
 
 	// Status updates
 
+	// The summary is kept up to date while the resources are still converging, so that progress shows in the status
+	if err := r.ReconcileClusterStatus(cluster); err != nil {
+		reqLogger.Error(err, "Failed to update VitessCluster status summary")
+		return reconcile.Result{}, err
+	}
+
+	if result.Requeue {
+		reqLogger.Info("Requeue after reconciling cluster member resources")
+		return result, nil
+	}
+
 	switch cluster.Phase() {
 	// Set cluster status to Created if it's a new cluster
 	case vitessv1alpha2.ClusterPhaseNone:
-		r.SetClusterPhase(cluster, vitessv1alpha2.ClusterPhaseCreating)
+		if err := r.SetClusterPhase(cluster, vitessv1alpha2.ClusterPhaseCreating); err != nil {
+			return reconcile.Result{}, err
+		}
 	// Set a creating cluster status to Ready if all tablet sets are ready
 	case vitessv1alpha2.ClusterPhaseCreating:
 		if cluster.AllTabletsReady() {
-			r.SetClusterPhase(cluster, vitessv1alpha2.ClusterPhaseReady)
+			if err := r.SetClusterPhase(cluster, vitessv1alpha2.ClusterPhaseReady); err != nil {
+				return reconcile.Result{}, err
+			}
 		} else {
 			// Requeue to re-check for readiness later
 			reqLogger.Info("Cluster created but not ready. Will try again later.")
@@ -144,28 +264,53 @@ func (r *ReconcileVitessCluster) Reconcile(request reconcile.Request) (reconcile
 		}
 	}
 
-	// Nothing to do - don't reqeue
+	// Nothing to do - only requeue to refresh the status
 	reqLogger.Info("Skip reconcile: all managed services in sync")
-	return reconcile.Result{}, nil
+	return reconcile.Result{RequeueAfter: ClusterStatusRequeueInterval}, nil
 }
 
 func (r *ReconcileVitessCluster) SetClusterPhase(cluster *vitessv1alpha2.VitessCluster, p vitessv1alpha2.ClusterPhase) error {
 	log.Info(fmt.Sprintf("Setting VitessCluster to %s phase", p))
 
-	// Get latest cluster
-	foundCluster := &vitessv1alpha2.VitessCluster{}
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: cluster.GetName(), Namespace: cluster.GetNamespace()}, foundCluster); err != nil {
-		return err
-	}
-
-	// set phase
-	foundCluster.SetPhase(p)
+	cluster.SetPhase(p)
 
-	// update
-	if err := r.client.Status().Update(context.TODO(), foundCluster); err != nil {
+	if err := r.updateClusterStatus(cluster, func(foundCluster *vitessv1alpha2.VitessCluster) (bool, error) {
+		foundCluster.SetPhase(p)
+		return true, nil
+	}); err != nil {
 		log.Error(err, "Failed to update VitessCluster phase")
 		return err
 	}
 
 	return nil
 }
+
+// getReader returns the reader that reads from the apiserver directly, or the client if there is none
+func (r *ReconcileVitessCluster) getReader() client.Reader {
+	if r.reader == nil {
+		return r.client
+	}
+	return r.reader
+}
+
+// updateClusterStatus writes the status of a freshly fetched copy of the cluster once setStatus changed it,
+// since updating the normalized cluster directly would overwrite it with the stored object.
+// The copy is read from the apiserver and the update retried on conflicts, since the status of the cluster
+// is written several times per reconcile and the cluster may be edited in the meantime.
+func (r *ReconcileVitessCluster) updateClusterStatus(cluster *vitessv1alpha2.VitessCluster, setStatus func(foundCluster *vitessv1alpha2.VitessCluster) (bool, error)) error {
+	reader := r.getReader()
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		foundCluster := &vitessv1alpha2.VitessCluster{}
+		if err := reader.Get(context.TODO(), types.NamespacedName{Name: cluster.GetName(), Namespace: cluster.GetNamespace()}, foundCluster); err != nil {
+			return err
+		}
+
+		changed, err := setStatus(foundCluster)
+		if err != nil || !changed {
+			return err
+		}
+
+		return r.client.Status().Update(context.TODO(), foundCluster)
+	})
+}
@@ -1,20 +1,29 @@
 package vitesscluster
 
 import (
+	"context"
+	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	// logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 
 	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
 	"vitess.io/vitess-operator/pkg/normalizer"
+	"vitess.io/vitess-operator/pkg/util/vtctld"
 )
 
 // TestLockserverLockserverRefMutuallyExclusive makes sure that lockserver and lockserverRef are mutually exclusive
@@ -52,7 +61,7 @@ func TestLockserverLockserverRefMutuallyExclusive(t *testing.T) {
 	// Create a fake client to mock API calls.
 	cl := fake.NewFakeClient(objs...)
 	// Create a ReconcileVitessCluster object with the scheme and fake client.
-	r := &ReconcileVitessCluster{client: cl, scheme: s}
+	r := &ReconcileVitessCluster{client: cl, scheme: s, recorder: &record.FakeRecorder{}}
 
 	// Mock request to simulate Reconcile() being called on an event for a
 	// watched resource .
@@ -206,17 +215,319 @@ func TestTabletTemplates(t *testing.T) {
 		}
 
 		for _, container := range vttabletInitContainers {
-			// make sure that the etcdpath and etcdaddress end up in the generated scripts for the vttablet container
+			// cells are registered in the global topology by the operator, not by every tablet
 			if container.Name == "init-vttablet" {
-				if !strings.Contains(container.Args[len(container.Args)-1], etcd2Address) {
-					t.Fatalf("Generated start script for init-vttablet container does not contain the etcd address")
+				if strings.Contains(container.Args[len(container.Args)-1], "UpdateCellInfo") {
+					t.Fatalf("Generated start script for init-vttablet container still registers the cell")
 				}
 
-				// make sure that the etcdpath and etcdaddress end up in the generated scripts for the vttablet container
-				if !strings.Contains(container.Args[len(container.Args)-1], etcd2Path) {
-					t.Fatalf("Generated start script for init-vttablet container does not contain the etcd path")
+				// the tablet UIDs come from the tablet ID set in the spec
+				if !strings.Contains(container.Args[len(container.Args)-1], "tablet_uid=$((10100 + $pod_index))") {
+					t.Fatalf("Generated init script for init-vttablet container does not use the tablet ID: %s", container.Args[len(container.Args)-1])
 				}
 			}
 		}
 	}
 }
+
+// TestTabletNotCreatedWithoutLockserver makes sure that tablets are only created once their lockservers are available
+func TestTabletNotCreatedWithoutLockserver(t *testing.T) {
+	var (
+		namespace   = "vitess"
+		clusterName = "vitess-operator"
+	)
+
+	lockserver := &vitessv1alpha2.VitessLockserver{
+		Spec: vitessv1alpha2.VitessLockserverSpec{
+			Type: vitessv1alpha2.LockserverTypeEtcd2,
+			Etcd2: &vitessv1alpha2.Etcd2Lockserver{
+				Address: "etcd2.test.address:12345",
+				Path:    "/vitess/global",
+			},
+		},
+	}
+
+	cluster := &vitessv1alpha2.VitessCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterName,
+			Namespace: namespace,
+		},
+		Spec: vitessv1alpha2.VitessClusterSpec{
+			Lockserver: lockserver.DeepCopy(),
+			Cells: []*vitessv1alpha2.VitessCell{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "default",
+					},
+					Spec: vitessv1alpha2.VitessCellSpec{
+						Lockserver: lockserver.DeepCopy(),
+					},
+				},
+			},
+			Keyspaces: []*vitessv1alpha2.VitessKeyspace{
+				{
+					Spec: vitessv1alpha2.VitessKeyspaceSpec{
+						Shards: []*vitessv1alpha2.VitessShard{
+							{
+								Spec: vitessv1alpha2.VitessShardSpec{
+									Defaults: &vitessv1alpha2.VitessShardOptions{
+										Containers: &vitessv1alpha2.TabletContainers{
+											VTTablet: &vitessv1alpha2.VTTabletContainer{
+												Image: "test",
+											},
+											MySQL: &vitessv1alpha2.MySQLContainer{
+												Image: "test",
+											},
+										},
+									},
+									Tablets: []*vitessv1alpha2.VitessTablet{
+										{
+											Spec: vitessv1alpha2.VitessTabletSpec{
+												TabletID: 101,
+												CellID:   "default",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Register operator types with the runtime scheme.
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessCluster{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessClusterList{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessTablet{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessTabletList{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessShard{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessShardList{})
+
+	// Create a fake client to mock API calls.
+	cl := fake.NewFakeClient(cluster)
+	r := &ReconcileVitessCluster{client: cl, scheme: s, recorder: &record.FakeRecorder{}}
+
+	norm := normalizer.New(cl)
+	if err := norm.NormalizeCluster(cluster); err != nil {
+		t.Fatalf("Error normalizing cluster: %s", err)
+	}
+
+	tablet := cluster.Tablets()[0]
+
+	// Neither lockserver has been probed yet
+	res, err := r.ReconcileTabletResources(tablet)
+	if err != nil {
+		t.Fatalf("Error reconciling tablet: %s", err)
+	}
+
+	if !res.Requeue {
+		t.Error("Tablet reconcile did not requeue while waiting for the lockserver")
+	}
+
+	// The shard keeps the requeue of its waiting tablet
+	defer func() { newVtctldClient = vtctld.NewClient }()
+	newVtctldClient = func(address string) vtctld.Client {
+		return &vtctld.FakeClient{Master: "default-0000000101"}
+	}
+
+	if res, err := r.ReconcileShard(tablet.Shard()); err != nil || !res.Requeue {
+		t.Errorf("Shard reconcile did not requeue while its tablet waits for the lockserver: %v, %v", res, err)
+	}
+
+	statefulSet := &appsv1.StatefulSet{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: tablet.GetStatefulSetName(), Namespace: namespace}, statefulSet); err == nil {
+		t.Fatal("Tablet StatefulSet was created without an available lockserver")
+	}
+
+	// Mark both lockservers available
+	for _, ls := range []*vitessv1alpha2.VitessLockserver{cluster.Lockserver(), tablet.Lockserver()} {
+		ls.SetCondition(vitessv1alpha2.LockserverConditionAvailable, corev1.ConditionTrue, "LeaderElected", "")
+	}
+
+	if _, err := r.ReconcileTabletResources(tablet); err != nil {
+		t.Fatalf("Error reconciling tablet: %s", err)
+	}
+
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: tablet.GetStatefulSetName(), Namespace: namespace}, statefulSet); err != nil {
+		t.Fatalf("Tablet StatefulSet was not created with an available lockserver: %s", err)
+	}
+
+	// A cell without a lockserver of its own only waits for the cluster lockserver
+	if err := cl.Delete(context.TODO(), statefulSet); err != nil {
+		t.Fatalf("Error deleting StatefulSet: %s", err)
+	}
+	tablet.Cell().Spec.Lockserver = nil
+
+	if _, err := r.ReconcileTabletResources(tablet); err != nil {
+		t.Fatalf("Error reconciling tablet: %s", err)
+	}
+
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: tablet.GetStatefulSetName(), Namespace: namespace}, statefulSet); err != nil {
+		t.Errorf("Tablet StatefulSet was not created without a cell lockserver: %s", err)
+	}
+}
+
+// TestReferencedLockserverStatus makes sure that a referenced lockserver keeps its own status instead of the one
+// mirrored into the cluster status
+func TestReferencedLockserverStatus(t *testing.T) {
+	var (
+		namespace   = "vitess"
+		clusterName = "vitess-operator"
+	)
+
+	lockserver := &vitessv1alpha2.VitessLockserver{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "global",
+			Namespace: namespace,
+		},
+		Spec: vitessv1alpha2.VitessLockserverSpec{
+			Type: vitessv1alpha2.LockserverTypeEtcd2,
+			Etcd2: &vitessv1alpha2.Etcd2Lockserver{
+				Address: "etcd2.test.address:12345",
+				Path:    "/vitess/global",
+			},
+		},
+	}
+	lockserver.SetCondition(vitessv1alpha2.LockserverConditionAvailable, corev1.ConditionTrue, "LeaderElected", "")
+
+	// The cluster status still has the lockserver from before it became available
+	stale := &vitessv1alpha2.VitessLockserver{}
+	stale.SetCondition(vitessv1alpha2.LockserverConditionAvailable, corev1.ConditionFalse, "Unreachable", "")
+
+	cluster := &vitessv1alpha2.VitessCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterName,
+			Namespace: namespace,
+		},
+		Spec: vitessv1alpha2.VitessClusterSpec{
+			LockserverRef: &corev1.LocalObjectReference{
+				Name: lockserver.GetName(),
+			},
+		},
+		Status: vitessv1alpha2.VitessClusterStatus{
+			Lockserver: stale.Status.DeepCopy(),
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessCluster{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessLockserver{})
+
+	cl := fake.NewFakeClient(cluster, lockserver)
+	r := &ReconcileVitessCluster{client: cl, scheme: s, recorder: &record.FakeRecorder{}}
+
+	norm := normalizer.New(cl)
+	if err := norm.NormalizeCluster(cluster); err != nil {
+		t.Fatalf("Error normalizing cluster: %s", err)
+	}
+
+	if _, err := r.ReconcileClusterLockserver(cluster); err != nil {
+		t.Fatalf("Error reconciling lockserver: %s", err)
+	}
+
+	if !cluster.Lockserver().IsAvailable() {
+		t.Error("Referenced lockserver status was overwritten by the stale cluster status")
+	}
+
+	found := &vitessv1alpha2.VitessCluster{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: clusterName, Namespace: namespace}, found); err != nil {
+		t.Fatalf("Error getting cluster: %s", err)
+	}
+	if found.Status.Lockserver == nil || !reflect.DeepEqual(*found.Status.Lockserver, lockserver.Status) {
+		t.Errorf("Referenced lockserver status not mirrored into the cluster status: %+v", found.Status.Lockserver)
+	}
+}
+
+// conflictingStatusClient fails the first status updates with a conflict, like the apiserver does with
+// updates of a copy of the cluster that is older than the stored one
+type conflictingStatusClient struct {
+	client.Client
+	conflicts int
+}
+
+func (c *conflictingStatusClient) Status() client.StatusWriter {
+	return &conflictingStatusWriter{c}
+}
+
+type conflictingStatusWriter struct {
+	c *conflictingStatusClient
+}
+
+func (w *conflictingStatusWriter) Update(ctx context.Context, obj runtime.Object) error {
+	if w.c.conflicts > 0 {
+		w.c.conflicts--
+		return errors.NewConflict(vitessv1alpha2.SchemeGroupVersion.WithResource("vitessclusters").GroupResource(), "vt", fmt.Errorf("the object has been modified"))
+	}
+	return w.c.Client.Status().Update(ctx, obj)
+}
+
+// TestClusterStatusConflict makes sure that the several status updates of a reconcile are retried on conflicts
+// and that the phase update reports its failures
+func TestClusterStatusConflict(t *testing.T) {
+	cluster, shard, _ := newReparentTestShard(1, "")
+
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessCluster{})
+
+	cl := fake.NewFakeClient(&vitessv1alpha2.VitessCluster{ObjectMeta: *cluster.ObjectMeta.DeepCopy()})
+	conflicting := &conflictingStatusClient{Client: cl, conflicts: 1}
+	r := &ReconcileVitessCluster{client: conflicting, reader: cl, scheme: s, recorder: &record.FakeRecorder{}}
+
+	if err := r.setShardStatus(shard, &vitessv1alpha2.VitessShardStatus{MasterAlias: "zone1-0000000100"}); err != nil {
+		t.Fatalf("Error setting shard status: %s", err)
+	}
+
+	conflicting.conflicts = 1
+	if err := r.SetClusterPhase(cluster, vitessv1alpha2.ClusterPhaseCreating); err != nil {
+		t.Fatalf("Error setting cluster phase: %s", err)
+	}
+
+	found := &vitessv1alpha2.VitessCluster{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: cluster.GetName(), Namespace: cluster.GetNamespace()}, found); err != nil {
+		t.Fatalf("Error getting cluster: %s", err)
+	}
+
+	if found.Phase() != vitessv1alpha2.ClusterPhaseCreating {
+		t.Errorf("Cluster phase not updated: %q", found.Phase())
+	}
+
+	if status := found.GetShardStatus(shard); status.MasterAlias != "zone1-0000000100" {
+		t.Errorf("Shard status overwritten by the phase update: %+v", status)
+	}
+
+	conflicting.conflicts = 100
+	if err := r.SetClusterPhase(cluster, vitessv1alpha2.ClusterPhaseReady); !errors.IsConflict(err) {
+		t.Errorf("Phase update conflicts not reported: %v", err)
+	}
+}
+
+// TestSpecChangedPredicate makes sure that the status updates written by the operator don't requeue the cluster
+func TestSpecChangedPredicate(t *testing.T) {
+	old := &vitessv1alpha2.VitessCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "vt", Namespace: "vitess", Generation: 1, Labels: map[string]string{"app": "vitess"}},
+	}
+
+	update := func(modify func(*vitessv1alpha2.VitessCluster)) bool {
+		updated := old.DeepCopy()
+		modify(updated)
+		return specChangedPredicate.Update(event.UpdateEvent{MetaOld: old, ObjectOld: old, MetaNew: updated, ObjectNew: updated})
+	}
+
+	if update(func(cluster *vitessv1alpha2.VitessCluster) {
+		cluster.Status.Phase = vitessv1alpha2.ClusterPhaseReady
+		cluster.ResourceVersion = "2"
+	}) {
+		t.Error("Status update requeued the cluster")
+	}
+
+	if !update(func(cluster *vitessv1alpha2.VitessCluster) { cluster.Generation = 2 }) {
+		t.Error("Spec update didn't requeue the cluster")
+	}
+
+	if !update(func(cluster *vitessv1alpha2.VitessCluster) { cluster.Labels["app"] = "other" }) {
+		t.Error("Label update didn't requeue the cluster")
+	}
+}
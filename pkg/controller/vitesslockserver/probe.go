@@ -0,0 +1,376 @@
+package vitesslockserver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+)
+
+// ProbeTimeout bounds every request made while probing a lockserver
+const ProbeTimeout = 5 * time.Second
+
+// LockserverHealth is the result of probing a lockserver
+type LockserverHealth struct {
+	Members        []vitessv1alpha2.LockserverMember
+	Leader         string
+	RootPathExists bool
+
+	// Unreachable lists the configured servers that did not answer, if known
+	Unreachable []string
+}
+
+// The probes are variables so that tests can stub out the network calls
+var (
+	probeEtcd2  = ProbeEtcd2
+	probeZk2    = ProbeZk2
+	probeConsul = ProbeConsul
+)
+
+// ProbeEtcd2 queries the etcd v3 JSON gateway at the given address for the member list, the current leader
+// and whether any keys exist under rootPath. The address may be a comma-separated list of host:port
+// endpoints, in which case the first one to answer is used. Endpoints are reached over https when
+// tlsConfig is set.
+func ProbeEtcd2(address string, rootPath string, tlsConfig *tls.Config) (*LockserverHealth, error) {
+	var lastErr error
+	for _, endpoint := range strings.Split(address, ",") {
+		health, err := probeEtcd2Endpoint(strings.TrimSpace(endpoint), rootPath, tlsConfig)
+		if err == nil {
+			return health, nil
+		}
+		lastErr = err
+	}
+
+	return nil, lastErr
+}
+
+type etcdMember struct {
+	ID         string   `json:"ID"`
+	Name       string   `json:"name"`
+	PeerURLs   []string `json:"peerURLs"`
+	ClientURLs []string `json:"clientURLs"`
+}
+
+type etcdMemberListResponse struct {
+	Members []etcdMember `json:"members"`
+}
+
+type etcdStatusResponse struct {
+	Leader string `json:"leader"`
+}
+
+type etcdRangeRequest struct {
+	Key       string `json:"key"`
+	RangeEnd  string `json:"range_end"`
+	CountOnly bool   `json:"count_only"`
+}
+
+type etcdRangeResponse struct {
+	Count string `json:"count"`
+}
+
+func probeEtcd2Endpoint(endpoint string, rootPath string, tlsConfig *tls.Config) (*LockserverHealth, error) {
+	client := &http.Client{Timeout: ProbeTimeout}
+
+	if tlsConfig != nil {
+		client.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "https://" + endpoint
+		}
+	} else if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+
+	members := &etcdMemberListResponse{}
+	if err := etcdGatewayCall(client, endpoint, "/cluster/member/list", struct{}{}, members); err != nil {
+		return nil, err
+	}
+
+	status := &etcdStatusResponse{}
+	if err := etcdGatewayCall(client, endpoint, "/maintenance/status", struct{}{}, status); err != nil {
+		return nil, err
+	}
+
+	// Count the keys under the root path without fetching them
+	prefix := strings.TrimSuffix(rootPath, "/") + "/"
+	keys := &etcdRangeResponse{}
+	rangeReq := etcdRangeRequest{
+		Key:       base64.StdEncoding.EncodeToString([]byte(prefix)),
+		RangeEnd:  base64.StdEncoding.EncodeToString(prefixRangeEnd([]byte(prefix))),
+		CountOnly: true,
+	}
+	if err := etcdGatewayCall(client, endpoint, "/kv/range", rangeReq, keys); err != nil {
+		return nil, err
+	}
+
+	health := &LockserverHealth{}
+	for _, m := range members.Members {
+		health.Members = append(health.Members, vitessv1alpha2.LockserverMember{
+			Name:       m.Name,
+			ID:         m.ID,
+			ClientURLs: m.ClientURLs,
+			PeerURLs:   m.PeerURLs,
+		})
+
+		if m.ID == status.Leader {
+			health.Leader = m.Name
+		}
+	}
+
+	// Sort members so that the recorded status is stable between probes
+	sort.Slice(health.Members, func(i, j int) bool {
+		return health.Members[i].Name < health.Members[j].Name
+	})
+
+	if keys.Count != "" {
+		count, err := strconv.ParseInt(keys.Count, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid key count from etcd: %s", err)
+		}
+		health.RootPathExists = count > 0
+	}
+
+	return health, nil
+}
+
+// etcdGatewayPrefixes are the paths of the JSON gateway in the order they are tried. etcd 3.5 only serves /v3,
+// which older releases before 3.4 don't have.
+var etcdGatewayPrefixes = []string{"/v3", "/v3beta"}
+
+func etcdGatewayCall(client *http.Client, endpoint string, path string, req interface{}, resp interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	var err404 error
+	for _, prefix := range etcdGatewayPrefixes {
+		found, err := etcdGatewayPost(client, endpoint, prefix+path, body, resp)
+		if found || err != nil {
+			return err
+		}
+		err404 = fmt.Errorf("Unexpected status from etcd %s: %s", prefix+path, http.StatusText(http.StatusNotFound))
+	}
+
+	return err404
+}
+
+// etcdGatewayPost posts the body to the gateway path and decodes the response. It returns false if the path
+// isn't served.
+func etcdGatewayPost(client *http.Client, endpoint string, path string, body []byte, resp interface{}) (bool, error) {
+	httpResp, err := client.Post(endpoint+path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return true, fmt.Errorf("Unexpected status from etcd %s: %s", path, httpResp.Status)
+	}
+
+	return true, json.NewDecoder(httpResp.Body).Decode(resp)
+}
+
+// GetEtcdTLSConfig builds the client TLS configuration for an etcd2 lockserver from the Secrets it references.
+// It returns nil if the lockserver doesn't use TLS.
+func GetEtcdTLSConfig(c client.Client, namespace string, instance *vitessv1alpha2.VitessLockserver) (*tls.Config, error) {
+	lsTLS := instance.GetEtcdTLS()
+	if lsTLS == nil {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{}
+
+	if lsTLS.CASecretRef != nil {
+		ca, err := getSecretKey(c, namespace, lsTLS.CASecretRef)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("No certificates found in etcd CA Secret %s", lsTLS.CASecretRef.Name)
+		}
+	}
+
+	if lsTLS.CertSecretRef != nil && lsTLS.KeySecretRef != nil {
+		cert, err := getSecretKey(c, namespace, lsTLS.CertSecretRef)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := getSecretKey(c, namespace, lsTLS.KeySecretRef)
+		if err != nil {
+			return nil, err
+		}
+
+		keyPair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid etcd client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{keyPair}
+	}
+
+	return tlsConfig, nil
+}
+
+func getSecretKey(c client.Client, namespace string, ref *corev1.SecretKeySelector) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: ref.Name, Namespace: namespace}, secret); err != nil {
+		return nil, err
+	}
+
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return nil, fmt.Errorf("Secret %s has no key %s", ref.Name, ref.Key)
+	}
+
+	return value, nil
+}
+
+// prefixRangeEnd returns the key that ends a range covering every key with the given prefix
+func prefixRangeEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+
+	// The prefix was all 0xff bytes, so there is no end to the range
+	return []byte{0}
+}
+
+// ProbeZk2 asks every ZooKeeper server for its mode with the "srvr" four letter word command,
+// which is whitelisted by default. The root path can't be checked without a full ZooKeeper client,
+// so RootPathExists is never set.
+func ProbeZk2(servers []string, rootPath string) (*LockserverHealth, error) {
+	health := &LockserverHealth{}
+
+	var lastErr error
+	for _, server := range servers {
+		mode, err := zkServerMode(server)
+		if err != nil {
+			lastErr = err
+			health.Unreachable = append(health.Unreachable, server)
+			continue
+		}
+
+		health.Members = append(health.Members, vitessv1alpha2.LockserverMember{
+			Name:       server,
+			ClientURLs: []string{server},
+		})
+
+		if mode == "leader" || mode == "standalone" {
+			health.Leader = server
+		}
+	}
+
+	if len(health.Members) == 0 {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("No ZooKeeper servers configured")
+		}
+		return nil, lastErr
+	}
+
+	return health, nil
+}
+
+func zkServerMode(server string) (string, error) {
+	conn, err := net.DialTimeout("tcp", server, ProbeTimeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(ProbeTimeout))
+	if _, err := io.WriteString(conn, "srvr"); err != nil {
+		return "", err
+	}
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "Mode: ") {
+			return strings.TrimPrefix(line, "Mode: "), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return "", fmt.Errorf("No mode in srvr response from %s", server)
+}
+
+// ProbeConsul asks the Consul agent at the given address for the raft peers and the current leader.
+// The status endpoints don't need an ACL token, so the operator never has to read the token Secret.
+// The KV store does, so RootPathExists is never set.
+func ProbeConsul(address string, rootPath string) (*LockserverHealth, error) {
+	endpoint := address
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+
+	client := &http.Client{Timeout: ProbeTimeout}
+
+	peers := []string{}
+	if err := consulStatusCall(client, endpoint, "/v1/status/peers", &peers); err != nil {
+		return nil, err
+	}
+
+	leader := ""
+	if err := consulStatusCall(client, endpoint, "/v1/status/leader", &leader); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(peers)
+
+	health := &LockserverHealth{}
+	for _, peer := range peers {
+		health.Members = append(health.Members, vitessv1alpha2.LockserverMember{
+			Name:     peer,
+			PeerURLs: []string{peer},
+		})
+
+		if peer == leader {
+			health.Leader = peer
+		}
+	}
+
+	return health, nil
+}
+
+func consulStatusCall(client *http.Client, endpoint string, path string, resp interface{}) error {
+	httpResp, err := client.Get(endpoint + path)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Unexpected status from consul %s: %s", path, httpResp.Status)
+	}
+
+	return json.NewDecoder(httpResp.Body).Decode(resp)
+}
@@ -0,0 +1,314 @@
+package vitesslockserver
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+)
+
+// newFakeEtcdGateway serves the subset of the etcd v3 JSON gateway used by the probe under /v3
+func newFakeEtcdGateway(t *testing.T, keyCount string) *httptest.Server {
+	return httptest.NewServer(fakeEtcdGatewayHandler(t, "/v3", keyCount))
+}
+
+func fakeEtcdGatewayHandler(t *testing.T, prefix string, keyCount string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case prefix + "/cluster/member/list":
+			w.Write([]byte(`{"members":[{"ID":"22","name":"etcd-1","clientURLs":["http://etcd-1:2379"]},{"ID":"11","name":"etcd-0","clientURLs":["http://etcd-0:2379"]}]}`))
+		case prefix + "/maintenance/status":
+			w.Write([]byte(`{"leader":"22","version":"3.3.10"}`))
+		case prefix + "/kv/range":
+			rangeReq := etcdRangeRequest{}
+			if err := json.NewDecoder(req.Body).Decode(&rangeReq); err != nil {
+				t.Fatalf("Error decoding range request: %s", err)
+			}
+			key, _ := base64.StdEncoding.DecodeString(rangeReq.Key)
+			rangeEnd, _ := base64.StdEncoding.DecodeString(rangeReq.RangeEnd)
+			if string(key) != "/vitess/global/" || string(rangeEnd) != "/vitess/global0" {
+				t.Errorf("Wrong range requested: %s - %s", key, rangeEnd)
+			}
+			w.Write([]byte(`{"count":"` + keyCount + `"}`))
+		default:
+			http.NotFound(w, req)
+		}
+	})
+}
+
+func TestProbeEtcd2(t *testing.T) {
+	server := newFakeEtcdGateway(t, "4")
+	defer server.Close()
+
+	// The first endpoint is unreachable and should be skipped
+	health, err := ProbeEtcd2("127.0.0.1:1,"+strings.TrimPrefix(server.URL, "http://"), "/vitess/global", nil)
+	if err != nil {
+		t.Fatalf("Error probing etcd: %s", err)
+	}
+
+	if len(health.Members) != 2 || health.Members[0].Name != "etcd-0" {
+		t.Errorf("Members not parsed and sorted: %v", health.Members)
+	}
+
+	if health.Leader != "etcd-1" {
+		t.Errorf("Wrong leader. Got: %s; Expected: etcd-1", health.Leader)
+	}
+
+	if !health.RootPathExists {
+		t.Error("Root path reported missing and shouldn't have been")
+	}
+
+	// An empty count is omitted from the gateway response
+	empty := newFakeEtcdGateway(t, "")
+	defer empty.Close()
+
+	health, err = ProbeEtcd2(empty.URL, "/vitess/global/", nil)
+	if err != nil {
+		t.Fatalf("Error probing etcd: %s", err)
+	}
+
+	if health.RootPathExists {
+		t.Error("Root path reported existing and shouldn't have been")
+	}
+
+	// etcd releases before 3.4 only serve the gateway under /v3beta
+	beta := httptest.NewServer(fakeEtcdGatewayHandler(t, "/v3beta", "1"))
+	defer beta.Close()
+
+	health, err = ProbeEtcd2(beta.URL, "/vitess/global", nil)
+	if err != nil {
+		t.Fatalf("Error probing etcd with the /v3beta gateway: %s", err)
+	}
+
+	if health.Leader != "etcd-1" || !health.RootPathExists {
+		t.Errorf("Wrong health from the /v3beta gateway: %+v", health)
+	}
+}
+
+func TestProbeEtcd2TLS(t *testing.T) {
+	server := httptest.NewTLSServer(fakeEtcdGatewayHandler(t, "/v3", "1"))
+	defer server.Close()
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	cl := fake.NewFakeClient(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "etcd-tls", Namespace: "vitess"},
+		Data:       map[string][]byte{"ca.crt": caPEM},
+	})
+
+	lockserver := &vitessv1alpha2.VitessLockserver{
+		Spec: vitessv1alpha2.VitessLockserverSpec{
+			Etcd2: &vitessv1alpha2.Etcd2Lockserver{
+				TLS: &vitessv1alpha2.LockserverTLS{
+					CASecretRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "etcd-tls"},
+						Key:                  "ca.crt",
+					},
+				},
+			},
+		},
+	}
+
+	tlsConfig, err := GetEtcdTLSConfig(cl, "vitess", lockserver)
+	if err != nil {
+		t.Fatalf("Error building etcd TLS config: %s", err)
+	}
+
+	if _, err := ProbeEtcd2(strings.TrimPrefix(server.URL, "https://"), "/vitess/global", tlsConfig); err != nil {
+		t.Errorf("Error probing etcd over TLS: %s", err)
+	}
+
+	// A missing Secret should be reported rather than falling back to plaintext
+	if _, err := GetEtcdTLSConfig(cl, "other", lockserver); err == nil {
+		t.Error("No error building etcd TLS config from a missing Secret")
+	}
+}
+
+func TestProbeLockserverConditions(t *testing.T) {
+	defer func() { probeEtcd2 = ProbeEtcd2 }()
+
+	// The spec asks for more replicas than the etcd cluster was created with, which are ignored
+	var replicas int32 = 5
+	lockserver := &vitessv1alpha2.VitessLockserver{
+		Spec: vitessv1alpha2.VitessLockserverSpec{
+			Provision: true,
+			Type:      vitessv1alpha2.LockserverTypeEtcd2,
+			Etcd2: &vitessv1alpha2.Etcd2Lockserver{
+				Address:  "etcd:2379",
+				Path:     "/vitess/global",
+				Replicas: &replicas,
+			},
+		},
+	}
+
+	tests := []struct {
+		health    *LockserverHealth
+		err       error
+		available corev1.ConditionStatus
+		degraded  corev1.ConditionStatus
+	}{
+		{
+			health:    &LockserverHealth{Members: make([]vitessv1alpha2.LockserverMember, 3), Leader: "etcd-0"},
+			available: corev1.ConditionTrue,
+			degraded:  corev1.ConditionFalse,
+		},
+		{
+			health:    &LockserverHealth{Members: make([]vitessv1alpha2.LockserverMember, 2), Leader: "etcd-0"},
+			available: corev1.ConditionTrue,
+			degraded:  corev1.ConditionTrue,
+		},
+		{
+			health:    &LockserverHealth{Members: make([]vitessv1alpha2.LockserverMember, 3)},
+			available: corev1.ConditionFalse,
+			degraded:  corev1.ConditionFalse,
+		},
+		{
+			err:       http.ErrHandlerTimeout,
+			available: corev1.ConditionFalse,
+			degraded:  corev1.ConditionFalse,
+		},
+	}
+
+	for _, tc := range tests {
+		probeEtcd2 = func(string, string, *tls.Config) (*LockserverHealth, error) {
+			return tc.health, tc.err
+		}
+
+		ProbeLockserver(lockserver, nil, 3, logf.Log)
+
+		if got := lockserver.GetCondition(vitessv1alpha2.LockserverConditionAvailable).Status; got != tc.available {
+			t.Errorf("Wrong Available condition. Got: %s; Expected: %s", got, tc.available)
+		}
+
+		if got := lockserver.GetCondition(vitessv1alpha2.LockserverConditionDegraded).Status; got != tc.degraded {
+			t.Errorf("Wrong Degraded condition. Got: %s; Expected: %s", got, tc.degraded)
+		}
+
+		if lockserver.Status.Reachable != (tc.err == nil) {
+			t.Errorf("Wrong reachability. Got: %t; Expected: %t", lockserver.Status.Reachable, tc.err == nil)
+		}
+	}
+}
+
+// newFakeZkServer answers the srvr four letter word command with the given mode
+func newFakeZkServer(t *testing.T, mode string) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error listening: %s", err)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			cmd := make([]byte, 4)
+			io.ReadFull(conn, cmd)
+			if string(cmd) == "srvr" {
+				io.WriteString(conn, "Zookeeper version: 3.4.13\nLatency min/avg/max: 0/0/0\nMode: "+mode+"\nNode count: 4\n")
+			}
+			conn.Close()
+		}
+	}()
+
+	return listener
+}
+
+func TestProbeZk2(t *testing.T) {
+	leader := newFakeZkServer(t, "leader")
+	defer leader.Close()
+	follower := newFakeZkServer(t, "follower")
+	defer follower.Close()
+
+	health, err := ProbeZk2([]string{follower.Addr().String(), leader.Addr().String(), "127.0.0.1:1"}, "/vitess/global")
+	if err != nil {
+		t.Fatalf("Error probing zookeeper: %s", err)
+	}
+
+	if len(health.Members) != 2 {
+		t.Errorf("Wrong member count. Got: %d; Expected: 2", len(health.Members))
+	}
+
+	if health.Leader != leader.Addr().String() {
+		t.Errorf("Wrong leader. Got: %s; Expected: %s", health.Leader, leader.Addr().String())
+	}
+
+	if len(health.Unreachable) != 1 || health.Unreachable[0] != "127.0.0.1:1" {
+		t.Errorf("Unreachable server not reported: %v", health.Unreachable)
+	}
+
+	if _, err := ProbeZk2([]string{"127.0.0.1:1"}, "/vitess/global"); err == nil {
+		t.Error("Probe of unreachable zookeeper did not fail")
+	}
+}
+
+func TestProbeLockserverZk2(t *testing.T) {
+	defer func() { probeZk2 = ProbeZk2 }()
+	probeZk2 = func(servers []string, root string) (*LockserverHealth, error) {
+		return &LockserverHealth{
+			Members:     []vitessv1alpha2.LockserverMember{{Name: servers[0]}},
+			Leader:      servers[0],
+			Unreachable: servers[1:],
+		}, nil
+	}
+
+	lockserver := &vitessv1alpha2.VitessLockserver{
+		Spec: vitessv1alpha2.VitessLockserverSpec{
+			Type: vitessv1alpha2.LockserverTypeZk2,
+			Zk2: &vitessv1alpha2.Zk2Lockserver{
+				Servers: []string{"zk-0:2181", "zk-1:2181"},
+				Path:    "/vitess/global",
+			},
+		},
+	}
+
+	ProbeLockserver(lockserver, nil, 0, logf.Log)
+
+	if !lockserver.IsAvailable() {
+		t.Errorf("ZooKeeper lockserver with a leader not available: %v", lockserver.Status)
+	}
+
+	if got := lockserver.GetCondition(vitessv1alpha2.LockserverConditionDegraded).Status; got != corev1.ConditionTrue {
+		t.Errorf("Wrong Degraded condition with an unreachable server. Got: %s; Expected: %s", got, corev1.ConditionTrue)
+	}
+}
+
+func TestProbeConsul(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/v1/status/peers":
+			w.Write([]byte(`["10.0.0.2:8300","10.0.0.1:8300","10.0.0.3:8300"]`))
+		case "/v1/status/leader":
+			w.Write([]byte(`"10.0.0.2:8300"`))
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer server.Close()
+
+	health, err := ProbeConsul(strings.TrimPrefix(server.URL, "http://"), "vitess/global")
+	if err != nil {
+		t.Fatalf("Error probing consul: %s", err)
+	}
+
+	if len(health.Members) != 3 || health.Members[0].Name != "10.0.0.1:8300" {
+		t.Errorf("Peers not parsed and sorted: %v", health.Members)
+	}
+
+	if health.Leader != "10.0.0.2:8300" {
+		t.Errorf("Wrong leader. Got: %s; Expected: 10.0.0.2:8300", health.Leader)
+	}
+}
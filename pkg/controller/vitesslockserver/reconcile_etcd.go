@@ -0,0 +1,382 @@
+package vitesslockserver
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+)
+
+// ReconcileEtcd creates and manages an etcd cluster for the given lockserver and points the
+// lockserver's etcd2 address at the provisioned client service
+func ReconcileEtcd(c client.Client, scheme *runtime.Scheme, recorder record.EventRecorder, instance *vitessv1alpha2.VitessLockserver, owner Owner, upstreamLog logr.Logger) (reconcile.Result, error) {
+	reqLogger := upstreamLog.WithValues("Lockserver.Name", instance.GetName())
+
+	if instance.Spec.Type == "" {
+		instance.Spec.Type = vitessv1alpha2.LockserverTypeDefault
+	}
+
+	if instance.Spec.Type != vitessv1alpha2.LockserverTypeEtcd2 {
+		return reconcile.Result{}, fmt.Errorf("Provisioning is not supported for lockserver type %s", instance.Spec.Type)
+	}
+
+	if instance.Spec.Etcd2 == nil {
+		instance.Spec.Etcd2 = &vitessv1alpha2.Etcd2Lockserver{
+			Path: "/vitess/" + instance.GetName(),
+		}
+	}
+
+	statefulSet, peerService, clientService, pdb := GetEtcdResources(instance, owner)
+
+	foundStatefulSet := &appsv1.StatefulSet{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: statefulSet.GetName(), Namespace: statefulSet.GetNamespace()}, foundStatefulSet)
+	if err != nil && errors.IsNotFound(err) {
+		controllerutil.SetControllerReference(owner, statefulSet, scheme)
+		err = c.Create(context.TODO(), statefulSet)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		recorder.Eventf(owner, corev1.EventTypeNormal, "Created", "Created etcd StatefulSet %s for lockserver %s", statefulSet.GetName(), instance.GetName())
+	} else if err != nil {
+		reqLogger.Error(err, "failed to get StatefulSet")
+		return reconcile.Result{}, err
+	} else {
+		// The members of a running etcd cluster are fixed by the initial cluster it was bootstrapped with, and
+		// new pods can't join without a member change, so the replica count is only set at creation. The ignored
+		// count is recorded on the StatefulSet so that it is only reported when it first changes.
+		var ignoredReplicas string
+		if !reflect.DeepEqual(foundStatefulSet.Spec.Replicas, statefulSet.Spec.Replicas) {
+			ignoredReplicas = strconv.Itoa(int(*statefulSet.Spec.Replicas))
+		}
+
+		updateStatefulSet := false
+		if foundStatefulSet.GetAnnotations()[vitessv1alpha2.EtcdIgnoredReplicasAnnotation] != ignoredReplicas {
+			annotations := foundStatefulSet.GetAnnotations()
+			if ignoredReplicas == "" {
+				delete(annotations, vitessv1alpha2.EtcdIgnoredReplicasAnnotation)
+			} else {
+				if annotations == nil {
+					annotations = make(map[string]string)
+				}
+				annotations[vitessv1alpha2.EtcdIgnoredReplicasAnnotation] = ignoredReplicas
+				recorder.Eventf(owner, corev1.EventTypeWarning, "ReplicasChangeUnsupported", "Etcd StatefulSet %s keeps its %d replicas, since provisioned etcd clusters can't be scaled",
+					foundStatefulSet.GetName(), *foundStatefulSet.Spec.Replicas)
+			}
+			foundStatefulSet.SetAnnotations(annotations)
+			updateStatefulSet = true
+		}
+
+		// Only the image is allowed to change on a provisioned etcd cluster
+		updateImage := !reflect.DeepEqual(foundStatefulSet.Spec.Template.Spec.Containers[0].Image, statefulSet.Spec.Template.Spec.Containers[0].Image)
+		if updateImage {
+			reqLogger.Info("Updating etcd statefulSet for lockserver")
+
+			foundStatefulSet.Spec.Template.Spec.Containers[0].Image = statefulSet.Spec.Template.Spec.Containers[0].Image
+			updateStatefulSet = true
+		}
+
+		if updateStatefulSet {
+			err = c.Update(context.TODO(), foundStatefulSet)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+
+		if updateImage {
+			recorder.Eventf(owner, corev1.EventTypeNormal, "Updated", "Updated etcd StatefulSet %s for lockserver %s", foundStatefulSet.GetName(), instance.GetName())
+		}
+	}
+
+	for _, service := range []*corev1.Service{peerService, clientService} {
+		foundService := &corev1.Service{}
+		err = c.Get(context.TODO(), types.NamespacedName{Name: service.GetName(), Namespace: service.GetNamespace()}, foundService)
+		if err != nil && errors.IsNotFound(err) {
+			controllerutil.SetControllerReference(owner, service, scheme)
+			err = c.Create(context.TODO(), service)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+		} else if err != nil {
+			reqLogger.Error(err, "failed to get Service")
+			return reconcile.Result{}, err
+		}
+	}
+
+	foundPDB := &policyv1beta1.PodDisruptionBudget{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: pdb.GetName(), Namespace: pdb.GetNamespace()}, foundPDB)
+	if err != nil && errors.IsNotFound(err) {
+		controllerutil.SetControllerReference(owner, pdb, scheme)
+		err = c.Create(context.TODO(), pdb)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+	} else if err != nil {
+		reqLogger.Error(err, "failed to get PodDisruptionBudget")
+		return reconcile.Result{}, err
+	}
+
+	// Point the lockserver at the provisioned etcd cluster
+	address := fmt.Sprintf("%s.%s:%d", clientService.GetName(), clientService.GetNamespace(), vitessv1alpha2.EtcdClientPort)
+	if instance.Spec.Etcd2.Address != address {
+		instance.Spec.Etcd2.Address = address
+
+		// Embedded lockservers are rebuilt from their parent on every reconcile, so only
+		// a standalone lockserver needs its spec written back
+		if owner == Owner(instance) {
+			if err := c.Update(context.TODO(), instance); err != nil {
+				reqLogger.Error(err, "Failed to update VitessLockserver address")
+				return reconcile.Result{}, err
+			}
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// getEtcdMembers returns the member count of the etcd cluster provisioned for the given lockserver, which is the
+// replica count of its StatefulSet rather than the one in the spec since replica changes are ignored after creation.
+// It returns 0 if the StatefulSet doesn't exist yet.
+func getEtcdMembers(c client.Client, instance *vitessv1alpha2.VitessLockserver, owner metav1.Object) (int32, error) {
+	statefulSet := &appsv1.StatefulSet{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: getScopedName(instance, owner, "etcd"), Namespace: owner.GetNamespace()}, statefulSet)
+	if errors.IsNotFound(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	if statefulSet.Spec.Replicas == nil {
+		return 1, nil
+	}
+	return *statefulSet.Spec.Replicas, nil
+}
+
+// GetEtcdResources returns the StatefulSet, peer Service, client Service and PodDisruptionBudget
+// for an etcd cluster provisioned for the given lockserver
+func GetEtcdResources(instance *vitessv1alpha2.VitessLockserver, owner metav1.Object) (*appsv1.StatefulSet, *corev1.Service, *corev1.Service, *policyv1beta1.PodDisruptionBudget) {
+	name := getScopedName(instance, owner, "etcd")
+	clientName := getScopedName(instance, owner, "etcd", "client")
+	namespace := owner.GetNamespace()
+	replicas := instance.GetEtcdReplicas()
+
+	labels := map[string]string{
+		"app":        "vitess",
+		"component":  "etcd",
+		"lockserver": name,
+	}
+
+	// Every member is addressed through the headless peer service
+	var initialCluster []string
+	for i := int32(0); i < *replicas; i++ {
+		member := fmt.Sprintf("%s-%d", name, i)
+		initialCluster = append(initialCluster, fmt.Sprintf("%s=http://%s.%s:%d", member, member, name, vitessv1alpha2.EtcdPeerPort))
+	}
+
+	// setup volume requests
+	volumeRequests := make(corev1.ResourceList)
+	volumeRequests[corev1.ResourceStorage] = resource.MustParse("1Gi")
+
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			PodManagementPolicy: appsv1.ParallelPodManagement,
+			Replicas:            replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			ServiceName: name,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					Affinity: &corev1.Affinity{
+						PodAntiAffinity: &corev1.PodAntiAffinity{
+							PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+								{
+									Weight: 100,
+									PodAffinityTerm: corev1.PodAffinityTerm{
+										LabelSelector: &metav1.LabelSelector{
+											MatchLabels: labels,
+										},
+										TopologyKey: "kubernetes.io/hostname",
+									},
+								},
+							},
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:            "etcd",
+							Image:           instance.GetEtcdImage(),
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command:         []string{"etcd"},
+							Args: []string{
+								"--name=$(POD_NAME)",
+								"--data-dir=/var/run/etcd/default.etcd",
+								fmt.Sprintf("--listen-client-urls=http://0.0.0.0:%d", vitessv1alpha2.EtcdClientPort),
+								fmt.Sprintf("--listen-peer-urls=http://0.0.0.0:%d", vitessv1alpha2.EtcdPeerPort),
+								fmt.Sprintf("--advertise-client-urls=http://$(POD_NAME).%s:%d", name, vitessv1alpha2.EtcdClientPort),
+								fmt.Sprintf("--initial-advertise-peer-urls=http://$(POD_NAME).%s:%d", name, vitessv1alpha2.EtcdPeerPort),
+								"--initial-cluster=" + strings.Join(initialCluster, ","),
+								"--initial-cluster-state=new",
+								"--initial-cluster-token=" + name,
+							},
+							Env: []corev1.EnvVar{
+								{
+									Name: "POD_NAME",
+									ValueFrom: &corev1.EnvVarSource{
+										FieldRef: &corev1.ObjectFieldSelector{
+											FieldPath: "metadata.name",
+										},
+									},
+								},
+							},
+							Ports: []corev1.ContainerPort{
+								{
+									ContainerPort: vitessv1alpha2.EtcdClientPort,
+									Name:          "client",
+									Protocol:      corev1.ProtocolTCP,
+								},
+								{
+									ContainerPort: vitessv1alpha2.EtcdPeerPort,
+									Name:          "peer",
+									Protocol:      corev1.ProtocolTCP,
+								},
+							},
+							ReadinessProbe: &corev1.Probe{
+								Handler: corev1.Handler{
+									HTTPGet: &corev1.HTTPGetAction{
+										Path:   "/health",
+										Port:   intstr.FromInt(vitessv1alpha2.EtcdClientPort),
+										Scheme: corev1.URISchemeHTTP,
+									},
+								},
+								InitialDelaySeconds: 10,
+								TimeoutSeconds:      5,
+								PeriodSeconds:       10,
+								SuccessThreshold:    1,
+								FailureThreshold:    3,
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "etcd-data",
+									MountPath: "/var/run/etcd",
+								},
+							},
+						},
+					},
+				},
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "etcd-data",
+					},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						Resources: corev1.ResourceRequirements{
+							Requests: volumeRequests,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	peerService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+			Annotations: map[string]string{
+				"service.alpha.kubernetes.io/tolerate-unready-endpoints": "true",
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP:                corev1.ClusterIPNone,
+			Selector:                 labels,
+			Type:                     corev1.ServiceTypeClusterIP,
+			PublishNotReadyAddresses: true,
+			Ports: []corev1.ServicePort{
+				{
+					Name: "client",
+					Port: vitessv1alpha2.EtcdClientPort,
+				},
+				{
+					Name: "peer",
+					Port: vitessv1alpha2.EtcdPeerPort,
+				},
+			},
+		},
+	}
+
+	clientService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clientName,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: labels,
+			Type:     corev1.ServiceTypeClusterIP,
+			Ports: []corev1.ServicePort{
+				{
+					Name: "client",
+					Port: vitessv1alpha2.EtcdClientPort,
+				},
+			},
+		},
+	}
+
+	// Never voluntarily disrupt more members than it takes to lose quorum
+	minAvailable := intstr.FromInt(int(*replicas/2 + 1))
+	pdb := &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+		},
+	}
+
+	return statefulSet, peerService, clientService, pdb
+}
+
+// getScopedName prefixes the lockserver name with the owner name when the lockserver is
+// embedded in another resource, since embedded lockservers are only unique within their parent
+func getScopedName(instance *vitessv1alpha2.VitessLockserver, owner metav1.Object, extra ...string) string {
+	parts := []string{instance.GetName()}
+	if owner != metav1.Object(instance) {
+		parts = append([]string{owner.GetName()}, parts...)
+	}
+
+	return strings.Join(append(parts, extra...), "-")
+}
@@ -2,11 +2,20 @@ package vitesslockserver
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -16,10 +25,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	"vitess.io/vitess-operator/pkg/util/metrics"
 )
 
 var log = logf.Log.WithName("controller_vitesslockserver")
 
+// ProbeInterval is how often a standalone lockserver is re-probed
+const ProbeInterval = 30 * time.Second
+
 // Add creates a new VitessLockserver Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
@@ -28,13 +41,13 @@ func Add(mgr manager.Manager) error {
 
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcileVitessLockserver{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+	return &ReconcileVitessLockserver{client: mgr.GetClient(), scheme: mgr.GetScheme(), recorder: mgr.GetRecorder("vitesslockserver-controller")}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	// Create a new controller
-	c, err := controller.New("vitesslockserver-controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New("vitesslockserver-controller", mgr, controller.Options{Reconciler: metrics.InstrumentReconciler("vitesslockserver-controller", r)})
 	if err != nil {
 		return err
 	}
@@ -45,14 +58,19 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
-	// TODO(user): Modify this to be the types you create that are owned by the primary resource
-	// Watch for changes to secondary resource Pods and requeue the owner VitessLockserver
-	err = c.Watch(&source.Kind{Type: &corev1.Pod{}}, &handler.EnqueueRequestForOwner{
-		IsController: true,
-		OwnerType:    &vitessv1alpha2.VitessLockserver{},
-	})
-	if err != nil {
-		return err
+	for _, childType := range []runtime.Object{
+		&appsv1.StatefulSet{},
+		&corev1.Service{},
+		&policyv1beta1.PodDisruptionBudget{},
+	} {
+		// Watch for changes to provisioned resources and requeue the owner VitessLockserver
+		err = c.Watch(&source.Kind{Type: childType}, &handler.EnqueueRequestForOwner{
+			IsController: true,
+			OwnerType:    &vitessv1alpha2.VitessLockserver{},
+		})
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -64,8 +82,15 @@ var _ reconcile.Reconciler = &ReconcileVitessLockserver{}
 type ReconcileVitessLockserver struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client client.Client
-	scheme *runtime.Scheme
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+}
+
+// Owner is the object that owns the resources provisioned for a lockserver. Events about the lockserver are recorded on it.
+type Owner interface {
+	metav1.Object
+	runtime.Object
 }
 
 // Reconcile reads that state of the cluster for a VitessLockserver object and makes changes based on the state read
@@ -90,21 +115,130 @@ func (r *ReconcileVitessLockserver) Reconcile(request reconcile.Request) (reconc
 		return reconcile.Result{}, err
 	}
 
-	rr, err := ReconcileObject(instance, reqLogger)
+	oldStatus := instance.Status.DeepCopy()
 
-	return rr, err
+	// A standalone lockserver owns its own provisioned resources
+	rr, err := ReconcileObject(r.client, r.scheme, r.recorder, instance, instance, reqLogger)
+	if err != nil {
+		return rr, err
+	}
+
+	// Only write status when it changed so that probing doesn't trigger an endless stream of watch events
+	if !reflect.DeepEqual(oldStatus, &instance.Status) {
+		if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+			reqLogger.Error(err, "Failed to update VitessLockserver status")
+			return reconcile.Result{}, err
+		}
+	}
+
+	// Keep probing the lockserver so that status reflects outages
+	if !rr.Requeue && rr.RequeueAfter == 0 {
+		rr.RequeueAfter = ProbeInterval
+	}
+
+	return rr, nil
 }
 
-// ReconcileObject does all the actual reconcile work
-func ReconcileObject(instance *vitessv1alpha2.VitessLockserver, upstreamLog logr.Logger) (reconcile.Result, error) {
+// ReconcileObject does all the actual reconcile work. The owner is the object that any provisioned
+// resources are attached to. It is the lockserver itself unless the lockserver is embedded in another resource.
+func ReconcileObject(c client.Client, scheme *runtime.Scheme, recorder record.EventRecorder, instance *vitessv1alpha2.VitessLockserver, owner Owner, upstreamLog logr.Logger) (reconcile.Result, error) {
 	reqLogger := upstreamLog.WithValues()
 	reqLogger.Info("Reconciling VitessLockserver")
 
-	// TODO actual reconcile
-	// if instance.Status.State != "Ready" {
-	// 	instance.Status.State = "Ready"
-	// 	return reconcile.Result{Requeue: true}, nil
-	// }
+	var expectedMembers int32
+	if instance.Spec.Provision {
+		if r, err := ReconcileEtcd(c, scheme, recorder, instance, owner, reqLogger); err != nil || r.Requeue {
+			return r, err
+		}
+
+		members, err := getEtcdMembers(c, instance, owner)
+		if err != nil {
+			reqLogger.Error(err, "Failed to get etcd StatefulSet")
+			return reconcile.Result{}, err
+		}
+		expectedMembers = members
+	}
+
+	tlsConfig, err := GetEtcdTLSConfig(c, owner.GetNamespace(), instance)
+	if err != nil {
+		reqLogger.Error(err, "Failed to load lockserver TLS Secrets")
+		recorder.Eventf(owner, corev1.EventTypeWarning, "InvalidTLSConfig", "Unable to load the TLS Secrets of lockserver %s: %s", instance.GetName(), err)
+		return reconcile.Result{}, err
+	}
+
+	var wasAvailable *corev1.ConditionStatus
+	if cond := instance.GetCondition(vitessv1alpha2.LockserverConditionAvailable); cond != nil {
+		status := cond.Status
+		wasAvailable = &status
+	}
+
+	ProbeLockserver(instance, tlsConfig, expectedMembers, reqLogger)
+
+	// Embedded cell lockservers don't keep their status, so only changes to a known availability are recorded
+	if cond := instance.GetCondition(vitessv1alpha2.LockserverConditionAvailable); wasAvailable != nil && *wasAvailable != cond.Status {
+		switch cond.Status {
+		case corev1.ConditionTrue:
+			recorder.Eventf(owner, corev1.EventTypeNormal, "LockserverAvailable", "Lockserver %s is available", instance.GetName())
+		case corev1.ConditionFalse:
+			recorder.Eventf(owner, corev1.EventTypeWarning, "LockserverUnavailable", "Lockserver %s is unavailable: %s", instance.GetName(), cond.Message)
+		}
+	}
 
 	return reconcile.Result{}, nil
 }
+
+// ProbeLockserver checks the health of the lockserver and records the result in its status.
+// The tlsConfig is only used for etcd2 lockservers and may be nil. The expectedMembers is the member count
+// of a provisioned lockserver, or 0 if it isn't known.
+func ProbeLockserver(instance *vitessv1alpha2.VitessLockserver, tlsConfig *tls.Config, expectedMembers int32, upstreamLog logr.Logger) {
+	if instance.GetTopoServerAddress() == "" {
+		instance.Status.Reachable = false
+		instance.SetCondition(vitessv1alpha2.LockserverConditionAvailable, corev1.ConditionUnknown, "NotProbed", "No lockserver address to probe")
+		return
+	}
+
+	var health *LockserverHealth
+	var err error
+	switch instance.GetType() {
+	case vitessv1alpha2.LockserverTypeEtcd2:
+		health, err = probeEtcd2(instance.Spec.Etcd2.Address, instance.Spec.Etcd2.Path, tlsConfig)
+	case vitessv1alpha2.LockserverTypeZk2:
+		health, err = probeZk2(instance.Spec.Zk2.Servers, instance.Spec.Zk2.Path)
+	case vitessv1alpha2.LockserverTypeConsul:
+		health, err = probeConsul(instance.Spec.Consul.Address, instance.Spec.Consul.Path)
+	default:
+		err = fmt.Errorf("Unsupported lockserver type %s", instance.GetType())
+	}
+
+	if err != nil {
+		upstreamLog.Info("Lockserver probe failed", "Address", instance.GetTopoServerAddress(), "Error", err.Error())
+		instance.Status.Reachable = false
+		instance.Status.Members = nil
+		instance.Status.Leader = ""
+		instance.SetCondition(vitessv1alpha2.LockserverConditionAvailable, corev1.ConditionFalse, "Unreachable", err.Error())
+		instance.SetCondition(vitessv1alpha2.LockserverConditionDegraded, corev1.ConditionFalse, "Unreachable", "")
+		return
+	}
+
+	instance.Status.Reachable = true
+	instance.Status.Members = health.Members
+	instance.Status.Leader = health.Leader
+	instance.Status.RootPathExists = health.RootPathExists
+
+	if health.Leader == "" {
+		instance.SetCondition(vitessv1alpha2.LockserverConditionAvailable, corev1.ConditionFalse, "NoLeader", "Lockserver has no elected leader")
+	} else {
+		instance.SetCondition(vitessv1alpha2.LockserverConditionAvailable, corev1.ConditionTrue, "LeaderElected", "")
+	}
+
+	// The expected member count is only known for provisioned lockservers
+	if expectedMembers != 0 && int32(len(health.Members)) < expectedMembers {
+		instance.SetCondition(vitessv1alpha2.LockserverConditionDegraded, corev1.ConditionTrue, "MissingMembers",
+			fmt.Sprintf("Lockserver has %d of %d members", len(health.Members), expectedMembers))
+	} else if len(health.Unreachable) != 0 {
+		instance.SetCondition(vitessv1alpha2.LockserverConditionDegraded, corev1.ConditionTrue, "UnreachableMembers",
+			fmt.Sprintf("Lockserver members did not answer: %s", strings.Join(health.Unreachable, ",")))
+	} else {
+		instance.SetCondition(vitessv1alpha2.LockserverConditionDegraded, corev1.ConditionFalse, "", "")
+	}
+}
@@ -0,0 +1,245 @@
+package vitesslockserver
+
+import (
+	"context"
+	"crypto/tls"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+)
+
+// TestProvisionEtcd makes sure that a lockserver with provision set gets an etcd cluster
+func TestProvisionEtcd(t *testing.T) {
+	var (
+		namespace = "vitess"
+		name      = "global"
+	)
+
+	lockserver := &vitessv1alpha2.VitessLockserver{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: vitessv1alpha2.VitessLockserverSpec{
+			Provision: true,
+			Type:      vitessv1alpha2.LockserverTypeEtcd2,
+		},
+	}
+
+	// Objects to track in the fake client.
+	objs := []runtime.Object{
+		lockserver,
+	}
+
+	// Register operator types with the runtime scheme.
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessLockserver{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessLockserverList{})
+
+	// Don't probe the network
+	defer func() { probeEtcd2 = ProbeEtcd2 }()
+	probeEtcd2 = func(string, string, *tls.Config) (*LockserverHealth, error) {
+		return &LockserverHealth{Members: make([]vitessv1alpha2.LockserverMember, 3), Leader: "global-etcd-0"}, nil
+	}
+
+	// Create a fake client to mock API calls.
+	cl := fake.NewFakeClient(objs...)
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileVitessLockserver{client: cl, scheme: s, recorder: recorder}
+
+	req := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+	res, err := r.Reconcile(req)
+	if err != nil {
+		t.Fatalf("Error reconciling lockserver: %s", err)
+	}
+
+	if res.RequeueAfter == 0 {
+		t.Error("Lockserver was not requeued for probing")
+	}
+
+	statefulSet := &appsv1.StatefulSet{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: "global-etcd", Namespace: namespace}, statefulSet); err != nil {
+		t.Fatalf("Etcd StatefulSet was not created: %s", err)
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if event != "Normal Created Created etcd StatefulSet global-etcd for lockserver global" {
+			t.Errorf("Wrong event recorded: %s", event)
+		}
+	default:
+		t.Error("No event recorded for the etcd StatefulSet")
+	}
+
+	if *statefulSet.Spec.Replicas != vitessv1alpha2.EtcdReplicasDefault {
+		t.Errorf("Wrong etcd replica count. Got: %d; Expected: %d", *statefulSet.Spec.Replicas, vitessv1alpha2.EtcdReplicasDefault)
+	}
+
+	if !strings.Contains(strings.Join(statefulSet.Spec.Template.Spec.Containers[0].Args, " "), "global-etcd-2=http://global-etcd-2.global-etcd:2380") {
+		t.Errorf("Etcd initial cluster does not contain every member: %s", statefulSet.Spec.Template.Spec.Containers[0].Args)
+	}
+
+	for _, serviceName := range []string{"global-etcd", "global-etcd-client"} {
+		if err := cl.Get(context.TODO(), types.NamespacedName{Name: serviceName, Namespace: namespace}, &corev1.Service{}); err != nil {
+			t.Errorf("Etcd Service %s was not created: %s", serviceName, err)
+		}
+	}
+
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: "global-etcd", Namespace: namespace}, &policyv1beta1.PodDisruptionBudget{}); err != nil {
+		t.Errorf("Etcd PodDisruptionBudget was not created: %s", err)
+	}
+
+	// The address of the provisioned cluster should be written back to the lockserver
+	found := &vitessv1alpha2.VitessLockserver{}
+	if err := cl.Get(context.TODO(), req.NamespacedName, found); err != nil {
+		t.Fatalf("Error getting lockserver: %s", err)
+	}
+
+	if found.Spec.Etcd2 == nil || found.Spec.Etcd2.Address != "global-etcd-client.vitess:2379" {
+		t.Errorf("Lockserver address was not set to the provisioned etcd cluster: %v", found.Spec.Etcd2)
+	}
+
+	if !found.IsAvailable() {
+		t.Errorf("Lockserver status was not written back: %v", found.Status)
+	}
+}
+
+// TestProvisionEmbeddedEtcd makes sure that an embedded lockserver is scoped to and owned by its parent
+func TestProvisionEmbeddedEtcd(t *testing.T) {
+	cluster := &vitessv1alpha2.VitessCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "vt",
+			Namespace: "vitess",
+		},
+	}
+
+	var replicas int32 = 1
+	lockserver := &vitessv1alpha2.VitessLockserver{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "zone1",
+		},
+		Spec: vitessv1alpha2.VitessLockserverSpec{
+			Provision: true,
+			Etcd2: &vitessv1alpha2.Etcd2Lockserver{
+				Replicas: &replicas,
+			},
+		},
+	}
+
+	statefulSet, peerService, clientService, pdb := GetEtcdResources(lockserver, cluster)
+
+	if statefulSet.GetName() != "vt-zone1-etcd" || statefulSet.GetNamespace() != "vitess" {
+		t.Errorf("Embedded etcd StatefulSet not scoped to its parent: %s/%s", statefulSet.GetNamespace(), statefulSet.GetName())
+	}
+
+	if peerService.GetName() != statefulSet.Spec.ServiceName {
+		t.Errorf("Etcd StatefulSet does not use the peer service. Got: %s; Expected: %s", statefulSet.Spec.ServiceName, peerService.GetName())
+	}
+
+	if clientService.GetName() != "vt-zone1-etcd-client" {
+		t.Errorf("Wrong etcd client service name: %s", clientService.GetName())
+	}
+
+	if pdb.Spec.MinAvailable.IntValue() != 1 {
+		t.Errorf("Wrong PodDisruptionBudget minAvailable for a single member. Got: %d; Expected: 1", pdb.Spec.MinAvailable.IntValue())
+	}
+}
+
+// TestScaleProvisionedEtcd makes sure that the replicas of a running etcd cluster are left alone, since its
+// members can't change, while its image is still updated. The ignored replicas are only reported once.
+func TestScaleProvisionedEtcd(t *testing.T) {
+	var replicas int32 = 5
+	lockserver := &vitessv1alpha2.VitessLockserver{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "global",
+			Namespace: "vitess",
+		},
+		Spec: vitessv1alpha2.VitessLockserverSpec{
+			Provision: true,
+			Type:      vitessv1alpha2.LockserverTypeEtcd2,
+			Etcd2: &vitessv1alpha2.Etcd2Lockserver{
+				Address:  "global-etcd-client.vitess:2379",
+				Path:     "/vitess/global",
+				Replicas: &replicas,
+				Image:    "quay.io/coreos/etcd:v3.3.13",
+			},
+		},
+	}
+
+	// The etcd cluster was created with the default replicas and image
+	created := &vitessv1alpha2.VitessLockserver{
+		ObjectMeta: lockserver.ObjectMeta,
+		Spec:       vitessv1alpha2.VitessLockserverSpec{Provision: true, Type: vitessv1alpha2.LockserverTypeEtcd2},
+	}
+	existing, _, _, _ := GetEtcdResources(created, created)
+
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessLockserver{})
+
+	cl := fake.NewFakeClient(lockserver, existing)
+	recorder := record.NewFakeRecorder(10)
+	if _, err := ReconcileEtcd(cl, s, recorder, lockserver, lockserver, log); err != nil {
+		t.Fatalf("Error reconciling etcd: %s", err)
+	}
+
+	found := &appsv1.StatefulSet{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: "global-etcd", Namespace: "vitess"}, found); err != nil {
+		t.Fatalf("Error getting etcd StatefulSet: %s", err)
+	}
+
+	if *found.Spec.Replicas != vitessv1alpha2.EtcdReplicasDefault {
+		t.Errorf("Etcd StatefulSet scaled. Got: %d; Expected: %d", *found.Spec.Replicas, vitessv1alpha2.EtcdReplicasDefault)
+	}
+
+	// Health is checked against the members the etcd cluster has rather than the ones in the spec
+	if members, err := getEtcdMembers(cl, lockserver, lockserver); err != nil || members != vitessv1alpha2.EtcdReplicasDefault {
+		t.Errorf("Wrong etcd member count. Got: %d, %v; Expected: %d", members, err, vitessv1alpha2.EtcdReplicasDefault)
+	}
+
+	if image := found.Spec.Template.Spec.Containers[0].Image; image != "quay.io/coreos/etcd:v3.3.13" {
+		t.Errorf("Etcd image not updated: %s", image)
+	}
+
+	expected := []string{
+		"Warning ReplicasChangeUnsupported Etcd StatefulSet global-etcd keeps its 3 replicas, since provisioned etcd clusters can't be scaled",
+		"Normal Updated Updated etcd StatefulSet global-etcd for lockserver global",
+	}
+	for _, e := range expected {
+		select {
+		case event := <-recorder.Events:
+			if event != e {
+				t.Errorf("Wrong event recorded. Got: %s; Expected: %s", event, e)
+			}
+		default:
+			t.Errorf("Event not recorded: %s", e)
+		}
+	}
+
+	// The unsupported replica count is only reported when it first changes
+	if _, err := ReconcileEtcd(cl, s, recorder, lockserver, lockserver, log); err != nil {
+		t.Fatalf("Error reconciling etcd: %s", err)
+	}
+
+	select {
+	case event := <-recorder.Events:
+		t.Errorf("Unexpected event recorded: %s", event)
+	default:
+	}
+}
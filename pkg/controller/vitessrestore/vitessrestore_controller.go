@@ -0,0 +1,270 @@
+package vitessrestore
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	"vitess.io/vitess-operator/pkg/normalizer"
+	"vitess.io/vitess-operator/pkg/util/metrics"
+)
+
+var log = logf.Log.WithName("controller_vitessrestore")
+
+// RestoreRequeueInterval is how often a restore in progress is checked on
+const RestoreRequeueInterval = 30 * time.Second
+
+// Add creates a new VitessRestore Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileVitessRestore{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("vitessrestore-controller", mgr, controller.Options{Reconciler: metrics.InstrumentReconciler("vitessrestore-controller", r)})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource VitessRestore
+	err = c.Watch(&source.Kind{Type: &vitessv1alpha2.VitessRestore{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var _ reconcile.Reconciler = &ReconcileVitessRestore{}
+
+// ReconcileVitessRestore reconciles a VitessRestore object
+type ReconcileVitessRestore struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client client.Client
+	scheme *runtime.Scheme
+}
+
+// Reconcile tracks the progress of a restore. The VitessCluster controller does the actual work by
+// creating the tablets of the restored keyspaces with the restore source as their backup storage.
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileVitessRestore) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+
+	// Fetch the VitessRestore instance
+	instance := &vitessv1alpha2.VitessRestore{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// Return and don't requeue
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+
+	oldStatus := instance.Status.DeepCopy()
+
+	rr, err := r.ReconcileRestore(instance)
+	if err != nil {
+		return rr, err
+	}
+
+	if !reflect.DeepEqual(oldStatus, &instance.Status) {
+		reqLogger.Info("Updating VitessRestore status", "Phase", instance.Status.Phase)
+		if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+			reqLogger.Error(err, "Failed to update VitessRestore status")
+			return reconcile.Result{}, err
+		}
+	}
+
+	return rr, nil
+}
+
+// ReconcileRestore does the actual reconcile work, recording the progress in the status of the instance.
+// Problems that need a change to the restore or the cluster are reported in the status rather than returned.
+func (r *ReconcileVitessRestore) ReconcileRestore(instance *vitessv1alpha2.VitessRestore) (reconcile.Result, error) {
+	if !instance.IsActive() {
+		return reconcile.Result{}, nil
+	}
+
+	n := normalizer.New(r.client)
+
+	if err := n.ValidateBackupStorage(&instance.Spec.Source); err != nil {
+		setFailed(instance, fmt.Sprintf("Invalid source: %s", err))
+		return reconcile.Result{}, nil
+	}
+
+	if err := n.ValidateRestorePointInTime(instance); err != nil {
+		setFailed(instance, fmt.Sprintf("Invalid point in time: %s", err))
+		return reconcile.Result{}, nil
+	}
+
+	cluster := &vitessv1alpha2.VitessCluster{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: instance.Spec.Cluster, Namespace: instance.GetNamespace()}, cluster)
+	if err != nil && errors.IsNotFound(err) {
+		setPending(instance, fmt.Sprintf("VitessCluster %s not found", instance.Spec.Cluster))
+		return reconcile.Result{Requeue: true, RequeueAfter: RestoreRequeueInterval}, nil
+	} else if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err := n.NormalizeCluster(cluster); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// The binlog source is only needed once the backups are restored, but without it the restore can't complete
+	if instance.IsPointInTime() {
+		source := &vitessv1alpha2.VitessCluster{}
+		err := r.client.Get(context.TODO(), types.NamespacedName{Name: instance.Spec.BinlogSourceCluster, Namespace: instance.GetNamespace()}, source)
+		if err != nil && errors.IsNotFound(err) {
+			setPending(instance, fmt.Sprintf("Binlog source VitessCluster %s not found", instance.Spec.BinlogSourceCluster))
+			return reconcile.Result{Requeue: true, RequeueAfter: RestoreRequeueInterval}, nil
+		} else if err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	keyspaces := []*vitessv1alpha2.VitessKeyspace{}
+	for _, keyspace := range cluster.Keyspaces() {
+		if instance.AppliesTo(keyspace) {
+			keyspaces = append(keyspaces, keyspace)
+		}
+	}
+
+	if len(keyspaces) == 0 {
+		setFailed(instance, fmt.Sprintf("VitessCluster %s has no keyspace to restore", instance.Spec.Cluster))
+		return reconcile.Result{}, nil
+	}
+
+	shards := map[string]*vitessv1alpha2.VitessRestoreShardStatus{}
+	for _, keyspace := range keyspaces {
+		for _, shard := range keyspace.Shards() {
+			status, err := r.getShardRestoreStatus(instance, shard)
+			if err != nil {
+				setFailed(instance, err.Error())
+				return reconcile.Result{}, nil
+			}
+
+			shards[shard.GetKeyspaceShard()] = status
+		}
+	}
+
+	instance.Status.Shards = shards
+	instance.Status.Message = ""
+
+	phase := vitessv1alpha2.RestorePhaseComplete
+	for _, status := range shards {
+		switch {
+		case status.Phase == vitessv1alpha2.RestorePhaseRestoring:
+			phase = vitessv1alpha2.RestorePhaseRestoring
+		case status.Phase == vitessv1alpha2.RestorePhasePending && phase == vitessv1alpha2.RestorePhaseComplete:
+			phase = vitessv1alpha2.RestorePhasePending
+		}
+	}
+
+	instance.Status.Phase = phase
+	if phase == vitessv1alpha2.RestorePhaseComplete {
+		now := metav1.Now()
+		instance.Status.CompletionTime = &now
+		return reconcile.Result{}, nil
+	}
+
+	return reconcile.Result{Requeue: true, RequeueAfter: RestoreRequeueInterval}, nil
+}
+
+// getShardRestoreStatus returns the progress of the restore of the shard from the tablet restores recorded
+// in the status of the cluster. It returns an error if the shard can't be restored.
+func (r *ReconcileVitessRestore) getShardRestoreStatus(instance *vitessv1alpha2.VitessRestore, shard *vitessv1alpha2.VitessShard) (*vitessv1alpha2.VitessRestoreShardStatus, error) {
+	status := &vitessv1alpha2.VitessRestoreShardStatus{
+		Phase: vitessv1alpha2.RestorePhasePending,
+	}
+
+	started := false
+	for _, tablet := range shard.Tablets() {
+		status.Tablets += *tablet.GetReplicas()
+
+		statefulSet := &appsv1.StatefulSet{}
+		err := r.client.Get(context.TODO(), types.NamespacedName{Name: tablet.GetStatefulSetName(), Namespace: instance.GetNamespace()}, statefulSet)
+		if err != nil && errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		// Tablets that already had data when the restore was created don't restore anything
+		if statefulSet.GetAnnotations()[vitessv1alpha2.TabletRestoreAnnotation] != instance.GetName() {
+			return nil, fmt.Errorf("Tablet StatefulSet %s was not created by the restore", statefulSet.GetName())
+		}
+
+		for _, restore := range shard.Cluster().GetTabletStatus(tablet).Restores {
+			// The backup is only known once vttablet has logged it, and a pod that finished without one started empty
+			if restore.Backup == "" {
+				if restore.Complete {
+					return nil, fmt.Errorf("No backup of shard %s found in the source", shard.GetKeyspaceShard())
+				}
+				continue
+			}
+
+			if restore.Error != "" {
+				return nil, fmt.Errorf("Tablet pod %s can't be restored: %s", restore.PodName, restore.Error)
+			}
+
+			started = true
+			status.Backup = restore.Backup
+			if restore.BinlogsReplayed {
+				status.ReplayedTablets++
+			}
+			if restore.Complete {
+				status.RestoredTablets++
+			}
+		}
+	}
+
+	switch {
+	case started && status.RestoredTablets >= status.Tablets:
+		status.Phase = vitessv1alpha2.RestorePhaseComplete
+	case started:
+		status.Phase = vitessv1alpha2.RestorePhaseRestoring
+	}
+
+	return status, nil
+}
+
+// setPending records why the restore can't start yet
+func setPending(instance *vitessv1alpha2.VitessRestore, message string) {
+	instance.Status.Phase = vitessv1alpha2.RestorePhasePending
+	instance.Status.Message = message
+}
+
+// setFailed records why the restore failed. A failed restore is never retried.
+func setFailed(instance *vitessv1alpha2.VitessRestore, message string) {
+	log.Info("Restore failed", "Namespace", instance.GetNamespace(), "VitessRestore.Name", instance.GetName(), "Reason", message)
+	instance.Status.Phase = vitessv1alpha2.RestorePhaseFailed
+	instance.Status.Message = message
+}
@@ -0,0 +1,288 @@
+package vitessrestore
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+)
+
+var restoreCreated = time.Date(2019, time.February, 1, 12, 0, 0, 0, time.UTC)
+
+// newRestoreTestObjects returns a cluster with a single shard of two replica tablets, and a restore of it
+func newRestoreTestObjects() (*vitessv1alpha2.VitessCluster, *vitessv1alpha2.VitessRestore) {
+	replicas := int32(2)
+	cluster := &vitessv1alpha2.VitessCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "vt",
+			Namespace: "vitess",
+		},
+		Spec: vitessv1alpha2.VitessClusterSpec{
+			Cells: []*vitessv1alpha2.VitessCell{
+				{ObjectMeta: metav1.ObjectMeta{Name: "zone1"}},
+			},
+			Keyspaces: []*vitessv1alpha2.VitessKeyspace{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "main"},
+					Spec: vitessv1alpha2.VitessKeyspaceSpec{
+						Shards: []*vitessv1alpha2.VitessShard{
+							{
+								ObjectMeta: metav1.ObjectMeta{Name: "0"},
+								Spec: vitessv1alpha2.VitessShardSpec{
+									Tablets: []*vitessv1alpha2.VitessTablet{
+										{
+											ObjectMeta: metav1.ObjectMeta{Name: "replica"},
+											Spec: vitessv1alpha2.VitessTabletSpec{
+												CellID:   "zone1",
+												Type:     vitessv1alpha2.TabletTypeReplica,
+												Replicas: &replicas,
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	restore := &vitessv1alpha2.VitessRestore{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "main-from-prod",
+			Namespace:         "vitess",
+			CreationTimestamp: metav1.Time{Time: restoreCreated},
+		},
+		Spec: vitessv1alpha2.VitessRestoreSpec{
+			Cluster:  "vt",
+			Keyspace: "main",
+			Source: vitessv1alpha2.VitessBackupStorage{
+				S3: &vitessv1alpha2.S3BackupStorage{Bucket: "prod-backups"},
+			},
+		},
+	}
+
+	return cluster, restore
+}
+
+func newRestoreTestReconciler(objs ...runtime.Object) *ReconcileVitessRestore {
+	// Register operator types with the runtime scheme.
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessRestore{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessRestoreList{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessCluster{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessClusterList{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessShard{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessShardList{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessTablet{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessTabletList{})
+
+	return &ReconcileVitessRestore{client: fake.NewFakeClient(objs...), scheme: s}
+}
+
+func newTabletStatefulSet(restoreName string) *appsv1.StatefulSet {
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "vt-zone1-main-0-replica",
+			Namespace: "vitess",
+		},
+	}
+	if restoreName != "" {
+		statefulSet.SetAnnotations(map[string]string{vitessv1alpha2.TabletRestoreAnnotation: restoreName})
+	}
+	return statefulSet
+}
+
+// TestRestoreProgress makes sure that the restore goes from pending to complete as the tablet pods restore
+func TestRestoreProgress(t *testing.T) {
+	cluster, restore := newRestoreTestObjects()
+	r := newRestoreTestReconciler(cluster)
+
+	if res, err := r.ReconcileRestore(restore); err != nil || !res.Requeue {
+		t.Fatalf("Pending restore was not requeued: %v, %v", res, err)
+	}
+
+	if restore.Status.Phase != vitessv1alpha2.RestorePhasePending {
+		t.Errorf("Restore without tablets is not pending: %+v", restore.Status)
+	}
+
+	setRestores := func(restores ...vitessv1alpha2.TabletRestoreStatus) {
+		cluster.Status.Tablets = map[string]*vitessv1alpha2.VitessTabletStatus{
+			"vt-zone1-main-0-replica": {Restores: restores},
+		}
+		if err := r.client.Update(context.TODO(), cluster); err != nil {
+			t.Fatalf("Error updating cluster: %s", err)
+		}
+	}
+
+	if err := r.client.Create(context.TODO(), newTabletStatefulSet(restore.GetName())); err != nil {
+		t.Fatalf("Error creating StatefulSet: %s", err)
+	}
+
+	backup := "2019-02-01.030000.zone1-0000000101"
+	setRestores(
+		vitessv1alpha2.TabletRestoreStatus{PodName: "vt-zone1-main-0-replica-0", Backup: backup, Complete: true},
+		vitessv1alpha2.TabletRestoreStatus{PodName: "vt-zone1-main-0-replica-1", Backup: backup},
+	)
+
+	if _, err := r.ReconcileRestore(restore); err != nil {
+		t.Fatalf("Error reconciling restore: %s", err)
+	}
+
+	shard := restore.Status.Shards["main/0"]
+	if restore.Status.Phase != vitessv1alpha2.RestorePhaseRestoring || shard == nil || shard.Backup != backup || shard.RestoredTablets != 1 || shard.Tablets != 2 {
+		t.Fatalf("Wrong restore progress. Phase: %s; Shard: %+v", restore.Status.Phase, shard)
+	}
+
+	setRestores(
+		vitessv1alpha2.TabletRestoreStatus{PodName: "vt-zone1-main-0-replica-0", Backup: backup, Complete: true},
+		vitessv1alpha2.TabletRestoreStatus{PodName: "vt-zone1-main-0-replica-1", Backup: backup, Complete: true},
+	)
+
+	if res, err := r.ReconcileRestore(restore); err != nil || res.Requeue {
+		t.Fatalf("Complete restore was requeued: %v, %v", res, err)
+	}
+
+	if restore.Status.Phase != vitessv1alpha2.RestorePhaseComplete || restore.Status.CompletionTime == nil {
+		t.Errorf("Restore is not complete: %+v", restore.Status)
+	}
+}
+
+// TestRestoreFailures makes sure that restores that can't be carried out are failed with a reason
+func TestRestoreFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*vitessv1alpha2.VitessRestore)
+		objs    []runtime.Object
+		message string
+	}{
+		{
+			name: "invalid source",
+			modify: func(restore *vitessv1alpha2.VitessRestore) {
+				restore.Spec.Source = vitessv1alpha2.VitessBackupStorage{}
+			},
+			message: "Invalid source",
+		},
+		{
+			name: "point in time without binlog source",
+			modify: func(restore *vitessv1alpha2.VitessRestore) {
+				restore.Spec.RestoreToPosition = "MySQL56/00000000-0000-0000-0000-000000000000:1-100"
+			},
+			message: "Invalid point in time",
+		},
+		{
+			name: "missing keyspace",
+			modify: func(restore *vitessv1alpha2.VitessRestore) {
+				restore.Spec.Keyspace = "other"
+			},
+			message: "has no keyspace to restore",
+		},
+		{
+			name:    "existing tablets",
+			objs:    []runtime.Object{newTabletStatefulSet("")},
+			message: "was not created by the restore",
+		},
+	}
+
+	for _, test := range tests {
+		cluster, restore := newRestoreTestObjects()
+		if test.modify != nil {
+			test.modify(restore)
+		}
+
+		r := newRestoreTestReconciler(append(test.objs, cluster)...)
+		if res, err := r.ReconcileRestore(restore); err != nil || res.Requeue {
+			t.Errorf("%s: failed restore was requeued: %v, %v", test.name, res, err)
+		}
+
+		if restore.Status.Phase != vitessv1alpha2.RestorePhaseFailed || !strings.Contains(restore.Status.Message, test.message) {
+			t.Errorf("%s: wrong restore status: %+v", test.name, restore.Status)
+		}
+	}
+}
+
+// TestRestoreBinlogReplay makes sure that the pods that replayed their binlogs are counted, and that a pod that
+// can't replay them fails the restore
+func TestRestoreBinlogReplay(t *testing.T) {
+	cluster, restore := newRestoreTestObjects()
+	restore.Spec.RestoreToTime = &metav1.Time{Time: restoreCreated.Add(-time.Hour)}
+	restore.Spec.BinlogSourceCluster = "prod"
+
+	backup := "2019-02-01.030000.zone1-0000000101"
+	cluster.Status.Tablets = map[string]*vitessv1alpha2.VitessTabletStatus{
+		"vt-zone1-main-0-replica": {
+			Restores: []vitessv1alpha2.TabletRestoreStatus{
+				{PodName: "vt-zone1-main-0-replica-0", Backup: backup, BinlogsReplayed: true, Complete: true},
+				{PodName: "vt-zone1-main-0-replica-1", Backup: backup},
+			},
+		},
+	}
+
+	r := newRestoreTestReconciler(cluster, newTabletStatefulSet(restore.GetName()))
+
+	if res, err := r.ReconcileRestore(restore); err != nil || !res.Requeue {
+		t.Fatalf("Restore without binlog source was not requeued: %v, %v", res, err)
+	}
+
+	if restore.Status.Phase != vitessv1alpha2.RestorePhasePending || !strings.Contains(restore.Status.Message, "Binlog source VitessCluster prod not found") {
+		t.Fatalf("Missing binlog source not reported: %+v", restore.Status)
+	}
+
+	source := &vitessv1alpha2.VitessCluster{ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "vitess"}}
+	if err := r.client.Create(context.TODO(), source); err != nil {
+		t.Fatalf("Error creating binlog source cluster: %s", err)
+	}
+
+	if res, err := r.ReconcileRestore(restore); err != nil || !res.Requeue {
+		t.Fatalf("Replaying restore was not requeued: %v, %v", res, err)
+	}
+
+	shard := restore.Status.Shards["main/0"]
+	if restore.Status.Phase != vitessv1alpha2.RestorePhaseRestoring || shard == nil || shard.ReplayedTablets != 1 || shard.RestoredTablets != 1 {
+		t.Fatalf("Wrong restore progress. Phase: %s; Shard: %+v", restore.Status.Phase, shard)
+	}
+
+	cluster.Status.Tablets["vt-zone1-main-0-replica"].Restores[1].Error = "the restored backup is past the restore position"
+	if err := r.client.Update(context.TODO(), cluster); err != nil {
+		t.Fatalf("Error updating cluster: %s", err)
+	}
+
+	if res, err := r.ReconcileRestore(restore); err != nil || res.Requeue {
+		t.Fatalf("Failed restore was requeued: %v, %v", res, err)
+	}
+
+	if restore.Status.Phase != vitessv1alpha2.RestorePhaseFailed || !strings.Contains(restore.Status.Message, "past the restore position") {
+		t.Errorf("Binlog replay failure not reported: %+v", restore.Status)
+	}
+}
+
+// TestRestoreStatusUpdate makes sure that Reconcile stores the restore status
+func TestRestoreStatusUpdate(t *testing.T) {
+	_, restore := newRestoreTestObjects()
+	r := newRestoreTestReconciler(restore)
+
+	req := types.NamespacedName{Name: restore.GetName(), Namespace: restore.GetNamespace()}
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: req}); err != nil {
+		t.Fatalf("Error reconciling restore: %s", err)
+	}
+
+	found := &vitessv1alpha2.VitessRestore{}
+	if err := r.client.Get(context.TODO(), req, found); err != nil {
+		t.Fatalf("Error getting restore: %s", err)
+	}
+
+	if found.Status.Phase != vitessv1alpha2.RestorePhasePending || !strings.Contains(found.Status.Message, "VitessCluster vt not found") {
+		t.Errorf("Missing cluster not reported: %+v", found.Status)
+	}
+}
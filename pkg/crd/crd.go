@@ -0,0 +1,357 @@
+package crd
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// Definition describes a custom resource whose CRD is generated from its Go types
+type Definition struct {
+	// Objects are empty objects of every served version of the custom resource, starting with the stored
+	// version. Their type name is the kind.
+	Objects []runtime.Object
+
+	// Columns are the additional printer columns of every version. An Age column is always added.
+	Columns []apiextv1beta1.CustomResourceColumnDefinition
+}
+
+// Generator generates CRDs with OpenAPI v3 validation schemas from Go types
+type Generator struct {
+	// Scheme gives the group and version of the objects of the definitions
+	Scheme *runtime.Scheme
+
+	// Conversion is set on the CRDs that serve more than one version
+	Conversion *apiextv1beta1.CustomResourceConversion
+
+	// Enums lists the allowed values of the string types that are enums
+	Enums map[reflect.Type][]string
+}
+
+var (
+	timeType     = reflect.TypeOf(metav1.Time{})
+	durationType = reflect.TypeOf(metav1.Duration{})
+)
+
+// Generate returns the CRD of the given custom resource. Its versions share a top-level schema when their
+// schemas are the same, since the API server rejects identical per-version schemas.
+func (g *Generator) Generate(def Definition) (*apiextv1beta1.CustomResourceDefinition, error) {
+	if len(def.Objects) == 0 {
+		return nil, fmt.Errorf("Definition has no objects")
+	}
+
+	stored, err := apiutil.GVKForObject(def.Objects[0], g.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	singular := strings.ToLower(stored.Kind)
+	plural := singular + "s"
+
+	versions := []apiextv1beta1.CustomResourceDefinitionVersion{}
+	sameSchemas := true
+	for i, obj := range def.Objects {
+		gvk, err := apiutil.GVKForObject(obj, g.Scheme)
+		if err != nil {
+			return nil, err
+		}
+		if gvk.GroupKind() != stored.GroupKind() {
+			return nil, fmt.Errorf("%s is not a version of %s", gvk, stored.GroupKind())
+		}
+
+		schema, err := g.Schema(reflect.TypeOf(obj).Elem())
+		if err != nil {
+			return nil, fmt.Errorf("Error generating the schema of %s: %s", gvk, err)
+		}
+
+		versions = append(versions, apiextv1beta1.CustomResourceDefinitionVersion{
+			Name:    gvk.Version,
+			Served:  true,
+			Storage: i == 0,
+			Schema:  &apiextv1beta1.CustomResourceValidation{OpenAPIV3Schema: &schema},
+		})
+		sameSchemas = sameSchemas && reflect.DeepEqual(versions[0].Schema, versions[i].Schema)
+	}
+
+	var validation *apiextv1beta1.CustomResourceValidation
+	if sameSchemas {
+		validation = versions[0].Schema
+		for i := range versions {
+			versions[i].Schema = nil
+		}
+	}
+
+	var conversion *apiextv1beta1.CustomResourceConversion
+	if len(versions) > 1 {
+		conversion = g.Conversion
+	}
+
+	columns := append(def.Columns, apiextv1beta1.CustomResourceColumnDefinition{
+		Name:     "Age",
+		Type:     "date",
+		JSONPath: ".metadata.creationTimestamp",
+	})
+
+	return &apiextv1beta1.CustomResourceDefinition{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: apiextv1beta1.SchemeGroupVersion.String(),
+			Kind:       "CustomResourceDefinition",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: plural + "." + stored.Group,
+		},
+		Spec: apiextv1beta1.CustomResourceDefinitionSpec{
+			Group:    stored.Group,
+			Version:  stored.Version,
+			Versions: versions,
+			Names: apiextv1beta1.CustomResourceDefinitionNames{
+				Kind:     stored.Kind,
+				ListKind: stored.Kind + "List",
+				Plural:   plural,
+				Singular: singular,
+			},
+			Scope: apiextv1beta1.NamespaceScoped,
+			Subresources: &apiextv1beta1.CustomResourceSubresources{
+				Status: &apiextv1beta1.CustomResourceSubresourceStatus{},
+			},
+			Validation:               validation,
+			AdditionalPrinterColumns: columns,
+			Conversion:               conversion,
+		},
+	}, nil
+}
+
+// Schema returns the OpenAPI v3 schema of the JSON encoding of a Go type. Kubernetes types other than
+// metav1.Time and metav1.Duration are only checked to be objects, since the API server validates them where
+// they are defined. Marshal keeps their fields from being pruned.
+func (g *Generator) Schema(t reflect.Type) (apiextv1beta1.JSONSchemaProps, error) {
+	return g.schema(t, map[reflect.Type]bool{})
+}
+
+// isKubernetesType returns whether the type is defined by a Kubernetes package, which may be vendored
+func isKubernetesType(t reflect.Type) bool {
+	pkgPath := t.PkgPath()
+	if i := strings.LastIndex(pkgPath, "/vendor/"); i >= 0 {
+		pkgPath = pkgPath[i+len("/vendor/"):]
+	}
+	return strings.HasPrefix(pkgPath, "k8s.io/")
+}
+
+func (g *Generator) schema(t reflect.Type, visiting map[reflect.Type]bool) (apiextv1beta1.JSONSchemaProps, error) {
+	if values, ok := g.Enums[t]; ok {
+		enum := []apiextv1beta1.JSON{}
+		for _, value := range values {
+			enum = append(enum, apiextv1beta1.JSON{Raw: []byte(strconv.Quote(value))})
+		}
+		return apiextv1beta1.JSONSchemaProps{Type: "string", Enum: enum}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schema(t.Elem(), visiting)
+	case reflect.String:
+		return apiextv1beta1.JSONSchemaProps{Type: "string"}, nil
+	case reflect.Bool:
+		return apiextv1beta1.JSONSchemaProps{Type: "boolean"}, nil
+	case reflect.Int32, reflect.Int16, reflect.Int8, reflect.Uint16, reflect.Uint8:
+		return apiextv1beta1.JSONSchemaProps{Type: "integer", Format: "int32"}, nil
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return apiextv1beta1.JSONSchemaProps{Type: "integer", Format: "int64"}, nil
+	case reflect.Float32, reflect.Float64:
+		return apiextv1beta1.JSONSchemaProps{Type: "number"}, nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return apiextv1beta1.JSONSchemaProps{Type: "string", Format: "byte"}, nil
+		}
+		items, err := g.schema(t.Elem(), visiting)
+		if err != nil {
+			return apiextv1beta1.JSONSchemaProps{}, err
+		}
+		return apiextv1beta1.JSONSchemaProps{Type: "array", Items: &apiextv1beta1.JSONSchemaPropsOrArray{Schema: &items}}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return apiextv1beta1.JSONSchemaProps{}, fmt.Errorf("map %s doesn't have string keys", t)
+		}
+		values, err := g.schema(t.Elem(), visiting)
+		if err != nil {
+			return apiextv1beta1.JSONSchemaProps{}, err
+		}
+		return apiextv1beta1.JSONSchemaProps{Type: "object", AdditionalProperties: &apiextv1beta1.JSONSchemaPropsOrBool{Allows: true, Schema: &values}}, nil
+	case reflect.Struct:
+		if t == timeType {
+			return apiextv1beta1.JSONSchemaProps{Type: "string", Format: "date-time"}, nil
+		}
+		if t == durationType {
+			return apiextv1beta1.JSONSchemaProps{Type: "string"}, nil
+		}
+		if isKubernetesType(t) {
+			return apiextv1beta1.JSONSchemaProps{Type: "object"}, nil
+		}
+		if visiting[t] {
+			return apiextv1beta1.JSONSchemaProps{}, fmt.Errorf("type %s is recursive", t)
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		properties := map[string]apiextv1beta1.JSONSchemaProps{}
+		required, err := g.addProperties(t, properties, visiting)
+		if err != nil {
+			return apiextv1beta1.JSONSchemaProps{}, err
+		}
+		if len(required) == 0 {
+			required = nil
+		}
+		return apiextv1beta1.JSONSchemaProps{Type: "object", Properties: properties, Required: required}, nil
+	}
+
+	return apiextv1beta1.JSONSchemaProps{}, fmt.Errorf("type %s has no JSON schema", t)
+}
+
+// addProperties adds the schemas of the JSON fields of a struct to properties, including the fields of
+// embedded structs that are encoded inline, and returns the names of the required fields
+func (g *Generator) addProperties(t reflect.Type, properties map[string]apiextv1beta1.JSONSchemaProps, visiting map[reflect.Type]bool) ([]string, error) {
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !isWellFormedJSONTag(field.Tag) {
+			return nil, fmt.Errorf("field %s.%s has a malformed json tag: %s", t.Name(), field.Name, field.Tag)
+		}
+		tag := field.Tag.Get("json")
+
+		name, options := tag, ""
+		if comma := strings.Index(tag, ","); comma != -1 {
+			name, options = tag[:comma], tag[comma:]
+		}
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			inlined, err := g.addProperties(field.Type, properties, visiting)
+			if err != nil {
+				return nil, err
+			}
+			required = append(required, inlined...)
+			continue
+		}
+
+		// Unexported fields are not encoded
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		if _, ok := properties[name]; ok {
+			return nil, fmt.Errorf("field %s.%s encodes to %s more than once", t.Name(), field.Name, name)
+		}
+
+		// Schemas can't allow null, and an enum that is always encoded has to be set
+		omitempty := strings.Contains(options, ",omitempty")
+		switch field.Type.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map:
+			if !omitempty {
+				return nil, fmt.Errorf("field %s.%s would be encoded as null when it is unset: add omitempty to its json tag", t.Name(), field.Name)
+			}
+		}
+		if _, isEnum := g.Enums[field.Type]; isEnum && !omitempty {
+			required = append(required, name)
+		}
+
+		schema, err := g.schema(field.Type, visiting)
+		if err != nil {
+			return nil, err
+		}
+		properties[name] = schema
+	}
+
+	return required, nil
+}
+
+// isWellFormedJSONTag returns false for json tags that encoding/json silently misreads, like json:""name or
+// json:"name without a closing quote
+func isWellFormedJSONTag(tag reflect.StructTag) bool {
+	start := strings.Index(string(tag), `json:"`)
+	if start == -1 {
+		return true
+	}
+
+	value := string(tag)[start+len(`json:"`):]
+	end := strings.Index(value, `"`)
+	if end == -1 {
+		return false
+	}
+
+	rest := value[end+1:]
+	return rest == "" || rest[0] == ' '
+}
+
+// Marshal encodes a CRD as YAML, leaving out its status and the other fields that are set by the API server.
+// Fields missing from the schemas are pruned, as webhook conversion requires, except in the Kubernetes
+// types that the schemas don't describe. Both settings postdate the CRD types, so they are added to the
+// encoding. API servers that predate them ignore them and keep every field.
+func Marshal(crd *apiextv1beta1.CustomResourceDefinition) ([]byte, error) {
+	encoded, err := json.Marshal(crd)
+	if err != nil {
+		return nil, err
+	}
+
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(encoded, &obj); err != nil {
+		return nil, err
+	}
+	delete(obj, "status")
+	delete(obj["metadata"].(map[string]interface{}), "creationTimestamp")
+
+	spec := obj["spec"].(map[string]interface{})
+	spec["preserveUnknownFields"] = false
+	if validation, ok := spec["validation"].(map[string]interface{}); ok {
+		preserveUnknownFields(validation["openAPIV3Schema"].(map[string]interface{}), true)
+	}
+	if versions, ok := spec["versions"].([]interface{}); ok {
+		for _, version := range versions {
+			if validation, ok := version.(map[string]interface{})["schema"].(map[string]interface{}); ok {
+				preserveUnknownFields(validation["openAPIV3Schema"].(map[string]interface{}), true)
+			}
+		}
+	}
+
+	return yaml.Marshal(obj)
+}
+
+// preserveUnknownFields marks the objects of an encoded schema that have no properties, which are the
+// Kubernetes types, to keep all of their fields. The metadata of the resource itself is handled by the API
+// server, which doesn't allow anything but its type there.
+func preserveUnknownFields(schema map[string]interface{}, root bool) {
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		for name, property := range properties {
+			if root && name == "metadata" {
+				continue
+			}
+			preserveUnknownFields(property.(map[string]interface{}), false)
+		}
+		return
+	}
+
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		preserveUnknownFields(items, false)
+		return
+	}
+
+	if values, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+		preserveUnknownFields(values, false)
+		return
+	}
+
+	if schema["type"] == "object" {
+		schema["x-kubernetes-preserve-unknown-fields"] = true
+	}
+}
@@ -0,0 +1,197 @@
+package crd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// crdsDir is where the CRDs are deployed from, relative to this package
+const crdsDir = "../../deploy/crds"
+
+// TestCRDsUpToDate fails when the CRDs in deploy/crds drift from the Go types. Run
+// go run ./cmd/crd-gen to regenerate them.
+func TestCRDsUpToDate(t *testing.T) {
+	files, err := GenerateFiles()
+	if err != nil {
+		t.Fatalf("Error generating CRDs: %s", err)
+	}
+
+	for name, expected := range files {
+		found, err := ioutil.ReadFile(filepath.Join(crdsDir, name))
+		if err != nil {
+			t.Errorf("Error reading %s: %s", name, err)
+			continue
+		}
+		if string(found) != string(expected) {
+			t.Errorf("%s is out of date with the Go types. Run go run ./cmd/crd-gen to regenerate it.", name)
+		}
+	}
+
+	existing, err := ioutil.ReadDir(crdsDir)
+	if err != nil {
+		t.Fatalf("Error listing %s: %s", crdsDir, err)
+	}
+	for _, file := range existing {
+		if _, ok := files[file.Name()]; !ok && strings.Contains(file.Name(), "_crd.yaml") {
+			t.Errorf("%s is not generated from a Go type", file.Name())
+		}
+	}
+}
+
+func TestSchema(t *testing.T) {
+	type Kind string
+
+	type Inline struct {
+		Shared string `json:"shared"`
+	}
+
+	type Spec struct {
+		Inline `json:",inline"`
+
+		Kind     Kind              `json:"kind"`
+		Replicas *int32            `json:"replicas,omitempty"`
+		Labels   map[string]string `json:"labels,omitempty"`
+		hidden   string
+	}
+
+	g := &Generator{Enums: map[reflect.Type][]string{reflect.TypeOf(Kind("")): {"a", "b"}}}
+
+	schema, err := g.Schema(reflect.TypeOf(Spec{}))
+	if err != nil {
+		t.Fatalf("Error generating schema: %s", err)
+	}
+
+	expected := map[string]string{"shared": "string", "kind": "string", "replicas": "integer", "labels": "object"}
+	if len(schema.Properties) != len(expected) {
+		t.Errorf("Wrong properties. Got: %v; Expected: %v", schema.Properties, expected)
+	}
+	for name, typ := range expected {
+		if schema.Properties[name].Type != typ {
+			t.Errorf("Wrong type for %s. Got: %s; Expected: %s", name, schema.Properties[name].Type, typ)
+		}
+	}
+
+	if len(schema.Properties["kind"].Enum) != 2 || !reflect.DeepEqual(schema.Required, []string{"kind"}) {
+		t.Errorf("Enum not generated as a required enum: %+v, required %v", schema.Properties["kind"], schema.Required)
+	}
+
+	// The types are built at runtime so that go vet doesn't reject the tags first
+	structOf := func(tags ...reflect.StructTag) reflect.Type {
+		fields := []reflect.StructField{}
+		for i, tag := range tags {
+			fields = append(fields, reflect.StructField{Name: fmt.Sprintf("Field%d", i), Type: reflect.TypeOf(""), Tag: tag})
+		}
+		return reflect.StructOf(fields)
+	}
+
+	for _, invalid := range []reflect.Type{
+		reflect.TypeOf(struct {
+			Replicas *int32 `json:"replicas"`
+		}{}),
+		structOf(`json:""batch`),
+		structOf(`json:"cells:`),
+		structOf(`json:"image"`, `json:"image,omitempty"`),
+	} {
+		if _, err := g.Schema(invalid); err == nil {
+			t.Errorf("No error generating the schema of %s", invalid)
+		}
+	}
+}
+
+func TestGenerateVersions(t *testing.T) {
+	g, err := NewGenerator()
+	if err != nil {
+		t.Fatalf("Error creating generator: %s", err)
+	}
+
+	for _, def := range Definitions {
+		crd, err := g.Generate(def)
+		if err != nil {
+			t.Fatalf("Error generating CRD: %s", err)
+		}
+
+		versions := crd.Spec.Versions
+		if len(versions) != 2 || versions[0].Name != "v1alpha2" || !versions[0].Storage || versions[1].Name != "v1beta1" || versions[1].Storage {
+			t.Errorf("%s: wrong versions: %+v", crd.Name, versions)
+		}
+		if crd.Spec.Conversion == nil || crd.Spec.Conversion.Strategy != "Webhook" {
+			t.Errorf("%s: no conversion webhook", crd.Name)
+		}
+
+		// The API server rejects a top-level schema alongside per-version ones, and identical per-version schemas
+		perVersion := versions[0].Schema != nil
+		if perVersion == (crd.Spec.Validation != nil) || perVersion != (versions[1].Schema != nil) {
+			t.Errorf("%s: mixes top-level and per-version schemas", crd.Name)
+		}
+		if perVersion && reflect.DeepEqual(versions[0].Schema, versions[1].Schema) {
+			t.Errorf("%s: has identical per-version schemas", crd.Name)
+		}
+	}
+}
+
+// TestMarshalPruning makes sure that unknown fields are pruned from everything but the Kubernetes types
+func TestMarshalPruning(t *testing.T) {
+	crd := &apiextv1beta1.CustomResourceDefinition{
+		Spec: apiextv1beta1.CustomResourceDefinitionSpec{
+			Validation: &apiextv1beta1.CustomResourceValidation{
+				OpenAPIV3Schema: &apiextv1beta1.JSONSchemaProps{
+					Properties: map[string]apiextv1beta1.JSONSchemaProps{
+						"metadata": {Type: "object"},
+						"spec": {
+							Type: "object",
+							Properties: map[string]apiextv1beta1.JSONSchemaProps{
+								"resources": {Type: "object"},
+								"labels": {
+									Type:                 "object",
+									AdditionalProperties: &apiextv1beta1.JSONSchemaPropsOrBool{Schema: &apiextv1beta1.JSONSchemaProps{Type: "string"}},
+								},
+								"volumes": {
+									Type:  "array",
+									Items: &apiextv1beta1.JSONSchemaPropsOrArray{Schema: &apiextv1beta1.JSONSchemaProps{Type: "object"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	encoded, err := Marshal(crd)
+	if err != nil {
+		t.Fatalf("Error marshaling CRD: %s", err)
+	}
+
+	obj := map[string]interface{}{}
+	if err := yaml.Unmarshal(encoded, &obj); err != nil {
+		t.Fatalf("Error unmarshaling CRD: %s", err)
+	}
+
+	if preserve, found, _ := unstructured.NestedBool(obj, "spec", "preserveUnknownFields"); !found || preserve {
+		t.Error("Unknown fields are not pruned")
+	}
+
+	schema := []string{"spec", "validation", "openAPIV3Schema", "properties"}
+	for path, expected := range map[string]bool{
+		"metadata":                  false,
+		"spec":                      false,
+		"spec.properties.resources": true,
+		"spec.properties.labels":    false,
+		"spec.properties.labels.additionalProperties": false,
+		"spec.properties.volumes.items":               true,
+	} {
+		fields := append(append([]string{}, schema...), strings.Split(path, ".")...)
+		fields = append(fields, "x-kubernetes-preserve-unknown-fields")
+		if preserve, _, _ := unstructured.NestedBool(obj, fields...); preserve != expected {
+			t.Errorf("Wrong x-kubernetes-preserve-unknown-fields of %s. Got: %t; Expected: %t", path, preserve, expected)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package crd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"vitess.io/vitess-operator/pkg/apis"
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	vitessv1beta1 "vitess.io/vitess-operator/pkg/apis/vitess/v1beta1"
+)
+
+// Definitions are the Vitess custom resources with their printer columns. v1alpha2 is stored.
+var Definitions = []Definition{
+	{
+		Objects: []runtime.Object{&vitessv1alpha2.VitessCluster{}, &vitessv1beta1.VitessCluster{}},
+		Columns: []apiextv1beta1.CustomResourceColumnDefinition{
+			{Name: "Phase", Type: "string", JSONPath: ".status.phase"},
+			{Name: "Ready Tablets", Type: "integer", JSONPath: ".status.readyTablets"},
+		},
+	},
+	{
+		// Cells have no status of their own, it is recorded in the cluster status
+		Objects: []runtime.Object{&vitessv1alpha2.VitessCell{}, &vitessv1beta1.VitessCell{}},
+	},
+	{
+		Objects: []runtime.Object{&vitessv1alpha2.VitessKeyspace{}, &vitessv1beta1.VitessKeyspace{}},
+		Columns: []apiextv1beta1.CustomResourceColumnDefinition{
+			{Name: "Shards", Type: "integer", JSONPath: ".status.shards"},
+			{Name: "Ready Shards", Type: "integer", JSONPath: ".status.readyShards"},
+		},
+	},
+	{
+		Objects: []runtime.Object{&vitessv1alpha2.VitessShard{}, &vitessv1beta1.VitessShard{}},
+		Columns: []apiextv1beta1.CustomResourceColumnDefinition{
+			{Name: "Master", Type: "string", JSONPath: ".status.masterAlias"},
+			{Name: "Ready Tablets", Type: "integer", JSONPath: ".status.readyReplicas"},
+		},
+	},
+	{
+		Objects: []runtime.Object{&vitessv1alpha2.VitessTablet{}, &vitessv1beta1.VitessTablet{}},
+		Columns: []apiextv1beta1.CustomResourceColumnDefinition{
+			{Name: "Phase", Type: "string", JSONPath: ".status.phase"},
+			{Name: "Ready", Type: "integer", JSONPath: ".status.readyReplicas"},
+		},
+	},
+	{
+		Objects: []runtime.Object{&vitessv1alpha2.VitessLockserver{}, &vitessv1beta1.VitessLockserver{}},
+		Columns: []apiextv1beta1.CustomResourceColumnDefinition{
+			{Name: "Type", Type: "string", JSONPath: ".spec.type"},
+			{Name: "Reachable", Type: "boolean", JSONPath: ".status.reachable"},
+		},
+	},
+	{
+		Objects: []runtime.Object{&vitessv1alpha2.VitessBackupSchedule{}, &vitessv1beta1.VitessBackupSchedule{}},
+		Columns: []apiextv1beta1.CustomResourceColumnDefinition{
+			{Name: "Schedule", Type: "string", JSONPath: ".spec.schedule"},
+			{Name: "Last Backup", Type: "string", JSONPath: ".status.lastBackup"},
+		},
+	},
+	{
+		Objects: []runtime.Object{&vitessv1alpha2.VitessRestore{}, &vitessv1beta1.VitessRestore{}},
+		Columns: []apiextv1beta1.CustomResourceColumnDefinition{
+			{Name: "Phase", Type: "string", JSONPath: ".status.phase"},
+		},
+	},
+}
+
+// Conversion webhook of the CRDs. The namespace of the Service is set when the CRDs are deployed, and the
+// operator running there fills in the CA bundle when it starts.
+const (
+	ConversionServiceName = "vitess-operator-webhook"
+	ConversionPath        = "/convert"
+)
+
+// NewGenerator returns a generator of the Vitess CRDs
+func NewGenerator() (*Generator, error) {
+	s := runtime.NewScheme()
+	if err := apis.AddToScheme(s); err != nil {
+		return nil, err
+	}
+
+	path := ConversionPath
+	return &Generator{
+		Scheme: s,
+		Conversion: &apiextv1beta1.CustomResourceConversion{
+			Strategy: apiextv1beta1.WebhookConverter,
+			WebhookClientConfig: &apiextv1beta1.WebhookClientConfig{
+				Service: &apiextv1beta1.ServiceReference{
+					Namespace: "default",
+					Name:      ConversionServiceName,
+					Path:      &path,
+				},
+			},
+		},
+		Enums: map[reflect.Type][]string{
+			reflect.TypeOf(vitessv1alpha2.TabletType("")): {
+				string(vitessv1alpha2.TabletTypeMaster),
+				string(vitessv1alpha2.TabletTypeReplica),
+				string(vitessv1alpha2.TabletTypeReadOnly),
+				string(vitessv1alpha2.TabletTypeBackup),
+				string(vitessv1alpha2.TabletTypeRestore),
+				string(vitessv1alpha2.TabletTypeDrained),
+			},
+			reflect.TypeOf(vitessv1alpha2.LockserverType("")): {
+				string(vitessv1alpha2.LockserverTypeEtcd2),
+				string(vitessv1alpha2.LockserverTypeZk2),
+				string(vitessv1alpha2.LockserverTypeConsul),
+			},
+			reflect.TypeOf(vitessv1alpha2.TabletDatastoreType("")): {
+				string(vitessv1alpha2.TabletDatastoreTypeLocal),
+			},
+			reflect.TypeOf(vitessv1alpha2.ResourceSelectorOperator("")): {
+				string(vitessv1alpha2.ResourceSelectorOpIn),
+				string(vitessv1alpha2.ResourceSelectorOpNotIn),
+				string(vitessv1alpha2.ResourceSelectorOpExists),
+				string(vitessv1alpha2.ResourceSelectorOpDoesNotExist),
+			},
+			reflect.TypeOf(vitessv1beta1.TabletType("")): {
+				string(vitessv1beta1.TabletTypeMaster),
+				string(vitessv1beta1.TabletTypeReplica),
+				string(vitessv1beta1.TabletTypeReadOnly),
+				string(vitessv1beta1.TabletTypeBackup),
+				string(vitessv1beta1.TabletTypeRestore),
+				string(vitessv1beta1.TabletTypeDrained),
+			},
+			reflect.TypeOf(vitessv1beta1.LockserverType("")): {
+				string(vitessv1beta1.LockserverTypeEtcd2),
+				string(vitessv1beta1.LockserverTypeZk2),
+				string(vitessv1beta1.LockserverTypeConsul),
+			},
+			reflect.TypeOf(vitessv1beta1.TabletDatastoreType("")): {
+				string(vitessv1beta1.TabletDatastoreTypeLocal),
+			},
+			reflect.TypeOf(vitessv1beta1.ResourceSelectorOperator("")): {
+				string(vitessv1beta1.ResourceSelectorOpIn),
+				string(vitessv1beta1.ResourceSelectorOpNotIn),
+				string(vitessv1beta1.ResourceSelectorOpExists),
+				string(vitessv1beta1.ResourceSelectorOpDoesNotExist),
+			},
+		},
+	}, nil
+}
+
+// GenerateFiles returns the YAML of every Vitess CRD, keyed by its file name in deploy/crds
+func GenerateFiles() (map[string][]byte, error) {
+	g, err := NewGenerator()
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+	for _, def := range Definitions {
+		crd, err := g.Generate(def)
+		if err != nil {
+			return nil, err
+		}
+
+		encoded, err := Marshal(crd)
+		if err != nil {
+			return nil, err
+		}
+
+		// Files are named after the stored version
+		group := strings.Split(crd.Spec.Group, ".")[0]
+		files[fmt.Sprintf("%s_%s_%s_crd.yaml", group, crd.Spec.Version, crd.Spec.Names.Singular)] = append([]byte(Header), encoded...)
+	}
+
+	return files, nil
+}
+
+// Header marks the CRD files as generated
+const Header = "# Code generated by cmd/crd-gen from the Go types in pkg/apis. DO NOT EDIT.\n"
@@ -0,0 +1,210 @@
+package normalizer
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+)
+
+// ValidateObject runs the sanity and validation checks that apply to a single Vitess object, so that
+// invalid objects can be rejected when they are created or updated. Objects can be created in any order,
+// so anything that depends on objects that may not exist yet is left to the reconcile.
+func (n *Normalizer) ValidateObject(obj runtime.Object) error {
+	switch o := obj.(type) {
+	case *vitessv1alpha2.VitessCluster:
+		return n.validateClusterObject(o)
+	case *vitessv1alpha2.VitessCell:
+		return n.validateCellObject(o)
+	case *vitessv1alpha2.VitessKeyspace:
+		return n.validateKeyspaceObject(o)
+	case *vitessv1alpha2.VitessShard:
+		return n.validateShardObject(o)
+	case *vitessv1alpha2.VitessTablet:
+		return n.validateTabletObject(o)
+	case *vitessv1alpha2.VitessLockserver:
+		return n.ValidateLockserver(o)
+	}
+
+	return fmt.Errorf("Cannot validate objects of type %T", obj)
+}
+
+func (n *Normalizer) validateClusterObject(cluster *vitessv1alpha2.VitessCluster) error {
+	if err := n.TestClusterSanity(cluster); err != nil {
+		return err
+	}
+
+	if err := validateSelector("cellSelector", cluster.Spec.CellSelector); err != nil {
+		return err
+	}
+
+	if err := validateSelector("keyspaceSelector", cluster.Spec.KeyspaceSelector); err != nil {
+		return err
+	}
+
+	for _, cell := range cluster.Spec.Cells {
+		if err := n.validateCellObject(cell); err != nil {
+			return err
+		}
+	}
+
+	for _, keyspace := range cluster.Spec.Keyspaces {
+		if err := n.validateKeyspaceObject(keyspace); err != nil {
+			return err
+		}
+	}
+
+	// The full validation runs on a normalized copy, since normalizing sets parents and embeds the
+	// objects matched by the selectors. The object being admitted was just decoded so it has no parents yet.
+	normalized := cluster.DeepCopy()
+	if err := n.NormalizeCluster(normalized); err != nil {
+		// The lockserverRef may be created after the cluster
+		log.Info(fmt.Sprintf("Skipping validation of VitessCluster %s that can't be normalized yet: %s", cluster.GetName(), err))
+		return nil
+	}
+
+	// Existing tablets keep their UIDs, so the uniqueness check has to see them
+	if err := n.NormalizeClusterTabletUIDs(normalized); err != nil {
+		return err
+	}
+
+	if err := n.ValidateCluster(normalized); err != nil && !isPendingValidationError(err, cluster) {
+		return err
+	}
+
+	return nil
+}
+
+// isPendingValidationError returns whether a validation error could go away once the objects matched by the
+// selectors or references of the cluster are created
+func isPendingValidationError(err error, cluster *vitessv1alpha2.VitessCluster) bool {
+	if shardErr, ok := err.(*ShardValidationError); ok {
+		err = shardErr.Err
+	}
+
+	switch err {
+	case ValidationErrorNoCells, ValidationErrorNoCellForTablet:
+		return len(cluster.Spec.CellSelector) != 0
+	case ValidationErrorNoLockserverForCell:
+		if len(cluster.Spec.CellSelector) != 0 {
+			return true
+		}
+		for _, cell := range cluster.Spec.Cells {
+			if cell.Spec.LockserverRef != nil {
+				return true
+			}
+		}
+	case ValidationErrorNoKeyspaces:
+		return len(cluster.Spec.KeyspaceSelector) != 0
+	case ValidationErrorNoShards, ValidationErrorNoTablets, ValidationErrorKeyrangeGap:
+		return clusterHasShardOrTabletSelectors(cluster)
+	}
+
+	return false
+}
+
+// clusterHasShardOrTabletSelectors returns whether shards or tablets of the cluster may come from selectors
+func clusterHasShardOrTabletSelectors(cluster *vitessv1alpha2.VitessCluster) bool {
+	if len(cluster.Spec.KeyspaceSelector) != 0 {
+		return true
+	}
+
+	for _, keyspace := range cluster.Spec.Keyspaces {
+		if len(keyspace.Spec.ShardSelector) != 0 {
+			return true
+		}
+		for _, shard := range keyspace.Spec.Shards {
+			if len(shard.Spec.TabletSelector) != 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (n *Normalizer) validateCellObject(cell *vitessv1alpha2.VitessCell) error {
+	// Lockserver and LockserverRef are mutually exclusive in cells too
+	if cell.Spec.Lockserver != nil && cell.Spec.LockserverRef != nil {
+		return ValidationErrorLockserverAndLockserverRef
+	}
+
+	if cell.Spec.Lockserver != nil {
+		return n.ValidateLockserver(cell.Spec.Lockserver)
+	}
+
+	return nil
+}
+
+func (n *Normalizer) validateKeyspaceObject(keyspace *vitessv1alpha2.VitessKeyspace) error {
+	if err := validateSelector("shardSelector", keyspace.Spec.ShardSelector); err != nil {
+		return err
+	}
+
+	if err := validateShardOptions(keyspace.Spec.Defaults); err != nil {
+		return err
+	}
+
+	for _, shard := range keyspace.Spec.Shards {
+		if err := n.validateShardObject(shard); err != nil {
+			return err
+		}
+	}
+
+	// Shards matched by the selector may fill the gaps between the embedded shards
+	if len(keyspace.Spec.ShardSelector) == 0 {
+		return n.ValidateKeyspaceKeyranges(keyspace)
+	}
+
+	return nil
+}
+
+func (n *Normalizer) validateShardObject(shard *vitessv1alpha2.VitessShard) error {
+	if err := validateSelector("tabletSelector", shard.Spec.TabletSelector); err != nil {
+		return err
+	}
+
+	if err := validateShardOptions(shard.Spec.Defaults); err != nil {
+		return err
+	}
+
+	if _, _, err := shard.Spec.KeyRange.Parse(); err != nil {
+		return &ShardValidationError{Err: ValidationErrorInvalidKeyrange, Shards: []string{describeShard(shard)}}
+	}
+
+	for _, tablet := range shard.Spec.Tablets {
+		if err := n.validateTabletObject(tablet); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateShardOptions(options *vitessv1alpha2.VitessShardOptions) error {
+	if options == nil {
+		return nil
+	}
+
+	return validateSelector("defaults.cellSelector", options.CellSelector)
+}
+
+func (n *Normalizer) validateTabletObject(tablet *vitessv1alpha2.VitessTablet) error {
+	// The replicas default to those of the shard, which a standalone tablet doesn't have yet
+	var replicas int32
+	if tablet.Spec.Replicas != nil {
+		replicas = *tablet.Spec.Replicas
+	}
+
+	return validateTabletUIDRange(tablet, replicas)
+}
+
+// validateSelector makes sure that a selector only uses known operators and valid label keys and values
+func validateSelector(field string, rSels []vitessv1alpha2.ResourceSelector) error {
+	if _, err := ResourceSelectorsAsLabelSelector(rSels); err != nil {
+		return &SelectorValidationError{Field: field, Reason: err}
+	}
+
+	return nil
+}
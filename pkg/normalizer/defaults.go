@@ -0,0 +1,123 @@
+package normalizer
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+)
+
+// SetDefaults writes the defaults of a Vitess object and of the objects embedded in it into its spec, so that
+// the stored object shows what the operator will use. Defaults that are inherited from other objects are
+// only set on the object they are inherited from, so that they still follow it.
+func (n *Normalizer) SetDefaults(obj runtime.Object) error {
+	switch o := obj.(type) {
+	case *vitessv1alpha2.VitessCluster:
+		n.SetClusterDefaults(o)
+	case *vitessv1alpha2.VitessCell:
+		n.SetCellDefaults(o)
+	case *vitessv1alpha2.VitessKeyspace:
+		n.SetKeyspaceDefaults(o)
+	case *vitessv1alpha2.VitessShard:
+		n.SetShardDefaults(o)
+	case *vitessv1alpha2.VitessTablet:
+		n.SetTabletDefaults(o)
+	case *vitessv1alpha2.VitessLockserver:
+		n.SetLockserverDefaults(o)
+	default:
+		return fmt.Errorf("Cannot set defaults on objects of type %T", obj)
+	}
+
+	return nil
+}
+
+func (n *Normalizer) SetClusterDefaults(cluster *vitessv1alpha2.VitessCluster) {
+	if cluster.Spec.Lockserver != nil {
+		n.SetLockserverDefaults(cluster.Spec.Lockserver)
+	}
+
+	for _, cell := range cluster.Spec.Cells {
+		n.SetCellDefaults(cell)
+	}
+
+	for _, keyspace := range cluster.Spec.Keyspaces {
+		n.SetKeyspaceDefaults(keyspace)
+	}
+
+	if exporter := cluster.MySQLExporter(); exporter != nil {
+		exporter.Image = exporter.GetImage()
+	}
+}
+
+func (n *Normalizer) SetCellDefaults(cell *vitessv1alpha2.VitessCell) {
+	if cell.Spec.Lockserver != nil {
+		n.SetLockserverDefaults(cell.Spec.Lockserver)
+	}
+}
+
+func (n *Normalizer) SetKeyspaceDefaults(keyspace *vitessv1alpha2.VitessKeyspace) {
+	if keyspace.Spec.Defaults != nil {
+		setTabletContainersDefaults(keyspace.Spec.Defaults.Containers)
+	}
+
+	for _, shard := range keyspace.Spec.Shards {
+		n.SetShardDefaults(shard)
+	}
+}
+
+// SetShardDefaults sets the replica count of the shard defaults, which the tablets of the shard inherit
+func (n *Normalizer) SetShardDefaults(shard *vitessv1alpha2.VitessShard) {
+	if shard.Spec.Defaults == nil {
+		shard.Spec.Defaults = &vitessv1alpha2.VitessShardOptions{}
+	}
+
+	if shard.Spec.Defaults.Replicas == nil {
+		var replicas int32
+		shard.Spec.Defaults.Replicas = &replicas
+	}
+
+	setTabletContainersDefaults(shard.Spec.Defaults.Containers)
+
+	for _, tablet := range shard.Spec.Tablets {
+		n.SetTabletDefaults(tablet)
+	}
+}
+
+// SetTabletDefaults sets the tablet type, datastore type and DB flavors of the tablet. The replica count is
+// left to the shard, since the tablet inherits it from the shard defaults.
+func (n *Normalizer) SetTabletDefaults(tablet *vitessv1alpha2.VitessTablet) {
+	tablet.Spec.Type = tablet.GetType()
+
+	if tablet.Spec.Datastore.Type == "" {
+		tablet.Spec.Datastore.Type = vitessv1alpha2.TabletDatastoreTypeDefault
+	}
+
+	setTabletContainersDefaults(tablet.Spec.Containers)
+}
+
+// setTabletContainersDefaults sets the DB flavor of each container, which otherwise falls back to the DB flavor
+// of the containers
+func setTabletContainersDefaults(containers *vitessv1alpha2.TabletContainers) {
+	if containers == nil {
+		return
+	}
+
+	if containers.MySQL != nil {
+		containers.MySQL.DBFlavor = containers.GetDBFlavor(containers.MySQL.DBFlavor)
+	}
+
+	if containers.VTTablet != nil {
+		containers.VTTablet.DBFlavor = containers.GetDBFlavor(containers.VTTablet.DBFlavor)
+	}
+}
+
+// SetLockserverDefaults sets the lockserver type, and the image and replica count of provisioned etcd clusters
+func (n *Normalizer) SetLockserverDefaults(lockserver *vitessv1alpha2.VitessLockserver) {
+	lockserver.Spec.Type = lockserver.GetType()
+
+	if lockserver.Spec.Provision && lockserver.Spec.Etcd2 != nil {
+		lockserver.Spec.Etcd2.Replicas = lockserver.GetEtcdReplicas()
+		lockserver.Spec.Etcd2.Image = lockserver.GetEtcdImage()
+	}
+}
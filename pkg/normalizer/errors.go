@@ -3,25 +3,150 @@ package normalizer
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 type ValidationError error
 
 var (
+	ValidationErrorLockserverAndLockserverRef ValidationError = errors.New("Cannot specify both a lockserver and lockserverRef")
+	ValidationErrorInvalidSelector            ValidationError = errors.New("Invalid resource selector")
+
 	ValidationErrorNoLockserverForCluster ValidationError = errors.New("No Lockserver in Cluster")
 	ValidationErrorNoLockserverForCell    ValidationError = errors.New("No Lockserver in Cell")
 
+	ValidationErrorCellLockserverTypeMismatch        ValidationError = errors.New("Cell Lockserver type does not match the Cluster Lockserver type")
+	ValidationErrorCellLockserverCredentialsMismatch ValidationError = errors.New("Cell Lockserver TLS certificates or ACL token differ from the ones of the Cluster Lockserver")
+
+	ValidationErrorNoLockserverBackend        ValidationError = errors.New("No backend configuration in Lockserver")
+	ValidationErrorMultipleLockserverBackends ValidationError = errors.New("More than one backend configuration in Lockserver")
+	ValidationErrorLockserverTypeMismatch     ValidationError = errors.New("Lockserver backend configuration does not match the Lockserver type")
+	ValidationErrorIncompleteLockserverTLS    ValidationError = errors.New("Lockserver TLS client certificate and key must be set together")
+
+	ValidationErrorNoBackupStorageBackend        ValidationError = errors.New("No backend configuration in BackupStorage")
+	ValidationErrorMultipleBackupStorageBackends ValidationError = errors.New("More than one backend configuration in BackupStorage")
+	ValidationErrorIncompleteBackupStorage       ValidationError = errors.New("BackupStorage is missing its claim name or bucket")
+	ValidationErrorIncompleteBackupCredentials   ValidationError = errors.New("BackupStorage S3 access key ID and secret access key must be set together")
+
 	ValidationErrorNoCells     ValidationError = errors.New("No Cells in Cluster")
 	ValidationErrorNoShards    ValidationError = errors.New("No Shards in Cluster")
 	ValidationErrorNoTablets   ValidationError = errors.New("No Tablets in Cluster")
 	ValidationErrorNoKeyspaces ValidationError = errors.New("No Keyspaces in Cluster")
 
-	ValidationErrorOverlappingKeyrange ValidationError = errors.New("Multiple shards provided with the same keyrange")
+	ValidationErrorInvalidKeyrange     ValidationError = errors.New("Shard keyrange is not a valid hex keyrange")
+	ValidationErrorOverlappingKeyrange ValidationError = errors.New("Shard keyranges overlap")
+	ValidationErrorKeyrangeGap         ValidationError = errors.New("Shard keyranges don't cover the full keyspace ID range")
+	ValidationErrorUnshardedKeyrange   ValidationError = errors.New("The shard of an unsharded keyspace must cover the full keyspace ID range with keyrange 0")
 
-	ValidationErrorNoCellForTablet   ValidationError = errors.New("No Cell for Tablet")
-	ValidationErrorTabletNameTooLong ValidationError = errors.New("Tablet name is too long and would break mysql replication")
+	ValidationErrorNoCellForTablet       ValidationError = errors.New("No Cell for Tablet")
+	ValidationErrorTabletNameTooLong     ValidationError = errors.New("Tablet name is too long and would break mysql replication")
+	ValidationErrorTabletIDOutOfRange    ValidationError = errors.New("Tablet ID is out of range")
+	ValidationErrorTooManyTabletReplicas ValidationError = errors.New("Tablet has more replicas than it has UIDs")
+	ValidationErrorDuplicateTabletUID    ValidationError = errors.New("Multiple tablets would share a tablet UID")
+
+	ValidationErrorRestoreWithoutBackupStorage ValidationError = errors.New("Tablet restores from backup but its shard has no BackupStorage")
+
+	ValidationErrorRestoreToTimeAndPosition   ValidationError = errors.New("Restore cannot specify both restoreToTime and restoreToPosition")
+	ValidationErrorRestoreWithoutBinlogSource ValidationError = errors.New("Restore to a point in time has no binlogSourceCluster")
+	ValidationErrorUnsupportedRestorePosition ValidationError = errors.New("Restore position must be a MySQL56 GTID set")
 )
 
+// validationErrorNames are the short names of the validation errors, used to label metrics
+var validationErrorNames = map[error]string{
+	ValidationErrorLockserverAndLockserverRef:        "LockserverAndLockserverRef",
+	ValidationErrorInvalidSelector:                   "InvalidSelector",
+	ValidationErrorNoLockserverForCluster:            "NoLockserverForCluster",
+	ValidationErrorNoLockserverForCell:               "NoLockserverForCell",
+	ValidationErrorCellLockserverTypeMismatch:        "CellLockserverTypeMismatch",
+	ValidationErrorCellLockserverCredentialsMismatch: "CellLockserverCredentialsMismatch",
+	ValidationErrorNoLockserverBackend:               "NoLockserverBackend",
+	ValidationErrorMultipleLockserverBackends:        "MultipleLockserverBackends",
+	ValidationErrorLockserverTypeMismatch:            "LockserverTypeMismatch",
+	ValidationErrorIncompleteLockserverTLS:           "IncompleteLockserverTLS",
+	ValidationErrorNoBackupStorageBackend:            "NoBackupStorageBackend",
+	ValidationErrorMultipleBackupStorageBackends:     "MultipleBackupStorageBackends",
+	ValidationErrorIncompleteBackupStorage:           "IncompleteBackupStorage",
+	ValidationErrorIncompleteBackupCredentials:       "IncompleteBackupCredentials",
+	ValidationErrorNoCells:                           "NoCells",
+	ValidationErrorNoShards:                          "NoShards",
+	ValidationErrorNoTablets:                         "NoTablets",
+	ValidationErrorNoKeyspaces:                       "NoKeyspaces",
+	ValidationErrorInvalidKeyrange:                   "InvalidKeyrange",
+	ValidationErrorOverlappingKeyrange:               "OverlappingKeyrange",
+	ValidationErrorKeyrangeGap:                       "KeyrangeGap",
+	ValidationErrorUnshardedKeyrange:                 "UnshardedKeyrange",
+	ValidationErrorNoCellForTablet:                   "NoCellForTablet",
+	ValidationErrorTabletNameTooLong:                 "TabletNameTooLong",
+	ValidationErrorTabletIDOutOfRange:                "TabletIDOutOfRange",
+	ValidationErrorTooManyTabletReplicas:             "TooManyTabletReplicas",
+	ValidationErrorDuplicateTabletUID:                "DuplicateTabletUID",
+	ValidationErrorRestoreWithoutBackupStorage:       "RestoreWithoutBackupStorage",
+	ValidationErrorRestoreToTimeAndPosition:          "RestoreToTimeAndPosition",
+	ValidationErrorRestoreWithoutBinlogSource:        "RestoreWithoutBinlogSource",
+	ValidationErrorUnsupportedRestorePosition:        "UnsupportedRestorePosition",
+}
+
+// ShardValidationError is a ValidationError about some of the shards of a keyspace, which it names
+type ShardValidationError struct {
+	// Err is the validation error
+	Err ValidationError
+
+	Keyspace string
+
+	// Shards describes the offending shards
+	Shards []string
+}
+
+func (e *ShardValidationError) Error() string {
+	// Standalone shards are validated before they belong to a keyspace
+	if e.Keyspace == "" {
+		return fmt.Sprintf("%s: %s", e.Err, strings.Join(e.Shards, ", "))
+	}
+	return fmt.Sprintf("%s in keyspace %s: %s", e.Err, e.Keyspace, strings.Join(e.Shards, ", "))
+}
+
+// TabletValidationError is a ValidationError about some of the tablets of a cluster, which it names
+type TabletValidationError struct {
+	// Err is the validation error
+	Err ValidationError
+
+	// Tablets are the StatefulSet names of the offending tablets
+	Tablets []string
+}
+
+func (e *TabletValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Err, strings.Join(e.Tablets, ", "))
+}
+
+// SelectorValidationError is a ValidationErrorInvalidSelector with the reason the selector is invalid
+type SelectorValidationError struct {
+	// Field is the name of the invalid selector field
+	Field string
+
+	Reason error
+}
+
+func (e *SelectorValidationError) Error() string {
+	return fmt.Sprintf("%s %s: %s", ValidationErrorInvalidSelector, e.Field, e.Reason)
+}
+
+// GetValidationErrorName returns the short name of a validation error, or Other for any other error
+func GetValidationErrorName(err error) string {
+	switch typed := err.(type) {
+	case *ShardValidationError:
+		err = typed.Err
+	case *TabletValidationError:
+		err = typed.Err
+	case *SelectorValidationError:
+		err = ValidationErrorInvalidSelector
+	}
+
+	if name, ok := validationErrorNames[err]; ok {
+		return name
+	}
+	return "Other"
+}
+
 var ClientError = errors.New("Client Error")
 
 func NewClientError(err error) error {
@@ -3,7 +3,10 @@ package normalizer
 import (
 	"context"
 	"fmt"
+	"strconv"
 
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/selection"
@@ -151,6 +154,85 @@ func (n *Normalizer) NormalizeClusterKeyspaceShards(cluster *vitessv1alpha2.Vite
 	return nil
 }
 
+// NormalizeClusterRestores attaches VitessRestores to the tablets of the cluster they restore. Only tablets
+// whose StatefulSet doesn't exist yet start restoring, since the others already have data and changing their
+// backup storage would replace their pods. Restored tablets keep their restore for the same reason.
+// Restores that can't be carried out are left to the VitessRestore controller to fail.
+func (n *Normalizer) NormalizeClusterRestores(cluster *vitessv1alpha2.VitessCluster) error {
+	restoreList := &vitessv1alpha2.VitessRestoreList{}
+	if err := n.client.List(context.TODO(), &client.ListOptions{Namespace: cluster.GetNamespace()}, restoreList); err != nil {
+		return fmt.Errorf("Error getting restores for cluster %s", err)
+	}
+
+	if len(restoreList.Items) == 0 {
+		return nil
+	}
+
+	for _, tablet := range cluster.Tablets() {
+		statefulSet := &appsv1.StatefulSet{}
+		err := n.client.Get(context.TODO(), types.NamespacedName{Name: tablet.GetStatefulSetName(), Namespace: cluster.GetNamespace()}, statefulSet)
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("Error getting StatefulSet of tablet %s: %s", tablet.GetName(), err)
+		}
+		exists := err == nil
+
+		for i := range restoreList.Items {
+			restore := &restoreList.Items[i]
+			if !restore.AppliesTo(tablet.Keyspace()) {
+				continue
+			}
+
+			if exists && statefulSet.GetAnnotations()[vitessv1alpha2.TabletRestoreAnnotation] == restore.GetName() {
+				tablet.SetRestore(restore)
+				break
+			}
+
+			if !exists && restore.IsActive() && n.ValidateBackupStorage(&restore.Spec.Source) == nil && n.ValidateRestorePointInTime(restore) == nil {
+				log.Info(fmt.Sprintf("Tablet %s is restored by VitessRestore %s", tablet.GetName(), restore.GetName()))
+				tablet.SetRestore(restore)
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// NormalizeClusterTabletUIDs pins the UID base of tablets that already have a StatefulSet to the one their pods
+// were created with, so that changing the cell, the StatefulSet name hash or the tablet ID never renames existing
+// tablets. StatefulSets created before the UID base was recorded get the UID base of the old init script.
+func (n *Normalizer) NormalizeClusterTabletUIDs(cluster *vitessv1alpha2.VitessCluster) error {
+	for _, tablet := range cluster.Tablets() {
+		// Tablets in unknown cells fail validation
+		if tablet.Cell() == nil {
+			continue
+		}
+
+		statefulSet := &appsv1.StatefulSet{}
+		err := n.client.Get(context.TODO(), types.NamespacedName{Name: tablet.GetStatefulSetName(), Namespace: cluster.GetNamespace()}, statefulSet)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("Error getting StatefulSet of tablet %s: %s", tablet.GetName(), err)
+		}
+
+		value, ok := statefulSet.GetAnnotations()[vitessv1alpha2.TabletUIDBaseAnnotation]
+		if !ok {
+			tablet.SetTabletUIDBase(tablet.GetLegacyTabletUIDBase())
+			continue
+		}
+
+		base, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return fmt.Errorf("Invalid %s annotation on StatefulSet of tablet %s: %s", vitessv1alpha2.TabletUIDBaseAnnotation, tablet.GetName(), err)
+		}
+		tablet.SetTabletUIDBase(uint32(base))
+	}
+
+	return nil
+}
+
 func (n *Normalizer) NormalizeClusterShardTablets(cluster *vitessv1alpha2.VitessCluster, shard *vitessv1alpha2.VitessShard) error {
 	tabletList := &vitessv1alpha2.VitessTabletList{}
 	err := n.ListFromSelectors(context.TODO(), shard.Spec.TabletSelector, tabletList)
@@ -2,9 +2,14 @@ package normalizer
 
 import (
 	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -34,6 +39,15 @@ var (
 			Values:   []string{"yes"},
 		},
 	}
+
+	// simple valid spec for all lockservers
+	testLockserverSpec = vitessv1alpha2.VitessLockserverSpec{
+		Type: vitessv1alpha2.LockserverTypeEtcd2,
+		Etcd2: &vitessv1alpha2.Etcd2Lockserver{
+			Address: "etcd:2379",
+			Path:    "/vitess",
+		},
+	}
 )
 
 func TestSanity(t *testing.T) {
@@ -110,6 +124,7 @@ func TestValidation(t *testing.T) {
 					Namespace: testNamespace,
 					Labels:    testLabels,
 				},
+				Spec: testLockserverSpec,
 			},
 			ValidationErrorNoLockserverForCluster,
 		},
@@ -135,6 +150,7 @@ func TestValidation(t *testing.T) {
 					Namespace: testNamespace,
 					Labels:    testLabels,
 				},
+				Spec: testLockserverSpec,
 			},
 			ValidationErrorNoLockserverForCell,
 		},
@@ -332,6 +348,7 @@ func TestSaneNormalAndValidCluster(t *testing.T) {
 				Namespace: testNamespace,
 				Labels:    testLabels,
 			},
+			Spec: testLockserverSpec,
 		},
 		&vitessv1alpha2.VitessLockserver{
 			ObjectMeta: metav1.ObjectMeta{
@@ -339,6 +356,7 @@ func TestSaneNormalAndValidCluster(t *testing.T) {
 				Namespace: testNamespace,
 				Labels:    testLabels,
 			},
+			Spec: testLockserverSpec,
 		},
 		&vitessv1alpha2.VitessCell{
 			ObjectMeta: metav1.ObjectMeta{
@@ -354,7 +372,7 @@ func TestSaneNormalAndValidCluster(t *testing.T) {
 		},
 		&vitessv1alpha2.VitessKeyspace{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      "keyspace",
+				Name:      "main",
 				Namespace: testNamespace,
 				Labels:    testLabels,
 			},
@@ -364,7 +382,7 @@ func TestSaneNormalAndValidCluster(t *testing.T) {
 		},
 		&vitessv1alpha2.VitessShard{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      "shard",
+				Name:      "0",
 				Namespace: testNamespace,
 				Labels:    testLabels,
 			},
@@ -524,3 +542,782 @@ func TestSaneNormalAndValidCluster(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateLockserverBackend(t *testing.T) {
+	tests := []struct {
+		spec     vitessv1alpha2.VitessLockserverSpec
+		expected ValidationError
+	}{
+		{
+			vitessv1alpha2.VitessLockserverSpec{},
+			ValidationErrorNoLockserverBackend,
+		},
+		{
+			vitessv1alpha2.VitessLockserverSpec{
+				Provision: true,
+			},
+			nil,
+		},
+		{
+			vitessv1alpha2.VitessLockserverSpec{
+				Etcd2: &vitessv1alpha2.Etcd2Lockserver{},
+			},
+			nil,
+		},
+		{
+			vitessv1alpha2.VitessLockserverSpec{
+				Type: vitessv1alpha2.LockserverTypeZk2,
+				Zk2: &vitessv1alpha2.Zk2Lockserver{
+					Servers: []string{"zk-0:2181", "zk-1:2181"},
+					Path:    "/vitess/global",
+				},
+			},
+			nil,
+		},
+		{
+			vitessv1alpha2.VitessLockserverSpec{
+				Type: vitessv1alpha2.LockserverTypeConsul,
+				Consul: &vitessv1alpha2.ConsulLockserver{
+					Address: "consul:8500",
+					Path:    "vitess/global",
+				},
+			},
+			nil,
+		},
+		{
+			vitessv1alpha2.VitessLockserverSpec{
+				Type:  vitessv1alpha2.LockserverTypeZk2,
+				Etcd2: &vitessv1alpha2.Etcd2Lockserver{},
+			},
+			ValidationErrorLockserverTypeMismatch,
+		},
+		{
+			vitessv1alpha2.VitessLockserverSpec{
+				Consul: &vitessv1alpha2.ConsulLockserver{},
+			},
+			ValidationErrorLockserverTypeMismatch,
+		},
+		{
+			vitessv1alpha2.VitessLockserverSpec{
+				Etcd2: &vitessv1alpha2.Etcd2Lockserver{
+					TLS: &vitessv1alpha2.LockserverTLS{
+						CertSecretRef: &corev1.SecretKeySelector{Key: "tls.crt"},
+					},
+				},
+			},
+			ValidationErrorIncompleteLockserverTLS,
+		},
+		{
+			vitessv1alpha2.VitessLockserverSpec{
+				Type:  vitessv1alpha2.LockserverTypeZk2,
+				Etcd2: &vitessv1alpha2.Etcd2Lockserver{},
+				Zk2:   &vitessv1alpha2.Zk2Lockserver{},
+			},
+			ValidationErrorMultipleLockserverBackends,
+		},
+	}
+
+	n := New(fake.NewFakeClient())
+
+	for _, tc := range tests {
+		err := n.ValidateLockserver(&vitessv1alpha2.VitessLockserver{Spec: tc.spec})
+		if err != tc.expected {
+			t.Errorf("Unexpected error: Got: %s; Expected: %s", err, tc.expected)
+		}
+	}
+}
+
+func TestValidateBackupStorage(t *testing.T) {
+	tests := []struct {
+		storage  vitessv1alpha2.VitessBackupStorage
+		expected ValidationError
+	}{
+		{
+			vitessv1alpha2.VitessBackupStorage{},
+			ValidationErrorNoBackupStorageBackend,
+		},
+		{
+			vitessv1alpha2.VitessBackupStorage{
+				File: &vitessv1alpha2.FileBackupStorage{ClaimName: "backups"},
+			},
+			nil,
+		},
+		{
+			vitessv1alpha2.VitessBackupStorage{
+				File: &vitessv1alpha2.FileBackupStorage{},
+			},
+			ValidationErrorIncompleteBackupStorage,
+		},
+		{
+			vitessv1alpha2.VitessBackupStorage{
+				S3: &vitessv1alpha2.S3BackupStorage{
+					Bucket:                   "vitess-backups",
+					AccessKeyIDSecretRef:     &corev1.SecretKeySelector{Key: "id"},
+					SecretAccessKeySecretRef: &corev1.SecretKeySelector{Key: "secret"},
+				},
+			},
+			nil,
+		},
+		{
+			vitessv1alpha2.VitessBackupStorage{
+				S3: &vitessv1alpha2.S3BackupStorage{
+					Bucket:               "vitess-backups",
+					AccessKeyIDSecretRef: &corev1.SecretKeySelector{Key: "id"},
+				},
+			},
+			ValidationErrorIncompleteBackupCredentials,
+		},
+		{
+			vitessv1alpha2.VitessBackupStorage{
+				GCS: &vitessv1alpha2.GCSBackupStorage{},
+			},
+			ValidationErrorIncompleteBackupStorage,
+		},
+		{
+			vitessv1alpha2.VitessBackupStorage{
+				S3:  &vitessv1alpha2.S3BackupStorage{Bucket: "vitess-backups"},
+				GCS: &vitessv1alpha2.GCSBackupStorage{Bucket: "vitess-backups"},
+			},
+			ValidationErrorMultipleBackupStorageBackends,
+		},
+	}
+
+	n := New(fake.NewFakeClient())
+
+	for _, tc := range tests {
+		err := n.ValidateBackupStorage(&tc.storage)
+		if err != tc.expected {
+			t.Errorf("Unexpected error: Got: %s; Expected: %s", err, tc.expected)
+		}
+	}
+}
+
+func TestValidateRestorePointInTime(t *testing.T) {
+	restoreToTime := metav1.Now()
+
+	tests := []struct {
+		spec     vitessv1alpha2.VitessRestoreSpec
+		expected error
+	}{
+		{
+			vitessv1alpha2.VitessRestoreSpec{},
+			nil,
+		},
+		{
+			vitessv1alpha2.VitessRestoreSpec{
+				RestoreToTime:       &restoreToTime,
+				BinlogSourceCluster: "prod",
+			},
+			nil,
+		},
+		{
+			vitessv1alpha2.VitessRestoreSpec{
+				RestoreToPosition:   "MySQL56/00000000-0000-0000-0000-000000000000:1-100",
+				BinlogSourceCluster: "prod",
+			},
+			nil,
+		},
+		{
+			vitessv1alpha2.VitessRestoreSpec{
+				RestoreToTime:       &restoreToTime,
+				RestoreToPosition:   "MySQL56/00000000-0000-0000-0000-000000000000:1-100",
+				BinlogSourceCluster: "prod",
+			},
+			ValidationErrorRestoreToTimeAndPosition,
+		},
+		{
+			vitessv1alpha2.VitessRestoreSpec{
+				RestoreToPosition:   "MariaDB/0-1-100",
+				BinlogSourceCluster: "prod",
+			},
+			ValidationErrorUnsupportedRestorePosition,
+		},
+		{
+			vitessv1alpha2.VitessRestoreSpec{
+				RestoreToTime: &restoreToTime,
+			},
+			ValidationErrorRestoreWithoutBinlogSource,
+		},
+	}
+
+	n := New(fake.NewFakeClient())
+
+	for _, tc := range tests {
+		err := n.ValidateRestorePointInTime(&vitessv1alpha2.VitessRestore{Spec: tc.spec})
+		if err != tc.expected {
+			t.Errorf("Unexpected error: Got: %s; Expected: %s", err, tc.expected)
+		}
+	}
+}
+
+func TestValidateCellLockserverType(t *testing.T) {
+	cluster := &vitessv1alpha2.VitessCluster{
+		Spec: vitessv1alpha2.VitessClusterSpec{
+			Lockserver: &vitessv1alpha2.VitessLockserver{
+				Spec: testLockserverSpec,
+			},
+			Cells: []*vitessv1alpha2.VitessCell{
+				{
+					Spec: vitessv1alpha2.VitessCellSpec{
+						Lockserver: &vitessv1alpha2.VitessLockserver{
+							Spec: vitessv1alpha2.VitessLockserverSpec{
+								Type: vitessv1alpha2.LockserverTypeZk2,
+								Zk2: &vitessv1alpha2.Zk2Lockserver{
+									Servers: []string{"zk-0:2181"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	n := New(fake.NewFakeClient())
+
+	if err := n.ValidateCluster(cluster); err != ValidationErrorCellLockserverTypeMismatch {
+		t.Errorf("Unexpected error: Got: %s; Expected: %s", err, ValidationErrorCellLockserverTypeMismatch)
+	}
+}
+
+func TestValidateCellLockserverCredentials(t *testing.T) {
+	tokenRef := func(name string) *corev1.SecretKeySelector {
+		return &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: name},
+			Key:                  "token",
+		}
+	}
+	consul := func(token *corev1.SecretKeySelector) *vitessv1alpha2.VitessLockserver {
+		return &vitessv1alpha2.VitessLockserver{
+			Spec: vitessv1alpha2.VitessLockserverSpec{
+				Type: vitessv1alpha2.LockserverTypeConsul,
+				Consul: &vitessv1alpha2.ConsulLockserver{
+					Address:           "consul:8500",
+					Path:              "vitess",
+					ACLTokenSecretRef: token,
+				},
+			},
+		}
+	}
+
+	for _, tc := range []struct {
+		global, cell *corev1.SecretKeySelector
+		expected     error
+	}{
+		{tokenRef("global"), nil, nil},
+		{nil, tokenRef("cell"), nil},
+		{tokenRef("global"), tokenRef("global"), nil},
+		{tokenRef("global"), tokenRef("cell"), ValidationErrorCellLockserverCredentialsMismatch},
+	} {
+		cluster := &vitessv1alpha2.VitessCluster{
+			Spec: vitessv1alpha2.VitessClusterSpec{
+				Lockserver: consul(tc.global),
+				Cells: []*vitessv1alpha2.VitessCell{
+					{
+						Spec: vitessv1alpha2.VitessCellSpec{
+							Lockserver: consul(tc.cell),
+						},
+					},
+				},
+			},
+		}
+
+		n := New(fake.NewFakeClient())
+
+		// Later checks fail on the missing keyspaces
+		if err := n.ValidateCluster(cluster); (err == ValidationErrorCellLockserverCredentialsMismatch) != (tc.expected != nil) {
+			t.Errorf("Unexpected error for global token %v and cell token %v: Got: %s; Expected: %v", tc.global, tc.cell, err, tc.expected)
+		}
+	}
+}
+
+func TestValidateTabletUIDs(t *testing.T) {
+	cluster := &vitessv1alpha2.VitessCluster{ObjectMeta: metav1.ObjectMeta{Name: "vt"}}
+	keyspace := &vitessv1alpha2.VitessKeyspace{ObjectMeta: metav1.ObjectMeta{Name: "main"}}
+	shard := &vitessv1alpha2.VitessShard{ObjectMeta: metav1.ObjectMeta{Name: "0"}}
+
+	newTablet := func(cellName string, tabletID int64, replicas int32) *vitessv1alpha2.VitessTablet {
+		tablet := &vitessv1alpha2.VitessTablet{
+			Spec: vitessv1alpha2.VitessTabletSpec{
+				TabletID: tabletID,
+				Replicas: &replicas,
+				Type:     vitessv1alpha2.TabletTypeReplica,
+			},
+		}
+		tablet.SetParentCluster(cluster)
+		tablet.SetParentCell(&vitessv1alpha2.VitessCell{ObjectMeta: metav1.ObjectMeta{Name: cellName}})
+		tablet.SetParentKeyspace(keyspace)
+		tablet.SetParentShard(shard)
+		return tablet
+	}
+
+	tests := []struct {
+		tablets  []*vitessv1alpha2.VitessTablet
+		expected ValidationError
+		names    []string
+	}{
+		{
+			[]*vitessv1alpha2.VitessTablet{newTablet("zone1", 101, 3), newTablet("zone2", 102, 3)},
+			nil,
+			nil,
+		},
+		{
+			// Tablets in different cells still can't share a UID
+			[]*vitessv1alpha2.VitessTablet{newTablet("zone1", 101, 3), newTablet("zone2", 101, 3)},
+			ValidationErrorDuplicateTabletUID,
+			[]string{"vt-zone1-main-0-replica", "vt-zone2-main-0-replica"},
+		},
+		{
+			// Ranges that start together overlap whatever their order
+			[]*vitessv1alpha2.VitessTablet{newTablet("zone1", 101, 3), newTablet("zone2", 101, 1)},
+			ValidationErrorDuplicateTabletUID,
+			[]string{"vt-zone2-main-0-replica", "vt-zone1-main-0-replica"},
+		},
+		{
+			// Hashed UIDs include the cell name
+			[]*vitessv1alpha2.VitessTablet{newTablet("zone1", 0, 3), newTablet("zone2", 0, 3)},
+			nil,
+			nil,
+		},
+		{
+			[]*vitessv1alpha2.VitessTablet{newTablet("zone1", vitessv1alpha2.MaxTabletID+1, 1)},
+			ValidationErrorTabletIDOutOfRange,
+			nil,
+		},
+		{
+			[]*vitessv1alpha2.VitessTablet{newTablet("zone1", 101, vitessv1alpha2.TabletUIDOrdinalRange+1)},
+			ValidationErrorTooManyTabletReplicas,
+			nil,
+		},
+	}
+
+	n := New(fake.NewFakeClient())
+
+	for _, tc := range tests {
+		err := n.ValidateTabletUIDs(tc.tablets)
+		if tc.names == nil {
+			if err != tc.expected {
+				t.Errorf("Unexpected error: Got: %v; Expected: %v", err, tc.expected)
+			}
+			continue
+		}
+
+		// Both colliding tablets are named
+		tabletErr, ok := err.(*TabletValidationError)
+		if !ok || tabletErr.Err != tc.expected || !reflect.DeepEqual(tabletErr.Tablets, tc.names) {
+			t.Errorf("Wrong error. Got: %v; Expected: %s naming %v", err, tc.expected, tc.names)
+		}
+	}
+
+	if uid := newTablet("zone1", vitessv1alpha2.MaxTabletID, 1).GetTabletUID(vitessv1alpha2.TabletUIDOrdinalRange - 1); uid < vitessv1alpha2.MaxTabletID*vitessv1alpha2.TabletUIDOrdinalRange {
+		t.Errorf("Tablet UID overflowed: %d", uid)
+	}
+}
+
+func TestValidateKeyspaceKeyranges(t *testing.T) {
+	newKeyspace := func(keyranges ...vitessv1alpha2.KeyRange) *vitessv1alpha2.VitessKeyspace {
+		keyspace := &vitessv1alpha2.VitessKeyspace{ObjectMeta: metav1.ObjectMeta{Name: "main"}}
+		for _, keyrange := range keyranges {
+			keyspace.Spec.Shards = append(keyspace.Spec.Shards, &vitessv1alpha2.VitessShard{
+				Spec: vitessv1alpha2.VitessShardSpec{KeyRange: keyrange},
+			})
+		}
+		return keyspace
+	}
+
+	tests := []struct {
+		keyspace *vitessv1alpha2.VitessKeyspace
+		expected ValidationError
+		shards   []string
+	}{
+		{newKeyspace(vitessv1alpha2.KeyRange{}), nil, nil},
+		{newKeyspace(vitessv1alpha2.KeyRange{From: "80", To: "40"}, vitessv1alpha2.KeyRange{}), ValidationErrorInvalidKeyrange, []string{"80-40"}},
+		{newKeyspace(vitessv1alpha2.KeyRange{To: "8g"}, vitessv1alpha2.KeyRange{From: "8g"}), ValidationErrorInvalidKeyrange, []string{"-8g"}},
+		{newKeyspace(vitessv1alpha2.KeyRange{To: "80"}), ValidationErrorUnshardedKeyrange, []string{"-80"}},
+		{newKeyspace(vitessv1alpha2.KeyRange{From: "80"}, vitessv1alpha2.KeyRange{To: "80"}), nil, nil},
+		{
+			// Trailing zeros don't change a bound
+			newKeyspace(vitessv1alpha2.KeyRange{To: "40"}, vitessv1alpha2.KeyRange{From: "4000", To: "c0"}, vitessv1alpha2.KeyRange{From: "c0"}),
+			nil, nil,
+		},
+		{newKeyspace(vitessv1alpha2.KeyRange{To: "80"}, vitessv1alpha2.KeyRange{To: "80"}), ValidationErrorOverlappingKeyrange, []string{"-80", "-80"}},
+		{
+			newKeyspace(vitessv1alpha2.KeyRange{To: "80"}, vitessv1alpha2.KeyRange{From: "40", To: "c0"}, vitessv1alpha2.KeyRange{From: "c0"}),
+			ValidationErrorOverlappingKeyrange, []string{"-80", "40-c0"},
+		},
+		{newKeyspace(vitessv1alpha2.KeyRange{}, vitessv1alpha2.KeyRange{From: "80"}), ValidationErrorOverlappingKeyrange, []string{"0", "80-"}},
+		{
+			newKeyspace(vitessv1alpha2.KeyRange{To: "40"}, vitessv1alpha2.KeyRange{From: "80"}),
+			ValidationErrorKeyrangeGap, []string{"-40", "80-"},
+		},
+		{newKeyspace(vitessv1alpha2.KeyRange{From: "40", To: "80"}, vitessv1alpha2.KeyRange{From: "80"}), ValidationErrorKeyrangeGap, []string{"40-80"}},
+		{newKeyspace(vitessv1alpha2.KeyRange{To: "40"}, vitessv1alpha2.KeyRange{From: "40", To: "80"}), ValidationErrorKeyrangeGap, []string{"40-80"}},
+	}
+
+	n := New(fake.NewFakeClient())
+
+	for _, tc := range tests {
+		err := n.ValidateKeyspaceKeyranges(tc.keyspace)
+		if tc.expected == nil {
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			continue
+		}
+
+		shardErr, ok := err.(*ShardValidationError)
+		if !ok || shardErr.Err != tc.expected || !reflect.DeepEqual(shardErr.Shards, tc.shards) {
+			t.Errorf("Wrong error. Got: %v; Expected: %s naming %v", err, tc.expected, tc.shards)
+		}
+	}
+}
+
+func TestValidateObject(t *testing.T) {
+	newCluster := func() *vitessv1alpha2.VitessCluster {
+		replicas := int32(1)
+		return &vitessv1alpha2.VitessCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "vt", Namespace: testNamespace},
+			Spec: vitessv1alpha2.VitessClusterSpec{
+				Lockserver: &vitessv1alpha2.VitessLockserver{Spec: testLockserverSpec},
+				Cells: []*vitessv1alpha2.VitessCell{{
+					ObjectMeta: metav1.ObjectMeta{Name: "zone1"},
+					Spec: vitessv1alpha2.VitessCellSpec{
+						Lockserver: &vitessv1alpha2.VitessLockserver{Spec: testLockserverSpec},
+					},
+				}},
+				Keyspaces: []*vitessv1alpha2.VitessKeyspace{{
+					ObjectMeta: metav1.ObjectMeta{Name: "main"},
+					Spec: vitessv1alpha2.VitessKeyspaceSpec{
+						Shards: []*vitessv1alpha2.VitessShard{{
+							ObjectMeta: metav1.ObjectMeta{Name: "0"},
+							Spec: vitessv1alpha2.VitessShardSpec{
+								Tablets: []*vitessv1alpha2.VitessTablet{{
+									ObjectMeta: metav1.ObjectMeta{Name: "replica"},
+									Spec: vitessv1alpha2.VitessTabletSpec{
+										CellID:   "zone1",
+										Replicas: &replicas,
+									},
+								}},
+							},
+						}},
+					},
+				}},
+			},
+		}
+	}
+
+	bothLockservers := newCluster()
+	bothLockservers.Spec.LockserverRef = &corev1.LocalObjectReference{Name: "global"}
+
+	invalidSelector := newCluster()
+	invalidSelector.Spec.CellSelector = []vitessv1alpha2.ResourceSelector{{Key: "app", Operator: "Equals", Values: []string{"yes"}}}
+
+	// The cells matched by the selector may be created after the cluster
+	pendingCells := newCluster()
+	pendingCells.Spec.Cells = nil
+	pendingCells.Spec.CellSelector = testSel
+
+	noCells := newCluster()
+	noCells.Spec.Cells = nil
+
+	unknownCell := newCluster()
+	unknownCell.Spec.Keyspaces[0].Spec.Shards[0].Spec.Tablets[0].Spec.CellID = "zone2"
+
+	longName := newCluster()
+	longName.Spec.Keyspaces[0].Name = strings.Repeat("main", 12)
+
+	overlappingShards := newCluster()
+	overlappingShards.Spec.Keyspaces[0].Spec.Shards = []*vitessv1alpha2.VitessShard{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a"}, Spec: vitessv1alpha2.VitessShardSpec{KeyRange: vitessv1alpha2.KeyRange{To: "80"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b"}, Spec: vitessv1alpha2.VitessShardSpec{KeyRange: vitessv1alpha2.KeyRange{From: "40"}}},
+	}
+
+	tests := []struct {
+		name     string
+		obj      runtime.Object
+		expected string
+	}{
+		{"valid cluster", newCluster(), ""},
+		{"cluster with lockserver and lockserverRef", bothLockservers, "LockserverAndLockserverRef"},
+		{"cluster with invalid selector operator", invalidSelector, "InvalidSelector"},
+		{"cluster with selected cells", pendingCells, ""},
+		{"cluster without cells", noCells, "NoCells"},
+		{"tablet in unknown cell", unknownCell, "NoCellForTablet"},
+		{"tablet hostname too long", longName, "TabletNameTooLong"},
+		{"overlapping shards", overlappingShards, "OverlappingKeyrange"},
+		{
+			"cell with lockserver and lockserverRef",
+			&vitessv1alpha2.VitessCell{Spec: vitessv1alpha2.VitessCellSpec{
+				Lockserver:    &vitessv1alpha2.VitessLockserver{Spec: testLockserverSpec},
+				LockserverRef: &corev1.LocalObjectReference{Name: "cell-lockserver"},
+			}},
+			"LockserverAndLockserverRef",
+		},
+		{
+			"unsharded keyspace not covering the full range",
+			&vitessv1alpha2.VitessKeyspace{Spec: vitessv1alpha2.VitessKeyspaceSpec{
+				Shards: []*vitessv1alpha2.VitessShard{{Spec: vitessv1alpha2.VitessShardSpec{KeyRange: vitessv1alpha2.KeyRange{To: "40"}}}},
+			}},
+			"UnshardedKeyrange",
+		},
+		{
+			// The selector may match the other shards
+			"keyspace with selected shards",
+			&vitessv1alpha2.VitessKeyspace{Spec: vitessv1alpha2.VitessKeyspaceSpec{
+				ShardSelector: testSel,
+				Shards:        []*vitessv1alpha2.VitessShard{{Spec: vitessv1alpha2.VitessShardSpec{KeyRange: vitessv1alpha2.KeyRange{To: "40"}}}},
+			}},
+			"",
+		},
+		{
+			"shard with invalid keyrange",
+			&vitessv1alpha2.VitessShard{Spec: vitessv1alpha2.VitessShardSpec{KeyRange: vitessv1alpha2.KeyRange{From: "zz"}}},
+			"InvalidKeyrange",
+		},
+		{
+			"shard with invalid tabletSelector",
+			&vitessv1alpha2.VitessShard{Spec: vitessv1alpha2.VitessShardSpec{TabletSelector: []vitessv1alpha2.ResourceSelector{{Key: "app", Operator: "Like"}}}},
+			"InvalidSelector",
+		},
+		{
+			"tablet ID out of range",
+			&vitessv1alpha2.VitessTablet{Spec: vitessv1alpha2.VitessTabletSpec{TabletID: vitessv1alpha2.MaxTabletID + 1}},
+			"TabletIDOutOfRange",
+		},
+		{
+			"lockserver without backend",
+			&vitessv1alpha2.VitessLockserver{Spec: vitessv1alpha2.VitessLockserverSpec{Type: vitessv1alpha2.LockserverTypeEtcd2}},
+			"NoLockserverBackend",
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessCell{}, &vitessv1alpha2.VitessCellList{})
+
+	n := New(fake.NewFakeClient())
+
+	for _, tc := range tests {
+		err := n.ValidateObject(tc.obj)
+		if tc.expected == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %s", tc.name, err)
+			}
+			continue
+		}
+
+		if err == nil {
+			t.Errorf("%s: expected %s error, got none", tc.name, tc.expected)
+		} else if name := GetValidationErrorName(err); name != tc.expected {
+			t.Errorf("%s: wrong error. Got: %s (%s); Expected: %s", tc.name, name, err, tc.expected)
+		}
+	}
+}
+
+func TestSetDefaults(t *testing.T) {
+	shard := &vitessv1alpha2.VitessShard{
+		Spec: vitessv1alpha2.VitessShardSpec{
+			Defaults: &vitessv1alpha2.VitessShardOptions{
+				Containers: &vitessv1alpha2.TabletContainers{
+					DBFlavor: "mariadb",
+					MySQL:    &vitessv1alpha2.MySQLContainer{Image: "percona:5.7"},
+					VTTablet: &vitessv1alpha2.VTTabletContainer{Image: "vitess/vttablet", DBFlavor: "mysql57"},
+				},
+			},
+			Tablets: []*vitessv1alpha2.VitessTablet{{}, {Spec: vitessv1alpha2.VitessTabletSpec{Type: vitessv1alpha2.TabletTypeBackup}}},
+		},
+	}
+
+	n := New(fake.NewFakeClient())
+	if err := n.SetDefaults(shard); err != nil {
+		t.Fatalf("Error setting defaults: %s", err)
+	}
+
+	if replicas := shard.Spec.Defaults.Replicas; replicas == nil || *replicas != 0 {
+		t.Errorf("Shard replicas not defaulted: %v", replicas)
+	}
+
+	containers := shard.Spec.Defaults.Containers
+	if containers.MySQL.DBFlavor != "mariadb" || containers.VTTablet.DBFlavor != "mysql57" {
+		t.Errorf("Wrong DB flavors. Got: %s and %s; Expected: mariadb and mysql57", containers.MySQL.DBFlavor, containers.VTTablet.DBFlavor)
+	}
+
+	// The tablets keep inheriting the replicas from the shard
+	for i, expected := range []vitessv1alpha2.TabletType{vitessv1alpha2.TabletTypeReplica, vitessv1alpha2.TabletTypeBackup} {
+		tablet := shard.Spec.Tablets[i]
+		if tablet.Spec.Type != expected || tablet.Spec.Datastore.Type != vitessv1alpha2.TabletDatastoreTypeLocal || tablet.Spec.Replicas != nil {
+			t.Errorf("Wrong tablet defaults: %+v", tablet.Spec)
+		}
+	}
+
+	lockserver := &vitessv1alpha2.VitessLockserver{
+		Spec: vitessv1alpha2.VitessLockserverSpec{
+			Provision: true,
+			Etcd2:     &vitessv1alpha2.Etcd2Lockserver{},
+		},
+	}
+	cluster := &vitessv1alpha2.VitessCluster{
+		Spec: vitessv1alpha2.VitessClusterSpec{
+			Lockserver: lockserver,
+			Monitoring: &vitessv1alpha2.VitessMonitoring{MySQLExporter: &vitessv1alpha2.MySQLExporter{}},
+		},
+	}
+
+	if err := n.SetDefaults(cluster); err != nil {
+		t.Fatalf("Error setting defaults: %s", err)
+	}
+
+	if lockserver.Spec.Type != vitessv1alpha2.LockserverTypeEtcd2 || lockserver.Spec.Etcd2.Image != vitessv1alpha2.EtcdImageDefault || *lockserver.Spec.Etcd2.Replicas != vitessv1alpha2.EtcdReplicasDefault {
+		t.Errorf("Wrong lockserver defaults: %+v", lockserver.Spec)
+	}
+
+	if image := cluster.Spec.Monitoring.MySQLExporter.Image; image != vitessv1alpha2.MySQLExporterImageDefault {
+		t.Errorf("Wrong mysqld_exporter image. Got: %s; Expected: %s", image, vitessv1alpha2.MySQLExporterImageDefault)
+	}
+}
+
+// TestNormalizeClusterRestores makes sure that restores only start on new tablets and stick to the tablets they restored
+func TestNormalizeClusterRestores(t *testing.T) {
+	cluster := &vitessv1alpha2.VitessCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testClusterName,
+			Namespace: testNamespace,
+		},
+	}
+	cell := &vitessv1alpha2.VitessCell{ObjectMeta: metav1.ObjectMeta{Name: "zone1"}}
+	keyspace := &vitessv1alpha2.VitessKeyspace{ObjectMeta: metav1.ObjectMeta{Name: "main"}}
+	shard := &vitessv1alpha2.VitessShard{ObjectMeta: metav1.ObjectMeta{Name: "0"}}
+
+	cluster.Spec.Keyspaces = []*vitessv1alpha2.VitessKeyspace{keyspace}
+	keyspace.Spec.Shards = []*vitessv1alpha2.VitessShard{shard}
+	keyspace.SetParentCluster(cluster)
+	shard.SetParentCluster(cluster)
+	shard.SetParentKeyspace(keyspace)
+
+	for _, tabletType := range []vitessv1alpha2.TabletType{vitessv1alpha2.TabletTypeReplica, vitessv1alpha2.TabletTypeReadOnly, vitessv1alpha2.TabletTypeBackup} {
+		tablet := &vitessv1alpha2.VitessTablet{
+			ObjectMeta: metav1.ObjectMeta{Name: string(tabletType)},
+			Spec:       vitessv1alpha2.VitessTabletSpec{Type: tabletType},
+		}
+		tablet.SetParentCluster(cluster)
+		tablet.SetParentCell(cell)
+		tablet.SetParentKeyspace(keyspace)
+		tablet.SetParentShard(shard)
+		shard.Spec.Tablets = append(shard.Spec.Tablets, tablet)
+	}
+	existing, created, restored := shard.Spec.Tablets[0], shard.Spec.Tablets[1], shard.Spec.Tablets[2]
+
+	source := vitessv1alpha2.VitessBackupStorage{
+		File: &vitessv1alpha2.FileBackupStorage{ClaimName: "backups"},
+	}
+
+	objs := []runtime.Object{
+		&vitessv1alpha2.VitessRestore{
+			ObjectMeta: metav1.ObjectMeta{Name: "done", Namespace: testNamespace},
+			Spec:       vitessv1alpha2.VitessRestoreSpec{Cluster: testClusterName, Source: source},
+			Status:     vitessv1alpha2.VitessRestoreStatus{Phase: vitessv1alpha2.RestorePhaseComplete},
+		},
+		&vitessv1alpha2.VitessRestore{
+			ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: testNamespace},
+			Spec:       vitessv1alpha2.VitessRestoreSpec{Cluster: testClusterName, Source: source},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: existing.GetStatefulSetName(), Namespace: testNamespace},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        restored.GetStatefulSetName(),
+				Namespace:   testNamespace,
+				Annotations: map[string]string{vitessv1alpha2.TabletRestoreAnnotation: "done"},
+			},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessRestore{})
+	s.AddKnownTypes(vitessv1alpha2.SchemeGroupVersion, &vitessv1alpha2.VitessRestoreList{})
+
+	n := New(fake.NewFakeClient(objs...))
+	if err := n.NormalizeClusterRestores(cluster); err != nil {
+		t.Fatalf("Error normalizing cluster restores: %s", err)
+	}
+
+	// Restoring a tablet that already exists would replace its pods
+	if restore := existing.Restore(); restore != nil {
+		t.Errorf("Existing tablet restored by %s", restore.GetName())
+	}
+
+	if restore := created.Restore(); restore == nil || restore.GetName() != "pending" {
+		t.Errorf("New tablet not restored by the pending restore: %v", restore)
+	}
+
+	// Finishing the restore doesn't change the backup storage of the tablets it restored
+	if restore := restored.Restore(); restore == nil || restore.GetName() != "done" {
+		t.Errorf("Restored tablet not pinned to its restore: %v", restore)
+	}
+}
+
+func TestNormalizeClusterTabletUIDs(t *testing.T) {
+	cluster := &vitessv1alpha2.VitessCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testClusterName,
+			Namespace: testNamespace,
+		},
+	}
+	cell := &vitessv1alpha2.VitessCell{ObjectMeta: metav1.ObjectMeta{Name: "zone2"}}
+	keyspace := &vitessv1alpha2.VitessKeyspace{ObjectMeta: metav1.ObjectMeta{Name: "main"}}
+	shard := &vitessv1alpha2.VitessShard{ObjectMeta: metav1.ObjectMeta{Name: "0"}}
+
+	cluster.Spec.Keyspaces = []*vitessv1alpha2.VitessKeyspace{keyspace}
+	keyspace.Spec.Shards = []*vitessv1alpha2.VitessShard{shard}
+	keyspace.SetParentCluster(cluster)
+	shard.SetParentCluster(cluster)
+	shard.SetParentKeyspace(keyspace)
+
+	for _, tabletType := range []vitessv1alpha2.TabletType{vitessv1alpha2.TabletTypeReplica, vitessv1alpha2.TabletTypeReadOnly, vitessv1alpha2.TabletTypeBackup} {
+		tablet := &vitessv1alpha2.VitessTablet{
+			ObjectMeta: metav1.ObjectMeta{Name: string(tabletType)},
+			Spec:       vitessv1alpha2.VitessTabletSpec{Type: tabletType, TabletID: 101},
+		}
+		tablet.SetParentCluster(cluster)
+		tablet.SetParentCell(cell)
+		tablet.SetParentKeyspace(keyspace)
+		tablet.SetParentShard(shard)
+		shard.Spec.Tablets = append(shard.Spec.Tablets, tablet)
+	}
+	legacy, pinned, created := shard.Spec.Tablets[0], shard.Spec.Tablets[1], shard.Spec.Tablets[2]
+
+	objs := []runtime.Object{
+		// Created by an operator whose init script derived the UID itself
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: legacy.GetStatefulSetName(), Namespace: testNamespace},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        pinned.GetStatefulSetName(),
+				Namespace:   testNamespace,
+				Annotations: map[string]string{vitessv1alpha2.TabletUIDBaseAnnotation: "4200"},
+			},
+		},
+	}
+
+	n := New(fake.NewFakeClient(objs...))
+	if err := n.NormalizeClusterTabletUIDs(cluster); err != nil {
+		t.Fatalf("Error normalizing cluster tablet UIDs: %s", err)
+	}
+
+	// The old init script took the first 6 hex digits of md5sum of zone1-<pod prefix>, whatever the cell
+	sum := md5.Sum([]byte("zone1-" + legacy.GetStatefulSetName()))
+	hash, _ := strconv.ParseUint(hex.EncodeToString(sum[:])[:6], 16, 32)
+	if uid := legacy.GetTabletUID(2); uid != uint32(hash*100+2) {
+		t.Errorf("Existing tablet changed UID: Got: %d; Expected: %d", uid, hash*100+2)
+	}
+
+	// Setting a tablet ID doesn't rename tablets that already exist
+	if uid := pinned.GetTabletUID(1); uid != 4201 {
+		t.Errorf("Tablet UID not taken from its StatefulSet: Got: %d; Expected: 4201", uid)
+	}
+
+	if uid := created.GetTabletUID(1); uid != 10101 {
+		t.Errorf("New tablet UID not taken from its tablet ID: Got: %d; Expected: 10101", uid)
+	}
+}
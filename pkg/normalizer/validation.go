@@ -1,6 +1,9 @@
 package normalizer
 
 import (
+	"bytes"
+	"fmt"
+	"sort"
 	"strings"
 
 	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
@@ -21,6 +24,10 @@ func (n *Normalizer) ValidateCluster(cluster *vitessv1alpha2.VitessCluster) erro
 		return ValidationErrorNoLockserverForCluster
 	}
 
+	if err := n.ValidateLockserver(cluster.Lockserver()); err != nil {
+		return err
+	}
+
 	if len(cluster.Cells()) == 0 {
 		return ValidationErrorNoCells
 	}
@@ -29,28 +36,53 @@ func (n *Normalizer) ValidateCluster(cluster *vitessv1alpha2.VitessCluster) erro
 		if cell.Lockserver() == nil {
 			return ValidationErrorNoLockserverForCell
 		}
+
+		if err := n.ValidateLockserver(cell.Lockserver()); err != nil {
+			return err
+		}
+
+		// Vitess components take a single topo implementation for both the global and cell topology
+		if cell.Lockserver().GetType() != cluster.Lockserver().GetType() {
+			return ValidationErrorCellLockserverTypeMismatch
+		}
+
+		// ...and a single set of topo credentials
+		if cell.Lockserver().TopoCredentialsConflict(cluster.Lockserver()) {
+			return ValidationErrorCellLockserverCredentialsMismatch
+		}
 	}
 
 	if len(cluster.Keyspaces()) == 0 {
 		return ValidationErrorNoKeyspaces
 	}
 
-	if len(cluster.Shards()) == 0 {
-		return ValidationErrorNoShards
+	// Keyspaces and shards can replace the backup storage of the cluster
+	backupStorages := []*vitessv1alpha2.VitessBackupStorage{cluster.BackupStorage()}
+	for _, keyspace := range cluster.Keyspaces() {
+		backupStorages = append(backupStorages, keyspace.GetTabletBackupStorage())
+		for _, shard := range keyspace.Shards() {
+			backupStorages = append(backupStorages, shard.GetTabletBackupStorage())
+		}
 	}
 
-	// check for overlapping keyranges
-	for _, shard := range cluster.Shards() {
-		// store matched keyranges
-		keyranges := make(map[string]struct{})
+	for _, backupStorage := range backupStorages {
+		if backupStorage == nil {
+			continue
+		}
 
-		// if keyrange string is already in the map then it is a duplicate
-		if _, ok := keyranges[shard.Spec.KeyRange.String()]; ok {
-			return ValidationErrorOverlappingKeyrange
+		if err := n.ValidateBackupStorage(backupStorage); err != nil {
+			return err
 		}
+	}
 
-		// set keyrange string as existing
-		keyranges[shard.Spec.KeyRange.String()] = struct{}{}
+	if len(cluster.Shards()) == 0 {
+		return ValidationErrorNoShards
+	}
+
+	for _, keyspace := range cluster.Keyspaces() {
+		if err := n.ValidateKeyspaceKeyranges(keyspace); err != nil {
+			return err
+		}
 	}
 
 	if len(cluster.Tablets()) == 0 {
@@ -61,6 +93,248 @@ func (n *Normalizer) ValidateCluster(cluster *vitessv1alpha2.VitessCluster) erro
 		if tablet.Cell() == nil {
 			return ValidationErrorNoCellForTablet
 		}
+
+		if err := n.ValidateTablet(tablet); err != nil {
+			return err
+		}
+
+		if tablet.GetRestorePolicy().FromBackup && tablet.GetBackupStorage() == nil {
+			return ValidationErrorRestoreWithoutBackupStorage
+		}
+	}
+
+	if err := n.ValidateTabletUIDs(cluster.Tablets()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ValidateKeyspaceKeyranges makes sure that the shards of a sharded keyspace cover the full keyspace ID range
+// without overlapping, and that the only shard of an unsharded keyspace is shard 0
+func (n *Normalizer) ValidateKeyspaceKeyranges(keyspace *vitessv1alpha2.VitessKeyspace) error {
+	shards := keyspace.Shards()
+
+	shardError := func(err ValidationError, shards ...*vitessv1alpha2.VitessShard) error {
+		names := []string{}
+		for _, shard := range shards {
+			names = append(names, describeShard(shard))
+		}
+		return &ShardValidationError{Err: err, Keyspace: keyspace.GetName(), Shards: names}
+	}
+
+	type bounds struct {
+		shard *vitessv1alpha2.VitessShard
+		start []byte
+		end   []byte
+	}
+
+	ranges := []bounds{}
+	for _, shard := range shards {
+		start, end, err := shard.Spec.KeyRange.Parse()
+		if err != nil {
+			return shardError(ValidationErrorInvalidKeyrange, shard)
+		}
+		ranges = append(ranges, bounds{shard: shard, start: start, end: end})
+	}
+
+	switch len(ranges) {
+	case 0:
+		// Keyspaces without shards are caught with the rest of the cluster
+		return nil
+	case 1:
+		if !ranges[0].shard.Spec.KeyRange.IsFull() {
+			return shardError(ValidationErrorUnshardedKeyrange, ranges[0].shard)
+		}
+		return nil
+	}
+
+	// An empty end is the end of the keyspace ID range, so it sorts last
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if c := bytes.Compare(ranges[i].start, ranges[j].start); c != 0 {
+			return c < 0
+		}
+		return len(ranges[j].end) == 0 || (len(ranges[i].end) != 0 && bytes.Compare(ranges[i].end, ranges[j].end) < 0)
+	})
+
+	// Once sorted by start, any overlap shows between neighbours
+	for i := 1; i < len(ranges); i++ {
+		if overlaps, _ := ranges[i-1].shard.Spec.KeyRange.Overlaps(&ranges[i].shard.Spec.KeyRange); overlaps {
+			return shardError(ValidationErrorOverlappingKeyrange, ranges[i-1].shard, ranges[i].shard)
+		}
+	}
+
+	if first := ranges[0]; len(first.start) != 0 {
+		return shardError(ValidationErrorKeyrangeGap, first.shard)
+	}
+
+	for i := 1; i < len(ranges); i++ {
+		if !bytes.Equal(ranges[i-1].end, ranges[i].start) {
+			return shardError(ValidationErrorKeyrangeGap, ranges[i-1].shard, ranges[i].shard)
+		}
+	}
+
+	if last := ranges[len(ranges)-1]; len(last.end) != 0 {
+		return shardError(ValidationErrorKeyrangeGap, last.shard)
+	}
+
+	return nil
+}
+
+// describeShard names a shard in validation errors by its keyrange, and its object name if it has one
+func describeShard(shard *vitessv1alpha2.VitessShard) string {
+	if shard.GetName() == "" || shard.GetName() == shard.Spec.KeyRange.String() {
+		return shard.Spec.KeyRange.String()
+	}
+	return fmt.Sprintf("%s (%s)", shard.GetName(), shard.Spec.KeyRange.String())
+}
+
+// ValidateTabletUIDs makes sure that no two tablet pods in the cluster get the same UID. Besides the tablet
+// alias, the UID is used as the MySQL server_id, which has to be unique across cells for replication to work.
+func (n *Normalizer) ValidateTabletUIDs(tablets []*vitessv1alpha2.VitessTablet) error {
+	type uidRange struct {
+		start  uint32
+		end    uint32
+		tablet string
+	}
+
+	ranges := []uidRange{}
+	for _, tablet := range tablets {
+		replicas := *tablet.GetReplicas()
+		if err := validateTabletUIDRange(tablet, replicas); err != nil {
+			return err
+		}
+
+		if replicas > 0 {
+			base := tablet.GetTabletUIDBase()
+			ranges = append(ranges, uidRange{start: base, end: base + uint32(replicas), tablet: tablet.GetStatefulSetName()})
+		}
+	}
+
+	// Ties are broken by tablet name so that the order doesn't depend on the order of the tablets
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].start != ranges[j].start {
+			return ranges[i].start < ranges[j].start
+		}
+		if ranges[i].end != ranges[j].end {
+			return ranges[i].end < ranges[j].end
+		}
+		return ranges[i].tablet < ranges[j].tablet
+	})
+
+	for i := 1; i < len(ranges); i++ {
+		if ranges[i].start < ranges[i-1].end {
+			return &TabletValidationError{Err: ValidationErrorDuplicateTabletUID, Tablets: []string{ranges[i-1].tablet, ranges[i].tablet}}
+		}
+	}
+
+	return nil
+}
+
+// validateTabletUIDRange makes sure that the tablet ID and the number of replicas of a tablet fit in its UID range
+func validateTabletUIDRange(tablet *vitessv1alpha2.VitessTablet, replicas int32) error {
+	if tablet.Spec.TabletID < 0 || tablet.Spec.TabletID > vitessv1alpha2.MaxTabletID {
+		return ValidationErrorTabletIDOutOfRange
+	}
+
+	if replicas > vitessv1alpha2.TabletUIDOrdinalRange {
+		return ValidationErrorTooManyTabletReplicas
+	}
+
+	return nil
+}
+
+// ValidateLockserver makes sure that exactly one backend block is set and that it matches the lockserver type
+func (n *Normalizer) ValidateLockserver(lockserver *vitessv1alpha2.VitessLockserver) error {
+	backends := map[vitessv1alpha2.LockserverType]bool{
+		vitessv1alpha2.LockserverTypeEtcd2:  lockserver.Spec.Etcd2 != nil,
+		vitessv1alpha2.LockserverTypeZk2:    lockserver.Spec.Zk2 != nil,
+		vitessv1alpha2.LockserverTypeConsul: lockserver.Spec.Consul != nil,
+	}
+
+	count := 0
+	for _, set := range backends {
+		if set {
+			count++
+		}
+	}
+
+	switch {
+	case count > 1:
+		return ValidationErrorMultipleLockserverBackends
+	case count == 0 && lockserver.Spec.Provision:
+		// Provisioned lockservers get their backend block from the operator
+		return nil
+	case count == 0:
+		return ValidationErrorNoLockserverBackend
+	case !backends[lockserver.GetType()]:
+		return ValidationErrorLockserverTypeMismatch
+	}
+
+	if tls := lockserver.GetEtcdTLS(); tls != nil && (tls.CertSecretRef == nil) != (tls.KeySecretRef == nil) {
+		return ValidationErrorIncompleteLockserverTLS
+	}
+
+	return nil
+}
+
+// ValidateBackupStorage makes sure that exactly one backup storage backend is set and that it is complete
+func (n *Normalizer) ValidateBackupStorage(backupStorage *vitessv1alpha2.VitessBackupStorage) error {
+	count := 0
+	for _, set := range []bool{
+		backupStorage.File != nil,
+		backupStorage.S3 != nil,
+		backupStorage.GCS != nil,
+	} {
+		if set {
+			count++
+		}
+	}
+
+	switch {
+	case count > 1:
+		return ValidationErrorMultipleBackupStorageBackends
+	case count == 0:
+		return ValidationErrorNoBackupStorageBackend
+	}
+
+	switch backupStorage.GetType() {
+	case vitessv1alpha2.BackupStorageTypeFile:
+		if backupStorage.File.ClaimName == "" {
+			return ValidationErrorIncompleteBackupStorage
+		}
+	case vitessv1alpha2.BackupStorageTypeS3:
+		if backupStorage.S3.Bucket == "" {
+			return ValidationErrorIncompleteBackupStorage
+		}
+		if (backupStorage.S3.AccessKeyIDSecretRef == nil) != (backupStorage.S3.SecretAccessKeySecretRef == nil) {
+			return ValidationErrorIncompleteBackupCredentials
+		}
+	case vitessv1alpha2.BackupStorageTypeGCS:
+		if backupStorage.GCS.Bucket == "" {
+			return ValidationErrorIncompleteBackupStorage
+		}
+	}
+
+	return nil
+}
+
+// ValidateRestorePointInTime makes sure that a restore to a point in time has a single target and a binlog source
+func (n *Normalizer) ValidateRestorePointInTime(restore *vitessv1alpha2.VitessRestore) error {
+	if !restore.IsPointInTime() {
+		return nil
+	}
+
+	if restore.Spec.RestoreToTime != nil && restore.Spec.RestoreToPosition != "" {
+		return ValidationErrorRestoreToTimeAndPosition
+	}
+
+	if restore.Spec.RestoreToPosition != "" && !strings.HasPrefix(restore.Spec.RestoreToPosition, vitessv1alpha2.RestorePositionFlavor+"/") {
+		return ValidationErrorUnsupportedRestorePosition
+	}
+
+	if restore.Spec.BinlogSourceCluster == "" {
+		return ValidationErrorRestoreWithoutBinlogSource
 	}
 
 	return nil
@@ -0,0 +1,104 @@
+package conversion
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// Hub is the version of a kind that all of its other versions convert to and from
+type Hub interface {
+	runtime.Object
+	Hub()
+}
+
+// Convertible is a version of a kind that converts to and from the Hub of the kind
+type Convertible interface {
+	runtime.Object
+	ConvertTo(dst Hub) error
+	ConvertFrom(src Hub) error
+}
+
+// Convert returns obj converted to the given version of its kind. Conversions between two versions that
+// aren't the Hub go through the Hub. An object that already has the given version is returned as is.
+func Convert(s *runtime.Scheme, obj runtime.Object, gv schema.GroupVersion) (runtime.Object, error) {
+	gvk, err := apiutil.GVKForObject(obj, s)
+	if err != nil {
+		return nil, err
+	}
+
+	if gvk.GroupVersion() == gv {
+		return obj, nil
+	}
+
+	if gvk.Group != gv.Group {
+		return nil, fmt.Errorf("Cannot convert %s to a different group %s", gvk, gv)
+	}
+
+	dstGVK := gv.WithKind(gvk.Kind)
+	dst, err := s.New(dstGVK)
+	if err != nil {
+		return nil, err
+	}
+
+	hub, err := toHub(s, obj, gvk.GroupKind())
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := dst.(Hub); ok {
+		// obj isn't the Hub since its version differs, so hub was converted from it
+		dst = hub
+	} else {
+		convertible, ok := dst.(Convertible)
+		if !ok {
+			return nil, fmt.Errorf("%s is neither a Hub nor Convertible", dstGVK)
+		}
+		if err := convertible.ConvertFrom(hub); err != nil {
+			return nil, err
+		}
+	}
+
+	dst.GetObjectKind().SetGroupVersionKind(dstGVK)
+	return dst, nil
+}
+
+// toHub returns obj converted to the Hub of its kind, or obj itself if it is the Hub
+func toHub(s *runtime.Scheme, obj runtime.Object, gk schema.GroupKind) (Hub, error) {
+	if hub, ok := obj.(Hub); ok {
+		return hub, nil
+	}
+
+	convertible, ok := obj.(Convertible)
+	if !ok {
+		return nil, fmt.Errorf("%s %T is neither a Hub nor Convertible", gk, obj)
+	}
+
+	hub, err := newHub(s, gk)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := convertible.ConvertTo(hub); err != nil {
+		return nil, err
+	}
+
+	return hub, nil
+}
+
+// newHub returns an empty object of the version of a kind that is its Hub
+func newHub(s *runtime.Scheme, gk schema.GroupKind) (Hub, error) {
+	for _, gv := range s.PrioritizedVersionsForGroup(gk.Group) {
+		obj, err := s.New(gv.WithKind(gk.Kind))
+		if err != nil {
+			continue
+		}
+		if hub, ok := obj.(Hub); ok {
+			return hub, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s has no Hub version", gk)
+}
@@ -0,0 +1,72 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	for _, spec := range []string{
+		"* * * * *",
+		"*/15 1-5 * * 1,3",
+		"0 3 * * 7",
+		"5/10 * 1-31/2 * *",
+		"@daily",
+	} {
+		if _, err := Parse(spec); err != nil {
+			t.Errorf("Unexpected error parsing %q: %s", spec, err)
+		}
+	}
+
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * JAN *",
+		"@sometimes",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Expected an error parsing %q", spec)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	from := time.Date(2019, time.January, 30, 10, 17, 42, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2019, time.January, 30, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2019, time.January, 30, 10, 30, 0, 0, time.UTC)},
+		{"17 * * * *", time.Date(2019, time.January, 30, 11, 17, 0, 0, time.UTC)},
+		{"@daily", time.Date(2019, time.January, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 3 1 * *", time.Date(2019, time.February, 1, 3, 0, 0, 0, time.UTC)},
+		// January 30th 2019 is a Wednesday
+		{"0 0 * * 0", time.Date(2019, time.February, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2019, time.February, 3, 0, 0, 0, 0, time.UTC)},
+		{"30 9-17/4 * * 1-5", time.Date(2019, time.January, 30, 13, 30, 0, 0, time.UTC)},
+		// Restricting both day fields matches either of them
+		{"0 0 15 * 5", time.Date(2019, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2020, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.Time{}},
+	}
+
+	for _, test := range tests {
+		schedule, err := Parse(test.spec)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing %q: %s", test.spec, err)
+		}
+
+		if got := schedule.Next(from); !got.Equal(test.want) {
+			t.Errorf("Next for %q: got %s, want %s", test.spec, got, test.want)
+		}
+	}
+}
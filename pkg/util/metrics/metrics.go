@@ -0,0 +1,164 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const namespace = "vitess_operator"
+
+// Reconcile results
+const (
+	ResultSuccess = "success"
+	ResultRequeue = "requeue"
+	ResultError   = "error"
+)
+
+var (
+	// ReconcileTotal counts the reconciles of every controller by result
+	ReconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconcile_total",
+		Help:      "Total number of reconciles per controller and result",
+	}, []string{"controller", "result"})
+
+	// ReconcileDuration observes how long the reconciles of every controller take by result
+	ReconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Duration of reconciles per controller and result",
+	}, []string{"controller", "result"})
+
+	// ValidationErrors counts the clusters that failed validation by validation error
+	ValidationErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "validation_errors_total",
+		Help:      "Total number of cluster validation failures per validation error",
+	}, []string{"error"})
+
+	// Tablets is the number of tablets of every cluster by phase
+	Tablets = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "tablets",
+		Help:      "Number of managed tablets per cluster and phase",
+	}, []string{"namespace", "cluster", "phase"})
+
+	lastClusterReconcile = newSinceCollector(prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cluster", "seconds_since_last_successful_reconcile"),
+		"Seconds since the cluster was last reconciled without an error",
+		[]string{"namespace", "cluster"}, nil,
+	))
+
+	// timeNow is stubbed in tests
+	timeNow = time.Now
+)
+
+func init() {
+	// The manager serves the controller-runtime registry on its metrics address
+	metrics.Registry.MustRegister(
+		ReconcileTotal,
+		ReconcileDuration,
+		ValidationErrors,
+		Tablets,
+		lastClusterReconcile,
+	)
+}
+
+// instrumentedReconciler records the result and duration of every reconcile of a controller
+type instrumentedReconciler struct {
+	controller string
+	reconciler reconcile.Reconciler
+}
+
+// InstrumentReconciler returns a reconciler that records the reconciles of r under the given controller name
+func InstrumentReconciler(controller string, r reconcile.Reconciler) reconcile.Reconciler {
+	return &instrumentedReconciler{controller: controller, reconciler: r}
+}
+
+// Reconcile calls the wrapped reconciler and records its result and duration
+func (r *instrumentedReconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	start := timeNow()
+	result, err := r.reconciler.Reconcile(request)
+
+	outcome := ResultSuccess
+	if err != nil {
+		outcome = ResultError
+	} else if result.Requeue || result.RequeueAfter > 0 {
+		outcome = ResultRequeue
+	}
+
+	ReconcileTotal.WithLabelValues(r.controller, outcome).Inc()
+	ReconcileDuration.WithLabelValues(r.controller, outcome).Observe(timeNow().Sub(start).Seconds())
+
+	return result, err
+}
+
+// RecordValidationError counts a cluster that failed validation with the named error
+func RecordValidationError(name string) {
+	ValidationErrors.WithLabelValues(name).Inc()
+}
+
+// SetClusterTablets sets the number of tablets of the cluster in the given phase
+func SetClusterTablets(namespace string, cluster string, phase string, count int) {
+	Tablets.WithLabelValues(namespace, cluster, phase).Set(float64(count))
+}
+
+// ClusterReconciled records that the cluster was just reconciled without an error
+func ClusterReconciled(namespace string, cluster string) {
+	lastClusterReconcile.set(types.NamespacedName{Namespace: namespace, Name: cluster}, timeNow())
+}
+
+// ForgetCluster removes the metrics of a cluster that was deleted. The tablet phases are passed
+// in since the tablet gauges can only be deleted by their full labels.
+func ForgetCluster(namespace string, cluster string, phases []string) {
+	for _, phase := range phases {
+		Tablets.DeleteLabelValues(namespace, cluster, phase)
+	}
+	lastClusterReconcile.delete(types.NamespacedName{Namespace: namespace, Name: cluster})
+}
+
+// sinceCollector reports the seconds elapsed since a time recorded per object, computed when scraped
+// so that an object that stops being recorded shows up as growing
+type sinceCollector struct {
+	desc *prometheus.Desc
+
+	mu    sync.Mutex
+	times map[types.NamespacedName]time.Time
+}
+
+func newSinceCollector(desc *prometheus.Desc) *sinceCollector {
+	return &sinceCollector{desc: desc, times: map[types.NamespacedName]time.Time{}}
+}
+
+func (c *sinceCollector) set(key types.NamespacedName, t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.times[key] = t
+}
+
+func (c *sinceCollector) delete(key types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.times, key)
+}
+
+// Describe implements prometheus.Collector
+func (c *sinceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector
+func (c *sinceCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := timeNow()
+	for key, t := range c.times {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, now.Sub(t).Seconds(), key.Namespace, key.Name)
+	}
+}
@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type fakeReconciler struct {
+	result reconcile.Result
+	err    error
+}
+
+func (r *fakeReconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	return r.result, r.err
+}
+
+func TestInstrumentReconciler(t *testing.T) {
+	for _, test := range []struct {
+		reconciler *fakeReconciler
+		outcome    string
+	}{
+		{&fakeReconciler{}, ResultSuccess},
+		{&fakeReconciler{result: reconcile.Result{RequeueAfter: time.Minute}}, ResultRequeue},
+		{&fakeReconciler{err: errors.New("failed")}, ResultError},
+	} {
+		before := testutil.ToFloat64(ReconcileTotal.WithLabelValues("test-controller", test.outcome))
+
+		_, err := InstrumentReconciler("test-controller", test.reconciler).Reconcile(reconcile.Request{})
+		if err != test.reconciler.err {
+			t.Errorf("Error not passed through. Got: %v; Expected: %v", err, test.reconciler.err)
+		}
+
+		if after := testutil.ToFloat64(ReconcileTotal.WithLabelValues("test-controller", test.outcome)); after != before+1 {
+			t.Errorf("Reconcile not counted as %s. Got: %v; Expected: %v", test.outcome, after, before+1)
+		}
+	}
+}
+
+func TestClusterReconciled(t *testing.T) {
+	now := time.Date(2019, 1, 1, 12, 0, 0, 0, time.UTC)
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return now }
+
+	ClusterReconciled("vitess", "vt")
+	now = now.Add(90 * time.Second)
+
+	expected := `
+# HELP vitess_operator_cluster_seconds_since_last_successful_reconcile Seconds since the cluster was last reconciled without an error
+# TYPE vitess_operator_cluster_seconds_since_last_successful_reconcile gauge
+vitess_operator_cluster_seconds_since_last_successful_reconcile{cluster="vt",namespace="vitess"} 90
+`
+	if err := testutil.CollectAndCompare(lastClusterReconcile, strings.NewReader(expected)); err != nil {
+		t.Errorf("Wrong time since the last reconcile: %s", err)
+	}
+
+	SetClusterTablets("vitess", "vt", "Ready", 2)
+	ForgetCluster("vitess", "vt", []string{"None", "Ready"})
+
+	if err := testutil.CollectAndCompare(lastClusterReconcile, strings.NewReader("")); err != nil {
+		t.Errorf("Deleted cluster still reported: %s", err)
+	}
+
+	if err := testutil.CollectAndCompare(Tablets, strings.NewReader("")); err != nil {
+		t.Errorf("Tablets of a deleted cluster still reported: %s", err)
+	}
+}
@@ -0,0 +1,35 @@
+package scripts
+
+const (
+	// BackupFlagsTemplate is shared by every component that reads or writes backups.
+	// It is executed against the VitessBackupStorage with {{ template "backupflags" .BackupStorage }}.
+	BackupFlagsTemplate = `
+{{- define "backupflags" -}}
+  -backup_storage_implementation="{{ .GetType }}"
+{{- with .GetFileBackupStorageRoot }}
+  -file_backup_storage_root="{{ . }}"
+{{- end }}
+{{- with .S3 }}
+  -s3_backup_storage_bucket="{{ .Bucket }}"
+{{- with .Region }}
+  -s3_backup_aws_region="{{ . }}"
+{{- end }}
+{{- with .Endpoint }}
+  -s3_backup_aws_endpoint="{{ . }}"
+{{- end }}
+{{- with .Root }}
+  -s3_backup_storage_root="{{ . }}"
+{{- end }}
+{{- if .ForcePathStyle }}
+  -s3_backup_force_path_style=true
+{{- end }}
+{{- end }}
+{{- with .GCS }}
+  -gcs_backup_storage_bucket="{{ .Bucket }}"
+{{- with .Root }}
+  -gcs_backup_storage_root="{{ . }}"
+{{- end }}
+{{- end }}
+{{- end -}}
+`
+)
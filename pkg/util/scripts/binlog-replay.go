@@ -0,0 +1,45 @@
+package scripts
+
+const (
+	// BinlogReplayTemplate replays the binlogs of the binlog source on top of the backup that a restored tablet pod
+	// restored, up to the point in time of its VitessRestore. The operator sets the source host on the pod once
+	// the backup is restored, and reads the outcome from the logs of the container.
+	BinlogReplayTemplate = `
+set -eo pipefail
+
+until [ -s /podinfo/binlog-source ]; do
+  sleep 5
+done
+source_host=$(cat /podinfo/binlog-source)
+
+mysql="mysql -u vt_dba --socket=/vtdataroot/tabletdata/mysql.sock"
+restored=$($mysql -N -e 'SELECT @@GLOBAL.gtid_executed' | tr -d '\n')
+{{- with .Restore.GetRestoreToGTIDSet }}
+
+# Replaying can't go back to a position before the restored backup
+if [ "$($mysql -N -e "SELECT GTID_SUBSET('$restored', '{{ . }}')")" != "1" ]; then
+  echo "Binlog replay failed: the restored backup is past the restore position"
+  exec sleep infinity
+fi
+{{- end }}
+
+# With the GTID protocol and no binlog name, the source starts from the first binlog holding a transaction
+# that the restored backup doesn't have
+mysqlbinlog \
+  --read-from-remote-master=BINLOG-DUMP-GTIDS \
+  --host="$source_host" \
+  --user=vt_repl \
+  --to-last-log \
+  --exclude-gtids="$restored" \
+{{- with .Restore.Spec.RestoreToTime }}
+  --stop-datetime="{{ .UTC.Format "2006-01-02 15:04:05" }}" \
+{{- end }}
+{{- with .Restore.GetRestoreToGTIDSet }}
+  --include-gtids="{{ . }}" \
+{{- end }}
+  "" | $mysql
+
+echo "Binlog replay complete"
+exec sleep infinity
+`
+)
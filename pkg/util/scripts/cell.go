@@ -0,0 +1,34 @@
+package scripts
+
+const (
+	// RegisterCellTemplate creates or updates the cell in the global topology,
+	// pointing it at the cell's own lockserver
+	RegisterCellTemplate = `
+set -ex
+
+eval exec /vt/bin/vtctl $(cat <<END_OF_COMMAND
+  {{ template "topoflags" .GlobalLockserver }}
+  -logtostderr=true
+  -stderrthreshold=0
+  UpdateCellInfo
+  -server_address="{{ .LocalLockserver.GetTopoServerAddress }}"
+  -root="{{ .LocalLockserver.GetTopoRoot }}"
+  "{{ .Cell.Name }}"
+END_OF_COMMAND
+)
+`
+
+	// DeregisterCellTemplate removes the cell from the global topology
+	DeregisterCellTemplate = `
+set -ex
+
+eval exec /vt/bin/vtctl $(cat <<END_OF_COMMAND
+  {{ template "topoflags" .GlobalLockserver }}
+  -logtostderr=true
+  -stderrthreshold=0
+  DeleteCellInfo
+  "{{ .Cell.Name }}"
+END_OF_COMMAND
+)
+`
+)
@@ -54,8 +54,8 @@ func (csg *ContainerScriptGenerator) Generate() error {
 		if err != nil {
 			return err
 		}
-	case "init_replica_master":
-		csg.Start, err = csg.getTemplatedScript("init_replica_master", InitReplicaMaster)
+	case "binlog-replay":
+		csg.Start, err = csg.getTemplatedScript("binlog-replay", BinlogReplayTemplate)
 		if err != nil {
 			return err
 		}
@@ -69,6 +69,16 @@ func (csg *ContainerScriptGenerator) Generate() error {
 		if err != nil {
 			return err
 		}
+	case "register_cell":
+		csg.Start, err = csg.getTemplatedScript("register_cell", RegisterCellTemplate)
+		if err != nil {
+			return err
+		}
+	case "deregister_cell":
+		csg.Start, err = csg.getTemplatedScript("deregister_cell", DeregisterCellTemplate)
+		if err != nil {
+			return err
+		}
 	case "init-mysql-creds":
 		csg.Start, err = csg.getTemplatedScript("init-mysql-creds", InitMySQLCreds)
 		if err != nil {
@@ -82,7 +92,18 @@ func (csg *ContainerScriptGenerator) Generate() error {
 }
 
 func (csg *ContainerScriptGenerator) getTemplatedScript(name string, templateStr string) (string, error) {
-	tmpl, err := template.New(name).Parse(templateStr)
+	// Shared partials are parsed first so that every script can use them
+	tmpl, err := template.New(name).Parse(TopoFlagsTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err = tmpl.Parse(BackupFlagsTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err = tmpl.Parse(templateStr)
 	if err != nil {
 		return "", err
 	}
@@ -104,16 +125,21 @@ func (csg *ContainerScriptGenerator) getTemplatedScript(name string, templateStr
 	// end-user configurable could would potentially expose too much data and would need to be sanitized
 	var params map[string]interface{}
 
+	// GlobalLockserver carries the credentials of the cell lockserver too, since the topo flags
+	// configure the connections to both of them
+
 	// Configure tablet params
 	if tablet, ok := csg.Object.(*vitessv1alpha2.VitessTablet); ok {
 		params = map[string]interface{}{
 			"LocalLockserver":  tablet.Lockserver(),
-			"GlobalLockserver": tablet.Cluster().Lockserver(),
+			"GlobalLockserver": tablet.Cell().TopoLockserver(),
 			"Cluster":          tablet.Cluster(),
 			"Cell":             tablet.Cell(),
 			"Keyspace":         tablet.Keyspace(),
 			"Shard":            tablet.Shard(),
 			"Tablet":           tablet,
+			"BackupStorage":    tablet.GetBackupStorage(),
+			"Restore":          tablet.Restore(),
 			"ScopedName":       tablet.GetScopedName(),
 		}
 	}
@@ -122,9 +148,10 @@ func (csg *ContainerScriptGenerator) getTemplatedScript(name string, templateStr
 	if cell, ok := csg.Object.(*vitessv1alpha2.VitessCell); ok {
 		params = map[string]interface{}{
 			"LocalLockserver":  cell.Lockserver(),
-			"GlobalLockserver": cell.Cluster().Lockserver(),
+			"GlobalLockserver": cell.TopoLockserver(),
 			"Cluster":          cell.Cluster(),
 			"Cell":             cell,
+			"BackupStorage":    cell.Cluster().BackupStorage(),
 			"ScopedName":       cell.GetScopedName(),
 		}
 	}
@@ -21,6 +21,7 @@ touch /vtdataroot/tabletdata/general.log
 rm -f /vtdataroot/tabletdata/mysql.sock
 `
 
+	// mysqlctld gets the same backup flags as vttablet so that both are configured for the same backup storage.
 	MySQLStartTemplate = `
 set -ex
 if [ "$VT_DB_FLAVOR" = "percona" ]; then
@@ -55,6 +56,9 @@ eval exec /vt/bin/mysqlctld $(cat <<END_OF_COMMAND
   -tablet_uid "$(cat /vtdataroot/tabletdata/tablet-uid)"
   -socket_file "/vtdataroot/mysqlctl.sock"
   -init_db_sql_file "/vt/config/init_db.sql"
+{{- with .BackupStorage }}
+  {{ template "backupflags" . }}
+{{- end }}
 END_OF_COMMAND
 )
 `
@@ -0,0 +1,24 @@
+package scripts
+
+const (
+	// TopoFlagsTemplate is shared by every component that talks to the topology server.
+	// It is executed against the global VitessLockserver with {{ template "topoflags" .GlobalLockserver }}.
+	// Components find the cell-local lockserver through the cell registered in the global topology, and
+	// connect to it with the same TLS flags, so GlobalLockserver has the credentials of both merged in.
+	TopoFlagsTemplate = `
+{{- define "topoflags" -}}
+  -topo_implementation="{{ .GetType }}"
+  -topo_global_server_address="{{ .GetTopoServerAddress }}"
+  -topo_global_root="{{ .GetTopoRoot }}"
+{{- with .GetEtcdTLSCAFile }}
+  -topo_etcd_tls_ca="{{ . }}"
+{{- end }}
+{{- with .GetEtcdTLSCertFile }}
+  -topo_etcd_tls_cert="{{ . }}"
+{{- end }}
+{{- with .GetEtcdTLSKeyFile }}
+  -topo_etcd_tls_key="{{ . }}"
+{{- end }}
+{{- end -}}
+`
+)
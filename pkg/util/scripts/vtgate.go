@@ -12,11 +12,7 @@ const (
   -tablet_types_to_wait="MASTER,REPLICA"
   -gateway_implementation="discoverygateway"
   -mysql_server_version="5.5.10-Vitess"
-  {{- if eq .LocalLockserver.Spec.Type "etcd2" }}
-  -topo_implementation="etcd2"
-  -topo_global_server_address="{{ .LocalLockserver.Spec.Etcd2.Address }}"
-  -topo_global_root="{{ .LocalLockserver.Spec.Etcd2.Path }}"
-  {{- end }}
+  {{ template "topoflags" .GlobalLockserver }}
   {{- if .Cell.Spec.MySQLProtocol }}
   -mysql_server_port=3306
   {{- if .Cell.Spec.MySQLProtocol.PasswordSecretRef }}
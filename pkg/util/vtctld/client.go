@@ -0,0 +1,275 @@
+package vtctld
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RequestTimeout bounds every vtctl command. InitShardMaster waits for replication to be set up
+// on every tablet of the shard, so it is generous.
+const RequestTimeout = 2 * time.Minute
+
+// healthTimeout bounds the tablet health checks, which are polled for every tablet on each reconcile
+// and must not hold it up when a tablet hangs. It is a variable so that tests can shorten it.
+var healthTimeout = 5 * time.Second
+
+// Client runs vtctl commands through a vtctld
+type Client interface {
+	// ListShardTablets returns every tablet registered in the given keyspace/shard
+	ListShardTablets(keyspaceShard string) ([]Tablet, error)
+
+	// GetShardMaster returns the alias of the shard master, or an empty string if there is none
+	GetShardMaster(keyspaceShard string) (string, error)
+
+	// InitShardMaster makes the given tablet the master of a new shard
+	InitShardMaster(keyspaceShard string, tabletAlias string) error
+
+	// PlannedReparentShard gracefully moves mastership of the shard to the given tablet
+	PlannedReparentShard(keyspaceShard string, tabletAlias string) error
+
+	// GetReplicationLag returns how many seconds the given tablet is behind its master
+	GetReplicationLag(tabletAlias string) (uint32, error)
+
+	// GetTabletHealth returns the health the given tablet reports, even if it is unhealthy. It gives up
+	// after a few seconds on a tablet that doesn't answer.
+	GetTabletHealth(tabletAlias string) (*TabletHealth, error)
+
+	// ListBackups returns the names of the backups of the given keyspace/shard, oldest first
+	ListBackups(keyspaceShard string) ([]string, error)
+
+	// RemoveBackup removes a backup of the given keyspace/shard from the backup storage
+	RemoveBackup(keyspaceShard string, name string) error
+}
+
+// Tablet is a tablet as listed by vtctl
+type Tablet struct {
+	Alias    string
+	Keyspace string
+	Shard    string
+	Type     string
+	Hostname string
+}
+
+// Cell returns the cell part of the tablet alias
+func (t *Tablet) Cell() string {
+	if i := strings.LastIndex(t.Alias, "-"); i >= 0 {
+		return t.Alias[:i]
+	}
+	return ""
+}
+
+// PodName returns the name of the pod the tablet runs in, taken from its hostname
+func (t *Tablet) PodName() string {
+	return strings.SplitN(t.Hostname, ".", 2)[0]
+}
+
+// TabletHealth is the health of a tablet as reported by its health stream
+type TabletHealth struct {
+	// Serving is set when the tablet serves queries
+	Serving bool
+
+	// ReplicationLagSeconds is how many seconds the tablet is behind its master
+	ReplicationLagSeconds uint32
+
+	// HealthError is set when the tablet is unhealthy
+	HealthError string
+}
+
+type httpClient struct {
+	address string
+	client  *http.Client
+}
+
+// NewClient returns a Client that uses the vtctl API on the vtctld web port at the given address
+func NewClient(address string) Client {
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+
+	return &httpClient{
+		address: strings.TrimSuffix(address, "/"),
+		client:  &http.Client{Timeout: RequestTimeout},
+	}
+}
+
+type vtctlResponse struct {
+	Error  string
+	Output string
+}
+
+// vtctl runs a vtctl command through the vtctld /api/vtctl/ endpoint and returns its output
+func (c *httpClient) vtctl(args ...string) (string, error) {
+	return c.vtctlWithTimeout(RequestTimeout, args...)
+}
+
+// vtctlWithTimeout runs a vtctl command like vtctl, giving up after the given timeout
+func (c *httpClient) vtctlWithTimeout(timeout time.Duration, args ...string) (string, error) {
+	body, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequest(http.MethodPost, c.address+"/api/vtctl/", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Unexpected status from vtctld for %s: %s", args[0], httpResp.Status)
+	}
+
+	resp := &vtctlResponse{}
+	if err := json.NewDecoder(httpResp.Body).Decode(resp); err != nil {
+		return "", err
+	}
+
+	if resp.Error != "" {
+		return resp.Output, fmt.Errorf("vtctl %s failed: %s", args[0], resp.Error)
+	}
+
+	return resp.Output, nil
+}
+
+func (c *httpClient) ListShardTablets(keyspaceShard string) ([]Tablet, error) {
+	out, err := c.vtctl("ListShardTablets", keyspaceShard)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseTablets(out), nil
+}
+
+// ParseTablets parses the tablet lines printed by the vtctl List*Tablets commands:
+// <alias> <keyspace> <shard> <type> <hostname:port> <mysql hostname:port> <tags>
+func ParseTablets(out string) []Tablet {
+	tablets := []Tablet{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+
+		tablets = append(tablets, Tablet{
+			Alias:    fields[0],
+			Keyspace: fields[1],
+			Shard:    fields[2],
+			Type:     fields[3],
+			Hostname: strings.SplitN(fields[4], ":", 2)[0],
+		})
+	}
+
+	return tablets
+}
+
+type tabletAlias struct {
+	Cell string `json:"cell"`
+	UID  uint32 `json:"uid"`
+}
+
+func (a *tabletAlias) String() string {
+	return fmt.Sprintf("%s-%010d", a.Cell, a.UID)
+}
+
+type shardRecord struct {
+	MasterAlias *tabletAlias `json:"master_alias"`
+}
+
+func (c *httpClient) GetShardMaster(keyspaceShard string) (string, error) {
+	out, err := c.vtctl("GetShard", keyspaceShard)
+	if err != nil {
+		return "", err
+	}
+
+	shard := &shardRecord{}
+	if err := json.Unmarshal([]byte(out), shard); err != nil {
+		return "", fmt.Errorf("Invalid shard record for %s: %s", keyspaceShard, err)
+	}
+
+	if shard.MasterAlias == nil || shard.MasterAlias.UID == 0 {
+		return "", nil
+	}
+
+	return shard.MasterAlias.String(), nil
+}
+
+func (c *httpClient) InitShardMaster(keyspaceShard string, tabletAlias string) error {
+	_, err := c.vtctl("InitShardMaster", "-force", keyspaceShard, tabletAlias)
+	return err
+}
+
+func (c *httpClient) PlannedReparentShard(keyspaceShard string, tabletAlias string) error {
+	_, err := c.vtctl("PlannedReparentShard", "-keyspace_shard="+keyspaceShard, "-new_master="+tabletAlias)
+	return err
+}
+
+type streamHealthResponse struct {
+	Serving       bool `json:"serving"`
+	RealtimeStats *struct {
+		HealthError         string `json:"health_error"`
+		SecondsBehindMaster uint32 `json:"seconds_behind_master"`
+	} `json:"realtime_stats"`
+}
+
+func (c *httpClient) GetReplicationLag(tabletAlias string) (uint32, error) {
+	health, err := c.GetTabletHealth(tabletAlias)
+	if err != nil {
+		return 0, err
+	}
+
+	if health.HealthError != "" {
+		return 0, fmt.Errorf("Tablet %s is unhealthy: %s", tabletAlias, health.HealthError)
+	}
+
+	return health.ReplicationLagSeconds, nil
+}
+
+func (c *httpClient) GetTabletHealth(tabletAlias string) (*TabletHealth, error) {
+	out, err := c.vtctlWithTimeout(healthTimeout, "VtTabletStreamHealth", "-count", "1", tabletAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	health := &streamHealthResponse{}
+	if err := json.Unmarshal([]byte(out), health); err != nil {
+		return nil, fmt.Errorf("Invalid health record for %s: %s", tabletAlias, err)
+	}
+
+	if health.RealtimeStats == nil {
+		return nil, fmt.Errorf("No health stats for %s", tabletAlias)
+	}
+
+	return &TabletHealth{
+		Serving:               health.Serving,
+		ReplicationLagSeconds: health.RealtimeStats.SecondsBehindMaster,
+		HealthError:           health.RealtimeStats.HealthError,
+	}, nil
+}
+
+func (c *httpClient) ListBackups(keyspaceShard string) ([]string, error) {
+	out, err := c.vtctl("ListBackups", keyspaceShard)
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Fields(out), nil
+}
+
+func (c *httpClient) RemoveBackup(keyspaceShard string, name string) error {
+	_, err := c.vtctl("RemoveBackup", keyspaceShard, name)
+	return err
+}
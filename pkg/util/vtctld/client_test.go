@@ -0,0 +1,143 @@
+package vtctld
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newFakeVtctld answers vtctl API calls with the output for the command name
+func newFakeVtctld(t *testing.T, outputs map[string]vtctlResponse, calls *[][]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/api/vtctl/" {
+			http.NotFound(w, req)
+			return
+		}
+
+		args := []string{}
+		if err := json.NewDecoder(req.Body).Decode(&args); err != nil {
+			t.Fatalf("Error decoding vtctl args: %s", err)
+		}
+		*calls = append(*calls, args)
+
+		json.NewEncoder(w).Encode(outputs[args[0]])
+	}))
+}
+
+func TestClient(t *testing.T) {
+	calls := [][]string{}
+	server := newFakeVtctld(t, map[string]vtctlResponse{
+		"ListShardTablets": {Output: "zone1-0000000101 main 0 replica vt-zone1-main-0-replica-0.vt-tab:15002 vt-zone1-main-0-replica-0.vt-tab:3306 []\n" +
+			"zone2-0000000201 main 0 rdonly vt-zone2-main-0-rdonly-0.vt-tab:15002 vt-zone2-main-0-rdonly-0.vt-tab:3306 []\n"},
+		"GetShard":             {Output: `{"master_alias": {"cell": "zone1", "uid": 101}}`},
+		"InitShardMaster":      {Error: "tablet not found"},
+		"PlannedReparentShard": {},
+		"VtTabletStreamHealth": {Output: `{"serving": true, "realtime_stats": {"seconds_behind_master": 7}}`},
+		"ListBackups":          {Output: "2019-01-30.100000.zone1-0000000101\n2019-01-31.100000.zone2-0000000201\n"},
+		"RemoveBackup":         {},
+	}, &calls)
+	defer server.Close()
+
+	client := NewClient(strings.TrimPrefix(server.URL, "http://"))
+
+	tablets, err := client.ListShardTablets("main/0")
+	if err != nil {
+		t.Fatalf("Error listing tablets: %s", err)
+	}
+
+	if len(tablets) != 2 {
+		t.Fatalf("Wrong tablet count. Got: %d; Expected: 2", len(tablets))
+	}
+
+	if tablets[0].Cell() != "zone1" || tablets[0].Type != "replica" || tablets[0].PodName() != "vt-zone1-main-0-replica-0" {
+		t.Errorf("Tablet not parsed: %+v", tablets[0])
+	}
+
+	master, err := client.GetShardMaster("main/0")
+	if err != nil {
+		t.Fatalf("Error getting shard master: %s", err)
+	}
+
+	if master != "zone1-0000000101" {
+		t.Errorf("Wrong shard master. Got: %s; Expected: zone1-0000000101", master)
+	}
+
+	if err := client.InitShardMaster("main/0", "zone1-0000000101"); err == nil {
+		t.Error("vtctl error was not returned")
+	}
+
+	if got := strings.Join(calls[len(calls)-1], " "); got != "InitShardMaster -force main/0 zone1-0000000101" {
+		t.Errorf("Wrong InitShardMaster command: %s", got)
+	}
+
+	if err := client.PlannedReparentShard("main/0", "zone1-0000000102"); err != nil {
+		t.Errorf("Error reparenting shard: %s", err)
+	}
+
+	if got := strings.Join(calls[len(calls)-1], " "); got != "PlannedReparentShard -keyspace_shard=main/0 -new_master=zone1-0000000102" {
+		t.Errorf("Wrong PlannedReparentShard command: %s", got)
+	}
+
+	lag, err := client.GetReplicationLag("zone1-0000000102")
+	if err != nil {
+		t.Fatalf("Error getting replication lag: %s", err)
+	}
+
+	if lag != 7 {
+		t.Errorf("Wrong replication lag. Got: %d; Expected: 7", lag)
+	}
+
+	health, err := client.GetTabletHealth("zone1-0000000102")
+	if err != nil {
+		t.Fatalf("Error getting tablet health: %s", err)
+	}
+
+	if !health.Serving || health.ReplicationLagSeconds != 7 || health.HealthError != "" {
+		t.Errorf("Tablet health not parsed: %+v", health)
+	}
+
+	backups, err := client.ListBackups("main/0")
+	if err != nil {
+		t.Fatalf("Error listing backups: %s", err)
+	}
+
+	if len(backups) != 2 || backups[1] != "2019-01-31.100000.zone2-0000000201" {
+		t.Errorf("Backups not parsed: %v", backups)
+	}
+
+	if err := client.RemoveBackup("main/0", backups[0]); err != nil {
+		t.Errorf("Error removing backup: %s", err)
+	}
+
+	if got := strings.Join(calls[len(calls)-1], " "); got != "RemoveBackup main/0 2019-01-30.100000.zone1-0000000101" {
+		t.Errorf("Wrong RemoveBackup command: %s", got)
+	}
+}
+
+func TestGetTabletHealthTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { healthTimeout = timeout }(healthTimeout)
+	healthTimeout = 100 * time.Millisecond
+
+	// A hung tablet keeps VtTabletStreamHealth waiting until the caller gives up
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+		case <-done:
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	start := time.Now()
+	if _, err := NewClient(server.URL).GetTabletHealth("zone1-0000000101"); err == nil {
+		t.Error("Health check of a hung tablet did not fail")
+	}
+
+	if elapsed := time.Since(start); elapsed > RequestTimeout/2 {
+		t.Errorf("Health check was not cut short. Took: %s", elapsed)
+	}
+}
@@ -0,0 +1,57 @@
+package vtctld
+
+// FakeClient is a Client for tests. It serves the tablets, master, lags and backups it is given
+// and records the commands that change the shard.
+type FakeClient struct {
+	Tablets []Tablet
+	Master  string
+	Lags    map[string]uint32
+	Backups []string
+
+	// Initialized and Reparented record the "keyspace/shard alias" of every InitShardMaster and
+	// PlannedReparentShard call
+	Initialized []string
+	Reparented  []string
+
+	// Removed records the names of the removed backups
+	Removed []string
+}
+
+var _ Client = &FakeClient{}
+
+func (c *FakeClient) ListShardTablets(keyspaceShard string) ([]Tablet, error) {
+	return c.Tablets, nil
+}
+
+func (c *FakeClient) GetShardMaster(keyspaceShard string) (string, error) {
+	return c.Master, nil
+}
+
+func (c *FakeClient) InitShardMaster(keyspaceShard string, tabletAlias string) error {
+	c.Initialized = append(c.Initialized, keyspaceShard+" "+tabletAlias)
+	c.Master = tabletAlias
+	return nil
+}
+
+func (c *FakeClient) PlannedReparentShard(keyspaceShard string, tabletAlias string) error {
+	c.Reparented = append(c.Reparented, keyspaceShard+" "+tabletAlias)
+	c.Master = tabletAlias
+	return nil
+}
+
+func (c *FakeClient) GetReplicationLag(tabletAlias string) (uint32, error) {
+	return c.Lags[tabletAlias], nil
+}
+
+func (c *FakeClient) GetTabletHealth(tabletAlias string) (*TabletHealth, error) {
+	return &TabletHealth{Serving: true, ReplicationLagSeconds: c.Lags[tabletAlias]}, nil
+}
+
+func (c *FakeClient) ListBackups(keyspaceShard string) ([]string, error) {
+	return c.Backups, nil
+}
+
+func (c *FakeClient) RemoveBackup(keyspaceShard string, name string) error {
+	c.Removed = append(c.Removed, name)
+	return nil
+}
@@ -0,0 +1,10 @@
+package webhook
+
+import (
+	"vitess.io/vitess-operator/pkg/webhook/defaulter"
+)
+
+func init() {
+	// AddToServerFuncs is a list of functions to build webhooks and add them to the admission server.
+	AddToServerFuncs = append(AddToServerFuncs, defaulter.Build)
+}
@@ -0,0 +1,130 @@
+package converter
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+)
+
+// caCertKey is where the webhook server keeps its CA certificate in its Secret
+const caCertKey = "ca-cert.pem"
+
+// injectInterval is how often the CRDs are checked. The webhook server writes its certificate when it starts
+// and whenever it renews it.
+const injectInterval = time.Minute
+
+// CAInjector keeps the CA bundle of the conversion webhooks of the Vitess CRDs in sync with the certificate of
+// the webhook server. Only the CRDs whose conversion webhook names the Service of this operator are updated:
+// the CRDs are cluster-wide, so the namespace set in them when they are deployed picks the one operator that
+// converts their objects.
+type CAInjector struct {
+	client client.Client
+
+	// Service is the webhook Service the API server sends ConversionReviews to
+	Service types.NamespacedName
+
+	// Secret is where the webhook server keeps its certificate
+	Secret types.NamespacedName
+}
+
+var _ manager.Runnable = &CAInjector{}
+
+// NewCAInjector returns a CAInjector with its own uncached client, since the CRDs are cluster-scoped and the
+// cache of the Manager is restricted to the namespace of the operator
+func NewCAInjector(cfg *rest.Config, service, secret types.NamespacedName) (*CAInjector, error) {
+	s := runtime.NewScheme()
+	if err := corev1.AddToScheme(s); err != nil {
+		return nil, err
+	}
+	if err := apiextv1beta1.AddToScheme(s); err != nil {
+		return nil, err
+	}
+
+	c, err := client.New(cfg, client.Options{Scheme: s})
+	if err != nil {
+		return nil, err
+	}
+
+	return &CAInjector{client: c, Service: service, Secret: secret}, nil
+}
+
+// Start updates the CRDs periodically until stop is closed
+func (i *CAInjector) Start(stop <-chan struct{}) error {
+	wait.Until(func() {
+		if err := i.Inject(context.TODO()); err != nil {
+			log.Error(err, "Error injecting the webhook CA bundle into the CRDs")
+		}
+	}, injectInterval, stop)
+	return nil
+}
+
+// Inject sets the current CA certificate on every Vitess CRD that converts through the webhook Service.
+// The CRDs are handled as unstructured objects, since the CRD types the operator is built with don't know
+// every field the API server sets on them, and an update would drop those.
+func (i *CAInjector) Inject(ctx context.Context) error {
+	secret := &corev1.Secret{}
+	if err := i.client.Get(ctx, i.Secret, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			// The webhook server hasn't written its certificate yet
+			return nil
+		}
+		return err
+	}
+	caBundle := secret.Data[caCertKey]
+	if len(caBundle) == 0 {
+		return nil
+	}
+	encodedCABundle := base64.StdEncoding.EncodeToString(caBundle)
+
+	crds := &unstructured.UnstructuredList{}
+	crds.SetGroupVersionKind(apiextv1beta1.SchemeGroupVersion.WithKind("CustomResourceDefinitionList"))
+	if err := i.client.List(ctx, &client.ListOptions{}, crds); err != nil {
+		return err
+	}
+
+	for idx := range crds.Items {
+		crd := &crds.Items[idx]
+		if group, _, _ := unstructured.NestedString(crd.Object, "spec", "group"); group != vitessv1alpha2.SchemeGroupVersion.Group {
+			continue
+		}
+
+		if strategy, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "strategy"); strategy != string(apiextv1beta1.WebhookConverter) {
+			continue
+		}
+
+		config := []string{"spec", "conversion", "webhookClientConfig"}
+		namespace, _, _ := unstructured.NestedString(crd.Object, append(config, "service", "namespace")...)
+		name, _, _ := unstructured.NestedString(crd.Object, append(config, "service", "name")...)
+		if namespace != i.Service.Namespace || name != i.Service.Name {
+			continue
+		}
+
+		if current, _, _ := unstructured.NestedString(crd.Object, append(config, "caBundle")...); current == encodedCABundle {
+			continue
+		}
+
+		if err := unstructured.SetNestedField(crd.Object, encodedCABundle, append(config, "caBundle")...); err != nil {
+			return err
+		}
+		if err := i.client.Update(ctx, crd); err != nil {
+			return fmt.Errorf("Error updating the conversion webhook of %s: %s", crd.GetName(), err)
+		}
+		log.Info(fmt.Sprintf("Updated the CA bundle of the conversion webhook of %s", crd.GetName()))
+	}
+
+	return nil
+}
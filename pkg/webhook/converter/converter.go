@@ -0,0 +1,106 @@
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+
+	"vitess.io/vitess-operator/pkg/util/conversion"
+)
+
+var log = logf.Log.WithName("webhook_converter")
+
+// Converter is the conversion webhook of the Vitess CRDs. It converts the objects of a ConversionReview to
+// the version that the API server asks for.
+type Converter struct {
+	// scheme creates the objects that requests are decoded into and converts them
+	scheme *runtime.Scheme
+}
+
+var _ http.Handler = &Converter{}
+
+// New returns a Converter of the Vitess objects registered in the scheme
+func New(s *runtime.Scheme) *Converter {
+	return &Converter{scheme: s}
+}
+
+// ServeHTTP answers a ConversionReview with the converted objects, or with the error converting them
+func (c *Converter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	review := &apiextv1beta1.ConversionReview{}
+	if err := json.NewDecoder(r.Body).Decode(review); err != nil {
+		http.Error(w, fmt.Sprintf("Error decoding the ConversionReview: %s", err), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "ConversionReview has no request", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = c.Convert(review.Request)
+	review.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		log.Error(err, "Error encoding the ConversionReview")
+	}
+}
+
+// Convert converts the objects of a request. Either all of them are converted or the response is a failure.
+func (c *Converter) Convert(req *apiextv1beta1.ConversionRequest) *apiextv1beta1.ConversionResponse {
+	resp := &apiextv1beta1.ConversionResponse{
+		UID:    req.UID,
+		Result: metav1.Status{Status: metav1.StatusSuccess},
+	}
+
+	gv, err := schema.ParseGroupVersion(req.DesiredAPIVersion)
+	if err != nil {
+		return failed(resp, err)
+	}
+
+	for _, obj := range req.Objects {
+		converted, err := c.convertObject(obj.Raw, gv)
+		if err != nil {
+			return failed(resp, err)
+		}
+		// RawExtensions are encoded from Raw, never from Object
+		resp.ConvertedObjects = append(resp.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+
+	return resp
+}
+
+// convertObject converts the JSON of an object to the given version
+func (c *Converter) convertObject(raw []byte, gv schema.GroupVersion) ([]byte, error) {
+	typeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, err
+	}
+
+	obj, err := c.scheme.New(typeMeta.GroupVersionKind())
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, obj); err != nil {
+		return nil, err
+	}
+
+	converted, err := conversion.Convert(c.scheme, obj, gv)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(converted)
+}
+
+func failed(resp *apiextv1beta1.ConversionResponse, err error) *apiextv1beta1.ConversionResponse {
+	log.Info(fmt.Sprintf("Rejecting conversion %s: %s", resp.UID, err))
+	resp.ConvertedObjects = nil
+	resp.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+	return resp
+}
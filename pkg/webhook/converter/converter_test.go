@@ -0,0 +1,165 @@
+package converter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"vitess.io/vitess-operator/pkg/apis"
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	vitessv1beta1 "vitess.io/vitess-operator/pkg/apis/vitess/v1beta1"
+)
+
+func TestConverter(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := apis.AddToScheme(s); err != nil {
+		t.Fatalf("Error registering types: %s", err)
+	}
+
+	c := New(s)
+
+	tablet := &vitessv1alpha2.VitessTablet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: vitessv1alpha2.SchemeGroupVersion.String(), Kind: "VitessTablet"},
+		ObjectMeta: metav1.ObjectMeta{Name: "zone1-unsharded-replica", Namespace: "vitess"},
+		Spec: vitessv1alpha2.VitessTabletSpec{
+			TabletID: 101,
+			CellID:   "zone1",
+			Type:     vitessv1alpha2.TabletTypeReplica,
+		},
+	}
+	raw, err := json.Marshal(tablet)
+	if err != nil {
+		t.Fatalf("Error encoding tablet: %s", err)
+	}
+
+	review := func(desiredAPIVersion string) *apiextv1beta1.ConversionReview {
+		encoded, err := json.Marshal(&apiextv1beta1.ConversionReview{Request: &apiextv1beta1.ConversionRequest{
+			UID:               "uid",
+			DesiredAPIVersion: desiredAPIVersion,
+			Objects:           []runtime.RawExtension{{Raw: raw}},
+		}})
+		if err != nil {
+			t.Fatalf("Error encoding ConversionReview: %s", err)
+		}
+
+		w := httptest.NewRecorder()
+		c.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/convert", bytes.NewReader(encoded)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Wrong status code. Got: %d; Expected: %d", w.Code, http.StatusOK)
+		}
+
+		decoded := &apiextv1beta1.ConversionReview{}
+		if err := json.Unmarshal(w.Body.Bytes(), decoded); err != nil {
+			t.Fatalf("Error decoding ConversionReview: %s", err)
+		}
+		if decoded.Response == nil || decoded.Response.UID != "uid" {
+			t.Fatalf("Wrong response: %+v", decoded.Response)
+		}
+		return decoded
+	}
+
+	resp := review(vitessv1beta1.SchemeGroupVersion.String()).Response
+	if resp.Result.Status != metav1.StatusSuccess || len(resp.ConvertedObjects) != 1 {
+		t.Fatalf("Conversion failed: %+v", resp.Result)
+	}
+
+	converted := &vitessv1beta1.VitessTablet{}
+	if err := json.Unmarshal(resp.ConvertedObjects[0].Raw, converted); err != nil {
+		t.Fatalf("Error decoding converted tablet: %s", err)
+	}
+	if converted.APIVersion != vitessv1beta1.SchemeGroupVersion.String() || converted.Kind != "VitessTablet" {
+		t.Errorf("Wrong type of converted tablet: %s", converted.GroupVersionKind())
+	}
+	if converted.Name != tablet.Name || converted.Spec.Cell != "zone1" || converted.Spec.TabletID != 101 || converted.Spec.Type != vitessv1beta1.TabletTypeReplica {
+		t.Errorf("Tablet not converted: %+v", converted)
+	}
+
+	// Versions of other groups can't be converted to
+	resp = review("apps/v1").Response
+	if resp.Result.Status != metav1.StatusFailure || len(resp.ConvertedObjects) != 0 {
+		t.Errorf("Conversion to another group didn't fail: %+v", resp)
+	}
+}
+
+func TestCAInjector(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := corev1.AddToScheme(s); err != nil {
+		t.Fatalf("Error registering types: %s", err)
+	}
+	if err := apiextv1beta1.AddToScheme(s); err != nil {
+		t.Fatalf("Error registering types: %s", err)
+	}
+
+	path := "/convert"
+	newCRD := func(name, group, namespace string) *apiextv1beta1.CustomResourceDefinition {
+		return &apiextv1beta1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: apiextv1beta1.CustomResourceDefinitionSpec{
+				Group: group,
+				Conversion: &apiextv1beta1.CustomResourceConversion{
+					Strategy: apiextv1beta1.WebhookConverter,
+					WebhookClientConfig: &apiextv1beta1.WebhookClientConfig{
+						Service: &apiextv1beta1.ServiceReference{Namespace: namespace, Name: "vitess-operator-webhook", Path: &path},
+					},
+				},
+			},
+		}
+	}
+	crd := newCRD("vitesstablets.vitess.io", "vitess.io", "vitess")
+	// Another operator converts the objects of the CRDs that point at its namespace
+	owned := newCRD("vitesscells.vitess.io", "vitess.io", "vitess-staging")
+	other := newCRD("widgets.example.com", "example.com", "vitess")
+
+	c := fake.NewFakeClientWithScheme(s, crd, owned, other)
+	i := &CAInjector{
+		client:  c,
+		Service: types.NamespacedName{Namespace: "vitess", Name: "vitess-operator-webhook"},
+		Secret:  types.NamespacedName{Namespace: "vitess", Name: "vitess-operator-webhook-cert"},
+	}
+
+	// Nothing is injected before the webhook server writes its certificate
+	if err := i.Inject(context.TODO()); err != nil {
+		t.Fatalf("Error injecting without a certificate: %s", err)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "vitess", Name: "vitess-operator-webhook-cert"},
+		Data:       map[string][]byte{caCertKey: []byte("ca")},
+	}
+	if err := c.Create(context.TODO(), secret); err != nil {
+		t.Fatalf("Error creating secret: %s", err)
+	}
+
+	if err := i.Inject(context.TODO()); err != nil {
+		t.Fatalf("Error injecting: %s", err)
+	}
+
+	updated := &apiextv1beta1.CustomResourceDefinition{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: crd.Name}, updated); err != nil {
+		t.Fatalf("Error getting CRD: %s", err)
+	}
+	config := updated.Spec.Conversion.WebhookClientConfig
+	if config.Service.Namespace != "vitess" || string(config.CABundle) != "ca" || *config.Service.Path != path {
+		t.Errorf("Conversion webhook not injected: %+v", config)
+	}
+
+	for _, name := range []string{owned.Name, other.Name} {
+		untouched := &apiextv1beta1.CustomResourceDefinition{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: name}, untouched); err != nil {
+			t.Fatalf("Error getting CRD: %s", err)
+		}
+		if config := untouched.Spec.Conversion.WebhookClientConfig; len(config.CABundle) != 0 {
+			t.Errorf("Conversion webhook of %s injected: %+v", name, config)
+		}
+	}
+}
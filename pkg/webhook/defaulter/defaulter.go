@@ -0,0 +1,119 @@
+package defaulter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/builder"
+	atypes "sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	vitessv1beta1 "vitess.io/vitess-operator/pkg/apis/vitess/v1beta1"
+	"vitess.io/vitess-operator/pkg/normalizer"
+	"vitess.io/vitess-operator/pkg/util/conversion"
+)
+
+// kinds are the Vitess objects that get their defaults set on admission. Their webhooks and resources are
+// named after them.
+var kinds = []string{"vitesscluster", "vitesscell", "vitesskeyspace", "vitessshard", "vitesstablet", "vitesslockserver"}
+
+// Build returns a mutating webhook for every kind of Vitess object that the normalizer sets defaults on. The
+// webhooks get the objects of every served version, and set the defaults on a v1alpha2 copy of them.
+func Build(mgr manager.Manager) ([]webhook.Webhook, error) {
+	webhooks := []webhook.Webhook{}
+	for _, name := range kinds {
+		// The failure policy is left to the Ignore default. Objects stored without their defaults
+		// still work since the operator falls back to the same defaults.
+		wh, err := builder.NewWebhookBuilder().
+			Name(fmt.Sprintf("%s.mutating.vitess.io", name)).
+			Mutating().
+			Rules(admissionregistrationv1beta1.RuleWithOperations{
+				Operations: []admissionregistrationv1beta1.OperationType{admissionregistrationv1beta1.Create, admissionregistrationv1beta1.Update},
+				Rule: admissionregistrationv1beta1.Rule{
+					APIGroups:   []string{vitessv1alpha2.SchemeGroupVersion.Group},
+					APIVersions: []string{vitessv1alpha2.SchemeGroupVersion.Version, vitessv1beta1.SchemeGroupVersion.Version},
+					Resources:   []string{name + "s"},
+				},
+			}).
+			WithManager(mgr).
+			Handlers(&Defaulter{scheme: mgr.GetScheme()}).
+			Build()
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, wh)
+	}
+
+	return webhooks, nil
+}
+
+// Defaulter writes the defaults of Vitess objects into their spec
+type Defaulter struct {
+	client  client.Client
+	decoder atypes.Decoder
+
+	// scheme creates the objects that requests are decoded into and converts them
+	scheme *runtime.Scheme
+}
+
+var _ admission.Handler = &Defaulter{}
+
+// Handle decodes the object of the request and patches in its defaults
+func (d *Defaulter) Handle(ctx context.Context, req atypes.Request) atypes.Response {
+	gvk := schema.GroupVersionKind(req.AdmissionRequest.Kind)
+	obj, err := d.scheme.New(gvk)
+	if err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+	if err := d.decoder.Decode(req, obj); err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+
+	// Objects being deleted only get their finalizers removed
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+	if accessor.GetDeletionTimestamp() != nil {
+		return admission.PatchResponse(obj, obj)
+	}
+
+	hub, err := conversion.Convert(d.scheme, obj.DeepCopyObject(), vitessv1alpha2.SchemeGroupVersion)
+	if err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+
+	n := normalizer.New(d.client)
+	if err := n.SetDefaults(hub); err != nil {
+		return admission.ErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	// The patch is against the object in the version of the request
+	defaulted, err := conversion.Convert(d.scheme, hub, gvk.GroupVersion())
+	if err != nil {
+		return admission.ErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return admission.PatchResponse(obj, defaulted)
+}
+
+// InjectClient is called by the Manager and provides the client of the normalizer
+func (d *Defaulter) InjectClient(c client.Client) error {
+	d.client = c
+	return nil
+}
+
+// InjectDecoder is called by the Manager and provides the decoder of the requests
+func (d *Defaulter) InjectDecoder(dec atypes.Decoder) error {
+	d.decoder = dec
+	return nil
+}
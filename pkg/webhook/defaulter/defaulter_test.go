@@ -0,0 +1,84 @@
+package defaulter
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	atypes "sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+
+	"vitess.io/vitess-operator/pkg/apis"
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	vitessv1beta1 "vitess.io/vitess-operator/pkg/apis/vitess/v1beta1"
+)
+
+func TestDefaulter(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := apis.AddToScheme(s); err != nil {
+		t.Fatalf("Error registering types: %s", err)
+	}
+
+	decoder, err := admission.NewDecoder(s)
+	if err != nil {
+		t.Fatalf("Error creating decoder: %s", err)
+	}
+
+	d := &Defaulter{scheme: s}
+	d.InjectClient(fake.NewFakeClient())
+	d.InjectDecoder(decoder)
+
+	handle := func(tablet runtime.Object) map[string]interface{} {
+		raw, err := json.Marshal(tablet)
+		if err != nil {
+			t.Fatalf("Error encoding tablet: %s", err)
+		}
+
+		resp := d.Handle(context.TODO(), atypes.Request{AdmissionRequest: &admissionv1beta1.AdmissionRequest{
+			Kind:      metav1.GroupVersionKind(tablet.GetObjectKind().GroupVersionKind()),
+			Operation: admissionv1beta1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		}})
+
+		if !resp.Response.Allowed {
+			t.Fatalf("Tablet rejected: %v", resp.Response.Result)
+		}
+
+		patched := map[string]interface{}{}
+		for _, patch := range resp.Patches {
+			patched[patch.Path] = patch.Value
+		}
+		return patched
+	}
+
+	tablet := &vitessv1alpha2.VitessTablet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: vitessv1alpha2.SchemeGroupVersion.String(), Kind: "VitessTablet"},
+		ObjectMeta: metav1.ObjectMeta{Name: "replica", Namespace: "vitess"},
+		Spec: vitessv1alpha2.VitessTabletSpec{
+			CellID: "zone1",
+		},
+	}
+
+	patched := handle(tablet)
+	if len(patched) != 2 || patched["/spec/type"] != string(vitessv1alpha2.TabletTypeReplica) || patched["/spec/datastore/type"] != string(vitessv1alpha2.TabletDatastoreTypeLocal) {
+		t.Errorf("Tablet defaults not patched in: %v", patched)
+	}
+
+	// v1beta1 objects are patched in their own version
+	betaTablet := &vitessv1beta1.VitessTablet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: vitessv1beta1.SchemeGroupVersion.String(), Kind: "VitessTablet"},
+		ObjectMeta: tablet.ObjectMeta,
+		Spec: vitessv1beta1.VitessTabletSpec{
+			Cell: "zone1",
+		},
+	}
+
+	patched = handle(betaTablet)
+	if len(patched) != 2 || patched["/spec/type"] != string(vitessv1beta1.TabletTypeReplica) || patched["/spec/datastore/type"] != string(vitessv1beta1.TabletDatastoreTypeLocal) {
+		t.Errorf("v1beta1 tablet defaults not patched in: %v", patched)
+	}
+}
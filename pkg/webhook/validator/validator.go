@@ -0,0 +1,115 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/builder"
+	atypes "sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	vitessv1beta1 "vitess.io/vitess-operator/pkg/apis/vitess/v1beta1"
+	"vitess.io/vitess-operator/pkg/normalizer"
+	"vitess.io/vitess-operator/pkg/util/conversion"
+)
+
+var log = logf.Log.WithName("webhook_validator")
+
+// kinds are the Vitess objects that are validated on admission. Their webhooks and resources are named after them.
+var kinds = []string{"vitesscluster", "vitesscell", "vitesskeyspace", "vitessshard", "vitesstablet", "vitesslockserver"}
+
+// Build returns a validating webhook for every kind of Vitess object that the normalizer checks. The webhooks
+// get the objects of every served version, which are converted to v1alpha2 before they are validated.
+func Build(mgr manager.Manager) ([]webhook.Webhook, error) {
+	webhooks := []webhook.Webhook{}
+	for _, name := range kinds {
+		// The failure policy is left to the Ignore default so that Vitess objects can still be
+		// changed while the operator is down
+		wh, err := builder.NewWebhookBuilder().
+			Name(fmt.Sprintf("%s.validating.vitess.io", name)).
+			Validating().
+			Rules(admissionregistrationv1beta1.RuleWithOperations{
+				Operations: []admissionregistrationv1beta1.OperationType{admissionregistrationv1beta1.Create, admissionregistrationv1beta1.Update},
+				Rule: admissionregistrationv1beta1.Rule{
+					APIGroups:   []string{vitessv1alpha2.SchemeGroupVersion.Group},
+					APIVersions: []string{vitessv1alpha2.SchemeGroupVersion.Version, vitessv1beta1.SchemeGroupVersion.Version},
+					Resources:   []string{name + "s"},
+				},
+			}).
+			WithManager(mgr).
+			Handlers(&Validator{scheme: mgr.GetScheme()}).
+			Build()
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, wh)
+	}
+
+	return webhooks, nil
+}
+
+// Validator rejects Vitess objects that fail the sanity and validation checks of the normalizer
+type Validator struct {
+	client  client.Client
+	decoder atypes.Decoder
+
+	// scheme creates the objects that requests are decoded into and converts them
+	scheme *runtime.Scheme
+}
+
+var _ admission.Handler = &Validator{}
+
+// Handle decodes the object of the request and validates it
+func (v *Validator) Handle(ctx context.Context, req atypes.Request) atypes.Response {
+	obj, err := v.scheme.New(schema.GroupVersionKind(req.AdmissionRequest.Kind))
+	if err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+	if err := v.decoder.Decode(req, obj); err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+
+	// Objects being deleted only get their finalizers removed
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+	if accessor.GetDeletionTimestamp() != nil {
+		return admission.ValidationResponse(true, "")
+	}
+
+	hub, err := conversion.Convert(v.scheme, obj, vitessv1alpha2.SchemeGroupVersion)
+	if err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+
+	n := normalizer.New(v.client)
+	if err := n.ValidateObject(hub); err != nil {
+		log.Info(fmt.Sprintf("Rejecting %s %s/%s: %s", req.AdmissionRequest.Kind.Kind, req.AdmissionRequest.Namespace, req.AdmissionRequest.Name, err))
+		return admission.ValidationResponse(false, err.Error())
+	}
+
+	return admission.ValidationResponse(true, "")
+}
+
+// InjectClient is called by the Manager and provides the client used to resolve references and selectors
+func (v *Validator) InjectClient(c client.Client) error {
+	v.client = c
+	return nil
+}
+
+// InjectDecoder is called by the Manager and provides the decoder of the requests
+func (v *Validator) InjectDecoder(d atypes.Decoder) error {
+	v.decoder = d
+	return nil
+}
@@ -0,0 +1,89 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	atypes "sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+
+	"vitess.io/vitess-operator/pkg/apis"
+	vitessv1alpha2 "vitess.io/vitess-operator/pkg/apis/vitess/v1alpha2"
+	vitessv1beta1 "vitess.io/vitess-operator/pkg/apis/vitess/v1beta1"
+)
+
+func TestValidator(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := apis.AddToScheme(s); err != nil {
+		t.Fatalf("Error registering types: %s", err)
+	}
+
+	decoder, err := admission.NewDecoder(s)
+	if err != nil {
+		t.Fatalf("Error creating decoder: %s", err)
+	}
+
+	v := &Validator{scheme: s}
+	v.InjectClient(fake.NewFakeClient())
+	v.InjectDecoder(decoder)
+
+	handle := func(cell runtime.Object) *admissionv1beta1.AdmissionResponse {
+		raw, err := json.Marshal(cell)
+		if err != nil {
+			t.Fatalf("Error encoding cell: %s", err)
+		}
+
+		return v.Handle(context.TODO(), atypes.Request{AdmissionRequest: &admissionv1beta1.AdmissionRequest{
+			Kind:      metav1.GroupVersionKind(cell.GetObjectKind().GroupVersionKind()),
+			Operation: admissionv1beta1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		}}).Response
+	}
+
+	cell := &vitessv1alpha2.VitessCell{
+		TypeMeta:   metav1.TypeMeta{APIVersion: vitessv1alpha2.SchemeGroupVersion.String(), Kind: "VitessCell"},
+		ObjectMeta: metav1.ObjectMeta{Name: "zone1", Namespace: "vitess"},
+		Spec: vitessv1alpha2.VitessCellSpec{
+			LockserverRef: &corev1.LocalObjectReference{Name: "zone1"},
+		},
+	}
+
+	if resp := handle(cell); !resp.Allowed {
+		t.Errorf("Valid cell rejected: %v", resp.Result)
+	}
+
+	// Lockserver and LockserverRef are mutually exclusive
+	cell.Spec.Lockserver = &vitessv1alpha2.VitessLockserver{}
+
+	if resp := handle(cell); resp.Allowed {
+		t.Error("Cell with both a lockserver and a lockserverRef allowed")
+	}
+
+	// v1beta1 objects are validated once converted to v1alpha2
+	betaCell := &vitessv1beta1.VitessCell{
+		TypeMeta:   metav1.TypeMeta{APIVersion: vitessv1beta1.SchemeGroupVersion.String(), Kind: "VitessCell"},
+		ObjectMeta: cell.ObjectMeta,
+		Spec: vitessv1beta1.VitessCellSpec{
+			Lockserver:    &vitessv1beta1.VitessLockserver{},
+			LockserverRef: &corev1.LocalObjectReference{Name: "zone1"},
+		},
+	}
+
+	if resp := handle(betaCell); resp.Allowed {
+		t.Error("v1beta1 cell with both a lockserver and a lockserverRef allowed")
+	}
+
+	// Objects being deleted aren't validated so that their finalizers can be removed
+	now := metav1.Now()
+	cell.DeletionTimestamp = &now
+
+	if resp := handle(cell); !resp.Allowed {
+		t.Errorf("Deleted cell rejected: %v", resp.Result)
+	}
+}
@@ -0,0 +1,125 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"vitess.io/vitess-operator/pkg/crd"
+	"vitess.io/vitess-operator/pkg/webhook/converter"
+)
+
+// Port is where the webhook server listens. The webhook Service forwards port 443 to it.
+const Port = 9876
+
+// NamespaceLabel is set on the namespace of the operator to the name of the namespace. The admission webhooks
+// select namespaces by it, since namespaces have no label with their name that a selector could match.
+const NamespaceLabel = "vitess.io/operator-namespace"
+
+// AddToServerFuncs is a list of functions to build the webhooks served by the admission server
+var AddToServerFuncs []func(manager.Manager) ([]webhook.Webhook, error)
+
+// AddToManager adds the admission webhook server to the Manager. The server keeps its certificate in a Secret
+// and registers itself through a Service selecting the operator pods and a Mutating and a ValidatingWebhookConfiguration, all
+// named after the namespace so that operators in different namespaces don't overwrite each other's webhooks.
+// The admission webhooks only get the objects of the namespace the operator watches.
+// The server also serves the conversion webhook of the Vitess CRDs. Since CRDs are cluster-wide, only the
+// operator whose Service they name converts objects and keeps their CA bundle up to date.
+func AddToManager(m manager.Manager, namespace string) error {
+	name := fmt.Sprintf("vitess-operator-%s", namespace)
+	secret := types.NamespacedName{Namespace: namespace, Name: "vitess-operator-webhook-cert"}
+	service := types.NamespacedName{Namespace: namespace, Name: crd.ConversionServiceName}
+
+	server, err := webhook.NewServer("vitess-operator-admission-server", m, webhook.ServerOptions{
+		Port:    Port,
+		CertDir: "/tmp/cert",
+		BootstrapOptions: &webhook.BootstrapOptions{
+			MutatingWebhookConfigName:   name,
+			ValidatingWebhookConfigName: name,
+			Secret:                      &secret,
+			Service: &webhook.Service{
+				Namespace: service.Namespace,
+				Name:      service.Name,
+				Selectors: map[string]string{
+					"name": "vitess-operator",
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	webhooks := []webhook.Webhook{}
+	for _, f := range AddToServerFuncs {
+		built, err := f(m)
+		if err != nil {
+			return err
+		}
+		webhooks = append(webhooks, built...)
+	}
+
+	if namespace != "" {
+		c, err := client.New(m.GetConfig(), client.Options{Scheme: m.GetScheme()})
+		if err != nil {
+			return err
+		}
+		if err := labelNamespace(c, namespace); err != nil {
+			return err
+		}
+		scopeToNamespace(webhooks, namespace)
+	}
+
+	server.Handle(crd.ConversionPath, converter.New(m.GetScheme()))
+
+	injector, err := converter.NewCAInjector(m.GetConfig(), service, secret)
+	if err != nil {
+		return err
+	}
+	if err := m.Add(injector); err != nil {
+		return err
+	}
+
+	return server.Register(webhooks...)
+}
+
+// labelNamespace sets the NamespaceLabel on the namespace. The client of the Manager can't be used yet
+// since its cache hasn't started.
+func labelNamespace(c client.Client, namespace string) error {
+	ns := &corev1.Namespace{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: namespace}, ns); err != nil {
+		return fmt.Errorf("Error getting namespace %s: %s", namespace, err)
+	}
+
+	if ns.Labels[NamespaceLabel] == namespace {
+		return nil
+	}
+
+	if ns.Labels == nil {
+		ns.Labels = map[string]string{}
+	}
+	ns.Labels[NamespaceLabel] = namespace
+	if err := c.Update(context.TODO(), ns); err != nil {
+		return fmt.Errorf("Error labeling namespace %s: %s", namespace, err)
+	}
+
+	return nil
+}
+
+// scopeToNamespace restricts the admission webhooks to the objects of the namespace
+func scopeToNamespace(webhooks []webhook.Webhook, namespace string) {
+	for _, wh := range webhooks {
+		if admissionWebhook, ok := wh.(*admission.Webhook); ok {
+			admissionWebhook.NamespaceSelector = &metav1.LabelSelector{
+				MatchLabels: map[string]string{NamespaceLabel: namespace},
+			}
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// TestScopeToNamespace makes sure that the admission webhooks only select the labeled namespace of the operator
+func TestScopeToNamespace(t *testing.T) {
+	c := fake.NewFakeClient(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "vitess", Labels: map[string]string{"team": "db"}},
+	})
+
+	if err := labelNamespace(c, "vitess"); err != nil {
+		t.Fatalf("Error labeling namespace: %s", err)
+	}
+
+	ns := &corev1.Namespace{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: "vitess"}, ns); err != nil {
+		t.Fatalf("Error getting namespace: %s", err)
+	}
+
+	if ns.Labels[NamespaceLabel] != "vitess" || ns.Labels["team"] != "db" {
+		t.Errorf("Wrong namespace labels: %v", ns.Labels)
+	}
+
+	wh := &admission.Webhook{Name: "vitesscluster.validating.vitess.io"}
+	scopeToNamespace([]webhook.Webhook{wh}, "vitess")
+
+	if wh.NamespaceSelector == nil || wh.NamespaceSelector.MatchLabels[NamespaceLabel] != "vitess" {
+		t.Errorf("Webhook not scoped to the namespace: %v", wh.NamespaceSelector)
+	}
+}